@@ -0,0 +1,82 @@
+package ai
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+// context_file_max_size is the maximum number of bytes of a file injected into the context, the rest being truncated.
+const context_file_max_size = 32 * 1024
+
+// ErrNotATextFile is returned when the file injected into the context is not a text file.
+var ErrNotATextFile = errors.New("not a text file")
+
+// contextFile is a file injected into the context of the conversation.
+type contextFile struct {
+	path    string // The path of the file, as given by the user.
+	content string // The content of the file, truncated to context_file_max_size.
+}
+
+// InjectFileContext injects the content of a local text file, "~" being expanded, into the context of the
+// conversation. The files are kept when the conversation is reset, and injecting a file again replaces its content.
+func (e *Engine) InjectFileContext(path string) error {
+	expanded, err := homedir.Expand(path)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(expanded)
+	if err != nil {
+		return err
+	}
+	if bytes.IndexByte(content, 0) >= 0 || !utf8.Valid(content) {
+		return ErrNotATextFile
+	}
+
+	text := string(content)
+	if len(content) > context_file_max_size {
+		// Cut on a rune boundary
+		cut := context_file_max_size
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		text = fmt.Sprintf("%s\n[... %d bytes truncated ...]", content[:cut], len(content)-cut)
+	}
+
+	for i, file := range e.contextFiles {
+		if file.path == path {
+			e.contextFiles[i].content = text
+			return nil
+		}
+	}
+	e.contextFiles = append(e.contextFiles, contextFile{path: path, content: text})
+
+	return nil
+}
+
+// GetContextFiles returns the paths of the files injected into the context of the conversation.
+func (e *Engine) GetContextFiles() []string {
+	paths := make([]string, 0, len(e.contextFiles))
+	for _, file := range e.contextFiles {
+		paths = append(paths, file.path)
+	}
+
+	return paths
+}
+
+// prepareContextFilesPrompt prepares the prompt giving the content of the files injected into the context.
+func (e *Engine) prepareContextFilesPrompt() string {
+	var prompt strings.Builder
+	prompt.WriteString("Take into account the content of the following files of my context:")
+	for _, file := range e.contextFiles {
+		prompt.WriteString(fmt.Sprintf("\n\n%s:\n```\n%s\n```", file.path, strings.TrimRight(file.content, "\n")))
+	}
+
+	return prompt.String()
+}
@@ -0,0 +1,67 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineContextFiles(t *testing.T) {
+	t.Run("InjectFileContext", testInjectFileContext)
+	t.Run("InjectFileContextTruncated", testInjectFileContextTruncated)
+	t.Run("PrepareCompletionMessagesContextFiles", testPrepareCompletionMessagesContextFiles)
+}
+
+// testInjectFileContext tests the InjectFileContext method of the Engine type.
+func testInjectFileContext(t *testing.T) {
+	dir := t.TempDir()
+	readme := filepath.Join(dir, "README.md")
+	require.NoError(t, os.WriteFile(readme, []byte("# project"), 0600))
+	binary := filepath.Join(dir, "app")
+	require.NoError(t, os.WriteFile(binary, []byte("\x7fELF\x00\x00"), 0600))
+
+	e := &Engine{}
+	require.NoError(t, e.InjectFileContext(readme))
+	assert.Equal(t, []string{readme}, e.GetContextFiles())
+
+	require.NoError(t, os.WriteFile(readme, []byte("# renamed project"), 0600))
+	require.NoError(t, e.InjectFileContext(readme))
+	assert.Len(t, e.GetContextFiles(), 1, "Injecting a file again should replace it.")
+	assert.Contains(t, e.prepareContextFilesPrompt(), "# renamed project")
+
+	assert.ErrorIs(t, e.InjectFileContext(binary), ErrNotATextFile, "The binary files should be rejected.")
+	assert.Error(t, e.InjectFileContext(filepath.Join(dir, "missing")))
+	assert.Len(t, e.GetContextFiles(), 1, "The rejected files should not be injected.")
+
+	e.Reset()
+	assert.Len(t, e.GetContextFiles(), 1, "The files should be kept on reset.")
+}
+
+// testInjectFileContextTruncated tests that the large files are truncated.
+func testInjectFileContextTruncated(t *testing.T) {
+	large := filepath.Join(t.TempDir(), "large.log")
+	require.NoError(t, os.WriteFile(large, []byte(strings.Repeat("é", context_file_max_size)), 0600))
+
+	e := &Engine{}
+	require.NoError(t, e.InjectFileContext(large))
+	assert.Contains(t, e.contextFiles[0].content, "bytes truncated")
+	assert.LessOrEqual(t, len(e.contextFiles[0].content), context_file_max_size+64)
+}
+
+// testPrepareCompletionMessagesContextFiles tests that the injected files are sent before the conversation.
+func testPrepareCompletionMessagesContextFiles(t *testing.T) {
+	e := newTestEngine(t, ChatEngineMode, nil)
+	e.contextFiles = []contextFile{{path: ".env", content: "PORT=8080\n"}}
+	e.appendUserMessage("which port?")
+
+	messages := e.prepareCompletionMessages()
+	require.Len(t, messages, 3)
+	assert.Equal(t, openai.ChatMessageRoleUser, messages[1].Role)
+	assert.Contains(t, messages[1].Content, ".env:\n```\nPORT=8080\n```")
+	assert.Equal(t, "which port?", messages[2].Content)
+}
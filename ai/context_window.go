@@ -0,0 +1,41 @@
+package ai
+
+import "strings"
+
+// modelContextWindows are the sizes in tokens of the context windows of the known models, the dated versions of
+// a model having the context window of the model. The models API does not tell them.
+var modelContextWindows = map[string]int{
+	"gpt-3.5-turbo":        16385,
+	"gpt-3.5-turbo-0301":   4096,
+	"gpt-3.5-turbo-0613":   4096,
+	"gpt-3.5-turbo-16k":    16385,
+	"gpt-4":                8192,
+	"gpt-4-32k":            32768,
+	"gpt-4-0125-preview":   128000,
+	"gpt-4-1106-preview":   128000,
+	"gpt-4-vision-preview": 128000,
+	"gpt-4-turbo":          128000,
+	"gpt-4o":               128000,
+	"gpt-4o-mini":          128000,
+}
+
+// findModelContextWindow returns the size of the context window of a model, or of the longest known model prefixing
+// its name. The fine-tuned models, named like ft:gpt-4o-mini-2024-07-18:org::id, have the context window of their
+// base model.
+func findModelContextWindow(model string) (int, bool) {
+	if strings.HasPrefix(model, "ft:") {
+		model = strings.SplitN(strings.TrimPrefix(model, "ft:"), ":", 2)[0]
+	}
+
+	var found string
+	for name := range modelContextWindows {
+		if (model == name || strings.HasPrefix(model, name+"-")) && len(name) > len(found) {
+			found = name
+		}
+	}
+	if found == "" {
+		return 0, false
+	}
+
+	return modelContextWindows[found], true
+}
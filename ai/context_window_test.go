@@ -0,0 +1,73 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextWindow(t *testing.T) {
+	t.Run("FindModelContextWindow", testFindModelContextWindow)
+	t.Run("Detect", testDetectContextWindow)
+	t.Run("MaxContextTokens", testMaxContextTokens)
+}
+
+// testFindModelContextWindow tests that the context window of a model is the one of the longest known model
+// prefixing its name, the fine-tuned models having the one of their base model.
+func testFindModelContextWindow(t *testing.T) {
+	testCases := []struct {
+		model    string
+		expected int
+		known    bool
+	}{
+		{"gpt-4o", 128000, true},
+		{"gpt-4o-mini-2024-07-18", 128000, true},
+		{"gpt-4-0613", 8192, true},
+		{"gpt-4-1106-preview", 128000, true},
+		{"gpt-3.5-turbo-0613", 4096, true},
+		{"ft:gpt-3.5-turbo-0125:acme::abc123", 16385, true},
+		{"llama3", 0, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.model, func(t *testing.T) {
+			size, ok := findModelContextWindow(tc.model)
+			assert.Equal(t, tc.known, ok)
+			assert.Equal(t, tc.expected, size)
+		})
+	}
+}
+
+// testDetectContextWindow tests that the context window of the model is detected once it is retrieved, a model
+// derived from another one having the context window of its root.
+func testDetectContextWindow(t *testing.T) {
+	e := newTestEngine(t, ExecEngineMode, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": "gpt-test", "object": "model", "root": "gpt-4-0613"}`)
+	})
+	assert.Zero(t, e.GetContextWindow(), "The context window of an unknown model should not be known.")
+
+	require.NoError(t, e.PreloadModel(context.Background()))
+	assert.Equal(t, 8192, e.GetContextWindow(), "The context window of the root of the model should be detected.")
+	assert.Equal(t, 8192, e.GetMaxContextTokens(), "The detected context window should be used.")
+	assert.Empty(t, e.CheckMaxContextTokens())
+}
+
+// testMaxContextTokens tests that the configured context window overrides the detected one, a warning being returned
+// if it exceeds it.
+func testMaxContextTokens(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("USER_MAX_CONTEXT_TOKENS", 16000)
+	e := newTestEngine(t, ExecEngineMode, nil)
+	e.contextWindow = 8192
+
+	assert.Equal(t, 16000, e.GetMaxContextTokens(), "The configured context window should override the detected one.")
+	assert.Equal(t, "the max context tokens 16000 exceed the context window of gpt-test, 8192 tokens", e.CheckMaxContextTokens())
+
+	e.contextWindow = 0
+	assert.Empty(t, e.CheckMaxContextTokens(), "Nothing should be reported when the context window is unknown.")
+}
@@ -2,7 +2,6 @@ package ai
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -10,8 +9,11 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/akhilsharma90/terminal-assistant/config"
+	"github.com/akhilsharma90/terminal-assistant/run"
 	"github.com/akhilsharma90/terminal-assistant/system"
 
 	"github.com/sashabaranov/go-openai"
@@ -19,20 +21,70 @@ import (
 
 const noexec = "[noexec]"
 
+// ErrInterrupted is returned when a completion request is cancelled by the user.
+var ErrInterrupted = errors.New("interrupted")
+
+// invalid_json_prompt is the system message sending again a request whose answer contains an invalid JSON object.
+const invalid_json_prompt = "Your last response was not valid JSON. Respond with only the JSON object."
+
+// alias_prompt_max is the maximum number of aliases of the user told to the model.
+const alias_prompt_max = 30
+
 type Engine struct {
-	mode         EngineMode                     // The mode of the engine either ExecEngineMode or ChatEngineMode
-	config       *config.Config                 // The configuration settings for the engine
-	client       *openai.Client                 // The OpenAI API client
-	execMessages []openai.ChatCompletionMessage // Messages for executing commands
-	chatMessages []openai.ChatCompletionMessage // Messages for chat interactions
-	channel      chan EngineChatStreamOutput    // The channel for sending chat stream output
-	pipe         string                         // The pipe for communication with the engine
-	running      bool                           // Indicates whether the engine is running or not
+	mode          EngineMode                     // The mode of the engine either ExecEngineMode or ChatEngineMode
+	config        *config.Config                 // The configuration settings for the engine
+	client        *openai.Client                 // The OpenAI API client
+	execMessages  []openai.ChatCompletionMessage // Messages for executing commands
+	chatMessages  []openai.ChatCompletionMessage // Messages for chat interactions
+	channel       chan EngineChatStreamOutput    // The channel for sending chat stream output
+	statuses      chan EngineStatus              // The channel for sending the statuses of the request in flight
+	pipe          string                         // The pipe for communication with the engine
+	running       bool                           // Indicates whether the engine is running or not
+	streamTokens  int                            // The estimated number of tokens of the answer being streamed.
+	tools         []Tool                         // The local tools the model can call
+	images        []string                       // The data URLs of the images attached to the next user message
+	aliases       run.Aliases                    // The aliases of the shell of the user, told to the model
+	contextFiles  []contextFile                  // The files injected into the context of the conversation
+	usage         EngineUsage                    // The tokens used by the completion requests of the session
+	contextWindow int                            // The context window of the model retrieved by PreloadModel, 0 if unknown
+	cancelMutex   sync.Mutex                     // Guards the cancellation of the completion request in flight
+	cancel        context.CancelFunc             // The cancellation of the completion request in flight, if any
+}
+
+// EngineOption is a function that customizes an Engine when it is created.
+type EngineOption func(*Engine)
+
+// WithPipe is an EngineOption that sets the pipe of the Engine, NewEngine returning an error if it is not text
+// or exceeds the configured maximum size or the context window.
+func WithPipe(pipe string) EngineOption {
+	return func(e *Engine) {
+		e.pipe = pipe
+	}
+}
+
+// WithAliases is an EngineOption that sets the aliases of the shell of the user told to the model.
+func WithAliases(aliases run.Aliases) EngineOption {
+	return func(e *Engine) {
+		e.SetAliases(aliases)
+	}
+}
+
+// WithUsage is an EngineOption that sets the tokens already used in the session, so they keep being counted
+// when the engine is recreated.
+func WithUsage(usage EngineUsage) EngineOption {
+	return func(e *Engine) {
+		e.usage = usage
+	}
 }
 
 // NewEngine creates a new instance of the Engine struct.
-// It takes the mode (EngineMode) and config (*config.Config) as parameters.
-func NewEngine(mode EngineMode, config *config.Config) (*Engine, error) {
+// It takes the mode (EngineMode), config (*config.Config) and options (EngineOption) as parameters.
+// The context bounds the initialization of the engine, an error being returned if it is already done.
+func NewEngine(ctx context.Context, mode EngineMode, config *config.Config, opts ...EngineOption) (*Engine, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	var client *openai.Client
 
 	// Check if a proxy is configured in the AI config
@@ -44,7 +96,7 @@ func NewEngine(mode EngineMode, config *config.Config) (*Engine, error) {
 		// Parse the proxy URL
 		proxyUrl, err := url.Parse(config.GetAiConfig().GetProxy())
 		if err != nil {
-			return nil, err
+			return nil, setupError(fmt.Errorf("invalid proxy: %w", err))
 		}
 
 		// Create a transport with the proxy URL
@@ -65,16 +117,36 @@ func NewEngine(mode EngineMode, config *config.Config) (*Engine, error) {
 	}
 
 	// Create a new instance of the Engine struct with the provided parameters
-	return &Engine{
+	engine := &Engine{
 		mode:         mode,
 		config:       config,
 		client:       client,
 		execMessages: make([]openai.ChatCompletionMessage, 0),
 		chatMessages: make([]openai.ChatCompletionMessage, 0),
 		channel:      make(chan EngineChatStreamOutput),
+		statuses:     make(chan EngineStatus, 1),
 		pipe:         "",
 		running:      false,
-	}, nil
+	}
+
+	// Let the model execute the safe commands if enabled, never when the execution of commands is disabled
+	if isShellToolEnabled(config) {
+		engine.RegisterShellTool(run.NewPolicy(
+			config.GetUserConfig().GetExecAllowlist(),
+			config.GetUserConfig().GetExecBlocklist(),
+		))
+	}
+
+	for _, opt := range opts {
+		opt(engine)
+	}
+
+	// Refuse the binary or too large pipes before any request is sent
+	if err := engine.checkPipe(engine.pipe); err != nil {
+		return nil, err
+	}
+
+	return engine, nil
 }
 
 // SetMode sets the mode of the Engine.
@@ -94,13 +166,57 @@ func (e *Engine) GetChannel() chan EngineChatStreamOutput {
 	return e.channel
 }
 
-// SetPipe sets the pipe of the Engine.
-func (e *Engine) SetPipe(pipe string) *Engine {
-	e.pipe = pipe
+// GetUsage returns the tokens used by the completion requests of the session.
+func (e *Engine) GetUsage() EngineUsage {
+	return e.usage
+}
+
+// SetAliases sets the aliases of the shell of the user, told to the model.
+func (e *Engine) SetAliases(aliases run.Aliases) *Engine {
+	e.aliases = aliases
 
 	return e
 }
 
+// GetAliases returns the aliases of the shell of the user.
+func (e *Engine) GetAliases() run.Aliases {
+	return e.aliases
+}
+
+// SetPipe sets the pipe of the Engine. ErrBinaryPipe is returned if the pipe is not text, and a PipeSizeError if it
+// exceeds the configured maximum size or the context window, the current pipe being kept.
+func (e *Engine) SetPipe(pipe string) error {
+	if err := e.checkPipe(pipe); err != nil {
+		return err
+	}
+	e.pipe = pipe
+
+	return nil
+}
+
+// checkPipe checks that a pipe is text and does not exceed the configured maximum size or the context window.
+func (e *Engine) checkPipe(pipe string) error {
+	if err := validatePipe(pipe); err != nil {
+		return err
+	}
+	if e.config == nil {
+		return nil
+	}
+
+	return checkPipeSize(pipe, e.GetMaxPipeSizeBytes())
+}
+
+// GetMaxPipeSizeBytes returns the maximum size in bytes of the pipe, the configured maximum capped to the estimated size
+// of the context window, unlimited if both are unknown.
+func (e *Engine) GetMaxPipeSizeBytes() int {
+	max := e.config.GetUserConfig().GetMaxPipeSizeBytes()
+	if tokens := e.GetMaxContextTokens(); tokens > 0 && (max <= 0 || tokens*pipe_bytes_per_token < max) {
+		max = tokens * pipe_bytes_per_token
+	}
+
+	return max
+}
+
 // Interrupt interrupts the Engine operation.
 func (e *Engine) Interrupt() *Engine {
 	// Send an EngineChatStreamOutput with the interrupt flag set to true
@@ -117,6 +233,100 @@ func (e *Engine) Interrupt() *Engine {
 	return e
 }
 
+// Cancel cancels the completion request in flight, if any. A cancelled chat stream sends a last interrupted output,
+// the answer streamed so far being kept in the conversation, and a cancelled exec request returns ErrInterrupted.
+func (e *Engine) Cancel() *Engine {
+	e.cancelMutex.Lock()
+	defer e.cancelMutex.Unlock()
+
+	if e.cancel != nil {
+		e.cancel()
+	}
+
+	return e
+}
+
+// startRequest returns the context of a completion request, cancelled by Cancel.
+func (e *Engine) startRequest() context.Context {
+	e.cancelMutex.Lock()
+	defer e.cancelMutex.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+
+	return ctx
+}
+
+// finishRequest releases the context of the finished completion request.
+func (e *Engine) finishRequest() {
+	e.cancelMutex.Lock()
+	defer e.cancelMutex.Unlock()
+
+	if e.cancel != nil {
+		e.cancel()
+		e.cancel = nil
+	}
+}
+
+// PreloadModel retrieves the configured model, establishing the connection to the OpenAI API before the first
+// completion request, and detects its context window. An error is returned if the API cannot be reached, the key is
+// invalid or the model is unknown, a ConnectionError of the kind of ErrUnauthorized, ErrQuotaExceeded or
+// ErrNetworkUnreachable when it is one of them.
+func (e *Engine) PreloadModel(ctx context.Context) error {
+	model, err := e.client.GetModel(ctx, e.config.GetAiConfig().GetModel())
+	if err != nil {
+		return classifyConnectionError(err)
+	}
+
+	// The models derived from another one, like the fine-tuned models, have the context window of their root
+	for _, name := range []string{model.ID, model.Root, model.Parent} {
+		if size, ok := findModelContextWindow(name); ok {
+			e.contextWindow = size
+			break
+		}
+	}
+
+	return nil
+}
+
+// GetContextWindow returns the size in tokens of the context window of the model, detected by PreloadModel or known
+// from the name of the configured model, 0 if unknown.
+func (e *Engine) GetContextWindow() int {
+	if e.contextWindow > 0 {
+		return e.contextWindow
+	}
+	size, _ := findModelContextWindow(e.config.GetAiConfig().GetModel())
+
+	return size
+}
+
+// GetMaxContextTokens returns the size in tokens of the context window, the configured size overriding the context
+// window of the model, 0 if unknown.
+func (e *Engine) GetMaxContextTokens() int {
+	if max := e.config.GetUserConfig().GetMaxContextTokens(); max > 0 {
+		return max
+	}
+
+	return e.GetContextWindow()
+}
+
+// CheckMaxContextTokens returns a warning if the configured size of the context window exceeds the context window of
+// the model, the requests exceeding it being refused by the API, empty otherwise.
+func (e *Engine) CheckMaxContextTokens() string {
+	max := e.config.GetUserConfig().GetMaxContextTokens()
+	window := e.GetContextWindow()
+	if max <= window || window == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(
+		"the max context tokens %d exceed the context window of %s, %d tokens",
+		max,
+		e.config.GetAiConfig().GetModel(),
+		window,
+	)
+}
+
 // Clear clears the Engine messages based on the current mode.
 func (e *Engine) Clear() *Engine {
 	if e.mode == ExecEngineMode {
@@ -132,51 +342,136 @@ func (e *Engine) Clear() *Engine {
 func (e *Engine) Reset() *Engine {
 	e.execMessages = []openai.ChatCompletionMessage{}
 	e.chatMessages = []openai.ChatCompletionMessage{}
+	e.images = nil
 
 	return e
 }
 
 // ExecCompletion execute a completion request to the OpenAI API and process the response.
 func (e *Engine) ExecCompletion(input string) (*EngineExecOutput, error) {
-	ctx := context.Background()
+	ctx := e.startRequest()
+	defer e.finishRequest()
 
 	// Set the running flag to true
 	e.running = true
 
-	// Append user message to the chat messages
-	e.appendUserMessage(input)
+	// Append user message to the chat messages, removed if the request fails so it can be sent again
+	count := e.countMessages()
+	e.appendUserMessage(e.wrapInput(input))
 
-	// Create a chat completion request to the OpenAI API
-	resp, err := e.client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model:     e.config.GetAiConfig().GetModel(),
-			MaxTokens: e.config.GetAiConfig().GetMaxTokens(),
-			Messages:  e.prepareCompletionMessages(),
-		},
-	)
-	if err != nil {
-		return nil, err
+	return e.execCompletion(ctx, count)
+}
+
+// ExecCompletionWithRetry executes a completion request like ExecCompletion, and sends it again up to maxRetries
+// times while the answer contains a JSON object that cannot be read, telling the model to answer with the JSON
+// object only. The error of the first answer is returned if all the answers are invalid. The invalid answers and
+// the messages asking for the JSON object are removed once retried, only the message of the user and the valid
+// answer, if any, being kept for the next requests.
+func (e *Engine) ExecCompletionWithRetry(input string, maxRetries int) (*EngineExecOutput, error) {
+	count := e.countMessages()
+	output, err := e.ExecCompletion(input)
+	if !IsInvalidJSON(err) || maxRetries <= 0 {
+		return output, err
+	}
+
+	// Keep the message of the user, the messages after it being the invalid answers and the retries
+	count++
+	for retry := 0; retry < maxRetries; retry++ {
+		retryOutput, retryErr := e.retryExecCompletion()
+		if retryErr == nil {
+			// Keep the valid answer after the message of the user
+			answer := e.lastMessage()
+			e.truncateMessages(count).appendMessage(answer)
+			return retryOutput, nil
+		}
+		if !IsInvalidJSON(retryErr) {
+			e.truncateMessages(count)
+			return retryOutput, retryErr
+		}
 	}
+	e.truncateMessages(count)
+
+	return output, err
+}
+
+// lastMessage returns the last chat message of the current mode in the Engine.
+func (e *Engine) lastMessage() openai.ChatCompletionMessage {
+	if e.mode == ExecEngineMode {
+		return e.execMessages[len(e.execMessages)-1]
+	}
+
+	return e.chatMessages[len(e.chatMessages)-1]
+}
+
+// retryExecCompletion sends the exec messages again after an answer with an invalid JSON object, with a system
+// message asking for the JSON object only.
+func (e *Engine) retryExecCompletion() (*EngineExecOutput, error) {
+	ctx := e.startRequest()
+	defer e.finishRequest()
 
-	// Get the assistant message from the response
-	content := resp.Choices[0].Message.Content
+	e.running = true
+
+	count := e.countMessages()
+	e.appendMessage(openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleSystem,
+		Content: invalid_json_prompt,
+	})
+
+	return e.execCompletion(ctx, count)
+}
+
+// execCompletion creates the completion requests of the exec messages and reads the command of the answer, the
+// messages added after the given number of messages being removed if a request fails.
+func (e *Engine) execCompletion(ctx context.Context, count int) (*EngineExecOutput, error) {
+	// Create chat completion requests to the OpenAI API, until the model stops calling tools
+	var content string
+	for round := 0; ; round++ {
+		e.sendContactingStatus()
+		resp, err := e.client.CreateChatCompletion(
+			ctx,
+			openai.ChatCompletionRequest{
+				Model:     e.config.GetAiConfig().GetModel(),
+				MaxTokens: e.config.GetAiConfig().GetMaxTokens(),
+				Messages:  e.prepareCompletionMessages(),
+				Tools:     e.prepareTools(round),
+			},
+		)
+		if err != nil {
+			e.running = false
+			if ctx.Err() != nil {
+				return nil, ErrInterrupted
+			}
+			e.truncateMessages(count)
+			return nil, classifyError(err)
+		}
+		e.usage = e.usage.add(resp.Usage)
+
+		// Dispatch the tool calls and feed their results back to the model
+		message := resp.Choices[0].Message
+		if len(message.ToolCalls) > 0 {
+			e.callTools(message.Content, message.ToolCalls)
+			continue
+		}
+
+		// Get the assistant message from the response
+		content = message.Content
+		break
+	}
 
 	// Append assistant message to the chat messages
 	e.appendAssistantMessage(content)
 
-	var output EngineExecOutput
-
-	// Unmarshal the content into the EngineExecOutput struct
-	err = json.Unmarshal([]byte(content), &output)
+	// Read the content with the field names of the configuration
+	fields := e.config.GetUserConfig().GetExecOutputFields()
+	output, err := parseExecOutput(content, fields)
 	if err != nil {
 		re := regexp.MustCompile(`\{.*?\}`)
 		match := re.FindString(content)
 		if match != "" {
-			// Unmarshal the match into the EngineExecOutput struct
-			err = json.Unmarshal([]byte(match), &output)
+			// Read the JSON object found in the content
+			output, err = parseExecOutput(match, fields)
 			if err != nil {
-				return nil, err
+				return nil, NewInvalidJSONError(err)
 			}
 		} else {
 			// If unable to unmarshal, create a default EngineExecOutput
@@ -191,102 +486,302 @@ func (e *Engine) ExecCompletion(input string) (*EngineExecOutput, error) {
 	return &output, nil
 }
 
+// FixCompletion asks the OpenAI API to fix a command that failed, giving the original request,
+// the failed command, its failure and its standard error if it was captured.
+func (e *Engine) FixCompletion(request string, command string, failure string, stderr string) (*EngineExecOutput, error) {
+	return e.ExecCompletion(e.prepareFixPrompt(request, command, failure, stderr))
+}
+
 // ChatCompletion execute a completion request to the OpenAI API and process the response in real-time.
 func (e *Engine) ChatStreamCompletion(input string) error {
-	ctx := context.Background()
+	ctx := e.startRequest()
+	defer e.finishRequest()
 
 	// Set the running flag to true
 	e.running = true
 
-	// Append user message to chat messages
-	e.appendUserMessage(input)
+	// Append user message to chat messages, removed if the request fails so it can be sent again
+	count := e.countMessages()
+	e.appendUserMessage(e.wrapInput(input))
 
-	// Create a chat completion request to the OpenAI API
-	req := openai.ChatCompletionRequest{
-		Model:     e.config.GetAiConfig().GetModel(),
-		MaxTokens: e.config.GetAiConfig().GetMaxTokens(),
-		Messages:  e.prepareCompletionMessages(),
-		Stream:    true,
-	}
+	for round := 0; ; round++ {
+		// Create a chat completion request to the OpenAI API
+		req := openai.ChatCompletionRequest{
+			Model:     e.config.GetAiConfig().GetModel(),
+			MaxTokens: e.config.GetAiConfig().GetMaxTokens(),
+			Messages:  e.prepareCompletionMessages(),
+			Stream:    true,
+			Tools:     e.prepareTools(round),
+			// The usage is sent in a last chunk without choices
+			StreamOptions: &openai.StreamOptions{IncludeUsage: true},
+		}
 
-	// Create chat completion stream
-	stream, err := e.client.CreateChatCompletionStream(ctx, req)
-	if err != nil {
-		return err
-	}
-	defer stream.Close()
+		// Create chat completion stream
+		e.sendContactingStatus()
+		e.streamTokens = 0
+		stream, err := e.client.CreateChatCompletionStream(ctx, req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return e.interruptChatStream("")
+			}
+			e.truncateMessages(count)
+			return classifyError(err)
+		}
 
-	var output string
+		output, toolCalls, err := e.receiveChatStream(stream)
+		stream.Close()
+		if ctx.Err() != nil {
+			return e.interruptChatStream(output)
+		}
+		if err != nil {
+			e.running = false
+			e.truncateMessages(count)
+			return classifyError(err)
+		}
 
-	for {
-		if e.running {
-			resp, err := stream.Recv()
-
-			// Check if completion is finished
-			if errors.Is(err, io.EOF) {
-				executable := false
-				// Check if the output is executable
-				if e.mode == ExecEngineMode {
-					if !strings.HasPrefix(output, noexec) && !strings.Contains(output, "\n") {
-						executable = true
-					}
-				}
-
-				// Send last output to channel
-				e.channel <- EngineChatStreamOutput{
-					content:    "",
-					last:       true,
-					executable: executable,
-				}
-				e.running = false
-
-				// Append assistant message to chat messages
-				e.appendAssistantMessage(output)
-
-				return nil
+		// Stop if the stream was interrupted
+		if !e.running {
+			return nil
+		}
+
+		// Dispatch the tool calls and continue the conversation with their results
+		if len(toolCalls) > 0 {
+			e.callTools(output, toolCalls)
+			continue
+		}
+
+		executable := false
+		// Check if the output is executable
+		if e.mode == ExecEngineMode {
+			if !strings.HasPrefix(output, noexec) && !strings.Contains(output, "\n") {
+				executable = true
 			}
+		}
+
+		// Send last output to channel
+		e.channel <- EngineChatStreamOutput{
+			content:              "",
+			last:                 true,
+			executable:           executable,
+			estimatedTotalTokens: e.streamTokens,
+		}
+		e.running = false
+
+		// Append assistant message to chat messages
+		e.appendAssistantMessage(output)
+
+		return nil
+	}
+}
 
+// ChatStreamCompletionWriter executes a chat completion request like ChatStreamCompletion, writing the content
+// streamed to a writer as it arrives instead of sending it to the channel, for the programs without the user
+// interface. The request is cancelled when the writer fails, like when the pipe it writes to is closed, and the error
+// of the writer is returned.
+func (e *Engine) ChatStreamCompletionWriter(input string, w io.Writer) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- e.ChatStreamCompletion(input)
+	}()
+
+	writer := &chanWriter{w: w}
+	for {
+		select {
+		case output := <-e.channel:
+			if writer.write(output) {
+				e.Cancel()
+			}
+		case err := <-done:
+			// The outputs are sent to the unbuffered channel before the completion returns
 			if err != nil {
-				e.running = false
 				return err
 			}
+			return writer.err
+		}
+	}
+}
 
-			// Get assistant message from response
-			delta := resp.Choices[0].Delta.Content
+// interruptChatStream ends a cancelled chat stream, keeping the answer streamed so far in the conversation
+// and sending a last interrupted output to the channel.
+func (e *Engine) interruptChatStream(output string) error {
+	if output != "" {
+		e.appendAssistantMessage(output)
+	}
 
-			output += delta
+	e.channel <- EngineChatStreamOutput{
+		content:              "",
+		last:                 true,
+		interrupt:            true,
+		estimatedTotalTokens: e.streamTokens,
+	}
+	e.running = false
 
-			// Send output to channel
-			e.channel <- EngineChatStreamOutput{
-				content: delta,
-				last:    false,
+	return nil
+}
+
+// receiveChatStream receives a chat stream until its end or an interruption, sending its content to the channel
+// in chunks of the configured number of tokens or delay. It returns the content and the tool calls of the stream.
+func (e *Engine) receiveChatStream(stream *openai.ChatCompletionStream) (string, []openai.ToolCall, error) {
+	var output string
+	var toolCalls []openai.ToolCall
+	chunker := newStreamChunker(e.config.GetUserConfig().GetStreamChunkTokens(), e.config.GetUserConfig().GetStreamChunkMs())
+	var sending sync.Mutex
+	stopFlushing := e.flushStalledChunks(chunker, &sending)
+	defer stopFlushing()
+
+	for e.running {
+		resp, err := stream.Recv()
+
+		// Check if completion is finished
+		if errors.Is(err, io.EOF) {
+			e.sendChunkLocked(&sending, chunker.flush)
+			return output, toolCalls, nil
+		}
+
+		if err != nil {
+			e.sendChunkLocked(&sending, chunker.flush)
+			return output, toolCalls, err
+		}
+
+		if resp.Usage != nil {
+			e.usage = e.usage.add(*resp.Usage)
+			// The usage tells the number of tokens of the answer, estimated from the tokens received until then
+			if resp.Usage.CompletionTokens > 0 {
+				sending.Lock()
+				e.streamTokens = resp.Usage.CompletionTokens
+				sending.Unlock()
 			}
+		}
 
-			// time.Sleep(time.Microsecond * 100)
-		} else {
-			stream.Close()
-			return nil
+		if len(resp.Choices) == 0 {
+			continue
 		}
+
+		// Collect the chunks of the tool calls
+		toolCalls = mergeToolCallDeltas(toolCalls, resp.Choices[0].Delta.ToolCalls)
+
+		// Get assistant message from response
+		delta := resp.Choices[0].Delta.Content
+		if delta == "" {
+			continue
+		}
+
+		output += delta
+
+		// Send output to channel once the chunk is complete
+		e.sendChunkLocked(&sending, func() (string, bool) {
+			e.streamTokens++
+			return chunker.add(delta)
+		})
 	}
+
+	// The interruption already sent the last output, the chunk left is no longer awaited
+	return output, toolCalls, nil
 }
 
-// appendUserMessage appends a user message to the chat messages in the Engine.
-func (e *Engine) appendUserMessage(content string) *Engine {
+// flushStalledChunks sends the tokens of a chat stream waiting for the delay of the chunker once it elapsed, even if no
+// other token is received meanwhile, like when the model pauses. The returned function stops the flushing, once the
+// chunk being sent, if any, was received.
+func (e *Engine) flushStalledChunks(chunker *streamChunker, sending *sync.Mutex) func() {
+	if chunker.interval <= 0 {
+		return func() {}
+	}
+
+	ticker := time.NewTicker(chunker.interval)
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-ticker.C:
+				e.sendChunkLocked(sending, chunker.flushDue)
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(stop)
+		<-stopped
+	}
+}
+
+// sendChunkLocked sends the chunk returned by a function of the chunker, if it must be sent, under the mutex of the
+// stream, so the chunks flushed by the stream and by the delay keep their order.
+func (e *Engine) sendChunkLocked(sending *sync.Mutex, next func() (string, bool)) {
+	sending.Lock()
+	defer sending.Unlock()
+
+	e.sendChunk(next())
+}
+
+// sendChunk sends a chunk of the content of a chat stream to the channel, if it must be sent.
+func (e *Engine) sendChunk(content string, send bool) {
+	if !send {
+		return
+	}
+
+	e.channel <- EngineChatStreamOutput{
+		content:              content,
+		last:                 false,
+		estimatedTotalTokens: e.streamTokens,
+	}
+}
+
+// appendMessage appends a message to the chat messages of the current mode in the Engine.
+func (e *Engine) appendMessage(message openai.ChatCompletionMessage) *Engine {
 	if e.mode == ExecEngineMode {
-		e.execMessages = append(e.execMessages, openai.ChatCompletionMessage{
-			Role:    openai.ChatMessageRoleUser,
-			Content: content,
-		})
+		e.execMessages = append(e.execMessages, message)
 	} else {
-		e.chatMessages = append(e.chatMessages, openai.ChatCompletionMessage{
-			Role:    openai.ChatMessageRoleUser,
-			Content: content,
-		})
+		e.chatMessages = append(e.chatMessages, message)
 	}
 
 	return e
 }
 
+// countMessages returns the number of chat messages of the current mode in the Engine.
+func (e *Engine) countMessages() int {
+	if e.mode == ExecEngineMode {
+		return len(e.execMessages)
+	}
+
+	return len(e.chatMessages)
+}
+
+// truncateMessages removes the chat messages of the current mode added after the given number of messages, like
+// the messages of a failed request.
+func (e *Engine) truncateMessages(count int) *Engine {
+	if e.mode == ExecEngineMode && count < len(e.execMessages) {
+		e.execMessages = e.execMessages[:count]
+	} else if e.mode == ChatEngineMode && count < len(e.chatMessages) {
+		e.chatMessages = e.chatMessages[:count]
+	}
+
+	return e
+}
+
+// wrapInput returns the input of the user between the configured prompt prefix and suffix, like the context the user
+// always gives, separated from the input by a space.
+func (e *Engine) wrapInput(input string) string {
+	parts := []string{input}
+	if prefix := strings.TrimSpace(e.config.GetUserConfig().GetPromptPrefix()); prefix != "" {
+		parts = append([]string{prefix}, parts...)
+	}
+	if suffix := strings.TrimSpace(e.config.GetUserConfig().GetPromptSuffix()); suffix != "" {
+		parts = append(parts, suffix)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// appendUserMessage appends a user message, with the attached images, to the chat messages in the Engine.
+func (e *Engine) appendUserMessage(content string) *Engine {
+	return e.appendMessage(e.prepareUserMessage(content))
+}
+
 // appendAssistantMessage appends an assistant message to the chat messages in the Engine.
 func (e *Engine) appendAssistantMessage(content string) *Engine {
 	if e.mode == ExecEngineMode {
@@ -325,6 +820,17 @@ func (e *Engine) prepareCompletionMessages() []openai.ChatCompletionMessage {
 		)
 	}
 
+	// If files are injected into the context, append their content to the messages.
+	if len(e.contextFiles) > 0 {
+		messages = append(
+			messages,
+			openai.ChatCompletionMessage{
+				Role:    openai.ChatMessageRoleUser,
+				Content: e.prepareContextFilesPrompt(),
+			},
+		)
+	}
+
 	if e.mode == ExecEngineMode {
 		messages = append(messages, e.execMessages...) // Append the user and assistant messages for execution mode.
 	} else {
@@ -334,6 +840,22 @@ func (e *Engine) prepareCompletionMessages() []openai.ChatCompletionMessage {
 	return messages
 }
 
+// prepareFixPrompt prepares the prompt asking to fix a failed command.
+func (e *Engine) prepareFixPrompt(request string, command string, failure string, stderr string) string {
+	prompt := fmt.Sprintf(
+		"The command `%s` you generated for \"%s\" failed with: %s.\n",
+		command,
+		request,
+		failure,
+	)
+	if stderr != "" {
+		prompt += fmt.Sprintf("Its standard error was:\n%s\n", stderr)
+	}
+	prompt += "Generate a fixed command."
+
+	return prompt
+}
+
 // preparePipePrompt prepares the pipe prompt.
 func (e *Engine) preparePipePrompt() string {
 	return fmt.Sprintf("I will work on the following input: %s", e.pipe)
@@ -353,22 +875,25 @@ func (e *Engine) prepareSystemPrompt() string {
 	return fmt.Sprintf("%s\n%s", bodyPart, e.prepareSystemPromptContextPart())
 }
 
-// prepareSystemPromptExecPart prepares the system prompt for execution mode.
+// prepareSystemPromptExecPart prepares the system prompt for execution mode, asking for the field names of the configuration.
 func (e *Engine) prepareSystemPromptExecPart() string {
-	return "Your are terminal-assistant, a powerful terminal assistant generating a JSON containing a command line for my input.\n" +
-		"You will always reply using the following json structure: {\"cmd\":\"the command\", \"exp\": \"some explanation\", \"exec\": true}.\n" +
+	fields := e.config.GetUserConfig().GetExecOutputFields()
+	replacer := strings.NewReplacer("{cmd}", fields.Command, "{exp}", fields.Explanation, "{exec}", fields.Executable)
+
+	return replacer.Replace("Your are terminal-assistant, a powerful terminal assistant generating a JSON containing a command line for my input.\n" +
+		"You will always reply using the following json structure: {\"{cmd}\":\"the command\", \"{exp}\": \"some explanation\", \"{exec}\": true}.\n" +
 		"Your answer will always only contain the json structure, never add any advice or supplementary detail or information, even if I asked the same question before.\n" +
-		"The field cmd will contain a single line command (don't use new lines, use separators like && and ; instead).\n" +
-		"The field exp will contain an short explanation of the command if you managed to generate an executable command, otherwise it will contain the reason of your failure.\n" +
-		"The field exec will contain true if you managed to generate an executable command, false otherwise." +
+		"The field {cmd} will contain a single line command (don't use new lines, use separators like && and ; instead).\n" +
+		"The field {exp} will contain an short explanation of the command if you managed to generate an executable command, otherwise it will contain the reason of your failure.\n" +
+		"The field {exec} will contain true if you managed to generate an executable command, false otherwise." +
 		"\n" +
 		"Examples:\n" +
 		"Me: list all files in my home dir\n" +
-		"terminal-assistant: {\"cmd\":\"ls ~\", \"exp\": \"list all files in your home dir\", \"exec\\: true}\n" +
+		"terminal-assistant: {\"{cmd}\":\"ls ~\", \"{exp}\": \"list all files in your home dir\", \"{exec}\": true}\n" +
 		"Me: list all pods of all namespaces\n" +
-		"terminal-assistant: {\"cmd\":\"kubectl get pods --all-namespaces\", \"exp\": \"list pods form all k8s namespaces\", \"exec\": true}\n" +
+		"terminal-assistant: {\"{cmd}\":\"kubectl get pods --all-namespaces\", \"{exp}\": \"list pods form all k8s namespaces\", \"{exec}\": true}\n" +
 		"Me: how are you ?\n" +
-		"terminal-assistant: {\"cmd\":\"\", \"exp\": \"I'm good thanks but I cannot generate a command for this. Use the chat mode to discuss.\", \"exec\": false}"
+		"terminal-assistant: {\"{cmd}\":\"\", \"{exp}\": \"I'm good thanks but I cannot generate a command for this. Use the chat mode to discuss.\", \"{exec}\": false}")
 }
 
 // prepareSystemPromptChatPart prepares the system prompt for chat mode.
@@ -407,6 +932,10 @@ func (e *Engine) prepareSystemPromptContextPart() string {
 		// If the editor is not empty, append the editor to the context part.
 		part += fmt.Sprintf("my editor is %s, ", e.config.GetSystemConfig().GetEditor())
 	}
+	if len(e.aliases) > 0 {
+		// If the aliases are resolved, append the first ones to the context part.
+		part += fmt.Sprintf("my shell aliases are %s, ", e.aliases.Summary(alias_prompt_max))
+	}
 	part += "take this into account. "
 
 	// If the preferences are not empty, append the preferences to the context part.
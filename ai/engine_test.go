@@ -0,0 +1,327 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/akhilsharma90/terminal-assistant/config"
+	"github.com/akhilsharma90/terminal-assistant/run"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewEngine is a test function for testing the NewEngine function with a done context and with options
+func TestNewEngine(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewEngine(ctx, ExecEngineMode, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	e := newTestEngine(t, ExecEngineMode, nil)
+	WithPipe("piped content")(e)
+	WithAliases(run.Aliases{"ll": "ls -alF"})(e)
+	assert.Equal(t, "piped content", e.pipe)
+	assert.Equal(t, run.Aliases{"ll": "ls -alF"}, e.GetAliases())
+
+	_, err = NewEngine(context.Background(), ExecEngineMode, e.config, WithPipe("\x89PNG\r\n\x1a\n\x00"))
+	assert.ErrorIs(t, err, ErrBinaryPipe, "The binary pipes should be refused before any request.")
+}
+
+// TestEngineSetPipe is a test function for testing that SetPipe keeps the current pipe when the new one is binary
+func TestEngineSetPipe(t *testing.T) {
+	e := &Engine{}
+
+	assert.NoError(t, e.SetPipe("piped content"))
+	assert.ErrorIs(t, e.SetPipe("binary\x00content"), ErrBinaryPipe)
+	assert.Equal(t, "piped content", e.pipe, "The current pipe should be kept.")
+}
+
+// TestEnginePreloadModel is a test function for testing that PreloadModel retrieves the configured model
+func TestEnginePreloadModel(t *testing.T) {
+	e := newTestEngine(t, ExecEngineMode, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/models/gpt-test", r.URL.Path, "The configured model should be retrieved.")
+		fmt.Fprint(w, `{"id": "gpt-test", "object": "model"}`)
+	})
+	assert.NoError(t, e.PreloadModel(context.Background()))
+
+	e = newTestEngine(t, ExecEngineMode, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error": {"message": "Incorrect API key provided", "type": "invalid_request_error"}}`)
+	})
+	assert.ErrorContains(t, e.PreloadModel(context.Background()), "Incorrect API key provided")
+}
+
+// TestEnginePreloadModelErrors is a test function for testing that PreloadModel returns the kind of its error
+func TestEnginePreloadModelErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		status   int
+		body     string
+		expected error
+	}{
+		{"Unauthorized", http.StatusUnauthorized, `{"error": {"message": "Incorrect API key provided", "type": "invalid_request_error"}}`, ErrUnauthorized},
+		{"QuotaExceeded", http.StatusTooManyRequests, `{"error": {"message": "You exceeded your current quota", "type": "insufficient_quota", "code": "insufficient_quota"}}`, ErrQuotaExceeded},
+		{"RateLimit", http.StatusTooManyRequests, `{"error": {"message": "Rate limit reached", "type": "requests"}}`, nil},
+		{"NotFound", http.StatusNotFound, `{"error": {"message": "The model does not exist", "type": "invalid_request_error"}}`, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := newTestEngine(t, ExecEngineMode, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, tc.body)
+			})
+
+			err := e.PreloadModel(context.Background())
+			switch {
+			case tc.expected != nil:
+				assert.ErrorIs(t, err, tc.expected)
+			case tc.status == http.StatusTooManyRequests:
+				assert.NoError(t, err, "The key should be accepted despite a rate limit.")
+			default:
+				assert.ErrorContains(t, err, "The model does not exist")
+				assert.NotErrorIs(t, err, ErrUnauthorized)
+			}
+		})
+	}
+
+	t.Run("RefusedKeySetupError", func(t *testing.T) {
+		e := newTestEngine(t, ExecEngineMode, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error": {"message": "Incorrect API key provided", "type": "invalid_request_error"}}`)
+		})
+		err := e.PreloadModel(context.Background())
+		assert.True(t, config.IsSetupError(err), "A refused key should still be an error of the setup.")
+		assert.ErrorContains(t, err, "the API key was refused: ")
+	})
+
+	t.Run("NetworkUnreachable", func(t *testing.T) {
+		e := newTestEngine(t, ExecEngineMode, func(w http.ResponseWriter, r *http.Request) {})
+		clientConfig := openai.DefaultConfig("test_key")
+		clientConfig.BaseURL = "http://127.0.0.1:1/v1"
+		e.client = openai.NewClientWithConfig(clientConfig)
+
+		assert.ErrorIs(t, e.PreloadModel(context.Background()), ErrNetworkUnreachable)
+	})
+}
+
+// TestEnginePrepareFixPrompt is a test function for testing the prepareFixPrompt method of the Engine type
+func TestEnginePrepareFixPrompt(t *testing.T) {
+	e := &Engine{}
+
+	prompt := e.prepareFixPrompt("list files", "lss -la", "exit status 127", "lss: command not found\n")
+	assert.Contains(t, prompt, "`lss -la`")
+	assert.Contains(t, prompt, "list files")
+	assert.Contains(t, prompt, "exit status 127")
+	assert.Contains(t, prompt, "lss: command not found")
+
+	prompt = e.prepareFixPrompt("list files", "lss -la", "exit status 127", "")
+	assert.NotContains(t, prompt, "standard error")
+}
+
+// TestEnginePrepareSystemPromptExecPart is a test function for testing that the model is asked for the configured field names
+func TestEnginePrepareSystemPromptExecPart(t *testing.T) {
+	e := newTestEngine(t, ExecEngineMode, nil)
+
+	prompt := e.prepareSystemPromptExecPart()
+	assert.Contains(t, prompt, `{"cmd":"the command", "exp": "some explanation", "exec": true}`)
+	assert.NotContains(t, prompt, "{cmd}", "The placeholders should be replaced.")
+}
+
+// TestEnginePrepareSystemPromptContextPart is a test function for testing that the aliases of the user are told to the model
+func TestEnginePrepareSystemPromptContextPart(t *testing.T) {
+	e := newTestEngine(t, ExecEngineMode, nil)
+	assert.NotContains(t, e.prepareSystemPromptContextPart(), "aliases")
+
+	e.SetAliases(run.Aliases{"ll": "ls -alF"})
+	assert.Contains(t, e.prepareSystemPromptContextPart(), "my shell aliases are ll='ls -alF'")
+}
+
+// TestEngineWrapInput is a test function for testing that the configured prompt prefix and suffix wrap the messages
+// of the user sent to the model, the raw input being kept otherwise
+func TestEngineWrapInput(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("USER_PROMPT_PREFIX", "As a sysadmin,")
+	viper.Set("USER_PROMPT_SUFFIX", " on macOS 14 ")
+	var request openai.ChatCompletionRequest
+	e := newTestEngine(t, ExecEngineMode, func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{
+				Role:    openai.ChatMessageRoleAssistant,
+				Content: `{"cmd":"ls -la", "exp": "list files", "exec": true}`,
+			}}},
+		})
+	})
+
+	_, err := e.ExecCompletion("list files")
+	require.NoError(t, err)
+	last := request.Messages[len(request.Messages)-1]
+	assert.Equal(t, "As a sysadmin, list files on macOS 14", last.Content, "The input should be wrapped.")
+
+	viper.Set("USER_PROMPT_PREFIX", "")
+	viper.Set("USER_PROMPT_SUFFIX", "")
+	e = newTestEngine(t, ExecEngineMode, nil)
+	assert.Equal(t, "list files", e.wrapInput("list files"), "The input should be kept without prefix and suffix.")
+}
+
+// TestEngineCancel is a test function for testing the cancellation of the completion requests in flight
+func TestEngineCancel(t *testing.T) {
+	t.Run("ChatStream", testEngineCancelChatStream)
+	t.Run("Exec", testEngineCancelExec)
+}
+
+// testEngineCancelChatStream tests that a cancelled chat stream sends a last interrupted output
+// and keeps the answer streamed so far.
+func testEngineCancelChatStream(t *testing.T) {
+	e := newTestEngine(t, ChatEngineMode, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"choices":[{"index":0,"delta":{"content":"Once upon "}}]}`+"\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- e.ChatStreamCompletion("tell me a story")
+	}()
+
+	output := <-e.GetChannel()
+	assert.Equal(t, "Once upon ", output.GetContent())
+
+	e.Cancel()
+	output = <-e.GetChannel()
+	assert.True(t, output.IsLast(), "The cancelled stream should send a last output.")
+	assert.True(t, output.IsInterrupt(), "The cancelled stream should be interrupted.")
+	require.NoError(t, <-errs)
+
+	require.Len(t, e.chatMessages, 2, "The answer streamed so far should be kept.")
+	assert.Equal(t, openai.ChatMessageRoleAssistant, e.chatMessages[1].Role)
+	assert.Equal(t, "Once upon ", e.chatMessages[1].Content)
+}
+
+// testEngineCancelExec tests that a cancelled exec request returns ErrInterrupted.
+func testEngineCancelExec(t *testing.T) {
+	received := make(chan struct{})
+	e := newTestEngine(t, ExecEngineMode, func(w http.ResponseWriter, r *http.Request) {
+		// The body is read so the server notices the cancellation of the request
+		io.Copy(io.Discard, r.Body)
+		close(received)
+		<-r.Context().Done()
+	})
+
+	go func() {
+		<-received
+		e.Cancel()
+	}()
+
+	_, err := e.ExecCompletion("list files")
+	assert.ErrorIs(t, err, ErrInterrupted)
+}
+
+// TestEngineExecCompletionWithRetry is a test function for testing that the answers with an invalid JSON object are
+// requested again with a system message asking for the JSON object only, up to the maximum number of retries
+func TestEngineExecCompletionWithRetry(t *testing.T) {
+	t.Run("Retried", testEngineExecCompletionWithRetryRetried)
+	t.Run("Exhausted", testEngineExecCompletionWithRetryExhausted)
+}
+
+// newTestRetryEngine returns an engine answering the given contents in order, and the requests it received.
+func newTestRetryEngine(t *testing.T, contents ...string) (*Engine, *[]openai.ChatCompletionRequest) {
+	t.Helper()
+
+	requests := []openai.ChatCompletionRequest{}
+	e := newTestEngine(t, ExecEngineMode, func(w http.ResponseWriter, r *http.Request) {
+		var request openai.ChatCompletionRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		requests = append(requests, request)
+
+		content := contents[len(contents)-1]
+		if len(requests) <= len(contents) {
+			content = contents[len(requests)-1]
+		}
+		json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{
+				Role:    openai.ChatMessageRoleAssistant,
+				Content: content,
+			}}},
+		})
+	})
+
+	return e, &requests
+}
+
+// testEngineExecCompletionWithRetryRetried tests that a valid answer to a retry is returned.
+func testEngineExecCompletionWithRetryRetried(t *testing.T) {
+	invalid := `Here it is: {"cmd": "ls -la", "exp": } done`
+	e, requests := newTestRetryEngine(t, invalid, invalid, `{"cmd":"ls -la", "exp": "list files", "exec": true}`)
+
+	output, err := e.ExecCompletionWithRetry("list files", 3)
+	require.NoError(t, err)
+	assert.Equal(t, "ls -la", output.GetCommand())
+
+	require.Len(t, *requests, 3, "The request should be sent until the answer is valid.")
+	last := (*requests)[2].Messages[len((*requests)[2].Messages)-1]
+	assert.Equal(t, openai.ChatMessageRoleSystem, last.Role)
+	assert.Equal(t, invalid_json_prompt, last.Content, "The model should be asked for the JSON object only.")
+
+	require.Len(t, e.execMessages, 2, "Only the message of the user and the valid answer should be kept.")
+	assert.Equal(t, openai.ChatMessageRoleUser, e.execMessages[0].Role)
+	assert.Equal(t, `{"cmd":"ls -la", "exp": "list files", "exec": true}`, e.execMessages[1].Content)
+}
+
+// testEngineExecCompletionWithRetryExhausted tests that the error of the first answer is returned once all the
+// retries failed.
+func testEngineExecCompletionWithRetryExhausted(t *testing.T) {
+	e, requests := newTestRetryEngine(t, `{"cmd": "ls -la", "exp": }`, `{"cmd": 1}`)
+
+	_, err := e.ExecCompletionWithRetry("list files", 2)
+	require.Error(t, err)
+	assert.True(t, IsInvalidJSON(err))
+	assert.Contains(t, err.Error(), "invalid character", "The error of the first answer should be returned.")
+	assert.Len(t, *requests, 3, "The request should be sent again up to the maximum number of retries.")
+	require.Len(t, e.execMessages, 1, "The invalid answers and the retries should be removed.")
+	assert.Equal(t, openai.ChatMessageRoleUser, e.execMessages[0].Role)
+
+	_, err = e.ExecCompletionWithRetry("list files", 0)
+	assert.True(t, IsInvalidJSON(err))
+	assert.Len(t, *requests, 4, "The request should not be sent again without retries.")
+}
+
+// TestEngineStreamTokens is a test function for testing that the outputs of a chat stream tell the number of tokens
+// received so far, the last one telling the number of tokens of the usage
+func TestEngineStreamTokens(t *testing.T) {
+	e := newTestEngine(t, ChatEngineMode, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"choices":[{"index":0,"delta":{"content":"Once upon "}}]}`+"\n\n")
+		fmt.Fprint(w, `data: {"choices":[{"index":0,"delta":{"content":"a time."}}]}`+"\n\n")
+		fmt.Fprint(w, `data: {"choices":[],"usage":{"prompt_tokens":5,"completion_tokens":7,"total_tokens":12}}`+"\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- e.ChatStreamCompletion("tell me a story")
+	}()
+
+	tokens := []int{}
+	for output := range e.GetChannel() {
+		tokens = append(tokens, output.GetEstimatedTotalTokens())
+		if output.IsLast() {
+			break
+		}
+	}
+	require.NoError(t, <-errs)
+
+	require.GreaterOrEqual(t, len(tokens), 2)
+	assert.LessOrEqual(t, tokens[0], 2, "The tokens received so far should be counted.")
+	assert.Equal(t, 7, tokens[len(tokens)-1], "The last output should tell the number of tokens of the usage.")
+	assert.Equal(t, 7, e.streamTokens)
+}
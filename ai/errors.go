@@ -0,0 +1,193 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/akhilsharma90/terminal-assistant/config"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// quota_exceeded_code is the code of the errors of the OpenAI API telling the quota of the key is exceeded.
+const quota_exceeded_code = "insufficient_quota"
+
+// ErrUnauthorized is returned by PreloadModel when the key is refused by the OpenAI API.
+var ErrUnauthorized = errors.New("the API key was refused")
+
+// ErrQuotaExceeded is returned by PreloadModel when the quota of the key is exceeded.
+var ErrQuotaExceeded = errors.New("the quota of the API key is exceeded")
+
+// ErrNetworkUnreachable is returned by PreloadModel when the OpenAI API cannot be reached.
+var ErrNetworkUnreachable = errors.New("the API cannot be reached")
+
+// TransientError is an error of a request to the OpenAI API that may succeed when sent again, like a network
+// failure, a timeout or a rate limit.
+type TransientError struct {
+	err error // The error of the request.
+}
+
+// NewTransientError is a function that wraps the error of a request that may succeed when sent again into
+// a TransientError.
+func NewTransientError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &TransientError{err: err}
+}
+
+// Error returns the message of the error of the request.
+func (e *TransientError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the error of the request.
+func (e *TransientError) Unwrap() error {
+	return e.err
+}
+
+// IsTransient is a function that checks if an error is, or wraps, an error of a request that may succeed when
+// sent again.
+func IsTransient(err error) bool {
+	var transientErr *TransientError
+
+	return errors.As(err, &transientErr)
+}
+
+// InvalidJSONError is an error of an answer of the model in exec mode containing a JSON object that cannot be read.
+type InvalidJSONError struct {
+	err error // The error reading the JSON object.
+}
+
+// NewInvalidJSONError is a function that wraps the error reading the JSON object answered by the model into
+// an InvalidJSONError.
+func NewInvalidJSONError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &InvalidJSONError{err: err}
+}
+
+// Error returns the message of the error reading the JSON object.
+func (e *InvalidJSONError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the error reading the JSON object.
+func (e *InvalidJSONError) Unwrap() error {
+	return e.err
+}
+
+// IsInvalidJSON is a function that checks if an error is, or wraps, an error reading the JSON object answered by
+// the model.
+func IsInvalidJSON(err error) bool {
+	var invalidJSONErr *InvalidJSONError
+
+	return errors.As(err, &invalidJSONErr)
+}
+
+// classifyError is a function that wraps the error of a request to the OpenAI API into a TransientError if it may
+// succeed when sent again, or into a config.SetupError if the key is refused. The other errors are returned as is.
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	status := requestStatus(err)
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return setupError(err)
+	case status == http.StatusRequestTimeout || status == http.StatusTooManyRequests || status >= http.StatusInternalServerError:
+		return NewTransientError(err)
+	case status == 0 && (isNetworkError(err) || errors.Is(err, io.ErrUnexpectedEOF)):
+		return NewTransientError(err)
+	}
+
+	return err
+}
+
+// requestStatus is a function that returns the HTTP status code of the error of a request to the OpenAI API, 0 if
+// the API did not answer.
+func requestStatus(err error) int {
+	var apiErr *openai.APIError
+	var requestErr *openai.RequestError
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr.HTTPStatusCode
+	case errors.As(err, &requestErr):
+		return requestErr.HTTPStatusCode
+	}
+
+	return 0
+}
+
+// isNetworkError is a function that checks if the error of a request is a failure of the network or a timeout.
+func isNetworkError(err error) bool {
+	var netErr net.Error
+
+	return errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// isQuotaExceeded is a function that checks if the error of a request tells the quota of the key is exceeded, unlike
+// the rate limits sharing its status code.
+func isQuotaExceeded(err error) bool {
+	var apiErr *openai.APIError
+
+	return errors.As(err, &apiErr) && (apiErr.Type == quota_exceeded_code || fmt.Sprint(apiErr.Code) == quota_exceeded_code)
+}
+
+// ConnectionError is an error of the lookup of the model checking the connection to the OpenAI API, of the kind of
+// ErrUnauthorized, ErrQuotaExceeded or ErrNetworkUnreachable.
+type ConnectionError struct {
+	kind error // The kind of the error.
+	err  error // The error of the request.
+}
+
+// Error returns the kind of the error followed by the message of the error of the request.
+func (e *ConnectionError) Error() string {
+	return fmt.Sprintf("%s: %s", e.kind, e.err)
+}
+
+// Is checks if the error is of a kind, like ErrUnauthorized.
+func (e *ConnectionError) Is(target error) bool {
+	return target == e.kind
+}
+
+// Unwrap returns the error of the request.
+func (e *ConnectionError) Unwrap() error {
+	return e.err
+}
+
+// classifyConnectionError is a function that wraps the error of the lookup of the model checking the connection into
+// a ConnectionError of its kind. The rate limits not exceeding the quota are ignored, the key being accepted, and the
+// other errors are classified like the errors of the completion requests.
+func classifyConnectionError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	status := requestStatus(err)
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return &ConnectionError{kind: ErrUnauthorized, err: classifyError(err)}
+	case status == http.StatusTooManyRequests && isQuotaExceeded(err):
+		return &ConnectionError{kind: ErrQuotaExceeded, err: err}
+	case status == http.StatusTooManyRequests:
+		return nil
+	case status == 0 && isNetworkError(err):
+		return &ConnectionError{kind: ErrNetworkUnreachable, err: classifyError(err)}
+	}
+
+	return classifyError(err)
+}
+
+// setupError is a function that wraps an error of the setup of the engine into a config.SetupError.
+func setupError(err error) error {
+	return config.NewSetupError(err)
+}
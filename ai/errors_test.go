@@ -0,0 +1,78 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/akhilsharma90/terminal-assistant/config"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrors(t *testing.T) {
+	t.Run("ClassifyError", testClassifyError)
+	t.Run("FailedRequest", testFailedRequest)
+}
+
+// testClassifyError tests that the errors of the requests that may succeed when sent again are transient, and that
+// a refused key is an error of the setup.
+func testClassifyError(t *testing.T) {
+	testCases := []struct {
+		name      string
+		err       error
+		transient bool
+		setup     bool
+	}{
+		{"RateLimit", &openai.APIError{HTTPStatusCode: http.StatusTooManyRequests}, true, false},
+		{"ServerError", &openai.RequestError{HTTPStatusCode: http.StatusBadGateway}, true, false},
+		{"Timeout", &openai.APIError{HTTPStatusCode: http.StatusRequestTimeout}, true, false},
+		{"Network", &url.Error{Op: "Post", URL: "https://api.openai.com", Err: errors.New("connection refused")}, true, false},
+		{"Deadline", fmt.Errorf("request: %w", context.DeadlineExceeded), true, false},
+		{"UnexpectedEOF", io.ErrUnexpectedEOF, true, false},
+		{"InvalidKey", &openai.APIError{HTTPStatusCode: http.StatusUnauthorized}, false, true},
+		{"BadRequest", &openai.APIError{HTTPStatusCode: http.StatusBadRequest}, false, false},
+		{"Other", errors.New("invalid field command"), false, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := classifyError(tc.err)
+			assert.Equal(t, tc.transient, IsTransient(err))
+			assert.Equal(t, tc.setup, config.IsSetupError(err))
+			assert.ErrorIs(t, err, tc.err, "The error of the request should be kept.")
+		})
+	}
+
+	assert.NoError(t, classifyError(nil))
+}
+
+// testFailedRequest tests that a failed request returns a classified error and is removed from the conversation,
+// so it can be sent again.
+func testFailedRequest(t *testing.T) {
+	e := newTestEngine(t, ExecEngineMode, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"error": {"message": "Rate limit reached", "type": "requests"}}`)
+	})
+
+	_, err := e.ExecCompletion("list files")
+	require.Error(t, err)
+	assert.True(t, IsTransient(err), "A rate limit should be transient.")
+	assert.Empty(t, e.execMessages, "The failed request should be removed from the conversation.")
+
+	e = newTestEngine(t, ChatEngineMode, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error": {"message": "Incorrect API key provided", "type": "invalid_request_error"}}`)
+	})
+	e.chatMessages = []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "hello"}}
+
+	err = e.ChatStreamCompletion("tell me a story")
+	assert.True(t, config.IsSetupError(err), "A refused key should be an error of the setup.")
+	assert.Len(t, e.chatMessages, 1, "Only the failed request should be removed from the conversation.")
+}
@@ -0,0 +1,99 @@
+package ai
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/sashabaranov/go-openai"
+)
+
+// image_max_size is the maximum size in bytes of an attached image.
+const image_max_size = 20 * 1024 * 1024
+
+// imageContentTypes is the list of the content types of the images the model accepts.
+var imageContentTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp"}
+
+// ErrNotAnImage is returned when the attached file is not a supported image.
+var ErrNotAnImage = errors.New("not a supported image, expected a png, jpeg, gif or webp file")
+
+// ErrImageTooLarge is returned when the attached image exceeds image_max_size.
+var ErrImageTooLarge = fmt.Errorf("image larger than %d MB", image_max_size/1024/1024)
+
+// AttachImage attaches a local image, "~" being expanded, to the next user message as a base64 data URL.
+// The type of the image is detected from its content, whatever its extension.
+func (e *Engine) AttachImage(path string) error {
+	expanded, err := homedir.Expand(path)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(expanded)
+	if err != nil {
+		return err
+	}
+	if info.Size() > image_max_size {
+		return ErrImageTooLarge
+	}
+
+	content, err := os.ReadFile(expanded)
+	if err != nil {
+		return err
+	}
+
+	contentType := http.DetectContentType(content)
+	supported := false
+	for _, imageContentType := range imageContentTypes {
+		if contentType == imageContentType {
+			supported = true
+		}
+	}
+	if !supported {
+		return ErrNotAnImage
+	}
+
+	e.images = append(e.images, fmt.Sprintf("data:%s;base64,%s", contentType, base64.StdEncoding.EncodeToString(content)))
+
+	return nil
+}
+
+// GetImages returns the data URLs of the images attached to the next user message.
+func (e *Engine) GetImages() []string {
+	return e.images
+}
+
+// prepareUserMessage prepares a user message, the attached images being added as image parts after the text
+// and then detached.
+func (e *Engine) prepareUserMessage(content string) openai.ChatCompletionMessage {
+	if len(e.images) == 0 {
+		return openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleUser,
+			Content: content,
+		}
+	}
+
+	parts := []openai.ChatMessagePart{
+		{
+			Type: openai.ChatMessagePartTypeText,
+			Text: content,
+		},
+	}
+	for _, image := range e.images {
+		parts = append(parts, openai.ChatMessagePart{
+			Type: openai.ChatMessagePartTypeImageURL,
+			ImageURL: &openai.ChatMessageImageURL{
+				URL:    image,
+				Detail: openai.ImageURLDetailAuto,
+			},
+		})
+	}
+	e.images = nil
+
+	return openai.ChatCompletionMessage{
+		Role:         openai.ChatMessageRoleUser,
+		MultiContent: parts,
+	}
+}
@@ -0,0 +1,94 @@
+package ai
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// test_png is the signature of a PNG file, enough for its type to be detected.
+const test_png = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+
+func TestEngineImages(t *testing.T) {
+	t.Run("AttachImage", testAttachImage)
+	t.Run("PrepareUserMessage", testPrepareUserMessage)
+	t.Run("ChatStreamCompletionImage", testChatStreamCompletionImage)
+}
+
+// testAttachImage tests the AttachImage method of the Engine type.
+func testAttachImage(t *testing.T) {
+	dir := t.TempDir()
+	image := filepath.Join(dir, "screenshot.png")
+	require.NoError(t, os.WriteFile(image, []byte(test_png), 0600))
+	text := filepath.Join(dir, "notes.png")
+	require.NoError(t, os.WriteFile(text, []byte("not an image"), 0600))
+
+	e := &Engine{}
+	require.NoError(t, e.AttachImage(image))
+	require.Len(t, e.GetImages(), 1)
+	assert.True(t, strings.HasPrefix(e.GetImages()[0], "data:image/png;base64,"), "The image should be encoded as a data URL.")
+
+	assert.ErrorIs(t, e.AttachImage(text), ErrNotAnImage, "The type should be detected from the content.")
+	assert.Error(t, e.AttachImage(filepath.Join(dir, "missing.png")))
+	assert.Len(t, e.GetImages(), 1, "The rejected files should not be attached.")
+
+	e.Reset()
+	assert.Empty(t, e.GetImages(), "The images should be detached on reset.")
+}
+
+// testPrepareUserMessage tests the prepareUserMessage method of the Engine type.
+func testPrepareUserMessage(t *testing.T) {
+	e := &Engine{}
+	message := e.prepareUserMessage("hello")
+	assert.Equal(t, "hello", message.Content)
+	assert.Empty(t, message.MultiContent)
+
+	e.images = []string{"data:image/png;base64,AAAA"}
+	message = e.prepareUserMessage("what is this?")
+	assert.Empty(t, message.Content)
+	require.Len(t, message.MultiContent, 2)
+	assert.Equal(t, openai.ChatMessagePartTypeText, message.MultiContent[0].Type)
+	assert.Equal(t, "what is this?", message.MultiContent[0].Text)
+	assert.Equal(t, openai.ChatMessagePartTypeImageURL, message.MultiContent[1].Type)
+	assert.Equal(t, "data:image/png;base64,AAAA", message.MultiContent[1].ImageURL.URL)
+	assert.Empty(t, e.GetImages(), "The images should only be sent with the next message.")
+}
+
+// testChatStreamCompletionImage tests that the attached images are sent as image_url content parts.
+func testChatStreamCompletionImage(t *testing.T) {
+	body := ""
+	e := newTestEngine(t, ChatEngineMode, func(w http.ResponseWriter, r *http.Request) {
+		content, _ := io.ReadAll(r.Body)
+		body = string(content)
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"A diagram.\"}}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+
+	image := filepath.Join(t.TempDir(), "diagram.png")
+	require.NoError(t, os.WriteFile(image, []byte(test_png), 0600))
+	require.NoError(t, e.AttachImage(image))
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- e.ChatStreamCompletion("what is this?")
+	}()
+	for output := range e.GetChannel() {
+		if output.IsLast() {
+			break
+		}
+	}
+	require.NoError(t, <-errs)
+
+	assert.Contains(t, body, `"type":"image_url"`)
+	assert.Contains(t, body, `"url":"data:image/png;base64,`)
+	assert.Contains(t, body, `"text":"what is this?"`)
+}
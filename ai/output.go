@@ -1,10 +1,69 @@
 package ai
 
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/akhilsharma90/terminal-assistant/config"
+)
+
+// backtickRuns matches the runs of backticks of a command.
+var backtickRuns = regexp.MustCompile("`+")
+
 // EngineExecOutput represents the output of an AI engine execution.
+// It is read from the JSON answered by the AI with parseExecOutput, the names of its fields being configurable.
 type EngineExecOutput struct {
-	Command     string `json:"cmd"`  // Command executed by the AI engine
-	Explanation string `json:"exp"`  // Explanation of the command
-	Executable  bool   `json:"exec"` // Indicates if the command is executable.
+	Command     string // Command executed by the AI engine
+	Explanation string // Explanation of the command
+	Executable  bool   // Indicates if the command is executable.
+	Empty       bool   // Indicates if the command flagged as executable was empty, so it is explained instead.
+}
+
+// parseExecOutput reads the JSON answered by the AI in the exec mode, finding the command, the explanation and the
+// executable flag under the given field names. Like with struct tags, the names are matched case-insensitively when
+// there is no exact match, and the missing fields keep their zero value.
+func parseExecOutput(content string, fields config.ExecOutputFieldNames) (EngineExecOutput, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(content), &raw); err != nil {
+		return EngineExecOutput{}, err
+	}
+
+	var output EngineExecOutput
+	targets := []struct {
+		name  string
+		value interface{}
+	}{
+		{fields.Command, &output.Command},
+		{fields.Explanation, &output.Explanation},
+		{fields.Executable, &output.Executable},
+	}
+	for _, target := range targets {
+		value, ok := lookupField(raw, target.name)
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(value, target.value); err != nil {
+			return EngineExecOutput{}, fmt.Errorf("invalid field %s: %w", target.name, err)
+		}
+	}
+
+	return output.Normalize(), nil
+}
+
+// lookupField returns the value of a field of a JSON object, preferring an exact match of its name.
+func lookupField(raw map[string]json.RawMessage, name string) (json.RawMessage, bool) {
+	if value, ok := raw[name]; ok {
+		return value, true
+	}
+	for key, value := range raw {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+
+	return nil, false
 }
 
 // GetCommand returns the command executed by the AI engine.
@@ -12,6 +71,44 @@ func (eo EngineExecOutput) GetCommand() string {
 	return eo.Command
 }
 
+// GetDisplayCommand returns the command as a markdown code span, safe to render whatever characters it contains.
+// The span is delimited by more backticks than the longest run of backticks of the command, and padded with spaces
+// when the command starts or ends with a backtick, so the command is displayed verbatim.
+func (eo EngineExecOutput) GetDisplayCommand() string {
+	fence := strings.Repeat("`", eo.longestBacktickRun()+1)
+	padding := ""
+	if strings.HasPrefix(eo.Command, "`") || strings.HasSuffix(eo.Command, "`") {
+		padding = " "
+	}
+
+	return fence + padding + eo.Command + padding + fence
+}
+
+// GetDisplayCommandBlock returns the command as a markdown code block tagged with a language, like the shell
+// executing it, so it is highlighted. The block is delimited by more backticks than the longest run of backticks
+// of the command.
+func (eo EngineExecOutput) GetDisplayCommandBlock(language string) string {
+	length := eo.longestBacktickRun() + 1
+	if length < 3 {
+		length = 3
+	}
+	fence := strings.Repeat("`", length)
+
+	return fmt.Sprintf("%s%s\n%s\n%s", fence, language, eo.Command, fence)
+}
+
+// longestBacktickRun returns the length of the longest run of backticks of the command.
+func (eo EngineExecOutput) longestBacktickRun() int {
+	longest := 0
+	for _, backticks := range backtickRuns.FindAllString(eo.Command, -1) {
+		if len(backticks) > longest {
+			longest = len(backticks)
+		}
+	}
+
+	return longest
+}
+
 // GetExplanation returns the explanation of the command executed by the AI engine.
 func (eo EngineExecOutput) GetExplanation() string {
 	return eo.Explanation
@@ -22,12 +119,45 @@ func (eo EngineExecOutput) IsExecutable() bool {
 	return eo.Executable
 }
 
+// HasCommand returns a boolean indicating if the command has a line to execute, other than blank lines or comments.
+func (eo EngineExecOutput) HasCommand() bool {
+	for _, line := range strings.Split(eo.Command, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Normalize returns the output, a command flagged as executable without a line to execute being not executable and
+// flagged as empty, so its explanation is displayed instead of confirming nothing.
+func (eo EngineExecOutput) Normalize() EngineExecOutput {
+	if eo.Executable && !eo.HasCommand() {
+		eo.Command = ""
+		eo.Executable = false
+		eo.Empty = true
+	}
+
+	return eo
+}
+
 // EngineChatStreamOutput represents the output of an AI engine chat stream.
 type EngineChatStreamOutput struct {
 	content    string // The content of the chat stream.
 	last       bool   // Indicates if this is the last output in the chat stream.
 	interrupt  bool   // Indicates if the chat stream was interrupted.
 	executable bool   // Indicates if the content is executable.
+	// The estimated number of tokens of the answer, counted as they arrive and told by the usage on the last output.
+	estimatedTotalTokens int
+}
+
+// NewEngineChatStreamOutput creates an output of a chat stream with a content, the last one ending the stream.
+func NewEngineChatStreamOutput(content string, last bool) EngineChatStreamOutput {
+	return EngineChatStreamOutput{
+		content: content,
+		last:    last,
+	}
 }
 
 // GetContent returns the content of the chat stream.
@@ -49,3 +179,9 @@ func (co EngineChatStreamOutput) IsInterrupt() bool {
 func (co EngineChatStreamOutput) IsExecutable() bool {
 	return co.executable
 }
+
+// GetEstimatedTotalTokens returns the estimated number of tokens of the answer streamed so far, the number of tokens
+// of the whole answer told by the API on the last output.
+func (co EngineChatStreamOutput) GetEstimatedTotalTokens() int {
+	return co.estimatedTotalTokens
+}
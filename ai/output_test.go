@@ -3,6 +3,8 @@ package ai
 import (
 	"testing"
 
+	"github.com/akhilsharma90/terminal-assistant/config"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -14,6 +16,91 @@ func TestEngineExecOutputGetCommand(t *testing.T) {
 	assert.Equal(t, "testCommand", result)
 }
 
+// TestEngineExecOutputGetDisplayCommand is a test function for testing the GetDisplayCommand method of the EngineExecOutput type
+func TestEngineExecOutputGetDisplayCommand(t *testing.T) {
+	testCases := []struct {
+		name     string
+		command  string
+		expected string
+	}{
+		{"Plain", "ls -la", "`ls -la`"},
+		{"SpecialCharacters", `echo "$HOME" !! <b>`, "`echo \"$HOME\" !! <b>`"},
+		{"Backticks", "echo `date`", "`` echo `date` ``"},
+		{"DoubleBackticks", "echo ``", "``` echo `` ```"},
+		{"LeadingBacktick", "`pwd`", "`` `pwd` ``"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			eo := EngineExecOutput{Command: tc.command}
+
+			assert.Equal(t, tc.expected, eo.GetDisplayCommand())
+			assert.Equal(t, tc.command, eo.GetCommand(), "The raw command should be kept for the execution.")
+		})
+	}
+}
+
+// TestEngineExecOutputGetDisplayCommandBlock is a test function for testing the GetDisplayCommandBlock method of the EngineExecOutput type
+func TestEngineExecOutputGetDisplayCommandBlock(t *testing.T) {
+	testCases := []struct {
+		name     string
+		command  string
+		expected string
+	}{
+		{"Plain", "ls -la", "```bash\nls -la\n```"},
+		{"Backticks", "echo `date`", "```bash\necho `date`\n```"},
+		{"Fence", "echo ````", "`````bash\necho ````\n`````"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			eo := EngineExecOutput{Command: tc.command}
+
+			assert.Equal(t, tc.expected, eo.GetDisplayCommandBlock("bash"))
+		})
+	}
+}
+
+// TestParseExecOutput is a test function for testing that the JSON answered by the AI is read with the configured field names
+func TestParseExecOutput(t *testing.T) {
+	defaults := config.ExecOutputFieldNames{Command: "cmd", Explanation: "exp", Executable: "exec"}
+	custom := config.ExecOutputFieldNames{Command: "command", Explanation: "explanation", Executable: "runnable"}
+
+	testCases := []struct {
+		name     string
+		content  string
+		fields   config.ExecOutputFieldNames
+		expected EngineExecOutput
+		err      bool
+	}{
+		{"Default", `{"cmd": "ls", "exp": "list files", "exec": true}`, defaults, EngineExecOutput{Command: "ls", Explanation: "list files", Executable: true}, false},
+		{"Custom", `{"command": "ls", "explanation": "list files", "runnable": true}`, custom, EngineExecOutput{Command: "ls", Explanation: "list files", Executable: true}, false},
+		{"CaseInsensitive", `{"CMD": "ls", "Exp": "list files", "exec": true}`, defaults, EngineExecOutput{Command: "ls", Explanation: "list files", Executable: true}, false},
+		{"Missing", `{"exp": "I cannot do that."}`, defaults, EngineExecOutput{Explanation: "I cannot do that."}, false},
+		{"OtherNames", `{"cmd": "ls", "exp": "list files", "exec": true}`, custom, EngineExecOutput{}, false},
+		{"InvalidType", `{"cmd": "ls", "exec": "yes"}`, defaults, EngineExecOutput{}, true},
+		{"NotJson", "ls -la", defaults, EngineExecOutput{}, true},
+		{"EmptyCommand", `{"cmd": "", "exp": "Nothing to run.", "exec": true}`, defaults, EngineExecOutput{Explanation: "Nothing to run.", Empty: true}, false},
+		{"WhitespaceCommand", `{"cmd": " \n\t", "exp": "Nothing to run.", "exec": true}`, defaults, EngineExecOutput{Explanation: "Nothing to run.", Empty: true}, false},
+		{"CommentCommand", `{"cmd": "# list the files\n  # with ls", "exp": "Use ls.", "exec": true}`, defaults, EngineExecOutput{Explanation: "Use ls.", Empty: true}, false},
+		{"CommentedCommand", `{"cmd": "# list the files\nls", "exp": "list files", "exec": true}`, defaults, EngineExecOutput{Command: "# list the files\nls", Explanation: "list files", Executable: true}, false},
+		{"NotExecutableEmptyCommand", `{"cmd": "", "exp": "I cannot do that.", "exec": false}`, defaults, EngineExecOutput{Explanation: "I cannot do that."}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output, err := parseExecOutput(tc.content, tc.fields)
+
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, output)
+		})
+	}
+}
+
 // TestEngineExecOutputGetExplanation is a test function for testing the GetExplanation method of the EngineExecOutput type
 func TestEngineExecOutputGetExplanation(t *testing.T) {
 	eo := EngineExecOutput{Explanation: "testExplanation"}
@@ -61,3 +148,32 @@ func TestEngineChatStreamOutputIsExecutable(t *testing.T) {
 
 	assert.True(t, result)
 }
+
+// TestNewEngineChatStreamOutput is a test function for testing the NewEngineChatStreamOutput function
+func TestNewEngineChatStreamOutput(t *testing.T) {
+	co := NewEngineChatStreamOutput("testContent", true)
+
+	assert.Equal(t, "testContent", co.GetContent())
+	assert.True(t, co.IsLast())
+	assert.False(t, co.IsInterrupt())
+}
+
+// TestEngineChatStreamOutputGetEstimatedTotalTokens is a test function for testing the estimated number of tokens of
+// the EngineChatStreamOutput type
+func TestEngineChatStreamOutputGetEstimatedTotalTokens(t *testing.T) {
+	co := NewEngineChatStreamOutput("testContent", false)
+	assert.Equal(t, 0, co.GetEstimatedTotalTokens())
+
+	co = newTestChatStreamOutput("testContent", false, 45)
+	assert.Equal(t, 45, co.GetEstimatedTotalTokens())
+	assert.Equal(t, "testContent", co.GetContent(), "The content should be kept.")
+}
+
+// newTestChatStreamOutput creates an output of a chat stream with the estimated number of tokens of the answer
+// streamed so far.
+func newTestChatStreamOutput(content string, last bool, tokens int) EngineChatStreamOutput {
+	co := NewEngineChatStreamOutput(content, last)
+	co.estimatedTotalTokens = tokens
+
+	return co
+}
@@ -0,0 +1,67 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrBinaryPipe is returned when the content piped to the engine is not text, like an image.
+var ErrBinaryPipe = errors.New("the piped content is binary, only text can be piped")
+
+// ErrPipeTooLarge is returned when the content piped to the engine exceeds the configured maximum size, or does not
+// fit in the context window of the model.
+var ErrPipeTooLarge = errors.New("the piped content is too large")
+
+// pipe_bytes_per_token is the estimated number of bytes of a token, capping the size of the piped content to the
+// context window of the model.
+const pipe_bytes_per_token = 4
+
+// PipeSizeError is the error of a content piped to the engine exceeding the maximum size, wrapping ErrPipeTooLarge.
+type PipeSizeError struct {
+	size int // The size of the piped content in bytes.
+	max  int // The maximum size of the piped content in bytes.
+}
+
+// Error returns the size of the piped content and the maximum size.
+func (e *PipeSizeError) Error() string {
+	return fmt.Sprintf("%s: %d bytes, the maximum is %d", ErrPipeTooLarge, e.size, e.max)
+}
+
+// Unwrap returns ErrPipeTooLarge.
+func (e *PipeSizeError) Unwrap() error {
+	return ErrPipeTooLarge
+}
+
+// GetMax returns the maximum size of the piped content in bytes, the piped content being truncated to it.
+func (e *PipeSizeError) GetMax() int {
+	return e.max
+}
+
+// validatePipe checks that a content piped to the engine is text: valid UTF-8 made of printable characters and
+// whitespaces. The escape character is accepted, so the colored outputs of the commands can be piped.
+func validatePipe(pipe string) error {
+	if !utf8.ValidString(pipe) {
+		return ErrBinaryPipe
+	}
+
+	for _, r := range pipe {
+		if r == '\x1b' || unicode.IsPrint(r) || unicode.IsSpace(r) {
+			continue
+		}
+		return ErrBinaryPipe
+	}
+
+	return nil
+}
+
+// checkPipeSize checks that a content piped to the engine does not exceed a maximum size in bytes,
+// the size being unlimited if the maximum is not positive.
+func checkPipeSize(pipe string, max int) error {
+	if max > 0 && len(pipe) > max {
+		return &PipeSizeError{size: len(pipe), max: max}
+	}
+
+	return nil
+}
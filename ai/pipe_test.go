@@ -0,0 +1,69 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestValidatePipe is a test function for testing that only the text contents can be piped to the engine
+func TestValidatePipe(t *testing.T) {
+	testCases := []struct {
+		name  string
+		pipe  string
+		valid bool
+	}{
+		{"Empty", "", true},
+		{"Text", "total 8\ndrwxr-xr-x  2 root root 4096 .\n", true},
+		{"Unicode", "héllo 世界\t✓\r\n", true},
+		{"Colored", "\x1b[31merror\x1b[0m", true},
+		{"NullByte", "hello\x00world", false},
+		{"InvalidUtf8", "\x89PNG\r\n\x1a\n", false},
+		{"ControlCharacter", "hello\x07", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validatePipe(tc.pipe)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrBinaryPipe)
+			}
+		})
+	}
+}
+
+// TestCheckPipeSize is a test function for testing that the pipes exceeding the maximum size are refused
+func TestCheckPipeSize(t *testing.T) {
+	assert.NoError(t, checkPipeSize("12345", 5))
+	assert.NoError(t, checkPipeSize("12345", 0), "The size should be unlimited without maximum.")
+
+	err := checkPipeSize("123456", 5)
+	assert.ErrorIs(t, err, ErrPipeTooLarge)
+	assert.EqualError(t, err, "the piped content is too large: 6 bytes, the maximum is 5")
+}
+
+// TestEngineSetPipeSize is a test function for testing that the engine refuses the pipes exceeding the configured size
+// or the context window
+func TestEngineSetPipeSize(t *testing.T) {
+	e := newTestEngine(t, ChatEngineMode, nil)
+	pipe := strings.Repeat("a", e.config.GetUserConfig().GetMaxPipeSizeBytes())
+
+	assert.NoError(t, e.SetPipe(pipe))
+	assert.ErrorIs(t, e.SetPipe(pipe+"a"), ErrPipeTooLarge)
+	assert.Equal(t, pipe, e.pipe, "The current pipe should be kept.")
+
+	_, err := NewEngine(context.Background(), ChatEngineMode, e.config, WithPipe(pipe+"a"))
+	assert.ErrorIs(t, err, ErrPipeTooLarge, "The too large pipes should be refused before any request.")
+
+	e.contextWindow = 10
+	assert.Equal(t, 10*pipe_bytes_per_token, e.GetMaxPipeSizeBytes(), "The pipe should be capped to the context window.")
+	err = e.SetPipe(strings.Repeat("a", 10*pipe_bytes_per_token+1))
+	var sizeErr *PipeSizeError
+	require.ErrorAs(t, err, &sizeErr)
+	assert.Equal(t, 10*pipe_bytes_per_token, sizeErr.GetMax(), "The maximum size should be told to truncate the pipe.")
+}
@@ -0,0 +1,84 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/akhilsharma90/terminal-assistant/config"
+	"github.com/akhilsharma90/terminal-assistant/run"
+)
+
+// Constants for the built-in run_shell tool.
+const (
+	shell_tool_name       = "run_shell"
+	shell_tool_timeout    = 30 * time.Second
+	shell_tool_max_output = 4000
+)
+
+// shellToolDescription is the description of the run_shell tool given to the model.
+const shellToolDescription = "Execute a non interactive shell command on the machine of the user and get its exit code, stdout and stderr. " +
+	"Only use it to gather information, like listing files or checking versions, never to modify anything."
+
+// shellToolParams is the JSON schema of the arguments of the run_shell tool.
+var shellToolParams = json.RawMessage(`{"type":"object","properties":{"command":{"type":"string","description":"The shell command to execute."}},"required":["command"]}`)
+
+// RegisterShellTool registers the built-in run_shell tool, executing without a TTY the commands allowed by a policy.
+func (e *Engine) RegisterShellTool(policy *run.Policy) *Engine {
+	return e.RegisterTool(shell_tool_name, shellToolDescription, shellToolParams, func(args string) (string, error) {
+		return runShellTool(policy, args)
+	})
+}
+
+// isShellToolEnabled checks if the run_shell tool is enabled in a configuration, it being disabled along with the
+// execution of the commands.
+func isShellToolEnabled(cfg *config.Config) bool {
+	return cfg.GetUserConfig().GetShellTool() && config.IsFeatureEnabled(config.ExecFeature, cfg)
+}
+
+// runShellTool executes the command of a run_shell tool call if it is safe, and returns its result.
+// Commands blocked by the policy, interactive commands and every command that is not strictly read-only, like the
+// destructive commands, the ones writing files or requiring elevated privileges, are refused since they are never
+// confirmed.
+func runShellTool(policy *run.Policy, args string) (string, error) {
+	var params struct {
+		Command string `json:"command"`
+	}
+	if err := json.Unmarshal([]byte(args), &params); err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(params.Command) == "" {
+		return "", errors.New("missing command")
+	}
+	if program, allowed := policy.Check(params.Command); !allowed {
+		return "", fmt.Errorf("%s is not allowed by the policy", program)
+	}
+	if run.IsInteractive(params.Command) {
+		return "", errors.New("interactive commands are not supported")
+	}
+	if !run.IsStrictlyReadOnly(params.Command) {
+		return "", fmt.Errorf("only read-only commands can be run, the risk of this command is %s", run.EstimateExecutionRisk(params.Command))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shell_tool_timeout)
+	defer cancel()
+
+	stdout, stderr, code, err := run.RunCaptured(ctx, params.Command)
+	if code < 0 {
+		return "", err
+	}
+
+	return truncate(fmt.Sprintf("exit code: %d\nstdout:\n%s\nstderr:\n%s", code, stdout, stderr), shell_tool_max_output), nil
+}
+
+// truncate shortens a text to a maximum number of bytes, telling the model it was truncated.
+func truncate(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+
+	return strings.ToValidUTF8(text[:max], "") + "\n[truncated]"
+}
@@ -0,0 +1,51 @@
+package ai
+
+import "fmt"
+
+// EngineStatus represents what the engine is doing while a completion request is in flight, like contacting
+// the model or running a tool it called.
+type EngineStatus struct {
+	message string // The description of what the engine is doing.
+}
+
+// NewEngineStatus creates a status of the engine with the description of what it is doing.
+func NewEngineStatus(message string) EngineStatus {
+	return EngineStatus{message: message}
+}
+
+// GetMessage returns the description of what the engine is doing.
+func (s EngineStatus) GetMessage() string {
+	return s.message
+}
+
+// GetStatusChannel returns the channel of the statuses of the Engine, distinct from the channel of the chat stream
+// outputs. Only the most recent status is kept while it is not received.
+func (e *Engine) GetStatusChannel() <-chan EngineStatus {
+	return e.statuses
+}
+
+// sendStatus sends a status to the status channel without blocking, replacing the status not received yet, as
+// nothing may receive them, like in the programs without the user interface.
+func (e *Engine) sendStatus(message string) {
+	if e.statuses == nil {
+		return
+	}
+
+	status := NewEngineStatus(message)
+	for {
+		select {
+		case e.statuses <- status:
+			return
+		default:
+		}
+		select {
+		case <-e.statuses:
+		default:
+		}
+	}
+}
+
+// sendContactingStatus sends the status of a completion request sent to the model.
+func (e *Engine) sendContactingStatus() {
+	e.sendStatus(fmt.Sprintf("contacting %s", e.config.GetAiConfig().GetModel()))
+}
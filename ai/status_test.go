@@ -0,0 +1,72 @@
+package ai
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiveStatus receives the status waiting on the status channel of an engine, or an empty message if none.
+func receiveStatus(e *Engine) string {
+	select {
+	case status := <-e.GetStatusChannel():
+		return status.GetMessage()
+	default:
+		return ""
+	}
+}
+
+// TestEngineSendStatus is a test function for testing that sendStatus never blocks and keeps the most recent status
+func TestEngineSendStatus(t *testing.T) {
+	e := &Engine{statuses: make(chan EngineStatus, 1)}
+	e.sendStatus("contacting gpt-test")
+	e.sendStatus("running lookup")
+
+	assert.Equal(t, "running lookup", receiveStatus(e), "The status not received should be replaced.")
+	assert.Equal(t, "", receiveStatus(e))
+
+	e = &Engine{}
+	e.sendStatus("contacting gpt-test")
+	assert.Nil(t, e.GetStatusChannel(), "The statuses should be dropped without a status channel.")
+}
+
+// TestEngineStatusCompletion is a test function for testing the statuses sent while contacting the model and
+// running the tools it called
+func TestEngineStatusCompletion(t *testing.T) {
+	statuses := []string{}
+	requests := 0
+	e := newTestEngine(t, ExecEngineMode, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		message := openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleAssistant,
+			Content: `{"cmd":"ls -la", "exp": "list files", "exec": true}`,
+		}
+		if requests == 1 {
+			message.Content = ""
+			message.ToolCalls = []openai.ToolCall{{
+				ID:       "call_1",
+				Type:     openai.ToolTypeFunction,
+				Function: openai.FunctionCall{Name: "lookup", Arguments: `{}`},
+			}}
+		}
+
+		json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{Message: message}},
+		})
+	})
+	e.RegisterTool("lookup", "look something up", json.RawMessage(`{"type":"object"}`), func(string) (string, error) {
+		statuses = append(statuses, receiveStatus(e))
+		return "ls -la", nil
+	})
+
+	assert.Equal(t, "", receiveStatus(e), "No status should be sent before a request.")
+	_, err := e.ExecCompletion("list files")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"running lookup"}, statuses)
+	assert.Equal(t, "contacting gpt-test", receiveStatus(e), "The model should be contacted again with the result of the tool.")
+}
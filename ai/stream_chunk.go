@@ -0,0 +1,68 @@
+package ai
+
+import (
+	"strings"
+	"time"
+)
+
+// streamChunker aggregates the tokens of a chat stream into chunks, so a single output is sent to the channel for
+// several tokens instead of one per token. A chunk is sent once it has enough tokens or once the delay since the last
+// chunk elapsed, the first token of the answer being sent at once. The tokens waiting for the delay are flushed by
+// flushDue, so they are sent even when no other token is received.
+type streamChunker struct {
+	tokens   int              // The number of tokens of a chunk, 0 to only wait for the delay.
+	interval time.Duration    // The delay after which the tokens received so far are sent, 0 to only count the tokens.
+	content  strings.Builder  // The content of the tokens received since the last chunk.
+	count    int              // The number of tokens received since the last chunk.
+	last     time.Time        // The time of the last chunk.
+	now      func() time.Time // The clock of the chunks.
+}
+
+// newStreamChunker creates a new streamChunker sending chunks of a number of tokens, or of the tokens received during
+// a number of milliseconds. Each token is sent on its own when neither is positive.
+func newStreamChunker(tokens int, ms int) *streamChunker {
+	return &streamChunker{
+		tokens:   tokens,
+		interval: time.Duration(ms) * time.Millisecond,
+		now:      time.Now,
+	}
+}
+
+// add adds the content of a token to the chunk, and returns the content of the chunk and true if it must be sent.
+func (c *streamChunker) add(delta string) (string, bool) {
+	c.content.WriteString(delta)
+	c.count++
+
+	full := c.tokens > 0 && c.count >= c.tokens
+	elapsed := c.interval > 0 && c.now().Sub(c.last) >= c.interval
+	if full || elapsed || (c.tokens <= 0 && c.interval <= 0) {
+		return c.flush()
+	}
+
+	return "", false
+}
+
+// flush returns the content of the tokens received since the last chunk and true if there is any, like when the
+// stream ends.
+func (c *streamChunker) flush() (string, bool) {
+	if c.count == 0 {
+		return "", false
+	}
+
+	content := c.content.String()
+	c.content.Reset()
+	c.count = 0
+	c.last = c.now()
+
+	return content, true
+}
+
+// flushDue returns the content of the tokens received since the last chunk and true if there is any and the delay
+// since the last chunk elapsed, like when the stream pauses.
+func (c *streamChunker) flushDue() (string, bool) {
+	if c.interval <= 0 || c.now().Sub(c.last) < c.interval {
+		return "", false
+	}
+
+	return c.flush()
+}
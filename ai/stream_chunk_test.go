@@ -0,0 +1,173 @@
+package ai
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamChunker(t *testing.T) {
+	t.Run("Tokens", testStreamChunkerTokens)
+	t.Run("Interval", testStreamChunkerInterval)
+	t.Run("Unbuffered", testStreamChunkerUnbuffered)
+	t.Run("FlushDue", testStreamChunkerFlushDue)
+	t.Run("ChatStream", testStreamChunkerChatStream)
+	t.Run("StalledChatStream", testStreamChunkerStalledChatStream)
+}
+
+// newTestStreamChunker creates a streamChunker whose clock is advanced by the tests.
+func newTestStreamChunker(tokens int, ms int, clock *time.Time) *streamChunker {
+	chunker := newStreamChunker(tokens, ms)
+	chunker.now = func() time.Time {
+		return *clock
+	}
+
+	return chunker
+}
+
+// testStreamChunkerTokens tests that the tokens are sent once the chunk has enough of them, the first one at once.
+func testStreamChunkerTokens(t *testing.T) {
+	clock := time.Now()
+	chunker := newTestStreamChunker(3, 0, &clock)
+
+	content, send := chunker.add("a")
+	assert.False(t, send, "Without delay, the first token should wait for the chunk to be complete.")
+	assert.Empty(t, content)
+	chunker.add("b")
+	content, send = chunker.add("c")
+	assert.True(t, send)
+	assert.Equal(t, "abc", content)
+
+	chunker.add("d")
+	content, send = chunker.flush()
+	assert.True(t, send, "The tokens left should be flushed at the end of the stream.")
+	assert.Equal(t, "d", content)
+	_, send = chunker.flush()
+	assert.False(t, send, "An empty chunk should not be sent.")
+}
+
+// testStreamChunkerInterval tests that the tokens received during the delay are sent together.
+func testStreamChunkerInterval(t *testing.T) {
+	clock := time.Now()
+	chunker := newTestStreamChunker(0, 50, &clock)
+
+	content, send := chunker.add("a")
+	assert.True(t, send, "The first token should be sent at once.")
+	assert.Equal(t, "a", content)
+
+	clock = clock.Add(20 * time.Millisecond)
+	_, send = chunker.add("b")
+	assert.False(t, send, "The tokens should wait for the delay.")
+	clock = clock.Add(30 * time.Millisecond)
+	content, send = chunker.add("c")
+	assert.True(t, send)
+	assert.Equal(t, "bc", content)
+}
+
+// testStreamChunkerUnbuffered tests that each token is sent on its own without number of tokens or delay.
+func testStreamChunkerUnbuffered(t *testing.T) {
+	clock := time.Now()
+	chunker := newTestStreamChunker(0, 0, &clock)
+
+	for _, token := range []string{"a", "b"} {
+		content, send := chunker.add(token)
+		assert.True(t, send)
+		assert.Equal(t, token, content)
+	}
+}
+
+// testStreamChunkerFlushDue tests that the tokens waiting for the delay are flushed once it elapsed, without another
+// token.
+func testStreamChunkerFlushDue(t *testing.T) {
+	clock := time.Now()
+	chunker := newTestStreamChunker(16, 50, &clock)
+
+	chunker.add("a")
+	clock = clock.Add(10 * time.Millisecond)
+	chunker.add("b")
+	clock = clock.Add(20 * time.Millisecond)
+	_, send := chunker.flushDue()
+	assert.False(t, send, "The tokens should wait for the delay.")
+
+	clock = clock.Add(30 * time.Millisecond)
+	content, send := chunker.flushDue()
+	assert.True(t, send, "The tokens should be flushed once the delay elapsed.")
+	assert.Equal(t, "b", content)
+
+	clock = clock.Add(100 * time.Millisecond)
+	_, send = chunker.flushDue()
+	assert.False(t, send, "An empty chunk should not be sent.")
+	_, send = newTestStreamChunker(1, 0, &clock).flushDue()
+	assert.False(t, send, "Nothing should be flushed without delay.")
+}
+
+// testStreamChunkerChatStream tests that a chat stream sends one output per chunk of the configured number of tokens.
+func testStreamChunkerChatStream(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("USER_STREAM_CHUNK_TOKENS", 4)
+	viper.Set("USER_STREAM_CHUNK_MS", 0)
+	e := newTestEngine(t, ChatEngineMode, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; i < 10; i++ {
+			fmt.Fprintf(w, `data: {"choices":[{"index":0,"delta":{"content":"%d"}}]}`+"\n\n", i)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- e.ChatStreamCompletion("count to ten")
+	}()
+
+	var chunks []string
+	for output := range e.GetChannel() {
+		if output.IsLast() {
+			break
+		}
+		chunks = append(chunks, output.GetContent())
+	}
+	require.NoError(t, <-errs)
+
+	assert.Equal(t, []string{"0123", "4567", "89"}, chunks, "The tokens should be sent in chunks, the last one at the end.")
+	assert.Equal(t, "0123456789", e.chatMessages[1].Content)
+}
+
+// testStreamChunkerStalledChatStream tests that the tokens of a chat stream waiting for the delay are sent while the
+// stream pauses, before the next tokens.
+func testStreamChunkerStalledChatStream(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("USER_STREAM_CHUNK_TOKENS", 100)
+	viper.Set("USER_STREAM_CHUNK_MS", 20)
+	e := newTestEngine(t, ChatEngineMode, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, token := range []string{"a", "b"} {
+			fmt.Fprintf(w, `data: {"choices":[{"index":0,"delta":{"content":"%s"}}]}`+"\n\n", token)
+		}
+		w.(http.Flusher).Flush()
+		time.Sleep(300 * time.Millisecond)
+		fmt.Fprint(w, `data: {"choices":[{"index":0,"delta":{"content":"c"}}]}`+"\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- e.ChatStreamCompletion("say abc")
+	}()
+
+	var chunks []string
+	for output := range e.GetChannel() {
+		if output.IsLast() {
+			break
+		}
+		chunks = append(chunks, output.GetContent())
+	}
+	require.NoError(t, <-errs)
+
+	assert.Equal(t, []string{"a", "b", "c"}, chunks, "The waiting token should be sent during the pause.")
+	assert.Equal(t, "abc", e.chatMessages[1].Content)
+}
@@ -0,0 +1,143 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// max_tool_rounds is the maximum number of tool call rounds of a completion, the model having to answer after it.
+const max_tool_rounds = 5
+
+// ToolFunc is a function executing a tool with the JSON arguments given by the model, and returning its result.
+type ToolFunc func(args string) (string, error)
+
+// Tool represents a local tool the model can call.
+type Tool struct {
+	name        string          // The name of the tool.
+	description string          // The description of the tool, telling the model when to use it.
+	params      json.RawMessage // The JSON schema of the arguments of the tool.
+	fn          ToolFunc        // The function executing the tool.
+}
+
+// GetName returns the name of the tool.
+func (t Tool) GetName() string {
+	return t.name
+}
+
+// GetDescription returns the description of the tool.
+func (t Tool) GetDescription() string {
+	return t.description
+}
+
+// GetParams returns the JSON schema of the arguments of the tool.
+func (t Tool) GetParams() json.RawMessage {
+	return t.params
+}
+
+// RegisterTool registers a tool the model can call, replacing any tool with the same name.
+func (e *Engine) RegisterTool(name string, description string, params json.RawMessage, fn func(args string) (string, error)) *Engine {
+	tool := Tool{
+		name:        name,
+		description: description,
+		params:      params,
+		fn:          fn,
+	}
+
+	for i := range e.tools {
+		if e.tools[i].name == name {
+			e.tools[i] = tool
+			return e
+		}
+	}
+	e.tools = append(e.tools, tool)
+
+	return e
+}
+
+// GetTools returns the tools the model can call.
+func (e *Engine) GetTools() []Tool {
+	return e.tools
+}
+
+// prepareTools prepares the tools sent to the OpenAI API, none being sent once the maximum number of rounds is reached.
+func (e *Engine) prepareTools(round int) []openai.Tool {
+	if len(e.tools) == 0 || round >= max_tool_rounds {
+		return nil
+	}
+
+	tools := make([]openai.Tool, 0, len(e.tools))
+	for _, tool := range e.tools {
+		tools = append(tools, openai.Tool{
+			Type: openai.ToolTypeFunction,
+			Function: &openai.FunctionDefinition{
+				Name:        tool.name,
+				Description: tool.description,
+				Parameters:  tool.params,
+			},
+		})
+	}
+
+	return tools
+}
+
+// callTools dispatches the tool calls of the model and appends the call and the results to the messages.
+func (e *Engine) callTools(content string, calls []openai.ToolCall) *Engine {
+	e.appendMessage(openai.ChatCompletionMessage{
+		Role:      openai.ChatMessageRoleAssistant,
+		Content:   content,
+		ToolCalls: calls,
+	})
+
+	for _, call := range calls {
+		e.sendStatus(fmt.Sprintf("running %s", call.Function.Name))
+		e.appendMessage(openai.ChatCompletionMessage{
+			Role:       openai.ChatMessageRoleTool,
+			Content:    e.callTool(call),
+			ToolCallID: call.ID,
+		})
+	}
+
+	return e
+}
+
+// callTool executes a tool call of the model and returns its result, errors being reported to the model.
+func (e *Engine) callTool(call openai.ToolCall) string {
+	for _, tool := range e.tools {
+		if tool.name == call.Function.Name {
+			result, err := tool.fn(call.Function.Arguments)
+			if err != nil {
+				return fmt.Sprintf("error: %s", err)
+			}
+
+			return result
+		}
+	}
+
+	return fmt.Sprintf("error: unknown tool %s", call.Function.Name)
+}
+
+// mergeToolCallDeltas merges the tool call chunks of a chat stream into the tool calls.
+func mergeToolCallDeltas(calls []openai.ToolCall, deltas []openai.ToolCall) []openai.ToolCall {
+	for _, delta := range deltas {
+		index := len(calls)
+		if delta.Index != nil {
+			index = *delta.Index
+		}
+		for len(calls) <= index {
+			calls = append(calls, openai.ToolCall{Type: openai.ToolTypeFunction})
+		}
+
+		if delta.ID != "" {
+			calls[index].ID = delta.ID
+		}
+		if delta.Type != "" {
+			calls[index].Type = delta.Type
+		}
+		calls[index].Function.Name += delta.Function.Name
+		calls[index].Function.Arguments += delta.Function.Arguments
+	}
+
+	return calls
+}
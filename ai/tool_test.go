@@ -0,0 +1,202 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akhilsharma90/terminal-assistant/config"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineTools(t *testing.T) {
+	t.Run("RegisterTool", testRegisterTool)
+	t.Run("CallTool", testCallTool)
+	t.Run("MergeToolCallDeltas", testMergeToolCallDeltas)
+	t.Run("ExecCompletionToolCall", testExecCompletionToolCall)
+	t.Run("ChatStreamCompletionToolCall", testChatStreamCompletionToolCall)
+}
+
+// newTestEngine creates an engine sending its requests to a fake OpenAI API.
+func newTestEngine(t *testing.T, mode EngineMode, handler http.HandlerFunc) *Engine {
+	t.Helper()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		filepath.Join(dir, "terminal-assistant.json"),
+		[]byte(`{"openai_key": "test_key", "openai_model": "gpt-test"}`),
+		0600,
+	))
+	viper.AddConfigPath(dir)
+
+	cfg, err := config.NewConfig()
+	require.NoError(t, err)
+
+	engine, err := NewEngine(context.Background(), mode, cfg)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	clientConfig := openai.DefaultConfig("test_key")
+	clientConfig.BaseURL = server.URL + "/v1"
+	engine.client = openai.NewClientWithConfig(clientConfig)
+
+	return engine
+}
+
+// testRegisterTool tests the RegisterTool method of the Engine type.
+func testRegisterTool(t *testing.T) {
+	e := &Engine{}
+	e.RegisterTool("first", "a tool", json.RawMessage(`{}`), nil).
+		RegisterTool("second", "another tool", json.RawMessage(`{}`), nil).
+		RegisterTool("first", "a replaced tool", json.RawMessage(`{}`), nil)
+
+	require.Len(t, e.GetTools(), 2)
+	assert.Equal(t, "first", e.GetTools()[0].GetName())
+	assert.Equal(t, "a replaced tool", e.GetTools()[0].GetDescription())
+	assert.Len(t, e.prepareTools(0), 2)
+	assert.Nil(t, e.prepareTools(max_tool_rounds), "No tools should be sent once the maximum number of rounds is reached.")
+}
+
+// testCallTool tests the callTool method of the Engine type.
+func testCallTool(t *testing.T) {
+	e := &Engine{}
+	e.RegisterTool("echo", "", nil, func(args string) (string, error) {
+		return args, nil
+	})
+	e.RegisterTool("fail", "", nil, func(args string) (string, error) {
+		return "", fmt.Errorf("failed")
+	})
+
+	assert.Equal(t, `{"a":1}`, e.callTool(openai.ToolCall{Function: openai.FunctionCall{Name: "echo", Arguments: `{"a":1}`}}))
+	assert.Equal(t, "error: failed", e.callTool(openai.ToolCall{Function: openai.FunctionCall{Name: "fail"}}))
+	assert.Equal(t, "error: unknown tool missing", e.callTool(openai.ToolCall{Function: openai.FunctionCall{Name: "missing"}}))
+}
+
+// testMergeToolCallDeltas tests the mergeToolCallDeltas function.
+func testMergeToolCallDeltas(t *testing.T) {
+	zero, one := 0, 1
+
+	calls := mergeToolCallDeltas(nil, []openai.ToolCall{
+		{Index: &zero, ID: "call_1", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "run_shell", Arguments: `{"comm`}},
+	})
+	calls = mergeToolCallDeltas(calls, []openai.ToolCall{
+		{Index: &zero, Function: openai.FunctionCall{Arguments: `and":"ls"}`}},
+		{Index: &one, ID: "call_2", Function: openai.FunctionCall{Name: "other"}},
+	})
+
+	require.Len(t, calls, 2)
+	assert.Equal(t, "call_1", calls[0].ID)
+	assert.Equal(t, "run_shell", calls[0].Function.Name)
+	assert.Equal(t, `{"command":"ls"}`, calls[0].Function.Arguments)
+	assert.Equal(t, "call_2", calls[1].ID)
+	assert.Equal(t, openai.ToolTypeFunction, calls[1].Type)
+}
+
+// testExecCompletionToolCall tests that ExecCompletion dispatches the tool calls and feeds their results back.
+func testExecCompletionToolCall(t *testing.T) {
+	requests := []openai.ChatCompletionRequest{}
+	e := newTestEngine(t, ExecEngineMode, func(w http.ResponseWriter, r *http.Request) {
+		var request openai.ChatCompletionRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		requests = append(requests, request)
+
+		message := openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleAssistant,
+			Content: `{"cmd":"ls -la", "exp": "list files", "exec": true}`,
+		}
+		if len(requests) == 1 {
+			message = openai.ChatCompletionMessage{
+				Role: openai.ChatMessageRoleAssistant,
+				ToolCalls: []openai.ToolCall{{
+					ID:       "call_1",
+					Type:     openai.ToolTypeFunction,
+					Function: openai.FunctionCall{Name: "lookup", Arguments: `{"key":"files"}`},
+				}},
+			}
+		}
+
+		json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{Message: message}},
+		})
+	})
+
+	args := ""
+	e.RegisterTool("lookup", "look something up", json.RawMessage(`{"type":"object"}`), func(a string) (string, error) {
+		args = a
+		return "ls -la", nil
+	})
+
+	output, err := e.ExecCompletion("list files")
+	require.NoError(t, err)
+
+	assert.Equal(t, "ls -la", output.GetCommand())
+	assert.Equal(t, `{"key":"files"}`, args, "The tool should be called with the arguments of the model.")
+	require.Len(t, requests, 2)
+	assert.Equal(t, "lookup", requests[0].Tools[0].Function.Name, "The tools should be sent to the model.")
+
+	result := requests[1].Messages[len(requests[1].Messages)-1]
+	assert.Equal(t, openai.ChatMessageRoleTool, result.Role, "The tool result should be fed back.")
+	assert.Equal(t, "call_1", result.ToolCallID)
+	assert.Equal(t, "ls -la", result.Content)
+}
+
+// testChatStreamCompletionToolCall tests that ChatStreamCompletion dispatches the streamed tool calls and continues the conversation.
+func testChatStreamCompletionToolCall(t *testing.T) {
+	requests := 0
+	e := newTestEngine(t, ChatEngineMode, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		chunks := []string{
+			`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"lookup","arguments":"{\"key\""}}]}}]}`,
+			`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":":\"files\"}"}}]}}]}`,
+		}
+		if requests > 1 {
+			chunks = []string{
+				`{"choices":[{"index":0,"delta":{"content":"There are "}}]}`,
+				`{"choices":[{"index":0,"delta":{"content":"3 files."}}]}`,
+			}
+		}
+		for _, chunk := range chunks {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+
+	args := ""
+	e.RegisterTool("lookup", "look something up", json.RawMessage(`{"type":"object"}`), func(a string) (string, error) {
+		args = a
+		return "3", nil
+	})
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- e.ChatStreamCompletion("how many files?")
+	}()
+
+	content := ""
+	for output := range e.GetChannel() {
+		content += output.GetContent()
+		if output.IsLast() {
+			break
+		}
+	}
+	require.NoError(t, <-errs)
+
+	assert.Equal(t, "There are 3 files.", content)
+	assert.Equal(t, `{"key":"files"}`, args, "The streamed tool call should be dispatched.")
+	assert.Equal(t, 2, requests)
+	require.Len(t, e.chatMessages, 4, "The user, tool call, tool result and assistant messages should be kept.")
+	assert.Equal(t, openai.ChatMessageRoleTool, e.chatMessages[2].Role)
+}
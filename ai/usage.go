@@ -0,0 +1,77 @@
+package ai
+
+import (
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// modelPrice is the price of a model in US dollars per million tokens.
+type modelPrice struct {
+	prompt     float64 // The price of the prompt tokens.
+	completion float64 // The price of the completion tokens.
+}
+
+// modelPrices are the prices of the known models, the dated versions of a model having the price of the model.
+var modelPrices = map[string]modelPrice{
+	"gpt-3.5-turbo": {prompt: 0.5, completion: 1.5},
+	"gpt-4":         {prompt: 30, completion: 60},
+	"gpt-4-turbo":   {prompt: 10, completion: 30},
+	"gpt-4o":        {prompt: 2.5, completion: 10},
+	"gpt-4o-mini":   {prompt: 0.15, completion: 0.6},
+}
+
+// EngineUsage represents the number of tokens used by the completion requests of an engine.
+type EngineUsage struct {
+	promptTokens     int // The number of tokens of the prompts.
+	completionTokens int // The number of tokens of the completions.
+}
+
+// GetPromptTokens returns the number of tokens of the prompts.
+func (eu EngineUsage) GetPromptTokens() int {
+	return eu.promptTokens
+}
+
+// GetCompletionTokens returns the number of tokens of the completions.
+func (eu EngineUsage) GetCompletionTokens() int {
+	return eu.completionTokens
+}
+
+// GetTotalTokens returns the total number of tokens.
+func (eu EngineUsage) GetTotalTokens() int {
+	return eu.promptTokens + eu.completionTokens
+}
+
+// EstimateCost returns the estimated cost in US dollars of the tokens used with a model,
+// false being returned if the price of the model is unknown.
+func (eu EngineUsage) EstimateCost(model string) (float64, bool) {
+	price, ok := findModelPrice(model)
+	if !ok {
+		return 0, false
+	}
+
+	return (float64(eu.promptTokens)*price.prompt + float64(eu.completionTokens)*price.completion) / 1e6, true
+}
+
+// add returns the usage increased by the usage of a completion request.
+func (eu EngineUsage) add(usage openai.Usage) EngineUsage {
+	eu.promptTokens += usage.PromptTokens
+	eu.completionTokens += usage.CompletionTokens
+
+	return eu
+}
+
+// findModelPrice returns the price of a model, or of the longest known model prefixing its name.
+func findModelPrice(model string) (modelPrice, bool) {
+	var found string
+	for name := range modelPrices {
+		if (model == name || strings.HasPrefix(model, name+"-")) && len(name) > len(found) {
+			found = name
+		}
+	}
+	if found == "" {
+		return modelPrice{}, false
+	}
+
+	return modelPrices[found], true
+}
@@ -0,0 +1,40 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngineUsage(t *testing.T) {
+	t.Run("Add", testEngineUsageAdd)
+	t.Run("EstimateCost", testEngineUsageEstimateCost)
+}
+
+// testEngineUsageAdd tests the counting of the tokens of the completion requests.
+func testEngineUsageAdd(t *testing.T) {
+	usage := EngineUsage{}.
+		add(openai.Usage{PromptTokens: 100, CompletionTokens: 20}).
+		add(openai.Usage{PromptTokens: 50, CompletionTokens: 5})
+
+	assert.Equal(t, 150, usage.GetPromptTokens(), "The prompt tokens should be summed.")
+	assert.Equal(t, 25, usage.GetCompletionTokens(), "The completion tokens should be summed.")
+	assert.Equal(t, 175, usage.GetTotalTokens(), "The total tokens should be summed.")
+}
+
+// testEngineUsageEstimateCost tests the EstimateCost method of the EngineUsage struct.
+func testEngineUsageEstimateCost(t *testing.T) {
+	usage := EngineUsage{promptTokens: 1000000, completionTokens: 1000000}
+
+	cost, ok := usage.EstimateCost("gpt-4o")
+	assert.True(t, ok, "The price of the model should be known.")
+	assert.InDelta(t, 12.5, cost, 0.0001, "The cost should be computed from the price of the model.")
+
+	cost, ok = usage.EstimateCost("gpt-4o-mini-2024-07-18")
+	assert.True(t, ok, "The price of a dated version should be known.")
+	assert.InDelta(t, 0.75, cost, 0.0001, "The dated version should have the price of the longest matching model.")
+
+	_, ok = usage.EstimateCost("llama3")
+	assert.False(t, ok, "The price of an unknown model should not be known.")
+}
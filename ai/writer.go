@@ -0,0 +1,21 @@
+package ai
+
+import "io"
+
+// chanWriter adapts the channel of the outputs of a chat stream to a writer, the content of each output being
+// written as it arrives.
+type chanWriter struct {
+	w   io.Writer // the writer the content is written to
+	err error     // the first error of the writer, the content of the next outputs being discarded
+}
+
+// write writes the content of an output of the chat stream, and returns whether the writer failed
+func (c *chanWriter) write(output EngineChatStreamOutput) bool {
+	if c.err != nil || output.GetContent() == "" {
+		return false
+	}
+
+	_, c.err = io.WriteString(c.w, output.GetContent())
+
+	return c.err != nil
+}
@@ -0,0 +1,57 @@
+package ai
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingWriter is a writer failing at every write, like a closed pipe.
+type failingWriter struct{}
+
+// Write returns an error without writing anything.
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func TestChatStreamCompletionWriter(t *testing.T) {
+	t.Run("Write", testChatStreamCompletionWriterWrite)
+	t.Run("WriterError", testChatStreamCompletionWriterError)
+}
+
+// testChatStreamCompletionWriterWrite tests that the streamed content is written as it arrives and kept in the
+// conversation.
+func testChatStreamCompletionWriterWrite(t *testing.T) {
+	e := newTestEngine(t, ChatEngineMode, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"choices":[{"index":0,"delta":{"content":"Once upon "}}]}`+"\n\n")
+		fmt.Fprint(w, `data: {"choices":[{"index":0,"delta":{"content":"a time."}}]}`+"\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
+
+	var out bytes.Buffer
+	require.NoError(t, e.ChatStreamCompletionWriter("tell me a story", &out))
+
+	assert.Equal(t, "Once upon a time.", out.String())
+	require.Len(t, e.chatMessages, 2)
+	assert.Equal(t, "Once upon a time.", e.chatMessages[1].Content)
+}
+
+// testChatStreamCompletionWriterError tests that the request is cancelled when the writer fails, its error being returned.
+func testChatStreamCompletionWriterError(t *testing.T) {
+	e := newTestEngine(t, ChatEngineMode, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"choices":[{"index":0,"delta":{"content":"Once upon "}}]}`+"\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+
+	err := e.ChatStreamCompletionWriter("tell me a story", failingWriter{})
+
+	assert.EqualError(t, err, "broken pipe")
+}
@@ -0,0 +1,251 @@
+package completions
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/akhilsharma90/terminal-assistant/system"
+	"github.com/akhilsharma90/terminal-assistant/ui"
+)
+
+// Shells whose completion scripts can be generated.
+const (
+	BashShell       = "bash"
+	ZshShell        = "zsh"
+	FishShell       = "fish"
+	PowershellShell = "powershell"
+)
+
+// shells are the shells whose completion scripts can be generated, in the order they are listed.
+var shells = []string{BashShell, ZshShell, FishShell, PowershellShell}
+
+// flagValues are the values completed after the flags taking one of a few values.
+var flagValues = map[string][]string{
+	"format":      {"text", "json"},
+	"completions": shells,
+}
+
+// fileFlags are the flags taking a path, after which the files are completed.
+var fileFlags = map[string]bool{
+	"o":      true,
+	"output": true,
+	"image":  true,
+}
+
+// completedFlag is a struct that represents a command-line flag of the program to complete.
+type completedFlag struct {
+	name    string   // The name of the flag, without dashes.
+	usage   string   // The description of the flag.
+	boolean bool     // Whether the flag takes no value.
+	values  []string // The values completed after the flag, if it takes one of a few values.
+	file    bool     // Whether the flag takes a path.
+}
+
+// option is a method that returns the flag as typed, with one dash for the single letter flags and two otherwise.
+func (f completedFlag) option() string {
+	if len(f.name) == 1 {
+		return "-" + f.name
+	}
+
+	return "--" + f.name
+}
+
+// Generate is a function that returns the completion script of a shell, completing the flags of the program, their
+// values and the slash commands. An error is returned if the shell is not supported.
+func Generate(shell string) (string, error) {
+	flags := listFlags()
+	slashCommands := listSlashCommands()
+
+	switch strings.ToLower(shell) {
+	case BashShell:
+		return generateBash(flags, slashCommands), nil
+	case ZshShell:
+		return generateZsh(flags, slashCommands), nil
+	case FishShell:
+		return generateFish(flags, slashCommands), nil
+	case PowershellShell:
+		return generatePowershell(flags, slashCommands), nil
+	}
+
+	return "", fmt.Errorf("unknown shell %q, use one of %s", shell, strings.Join(shells, ", "))
+}
+
+// listFlags is a function that returns the command-line flags of the program, sorted by name.
+func listFlags() []completedFlag {
+	var flags []completedFlag
+	ui.NewFlagSet().VisitAll(func(f *flag.Flag) {
+		boolean := false
+		if value, ok := f.Value.(interface{ IsBoolFlag() bool }); ok {
+			boolean = value.IsBoolFlag()
+		}
+		flags = append(flags, completedFlag{
+			name:    f.Name,
+			usage:   f.Usage,
+			boolean: boolean,
+			values:  flagValues[f.Name],
+			file:    fileFlags[f.Name],
+		})
+	})
+	sort.Slice(flags, func(i, j int) bool {
+		return flags[i].name < flags[j].name
+	})
+
+	return flags
+}
+
+// listSlashCommands is a function that returns the slash commands, with their slash.
+func listSlashCommands() []string {
+	var commands []string
+	for _, name := range ui.SlashCommands() {
+		commands = append(commands, "/"+name)
+	}
+
+	return commands
+}
+
+// generateBash is a function that returns the completion script of bash, the files being completed by default.
+func generateBash(flags []completedFlag, slashCommands []string) string {
+	var options []string
+	var script strings.Builder
+	function := "_" + strings.ReplaceAll(system.APPLICATION_NAME, "-", "_")
+
+	fmt.Fprintf(&script, "# bash completion for %s\n", system.APPLICATION_NAME)
+	fmt.Fprintf(&script, "%s() {\n", function)
+	script.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\" prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	script.WriteString("    case \"$prev\" in\n")
+	for _, f := range flags {
+		options = append(options, f.option())
+		switch {
+		case len(f.values) > 0:
+			fmt.Fprintf(&script, "        %s) COMPREPLY=($(compgen -W \"%s\" -- \"$cur\")); return ;;\n", f.option(), strings.Join(f.values, " "))
+		case f.file:
+			fmt.Fprintf(&script, "        %s) return ;;\n", f.option())
+		}
+	}
+	script.WriteString("    esac\n")
+	script.WriteString("    case \"$cur\" in\n")
+	fmt.Fprintf(&script, "        -*) COMPREPLY=($(compgen -W \"%s\" -- \"$cur\")) ;;\n", strings.Join(options, " "))
+	fmt.Fprintf(&script, "        /*) COMPREPLY=($(compgen -W \"%s\" -- \"$cur\")) ;;\n", strings.Join(slashCommands, " "))
+	script.WriteString("    esac\n")
+	script.WriteString("}\n")
+	fmt.Fprintf(&script, "complete -o default -F %s %s\n", function, system.APPLICATION_NAME)
+
+	return script.String()
+}
+
+// generateZsh is a function that returns the completion script of zsh, usable from the fpath or sourced.
+func generateZsh(flags []completedFlag, slashCommands []string) string {
+	var script strings.Builder
+	function := "_" + strings.ReplaceAll(system.APPLICATION_NAME, "-", "_")
+
+	fmt.Fprintf(&script, "#compdef %s\n\n", system.APPLICATION_NAME)
+	fmt.Fprintf(&script, "%s() {\n", function)
+	script.WriteString("    _arguments \\\n")
+	for _, f := range flags {
+		spec := fmt.Sprintf("%s[%s]", f.option(), escapeZsh(f.usage))
+		switch {
+		case len(f.values) > 0:
+			spec += fmt.Sprintf(":%s:(%s)", f.name, strings.Join(f.values, " "))
+		case f.file:
+			spec += ":file:_files"
+		case !f.boolean:
+			spec += fmt.Sprintf(":%s:", f.name)
+		}
+		if f.name == "image" {
+			spec = "*" + spec
+		}
+		fmt.Fprintf(&script, "        '%s' \\\n", spec)
+	}
+	fmt.Fprintf(&script, "        '*:question:(%s)'\n", strings.Join(slashCommands, " "))
+	script.WriteString("}\n\n")
+	fmt.Fprintf(&script, "compdef %s %s\n", function, system.APPLICATION_NAME)
+
+	return script.String()
+}
+
+// generateFish is a function that returns the completion script of fish.
+func generateFish(flags []completedFlag, slashCommands []string) string {
+	var script strings.Builder
+
+	fmt.Fprintf(&script, "# fish completion for %s\n", system.APPLICATION_NAME)
+	for _, f := range flags {
+		option := "-l " + f.name
+		if len(f.name) == 1 {
+			option = "-s " + f.name
+		}
+		switch {
+		case len(f.values) > 0:
+			option += fmt.Sprintf(" -x -a '%s'", strings.Join(f.values, " "))
+		case f.file:
+			option += " -r -F"
+		case !f.boolean:
+			option += " -x"
+		}
+		fmt.Fprintf(&script, "complete -c %s %s -d '%s'\n", system.APPLICATION_NAME, option, escapeFish(f.usage))
+	}
+	fmt.Fprintf(
+		&script,
+		"complete -c %s -n 'string match -q -- \"/*\" (commandline -ct)' -f -a '%s' -d 'slash command'\n",
+		system.APPLICATION_NAME,
+		strings.Join(slashCommands, " "),
+	)
+
+	return script.String()
+}
+
+// generatePowershell is a function that returns the completion script of PowerShell.
+func generatePowershell(flags []completedFlag, slashCommands []string) string {
+	var script strings.Builder
+
+	fmt.Fprintf(&script, "# PowerShell completion for %s\n", system.APPLICATION_NAME)
+	fmt.Fprintf(&script, "Register-ArgumentCompleter -Native -CommandName '%s' -ScriptBlock {\n", system.APPLICATION_NAME)
+	script.WriteString("    param($wordToComplete, $commandAst, $cursorPosition)\n")
+	script.WriteString("    $flags = [ordered]@{\n")
+	for _, f := range flags {
+		fmt.Fprintf(&script, "        '%s' = '%s'\n", f.option(), escapePowershell(f.usage))
+	}
+	script.WriteString("    }\n")
+	script.WriteString("    $values = @{\n")
+	for _, f := range flags {
+		if len(f.values) > 0 {
+			fmt.Fprintf(&script, "        '%s' = @('%s')\n", f.option(), strings.Join(f.values, "', '"))
+		}
+	}
+	script.WriteString("    }\n")
+	fmt.Fprintf(&script, "    $slashCommands = @('%s')\n", strings.Join(slashCommands, "', '"))
+	script.WriteString("    $elements = @($commandAst.CommandElements | ForEach-Object { $_.ToString() })\n")
+	script.WriteString("    $previous = if ($wordToComplete) { $elements[-2] } else { $elements[-1] }\n")
+	script.WriteString("    if ($values.Contains($previous)) {\n")
+	script.WriteString("        $candidates = $values[$previous]\n")
+	script.WriteString("    } elseif ($wordToComplete.StartsWith('/')) {\n")
+	script.WriteString("        $candidates = $slashCommands\n")
+	script.WriteString("    } elseif ($wordToComplete.StartsWith('-')) {\n")
+	script.WriteString("        $candidates = $flags.Keys\n")
+	script.WriteString("    } else {\n")
+	script.WriteString("        return\n")
+	script.WriteString("    }\n")
+	script.WriteString("    $candidates | Where-Object { $_ -like \"$wordToComplete*\" } | ForEach-Object {\n")
+	script.WriteString("        $tooltip = if ($flags.Contains($_)) { $flags[$_] } else { $_ }\n")
+	script.WriteString("        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $tooltip)\n")
+	script.WriteString("    }\n")
+	script.WriteString("}\n")
+
+	return script.String()
+}
+
+// escapeZsh is a function that escapes a description in a single-quoted spec of the _arguments function of zsh.
+func escapeZsh(description string) string {
+	return strings.NewReplacer("'", `'\''`, "[", `\[`, "]", `\]`, ":", `\:`).Replace(description)
+}
+
+// escapeFish is a function that escapes a description in a single-quoted string of fish.
+func escapeFish(description string) string {
+	return strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(description)
+}
+
+// escapePowershell is a function that escapes a description in a single-quoted string of PowerShell.
+func escapePowershell(description string) string {
+	return strings.ReplaceAll(description, "'", "''")
+}
@@ -0,0 +1,68 @@
+package completions
+
+import (
+	"testing"
+
+	"github.com/akhilsharma90/terminal-assistant/system"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompletions(t *testing.T) {
+	t.Run("Generate", testGenerate)
+	t.Run("UnknownShell", testUnknownShell)
+	t.Run("ListFlags", testListFlags)
+	t.Run("Escape", testEscape)
+}
+
+// testGenerate tests that the script of every shell completes the flags, their values and the slash commands.
+func testGenerate(t *testing.T) {
+	for _, shell := range shells {
+		t.Run(shell, func(t *testing.T) {
+			script, err := Generate(shell)
+			require.NoError(t, err)
+
+			assert.Contains(t, script, system.APPLICATION_NAME)
+			assert.Contains(t, script, "format")
+			assert.Contains(t, script, "json")
+			assert.Contains(t, script, "powershell")
+			assert.Contains(t, script, "/history")
+		})
+	}
+
+	script, err := Generate("BASH")
+	require.NoError(t, err, "The shell should be case insensitive.")
+	assert.Contains(t, script, `--format) COMPREPLY=($(compgen -W "text json" -- "$cur")); return ;;`)
+	assert.Contains(t, script, "complete -o default -F _terminal_assistant terminal-assistant")
+}
+
+// testUnknownShell tests that an error is returned for the unsupported shells.
+func testUnknownShell(t *testing.T) {
+	_, err := Generate("tcsh")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tcsh")
+}
+
+// testListFlags tests that the flags of the program are listed with their kind and values.
+func testListFlags(t *testing.T) {
+	flags := map[string]completedFlag{}
+	for _, f := range listFlags() {
+		flags[f.name] = f
+	}
+
+	assert.True(t, flags["e"].boolean)
+	assert.Equal(t, "-e", flags["e"].option())
+	assert.Equal(t, []string{"text", "json"}, flags["format"].values)
+	assert.Equal(t, "--format", flags["format"].option())
+	assert.False(t, flags["format"].boolean)
+	assert.True(t, flags["output"].file)
+	assert.True(t, flags["image"].file)
+	assert.Contains(t, flags, "completions")
+}
+
+// testEscape tests that the descriptions are escaped in the single-quoted strings of the shells.
+func testEscape(t *testing.T) {
+	assert.Equal(t, `it'\''s \[a\]\: b`, escapeZsh("it's [a]: b"))
+	assert.Equal(t, `it\'s \\`, escapeFish(`it's \`))
+	assert.Equal(t, "it''s", escapePowershell("it's"))
+}
@@ -2,6 +2,9 @@ package config
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/sashabaranov/go-openai"
@@ -11,9 +14,10 @@ import (
 )
 
 type Config struct {
-	ai     AiConfig         // ai config
-	user   UserConfig       // user config
-	system *system.Analysis // system config
+	ai         AiConfig         // ai config
+	user       UserConfig       // user config
+	system     *system.Analysis // system config
+	backupFile string           // backup of the previous config file, if any
 }
 
 // GetAiConfig returns the ai config
@@ -31,18 +35,26 @@ func (c *Config) GetSystemConfig() *system.Analysis {
 	return c.system
 }
 
+// GetBackupFile returns the path of the backup made before the config file was overwritten, if any
+func (c *Config) GetBackupFile() string {
+	return c.backupFile
+}
+
 // NewConfig creates a new Config instance by reading the configuration from the file.
 // It sets the default values for AI and user configurations if they are not present in the file.
 func NewConfig() (*Config, error) {
 	system := system.Analyse()
 
+	// Set the defaults for the keys missing from the file
+	setDefaults()
+
 	// Set the configuration file name and path
 	viper.SetConfigName(strings.ToLower(system.GetApplicationName()))
 	viper.AddConfigPath(fmt.Sprintf("%s/.config/", system.GetHomeDirectory()))
 
 	// Read the configuration from the file
 	if err := viper.ReadInConfig(); err != nil {
-		return nil, err
+		return nil, NewSetupError(err)
 	}
 
 	// Create a new Config instance with the read configuration values
@@ -55,8 +67,55 @@ func NewConfig() (*Config, error) {
 			maxTokens:   viper.GetInt(openai_max_tokens),
 		},
 		user: UserConfig{
-			defaultPromptMode: viper.GetString(user_default_prompt_mode),
-			preferences:       viper.GetString(user_preferences),
+			defaultPromptMode:         viper.GetString(user_default_prompt_mode),
+			preferences:               viper.GetString(user_preferences),
+			captureOutput:             viper.GetBool(user_capture_output),
+			maxHistorySize:            viper.GetInt(user_max_history_size),
+			blockElevation:            viper.GetBool(user_block_elevation),
+			fixFailedCommands:         viper.GetBool(user_fix_failed_commands),
+			fixMaxAttempts:            viper.GetInt(user_fix_max_attempts),
+			sandbox:                   viper.GetString(user_sandbox),
+			sandboxImage:              viper.GetString(user_sandbox_image),
+			execAllowlist:             viper.GetStringSlice(user_exec_allowlist),
+			execBlocklist:             viper.GetStringSlice(user_exec_blocklist),
+			shellTool:                 viper.GetBool(user_shell_tool),
+			autoExecSafeCommands:      viper.GetBool(user_auto_exec_safe),
+			resolveAliases:            viper.GetBool(user_resolve_aliases),
+			promptMaxHeight:           viper.GetInt(user_prompt_max_height),
+			defaultContextFiles:       viper.GetStringSlice(user_default_context),
+			statusBar:                 viper.GetBool(user_status_bar),
+			copyKey:                   viper.GetString(user_copy_key),
+			copyCodeBlock:             viper.GetBool(user_copy_code_block),
+			filterHistoryByMode:       viper.GetBool(user_filter_history),
+			spinnerStyle:              viper.GetString(user_spinner_style),
+			spinnerLabel:              viper.GetString(user_spinner_label),
+			codeStyle:                 viper.GetString(user_code_style),
+			maxPipeSizeBytes:          viper.GetInt(user_max_pipe_size),
+			truncatePipe:              viper.GetBool(user_truncate_pipe),
+			footerHints:               viper.GetBool(user_footer_hints),
+			promptIndicators:          ReadPromptIndicators(),
+			welcomeMessage:            viper.GetString(user_welcome_message),
+			mouse:                     viper.GetBool(user_mouse),
+			execOutputFields:          parseExecOutputFields(viper.GetStringMapString(user_exec_output_fields)),
+			editingMode:               viper.GetString(user_editing_mode),
+			autosuggest:               viper.GetBool(user_autosuggest),
+			confirmationWord:          viper.GetString(user_confirmation_word),
+			confirmationCaseSensitive: viper.GetBool(user_confirmation_case_sensitive),
+			retryKey:                  viper.GetString(user_retry_key),
+			maxContextTokens:          viper.GetInt(user_max_context_tokens),
+			notifyAfterSeconds:        viper.GetInt(user_notify_after_seconds),
+			transcriptKey:             viper.GetString(user_transcript_key),
+			transcriptDir:             viper.GetString(user_transcript_dir),
+			streamChunkTokens:         viper.GetInt(user_stream_chunk_tokens),
+			streamChunkMs:             viper.GetInt(user_stream_chunk_ms),
+			theme:                     viper.GetString(user_theme),
+			promptPrefix:              viper.GetString(user_prompt_prefix),
+			promptSuffix:              viper.GetString(user_prompt_suffix),
+			disabledFeatures:          viper.GetStringSlice(user_disabled_features),
+			markdownStyle:             viper.GetString(user_markdown_style),
+			colorScheme:               viper.GetString(user_color_scheme),
+			commandPreamble:           viper.GetString(user_command_preamble),
+			rawKey:                    viper.GetString(user_raw_key),
 		},
 		system: system,
 	}, nil
@@ -69,22 +128,150 @@ func WriteConfig(key string, write bool) (*Config, error) {
 	// Set the AI defaults
 	viper.Set(openai_key, key)
 	viper.Set(openai_model, openai.GPT3Dot5Turbo)
-	viper.SetDefault(openai_proxy, "")
-	viper.SetDefault(openai_temperature, 0.2)
-	viper.SetDefault(openai_max_tokens, 1000)
 
-	// Set the user defaults
-	viper.SetDefault(user_default_prompt_mode, "exec")
-	viper.SetDefault(user_preferences, "")
+	// Set the defaults
+	setDefaults()
 
+	backupFile := ""
 	if write {
 		// Write the configuration to the file
-		err := viper.SafeWriteConfigAs(system.GetConfigFile())
+		var err error
+		backupFile, err = writeConfigFile(system.GetConfigFile())
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	// Return a new Config instance
-	return NewConfig()
+	config, err := NewConfig()
+	if err != nil {
+		return nil, err
+	}
+	config.backupFile = backupFile
+
+	return config, nil
+}
+
+// writeConfigFile atomically writes the configuration to the given path by writing a temporary file
+// in the same directory and renaming it. An existing file is backed up first, and the backup path is returned.
+func writeConfigFile(path string) (string, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	// Write the configuration to a temporary file, keeping the extension so viper knows the format
+	tmp, err := os.CreateTemp(dir, fmt.Sprintf(".%s.*%s", strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), filepath.Ext(path)))
+	if err != nil {
+		return "", err
+	}
+	tmpPath := tmp.Name()
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+	if err := viper.WriteConfigAs(tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+
+	// Back up the existing configuration before overwriting it
+	backupFile := ""
+	if _, err := os.Stat(path); err == nil {
+		if err := backupConfig(path); err != nil {
+			os.Remove(tmpPath)
+			return "", err
+		}
+		backupFile = path + ".bak"
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+
+	return backupFile, nil
+}
+
+// backupConfig copies the config file at the given path to a ".bak" file next to it.
+func backupConfig(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".bak", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+
+	return dst.Close()
+}
+
+// setDefaults sets the default values of the AI and user configurations.
+func setDefaults() {
+	// Set the AI defaults
+	viper.SetDefault(openai_proxy, "")
+	viper.SetDefault(openai_temperature, 0.2)
+	viper.SetDefault(openai_max_tokens, 1000)
+
+	// Set the user defaults
+	viper.SetDefault(user_default_prompt_mode, "exec")
+	viper.SetDefault(user_preferences, "")
+	viper.SetDefault(user_capture_output, true)
+	viper.SetDefault(user_max_history_size, 1000)
+	viper.SetDefault(user_block_elevation, false)
+	viper.SetDefault(user_fix_failed_commands, false)
+	viper.SetDefault(user_fix_max_attempts, 2)
+	viper.SetDefault(user_sandbox, "")
+	viper.SetDefault(user_sandbox_image, "alpine:latest")
+	viper.SetDefault(user_exec_allowlist, []string{})
+	viper.SetDefault(user_exec_blocklist, []string{})
+	viper.SetDefault(user_shell_tool, false)
+	viper.SetDefault(user_auto_exec_safe, false)
+	viper.SetDefault(user_resolve_aliases, false)
+	viper.SetDefault(user_prompt_max_height, 6)
+	viper.SetDefault(user_default_context, []string{})
+	viper.SetDefault(user_status_bar, true)
+	viper.SetDefault(user_copy_key, "ctrl+y")
+	viper.SetDefault(user_copy_code_block, true)
+	viper.SetDefault(user_filter_history, false)
+	viper.SetDefault(user_spinner_style, "minidot")
+	viper.SetDefault(user_spinner_label, "")
+	viper.SetDefault(user_code_style, "")
+	viper.SetDefault(user_max_pipe_size, 32768)
+	viper.SetDefault(user_truncate_pipe, true)
+	viper.SetDefault(user_footer_hints, true)
+	viper.SetDefault(user_welcome_message, "Welcome")
+	viper.SetDefault(user_mouse, true)
+	viper.SetDefault(user_exec_output_fields, map[string]string{
+		"command":     exec_output_command_field,
+		"explanation": exec_output_explanation_field,
+		"executable":  exec_output_executable_field,
+	})
+	viper.SetDefault(user_editing_mode, "emacs")
+	viper.SetDefault(user_autosuggest, true)
+	viper.SetDefault(user_confirmation_word, "y")
+	viper.SetDefault(user_confirmation_case_sensitive, false)
+	viper.SetDefault(user_retry_key, "ctrl+g")
+	viper.SetDefault(user_max_context_tokens, 0)
+	viper.SetDefault(user_notify_after_seconds, 10)
+	viper.SetDefault(user_transcript_key, "ctrl+t")
+	viper.SetDefault(user_transcript_dir, system.GetTranscriptDirectory())
+	viper.SetDefault(user_stream_chunk_tokens, 16)
+	viper.SetDefault(user_stream_chunk_ms, 50)
+	viper.SetDefault(user_theme, "default")
+	viper.SetDefault(user_prompt_prefix, "")
+	viper.SetDefault(user_prompt_suffix, "")
+	viper.SetDefault(user_disabled_features, []string{})
+	viper.SetDefault(user_markdown_style, "auto")
+	viper.SetDefault(user_color_scheme, "auto")
+	viper.SetDefault(user_command_preamble, "")
+	viper.SetDefault(user_raw_key, "ctrl+x")
 }
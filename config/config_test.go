@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -17,6 +18,7 @@ func TestConfig(t *testing.T) {
 	// TestConfig is a test function that runs subtests for NewConfig and WriteConfig.
 	t.Run("NewConfig", testNewConfig)
 	t.Run("WriteConfig", testWriteConfig)
+	t.Run("WriteConfigFile", testWriteConfigFile)
 }
 
 // setupViper initializes the Viper configuration for testing purposes.
@@ -60,6 +62,53 @@ func testNewConfig(t *testing.T) {
 	assert.Equal(t, 2000, cfg.GetAiConfig().GetMaxTokens())
 	assert.Equal(t, "exec", cfg.GetUserConfig().GetDefaultPromptMode())
 	assert.Equal(t, "test_preferences", cfg.GetUserConfig().GetPreferences())
+	assert.True(t, cfg.GetUserConfig().GetCaptureOutput())
+	assert.Equal(t, 1000, cfg.GetUserConfig().GetMaxHistorySize())
+	assert.False(t, cfg.GetUserConfig().GetBlockElevation())
+	assert.False(t, cfg.GetUserConfig().GetFixFailedCommands())
+	assert.Equal(t, 2, cfg.GetUserConfig().GetFixMaxAttempts())
+	assert.Equal(t, "", cfg.GetUserConfig().GetSandbox())
+	assert.Equal(t, "alpine:latest", cfg.GetUserConfig().GetSandboxImage())
+	assert.Empty(t, cfg.GetUserConfig().GetExecAllowlist())
+	assert.Empty(t, cfg.GetUserConfig().GetExecBlocklist())
+	assert.False(t, cfg.GetUserConfig().GetShellTool())
+	assert.False(t, cfg.GetUserConfig().GetAutoExecSafeCommands())
+	assert.False(t, cfg.GetUserConfig().GetResolveAliases())
+	assert.Equal(t, 6, cfg.GetUserConfig().GetPromptMaxHeight())
+	assert.Empty(t, cfg.GetUserConfig().GetDefaultContextFiles())
+	assert.True(t, cfg.GetUserConfig().GetStatusBar())
+	assert.Equal(t, "ctrl+y", cfg.GetUserConfig().GetCopyKey())
+	assert.True(t, cfg.GetUserConfig().GetCopyCodeBlock())
+	assert.False(t, cfg.GetUserConfig().GetFilterHistoryByMode())
+	assert.Equal(t, "minidot", cfg.GetUserConfig().GetSpinnerStyle())
+	assert.Empty(t, cfg.GetUserConfig().GetSpinnerLabel())
+	assert.Empty(t, cfg.GetUserConfig().GetCodeStyle())
+	assert.Equal(t, 32768, cfg.GetUserConfig().GetMaxPipeSizeBytes())
+	assert.True(t, cfg.GetUserConfig().GetTruncatePipe())
+	assert.True(t, cfg.GetUserConfig().GetFooterHints())
+	assert.Equal(t, PromptIndicator{}, cfg.GetUserConfig().GetPromptIndicator("exec"))
+	assert.Equal(t, "Welcome", cfg.GetUserConfig().GetWelcomeMessage())
+	assert.True(t, cfg.GetUserConfig().GetMouse())
+	assert.Equal(t, ExecOutputFieldNames{Command: "cmd", Explanation: "exp", Executable: "exec"}, cfg.GetUserConfig().GetExecOutputFields())
+	assert.Equal(t, "emacs", cfg.GetUserConfig().GetEditingMode())
+	assert.True(t, cfg.GetUserConfig().GetAutosuggest())
+	assert.Equal(t, "y", cfg.GetUserConfig().GetConfirmationWord())
+	assert.False(t, cfg.GetUserConfig().GetConfirmationCaseSensitive())
+	assert.Equal(t, "ctrl+g", cfg.GetUserConfig().GetRetryKey())
+	assert.Equal(t, 0, cfg.GetUserConfig().GetMaxContextTokens())
+	assert.Equal(t, 10, cfg.GetUserConfig().GetNotifyAfterSeconds())
+	assert.Equal(t, "ctrl+t", cfg.GetUserConfig().GetTranscriptKey())
+	assert.Equal(t, system.GetTranscriptDirectory(), cfg.GetUserConfig().GetTranscriptDir())
+	assert.Equal(t, 16, cfg.GetUserConfig().GetStreamChunkTokens())
+	assert.Equal(t, 50, cfg.GetUserConfig().GetStreamChunkMs())
+	assert.Equal(t, "default", cfg.GetUserConfig().GetTheme())
+	assert.Equal(t, "", cfg.GetUserConfig().GetPromptPrefix())
+	assert.Equal(t, "", cfg.GetUserConfig().GetPromptSuffix())
+	assert.Empty(t, cfg.GetUserConfig().GetDisabledFeatures())
+	assert.Equal(t, "auto", cfg.GetUserConfig().GetMarkdownStyle())
+	assert.Equal(t, "auto", cfg.GetUserConfig().GetColorScheme())
+	assert.Equal(t, "", cfg.GetUserConfig().GetCommandPreamble())
+	assert.Equal(t, "ctrl+x", cfg.GetUserConfig().GetRawKey())
 
 	assert.NotNil(t, cfg.GetSystemConfig())
 }
@@ -92,3 +141,36 @@ func testWriteConfig(t *testing.T) {
 	assert.Equal(t, "exec", viper.GetString(user_default_prompt_mode))
 	assert.Equal(t, "test_preferences", viper.GetString(user_preferences))
 }
+
+// testWriteConfigFile is a unit test function that tests the atomic write and backup of the config file.
+func testWriteConfigFile(t *testing.T) {
+	setupViper(t)
+	defer cleanup(t)
+
+	path := filepath.Join(t.TempDir(), "terminal-assistant.json")
+
+	backupFile, err := writeConfigFile(path)
+	require.NoError(t, err)
+	assert.Empty(t, backupFile, "No backup should be made for a new file.")
+
+	original, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(original), "test_key")
+
+	viper.Set(openai_key, "new_test_key")
+	backupFile, err = writeConfigFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, path+".bak", backupFile, "The previous file should be backed up.")
+
+	backup, err := os.ReadFile(backupFile)
+	require.NoError(t, err)
+	assert.Equal(t, original, backup, "The backup should contain the previous config.")
+
+	written, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(written), "new_test_key")
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "No temporary file should be left behind.")
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// masked_config_value replaces the values of the sensitive fields in a ConfigChange.
+const masked_config_value = "***"
+
+// sensitiveConfigFields are the fields whose values are never displayed in a ConfigChange.
+var sensitiveConfigFields = map[string]bool{
+	"ai.Key": true,
+}
+
+// ConfigChange struct holds a field of the configuration whose value changed, like "user.Mouse".
+type ConfigChange struct {
+	Field    string      // name of the field, the section followed by the name of its getter without "Get"
+	OldValue interface{} // value of the field before the change, masked for the sensitive fields
+	NewValue interface{} // value of the field after the change, masked for the sensitive fields
+}
+
+// String returns the field followed by its old and new values, like "user.Mouse: true → false"
+func (c ConfigChange) String() string {
+	return fmt.Sprintf("%s: %v → %v", c.Field, c.OldValue, c.NewValue)
+}
+
+// Diff returns the fields of the ai and user configs whose values differ in another config, in the order of
+// the names of their getters. The system config, detected rather than configured, is not compared.
+func (c *Config) Diff(other *Config) []ConfigChange {
+	changes := diffGetters("ai", c.ai, other.ai)
+
+	return append(changes, diffGetters("user", c.user, other.user)...)
+}
+
+// diffGetters compares the values returned by the getters without arguments of two values of the same type
+func diffGetters(section string, old interface{}, new interface{}) []ConfigChange {
+	var changes []ConfigChange
+
+	oldValue, newValue := reflect.ValueOf(old), reflect.ValueOf(new)
+	for i := 0; i < oldValue.NumMethod(); i++ {
+		method := oldValue.Type().Method(i)
+		if !strings.HasPrefix(method.Name, "Get") || method.Type.NumIn() != 1 || method.Type.NumOut() != 1 {
+			continue
+		}
+
+		before := oldValue.Method(i).Call(nil)[0].Interface()
+		after := newValue.Method(i).Call(nil)[0].Interface()
+		if reflect.DeepEqual(before, after) {
+			continue
+		}
+
+		field := fmt.Sprintf("%s.%s", section, strings.TrimPrefix(method.Name, "Get"))
+		if sensitiveConfigFields[field] {
+			before, after = masked_config_value, masked_config_value
+		}
+		changes = append(changes, ConfigChange{Field: field, OldValue: before, NewValue: after})
+	}
+
+	return changes
+}
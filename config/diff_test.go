@@ -0,0 +1,27 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDiff tests that the changed fields of the ai and user configs are returned, the key being masked.
+func TestDiff(t *testing.T) {
+	old := &Config{
+		ai:   AiConfig{key: "old_key", model: "gpt-4", temperature: 0.2},
+		user: UserConfig{mouse: true, execAllowlist: []string{"ls"}},
+	}
+	new := &Config{
+		ai:   AiConfig{key: "new_key", model: "gpt-4", temperature: 0.5},
+		user: UserConfig{mouse: false, execAllowlist: []string{"ls"}},
+	}
+
+	assert.Empty(t, old.Diff(old), "An unchanged config should have no change.")
+	assert.Equal(t, []ConfigChange{
+		{Field: "ai.Key", OldValue: "***", NewValue: "***"},
+		{Field: "ai.Temperature", OldValue: 0.2, NewValue: 0.5},
+		{Field: "user.Mouse", OldValue: true, NewValue: false},
+	}, old.Diff(new))
+	assert.Equal(t, "user.Mouse: true → false", old.Diff(new)[2].String())
+}
@@ -0,0 +1,35 @@
+package config
+
+import "errors"
+
+// SetupError is an error of the setup of the assistant, like an unreadable configuration or an invalid key, which
+// cannot be recovered from without fixing the configuration.
+type SetupError struct {
+	err error // The error of the setup.
+}
+
+// NewSetupError is a function that wraps an error of the setup of the assistant into a SetupError.
+func NewSetupError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &SetupError{err: err}
+}
+
+// Error returns the message of the error of the setup.
+func (e *SetupError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the error of the setup.
+func (e *SetupError) Unwrap() error {
+	return e.err
+}
+
+// IsSetupError is a function that checks if an error is, or wraps, an error of the setup of the assistant.
+func IsSetupError(err error) bool {
+	var setupErr *SetupError
+
+	return errors.As(err, &setupErr)
+}
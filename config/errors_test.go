@@ -0,0 +1,22 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSetupError tests that the errors of the setup are recognized when wrapped, their cause being kept.
+func TestSetupError(t *testing.T) {
+	cause := viper.ConfigFileNotFoundError{}
+	err := fmt.Errorf("cannot start: %w", NewSetupError(cause))
+
+	assert.True(t, IsSetupError(err), "A wrapped setup error should be recognized.")
+	assert.True(t, errors.As(err, &viper.ConfigFileNotFoundError{}), "The cause of the setup error should be kept.")
+	assert.Equal(t, "cannot start: "+cause.Error(), err.Error())
+	assert.False(t, IsSetupError(errors.New("timeout")), "Another error should not be a setup error.")
+	assert.Nil(t, NewSetupError(nil))
+}
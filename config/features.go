@@ -0,0 +1,28 @@
+package config
+
+import "strings"
+
+// The names of the features that can be disabled with user_disabled_features, the slash commands being disabled by
+// their name, like import.
+const (
+	// ExecFeature is the exec prompt mode, generating the commands to execute.
+	ExecFeature = "exec"
+	// SettingsEditFeature is the edition of the settings with ctrl+s.
+	SettingsEditFeature = "settings_edit"
+)
+
+// IsFeatureEnabled is a function that checks if a feature is enabled, unless it is listed in the disabled features of
+// the configuration. The names are case-insensitive, and all the features are enabled without a configuration.
+func IsFeatureEnabled(name string, cfg *Config) bool {
+	if cfg == nil {
+		return true
+	}
+
+	for _, disabled := range cfg.GetUserConfig().GetDisabledFeatures() {
+		if strings.EqualFold(strings.TrimSpace(disabled), name) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIsFeatureEnabled tests that the features listed in user_disabled_features are disabled, whatever their case.
+func TestIsFeatureEnabled(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		filepath.Join(dir, "terminal-assistant.json"),
+		[]byte(`{"openai_key": "test_key", "user_disabled_features": ["EXEC", " import "]}`),
+		0600,
+	))
+	viper.AddConfigPath(dir)
+	cfg, err := NewConfig()
+	require.NoError(t, err)
+
+	assert.False(t, IsFeatureEnabled(ExecFeature, cfg))
+	assert.False(t, IsFeatureEnabled("import", cfg))
+	assert.True(t, IsFeatureEnabled(SettingsEditFeature, cfg))
+	assert.True(t, IsFeatureEnabled(ExecFeature, nil), "The features should be enabled without a configuration.")
+}
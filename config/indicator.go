@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+// PromptIndicator struct holds the symbol displayed before the input of a prompt mode and its color.
+type PromptIndicator struct {
+	symbol string // symbol displayed before the input, empty for the default one
+	color  string // color of the symbol and of the input, empty for the default one
+}
+
+// NewPromptIndicator is a constructor for PromptIndicator struct
+func NewPromptIndicator(symbol string, color string) PromptIndicator {
+	return PromptIndicator{
+		symbol: symbol,
+		color:  color,
+	}
+}
+
+// GetSymbol returns the symbol displayed before the input, empty for the default one
+func (i PromptIndicator) GetSymbol() string {
+	return i.symbol
+}
+
+// GetColor returns the color of the symbol and of the input, empty for the default one
+func (i PromptIndicator) GetColor() string {
+	return i.color
+}
+
+// ReadPromptIndicators reads the prompt indicators of the settings loaded so far, by prompt mode, so the prompt
+// asking for the key is customized before the configuration file is created.
+func ReadPromptIndicators() map[string]PromptIndicator {
+	return parsePromptIndicators(viper.GetStringMap(user_prompt_indicators))
+}
+
+// parsePromptIndicators reads the prompt indicators of the configuration, by prompt mode,
+// like {"exec": {"symbol": "❯", "color": "#ffa657"}}. The indicators that are not objects are ignored.
+func parsePromptIndicators(raw map[string]interface{}) map[string]PromptIndicator {
+	indicators := map[string]PromptIndicator{}
+	for mode, value := range raw {
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		indicator := PromptIndicator{}
+		if symbol, ok := fields["symbol"]; ok {
+			indicator.symbol = fmt.Sprint(symbol)
+		}
+		if color, ok := fields["color"]; ok {
+			indicator.color = fmt.Sprint(color)
+		}
+		indicators[mode] = indicator
+	}
+
+	return indicators
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromptIndicator(t *testing.T) {
+	t.Run("NewPromptIndicator", testNewPromptIndicator)
+	t.Run("ParsePromptIndicators", testParsePromptIndicators)
+}
+
+// testNewPromptIndicator tests the getters of the PromptIndicator.
+func testNewPromptIndicator(t *testing.T) {
+	indicator := NewPromptIndicator("❯", "#ffa657")
+
+	assert.Equal(t, "❯", indicator.GetSymbol())
+	assert.Equal(t, "#ffa657", indicator.GetColor())
+}
+
+// testParsePromptIndicators tests that the prompt indicators are read by prompt mode, the invalid ones being ignored.
+func testParsePromptIndicators(t *testing.T) {
+	indicators := parsePromptIndicators(map[string]interface{}{
+		"exec":   map[string]interface{}{"symbol": "❯", "color": "#ffa657"},
+		"chat":   map[string]interface{}{"symbol": "🗨"},
+		"config": "invalid",
+	})
+
+	assert.Equal(t, map[string]PromptIndicator{
+		"exec": NewPromptIndicator("❯", "#ffa657"),
+		"chat": NewPromptIndicator("🗨", ""),
+	}, indicators)
+	assert.Empty(t, parsePromptIndicators(nil), "No indicator should be configured by default.")
+}
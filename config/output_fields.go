@@ -0,0 +1,37 @@
+package config
+
+// Default names of the fields of the JSON answered by the AI in the exec prompt mode.
+const (
+	exec_output_command_field     = "cmd"
+	exec_output_explanation_field = "exp"
+	exec_output_executable_field  = "exec"
+)
+
+// ExecOutputFieldNames struct holds the names of the fields of the JSON answered by the AI in the exec prompt mode,
+// so the answer can still be read when the prompt asks for other names.
+type ExecOutputFieldNames struct {
+	Command     string // name of the field containing the command
+	Explanation string // name of the field containing the explanation of the command
+	Executable  string // name of the field telling whether the command is executable
+}
+
+// parseExecOutputFields reads the names of the fields of the configuration, like {"command": "cmd"}.
+// The names that are not configured, or empty, keep their default.
+func parseExecOutputFields(raw map[string]string) ExecOutputFieldNames {
+	fields := ExecOutputFieldNames{
+		Command:     exec_output_command_field,
+		Explanation: exec_output_explanation_field,
+		Executable:  exec_output_executable_field,
+	}
+	if name := raw["command"]; name != "" {
+		fields.Command = name
+	}
+	if name := raw["explanation"]; name != "" {
+		fields.Explanation = name
+	}
+	if name := raw["executable"]; name != "" {
+		fields.Executable = name
+	}
+
+	return fields
+}
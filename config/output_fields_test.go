@@ -0,0 +1,15 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseExecOutputFields tests that the configured field names replace the default ones, the empty ones being ignored.
+func TestParseExecOutputFields(t *testing.T) {
+	assert.Equal(t, ExecOutputFieldNames{Command: "cmd", Explanation: "exp", Executable: "exec"}, parseExecOutputFields(nil))
+
+	fields := parseExecOutputFields(map[string]string{"command": "command", "explanation": "", "executable": "runnable"})
+	assert.Equal(t, ExecOutputFieldNames{Command: "command", Explanation: "exp", Executable: "runnable"}, fields)
+}
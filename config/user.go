@@ -2,8 +2,55 @@ package config
 
 // Constants for the user configuration keys.
 const (
-	user_default_prompt_mode = "USER_DEFAULT_PROMPT_MODE"
-	user_preferences         = "USER_PREFERENCES"
+	user_default_prompt_mode         = "USER_DEFAULT_PROMPT_MODE"
+	user_preferences                 = "USER_PREFERENCES"
+	user_capture_output              = "USER_CAPTURE_OUTPUT"
+	user_max_history_size            = "USER_MAX_HISTORY_SIZE"
+	user_block_elevation             = "USER_BLOCK_ELEVATION"
+	user_fix_failed_commands         = "USER_FIX_FAILED_COMMANDS"
+	user_fix_max_attempts            = "USER_FIX_MAX_ATTEMPTS"
+	user_sandbox                     = "USER_SANDBOX"
+	user_sandbox_image               = "USER_SANDBOX_IMAGE"
+	user_exec_allowlist              = "USER_EXEC_ALLOWLIST"
+	user_exec_blocklist              = "USER_EXEC_BLOCKLIST"
+	user_shell_tool                  = "USER_SHELL_TOOL"
+	user_auto_exec_safe              = "USER_AUTO_EXEC_SAFE_COMMANDS"
+	user_resolve_aliases             = "USER_RESOLVE_ALIASES"
+	user_prompt_max_height           = "USER_PROMPT_MAX_HEIGHT"
+	user_default_context             = "USER_DEFAULT_CONTEXT_FILES"
+	user_status_bar                  = "USER_STATUS_BAR"
+	user_copy_key                    = "USER_COPY_KEY"
+	user_copy_code_block             = "USER_COPY_CODE_BLOCK"
+	user_filter_history              = "USER_FILTER_HISTORY_BY_MODE"
+	user_spinner_style               = "USER_SPINNER_STYLE"
+	user_spinner_label               = "USER_SPINNER_LABEL"
+	user_code_style                  = "USER_CODE_STYLE"
+	user_max_pipe_size               = "USER_MAX_PIPE_SIZE_BYTES"
+	user_truncate_pipe               = "USER_TRUNCATE_PIPE"
+	user_footer_hints                = "USER_FOOTER_HINTS"
+	user_prompt_indicators           = "USER_PROMPT_INDICATORS"
+	user_welcome_message             = "USER_WELCOME_MESSAGE"
+	user_mouse                       = "USER_MOUSE"
+	user_exec_output_fields          = "USER_EXEC_OUTPUT_FIELDS"
+	user_editing_mode                = "USER_EDITING_MODE"
+	user_autosuggest                 = "USER_AUTOSUGGEST"
+	user_confirmation_word           = "USER_CONFIRMATION_WORD"
+	user_confirmation_case_sensitive = "USER_CONFIRMATION_CASE_SENSITIVE"
+	user_retry_key                   = "USER_RETRY_KEY"
+	user_max_context_tokens          = "USER_MAX_CONTEXT_TOKENS"
+	user_notify_after_seconds        = "USER_NOTIFY_AFTER_SECONDS"
+	user_transcript_key              = "USER_TRANSCRIPT_KEY"
+	user_transcript_dir              = "USER_TRANSCRIPT_DIR"
+	user_stream_chunk_tokens         = "USER_STREAM_CHUNK_TOKENS"
+	user_stream_chunk_ms             = "USER_STREAM_CHUNK_MS"
+	user_theme                       = "USER_THEME"
+	user_prompt_prefix               = "USER_PROMPT_PREFIX"
+	user_prompt_suffix               = "USER_PROMPT_SUFFIX"
+	user_disabled_features           = "USER_DISABLED_FEATURES"
+	user_markdown_style              = "USER_MARKDOWN_STYLE"
+	user_color_scheme                = "USER_COLOR_SCHEME"
+	user_command_preamble            = "USER_COMMAND_PREAMBLE"
+	user_raw_key                     = "USER_RAW_KEY"
 )
 
 // UserConfig struct holds the user's configuration.
@@ -12,6 +59,100 @@ type UserConfig struct {
 	defaultPromptMode string
 	// preferences are the user's preferences.
 	preferences string
+	// captureOutput enables the captured execution of commands that don't need a TTY.
+	captureOutput bool
+	// maxHistorySize is the maximum number of inputs kept in the history.
+	maxHistorySize int
+	// blockElevation blocks the commands requiring elevated privileges.
+	blockElevation bool
+	// fixFailedCommands offers to ask the AI to fix the failed commands.
+	fixFailedCommands bool
+	// fixMaxAttempts is the maximum number of consecutive fix attempts.
+	fixMaxAttempts int
+	// sandbox is the container runtime used to execute the commands in a sandbox, empty if disabled.
+	sandbox string
+	// sandboxImage is the image of the sandbox containers.
+	sandboxImage string
+	// execAllowlist is the list of the programs allowed, all the programs being allowed if empty.
+	execAllowlist []string
+	// execBlocklist is the list of the programs never executed.
+	execBlocklist []string
+	// shellTool lets the AI execute the safe commands to gather information.
+	shellTool bool
+	// autoExecSafeCommands executes the read-only commands without confirmation.
+	autoExecSafeCommands bool
+	// resolveAliases resolves the aliases of the shell of the user at startup.
+	resolveAliases bool
+	// promptMaxHeight is the maximum number of lines of the prompt, which grows with its content.
+	promptMaxHeight int
+	// defaultContextFiles is the list of the files, or glob patterns, always injected into the context.
+	defaultContextFiles []string
+	// statusBar displays the status bar under the prompt in the REPL mode.
+	statusBar bool
+	// copyKey is the key copying the last answer to the clipboard.
+	copyKey string
+	// copyCodeBlock copies only the code block of the last answer when it has exactly one.
+	copyCodeBlock bool
+	// filterHistoryByMode navigates only the inputs of the current prompt mode in the history.
+	filterHistoryByMode bool
+	// spinnerStyle is the name of the character set of the spinner.
+	spinnerStyle string
+	// spinnerLabel is the message displayed next to the spinner, a random one being displayed if empty.
+	spinnerLabel string
+	// codeStyle is the chroma style highlighting the code blocks, the default highlighting being used if empty.
+	codeStyle string
+	// maxPipeSizeBytes is the maximum size in bytes of the content piped to the AI, unlimited if not positive.
+	maxPipeSizeBytes int
+	// truncatePipe truncates the content piped to the AI to the maximum size instead of refusing it.
+	truncatePipe bool
+	// footerHints displays the keys available in the current state under the prompt.
+	footerHints bool
+	// promptIndicators is the symbols and colors of the prompts, by prompt mode.
+	promptIndicators map[string]PromptIndicator
+	// welcomeMessage is the markdown message displayed when the REPL mode starts, none if empty.
+	welcomeMessage string
+	// mouse lets the mouse wheel scroll the conversation and the viewport.
+	mouse bool
+	// execOutputFields is the names of the fields of the JSON answered by the AI in the exec prompt mode.
+	execOutputFields ExecOutputFieldNames
+	// editingMode is the editing mode of the prompt, "emacs" for the default keys or "vi" for the modal editing.
+	editingMode string
+	// autosuggest suggests the most recent input of the history starting with the typed text after it.
+	autosuggest bool
+	// confirmationWord is the word typed to confirm the execution of a command, like "yes" or "execute".
+	confirmationWord string
+	// confirmationCaseSensitive makes the case of the confirmation word matter.
+	confirmationCaseSensitive bool
+	// retryKey is the key sending again the last request that failed.
+	retryKey string
+	// maxContextTokens is the size of the context window of the model in tokens, detected from the model when 0.
+	maxContextTokens int
+	// notifyAfterSeconds is the duration of a query in seconds above which the user is notified of its answer, 0 to never notify.
+	notifyAfterSeconds int
+	// transcriptKey is the key saving the transcript of the session.
+	transcriptKey string
+	// transcriptDir is the directory of the saved session transcripts.
+	transcriptDir string
+	// streamChunkTokens is the number of tokens of the answer streamed sent together, 0 to only wait for streamChunkMs.
+	streamChunkTokens int
+	// streamChunkMs is the delay in milliseconds after which the tokens of the answer streamed received so far are sent, 0 to only wait for streamChunkTokens.
+	streamChunkMs int
+	// theme is the theme of the conversation, "default" or "minimal" without the markers of the prompt modes.
+	theme string
+	// promptPrefix is the text prepended to every message of the user sent to the model.
+	promptPrefix string
+	// promptSuffix is the text appended to every message of the user sent to the model, like "on macOS 14".
+	promptSuffix string
+	// disabledFeatures are the names of the features disabled, like exec or settings_edit.
+	disabledFeatures []string
+	// markdownStyle is the style of the markdown content, "auto", "dark", "light" or the path of a glamour style JSON file.
+	markdownStyle string
+	// colorScheme is the color scheme of the prompt, the status bar and the messages, "auto", "dark" or "light".
+	colorScheme string
+	// commandPreamble is the preamble prepended to the commands executed, like sourcing a version manager.
+	commandPreamble string
+	// rawKey is the key toggling the last answer between its rendered and raw markdown.
+	rawKey string
 }
 
 // GetDefaultPromptMode returns the user's default prompt mode.
@@ -23,3 +164,247 @@ func (c UserConfig) GetDefaultPromptMode() string {
 func (c UserConfig) GetPreferences() string {
 	return c.preferences
 }
+
+// GetCaptureOutput returns whether commands that don't need a TTY should be executed with a captured output.
+func (c UserConfig) GetCaptureOutput() bool {
+	return c.captureOutput
+}
+
+// GetMaxHistorySize returns the maximum number of inputs kept in the history.
+func (c UserConfig) GetMaxHistorySize() int {
+	return c.maxHistorySize
+}
+
+// GetBlockElevation returns whether the commands requiring elevated privileges are blocked.
+func (c UserConfig) GetBlockElevation() bool {
+	return c.blockElevation
+}
+
+// GetFixFailedCommands returns whether the user is offered to ask the AI to fix the failed commands.
+func (c UserConfig) GetFixFailedCommands() bool {
+	return c.fixFailedCommands
+}
+
+// GetFixMaxAttempts returns the maximum number of consecutive fix attempts.
+func (c UserConfig) GetFixMaxAttempts() int {
+	return c.fixMaxAttempts
+}
+
+// GetSandbox returns the container runtime used to execute the commands in a sandbox, empty if disabled.
+func (c UserConfig) GetSandbox() string {
+	return c.sandbox
+}
+
+// GetSandboxImage returns the image of the sandbox containers.
+func (c UserConfig) GetSandboxImage() string {
+	return c.sandboxImage
+}
+
+// GetExecAllowlist returns the list of the programs allowed, all the programs being allowed if empty.
+func (c UserConfig) GetExecAllowlist() []string {
+	return c.execAllowlist
+}
+
+// GetExecBlocklist returns the list of the programs never executed.
+func (c UserConfig) GetExecBlocklist() []string {
+	return c.execBlocklist
+}
+
+// GetShellTool returns whether the AI can execute the safe commands to gather information.
+func (c UserConfig) GetShellTool() bool {
+	return c.shellTool
+}
+
+// GetAutoExecSafeCommands returns whether the read-only commands are executed without confirmation.
+func (c UserConfig) GetAutoExecSafeCommands() bool {
+	return c.autoExecSafeCommands
+}
+
+// GetResolveAliases returns whether the aliases of the shell of the user are resolved at startup.
+func (c UserConfig) GetResolveAliases() bool {
+	return c.resolveAliases
+}
+
+// GetPromptMaxHeight returns the maximum number of lines of the prompt, which grows with its content.
+func (c UserConfig) GetPromptMaxHeight() int {
+	return c.promptMaxHeight
+}
+
+// GetDefaultContextFiles returns the list of the files, or glob patterns, always injected into the context.
+func (c UserConfig) GetDefaultContextFiles() []string {
+	return c.defaultContextFiles
+}
+
+// GetStatusBar returns whether the status bar is displayed under the prompt in the REPL mode.
+func (c UserConfig) GetStatusBar() bool {
+	return c.statusBar
+}
+
+// GetCopyKey returns the key copying the last answer to the clipboard.
+func (c UserConfig) GetCopyKey() string {
+	return c.copyKey
+}
+
+// GetCopyCodeBlock returns whether only the code block of the last answer is copied when it has exactly one.
+func (c UserConfig) GetCopyCodeBlock() bool {
+	return c.copyCodeBlock
+}
+
+// GetFilterHistoryByMode returns whether only the inputs of the current prompt mode are navigated in the history.
+func (c UserConfig) GetFilterHistoryByMode() bool {
+	return c.filterHistoryByMode
+}
+
+// GetSpinnerStyle returns the name of the character set of the spinner.
+func (c UserConfig) GetSpinnerStyle() string {
+	return c.spinnerStyle
+}
+
+// GetSpinnerLabel returns the message displayed next to the spinner, a random one being displayed if empty.
+func (c UserConfig) GetSpinnerLabel() string {
+	return c.spinnerLabel
+}
+
+// GetCodeStyle returns the chroma style highlighting the code blocks, like "monokai", the default highlighting being used if empty.
+func (c UserConfig) GetCodeStyle() string {
+	return c.codeStyle
+}
+
+// GetMaxPipeSizeBytes returns the maximum size in bytes of the content piped to the AI, unlimited if not positive.
+func (c UserConfig) GetMaxPipeSizeBytes() int {
+	return c.maxPipeSizeBytes
+}
+
+// GetTruncatePipe returns whether the content piped to the AI is truncated to the maximum size instead of being refused.
+func (c UserConfig) GetTruncatePipe() bool {
+	return c.truncatePipe
+}
+
+// GetFooterHints returns whether the keys available in the current state are displayed under the prompt in the REPL mode.
+func (c UserConfig) GetFooterHints() bool {
+	return c.footerHints
+}
+
+// GetPromptIndicator returns the symbol and color of the prompt of a mode ("exec", "chat" or "config"),
+// empty for the default ones.
+func (c UserConfig) GetPromptIndicator(mode string) PromptIndicator {
+	return c.promptIndicators[mode]
+}
+
+// GetPromptIndicators returns the configured symbols and colors of the prompt modes, by prompt mode.
+func (c UserConfig) GetPromptIndicators() map[string]PromptIndicator {
+	return c.promptIndicators
+}
+
+// GetWelcomeMessage returns the markdown message displayed when the REPL mode starts, none if empty.
+func (c UserConfig) GetWelcomeMessage() string {
+	return c.welcomeMessage
+}
+
+// GetMouse returns whether the mouse wheel scrolls the conversation and the viewport, disabling the mouse support entirely if false.
+func (c UserConfig) GetMouse() bool {
+	return c.mouse
+}
+
+// GetExecOutputFields returns the names of the command, explanation and executable fields of the JSON answered by the AI in the exec prompt mode.
+func (c UserConfig) GetExecOutputFields() ExecOutputFieldNames {
+	return c.execOutputFields
+}
+
+// GetEditingMode returns the editing mode of the prompt, "emacs" for the default keys or "vi" for the modal editing.
+func (c UserConfig) GetEditingMode() string {
+	return c.editingMode
+}
+
+// GetAutosuggest returns whether the most recent input of the history starting with the typed text is suggested after it, accepted with the right arrow or end.
+func (c UserConfig) GetAutosuggest() bool {
+	return c.autosuggest
+}
+
+// GetConfirmationWord returns the word typed to confirm the execution of a command, "y" by default.
+func (c UserConfig) GetConfirmationWord() string {
+	return c.confirmationWord
+}
+
+// GetConfirmationCaseSensitive returns whether the case of the confirmation word matters.
+func (c UserConfig) GetConfirmationCaseSensitive() bool {
+	return c.confirmationCaseSensitive
+}
+
+// GetRetryKey returns the key sending again the last request that failed.
+func (c UserConfig) GetRetryKey() string {
+	return c.retryKey
+}
+
+// GetMaxContextTokens returns the size of the context window of the model in tokens, 0 to detect it from the model.
+func (c UserConfig) GetMaxContextTokens() int {
+	return c.maxContextTokens
+}
+
+// GetNotifyAfterSeconds returns the duration of a query in seconds above which the user is notified of its answer, 0 to never notify.
+func (c UserConfig) GetNotifyAfterSeconds() int {
+	return c.notifyAfterSeconds
+}
+
+// GetTranscriptKey returns the key saving the transcript of the session.
+func (c UserConfig) GetTranscriptKey() string {
+	return c.transcriptKey
+}
+
+// GetTranscriptDir returns the directory of the saved session transcripts.
+func (c UserConfig) GetTranscriptDir() string {
+	return c.transcriptDir
+}
+
+// GetStreamChunkTokens returns the number of tokens of the answer streamed sent together.
+func (c UserConfig) GetStreamChunkTokens() int {
+	return c.streamChunkTokens
+}
+
+// GetStreamChunkMs returns the delay in milliseconds after which the tokens of the answer streamed received so far are sent.
+func (c UserConfig) GetStreamChunkMs() int {
+	return c.streamChunkMs
+}
+
+// GetTheme returns the theme of the conversation.
+func (c UserConfig) GetTheme() string {
+	return c.theme
+}
+
+// GetPromptPrefix returns the text prepended to every message of the user sent to the model.
+func (c UserConfig) GetPromptPrefix() string {
+	return c.promptPrefix
+}
+
+// GetPromptSuffix returns the text appended to every message of the user sent to the model.
+func (c UserConfig) GetPromptSuffix() string {
+	return c.promptSuffix
+}
+
+// GetDisabledFeatures returns the names of the features disabled, like exec or settings_edit, all the features being
+// enabled if empty.
+func (c UserConfig) GetDisabledFeatures() []string {
+	return c.disabledFeatures
+}
+
+// GetMarkdownStyle returns the style of the markdown content, "auto" detecting the background of the terminal, "dark",
+// "light" or the path of a glamour style JSON file.
+func (c UserConfig) GetMarkdownStyle() string {
+	return c.markdownStyle
+}
+
+// GetColorScheme returns the color scheme of the prompt, the status bar and the messages, "auto" detecting the
+// background of the terminal, "dark" or "light".
+func (c UserConfig) GetColorScheme() string {
+	return c.colorScheme
+}
+
+// GetCommandPreamble returns the preamble prepended to the commands executed, like sourcing a version manager.
+func (c UserConfig) GetCommandPreamble() string {
+	return c.commandPreamble
+}
+
+// GetRawKey returns the key toggling the last answer between its rendered and raw markdown.
+func (c UserConfig) GetRawKey() string {
+	return c.rawKey
+}
@@ -0,0 +1,26 @@
+package config
+
+import "fmt"
+
+// welcome_message_max_length is the length of the welcome message above which it is reported as excessively long.
+const welcome_message_max_length = 500
+
+// Validate checks the values of the configuration that are accepted but likely mistaken,
+// and returns a warning for each of them.
+func (c *Config) Validate() []string {
+	warnings := []string{}
+
+	if length := len([]rune(c.GetUserConfig().GetWelcomeMessage())); length > welcome_message_max_length {
+		warnings = append(warnings, fmt.Sprintf(
+			"the welcome message is %d characters long, more than %d characters is excessive",
+			length,
+			welcome_message_max_length,
+		))
+	}
+
+	if mode := c.GetUserConfig().GetEditingMode(); mode != "" && mode != "emacs" && mode != "vi" {
+		warnings = append(warnings, fmt.Sprintf("the editing mode %q is unknown, the emacs mode is used instead of it", mode))
+	}
+
+	return warnings
+}
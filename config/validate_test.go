@@ -0,0 +1,35 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	t.Run("WelcomeMessage", testValidateWelcomeMessage)
+	t.Run("EditingMode", testValidateEditingMode)
+}
+
+// testValidateWelcomeMessage tests that an excessively long welcome message is reported.
+func testValidateWelcomeMessage(t *testing.T) {
+	assert.Empty(t, (&Config{user: UserConfig{welcomeMessage: "Welcome"}}).Validate())
+	assert.Empty(t, (&Config{user: UserConfig{welcomeMessage: strings.Repeat("é", 500)}}).Validate(), "The length should be counted in characters.")
+
+	warnings := (&Config{user: UserConfig{welcomeMessage: strings.Repeat("a", 501)}}).Validate()
+	if assert.Len(t, warnings, 1) {
+		assert.Contains(t, warnings[0], "the welcome message is 501 characters long")
+	}
+}
+
+// testValidateEditingMode tests that an unknown editing mode is reported.
+func testValidateEditingMode(t *testing.T) {
+	assert.Empty(t, (&Config{user: UserConfig{editingMode: "vi"}}).Validate())
+	assert.Empty(t, (&Config{user: UserConfig{editingMode: "emacs"}}).Validate())
+
+	warnings := (&Config{user: UserConfig{editingMode: "vim"}}).Validate()
+	if assert.Len(t, warnings, 1) {
+		assert.Contains(t, warnings[0], `the editing mode "vim" is unknown`)
+	}
+}
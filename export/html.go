@@ -0,0 +1,104 @@
+package export
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// markdownConverter converts the markdown content to HTML, the raw HTML of the content being omitted.
+var markdownConverter = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// htmlTemplate is the template of the HTML document of a conversation, following the light or dark mode of the browser.
+var htmlTemplate = template.Must(template.New("session").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="color-scheme" content="light dark">
+<title>terminal-assistant session</title>
+<style>
+body { max-width: 52rem; margin: 2rem auto; padding: 0 1rem; font-family: system-ui, sans-serif; line-height: 1.5; color: #1f2328; background: #ffffff; }
+h1 { font-size: 1.5rem; }
+.turn { margin: 1.5rem 0; }
+.role { display: inline-block; padding: 0 .5rem; border-radius: .25rem; font-weight: bold; color: #ffffff; }
+.user .role { background: #66b3ff; }
+.assistant .role { background: #46b946; }
+time { margin-left: .5rem; font-size: .85rem; color: #6e7781; }
+pre, code { font-family: ui-monospace, monospace; font-size: .9rem; background: #f6f8fa; border-radius: .25rem; }
+pre { padding: .75rem; overflow-x: auto; white-space: pre-wrap; }
+code { padding: .1rem .25rem; }
+pre code { padding: 0; }
+@media (prefers-color-scheme: dark) {
+body { color: #e6edf3; background: #0d1117; }
+time { color: #8d96a0; }
+pre, code { background: #161b22; }
+}
+</style>
+</head>
+<body>
+<h1>terminal-assistant session</h1>
+{{- range .}}
+<section class="turn {{.Role}}">
+<div><span class="role">{{.Label}}</span><time>{{.Time}}</time></div>
+{{.Content}}
+</section>
+{{- end}}
+</body>
+</html>
+`))
+
+// htmlTurn is a turn of a conversation prepared for the HTML template.
+type htmlTurn struct {
+	Role    string
+	Label   string
+	Time    string
+	Content template.HTML
+}
+
+// MarkdownToHTML converts a markdown content to an HTML fragment.
+func MarkdownToHTML(content string) (string, error) {
+	var buffer bytes.Buffer
+	if err := markdownConverter.Convert([]byte(content), &buffer); err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+// HTML renders the turns of a conversation as a standalone HTML document.
+func HTML(turns []ConversationTurn) (string, error) {
+	prepared := make([]htmlTurn, 0, len(turns))
+	for _, turn := range turns {
+		content, err := MarkdownToHTML(strings.TrimSpace(turn.GetContent()))
+		if err != nil {
+			return "", err
+		}
+		prepared = append(prepared, htmlTurn{
+			Role:    turn.GetRole(),
+			Label:   GetRoleLabel(turn.GetRole()),
+			Time:    formatTurnTime(turn),
+			Content: template.HTML(content),
+		})
+	}
+
+	var buffer bytes.Buffer
+	if err := htmlTemplate.Execute(&buffer, prepared); err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+// SaveHTML writes the turns of a conversation as an HTML document to a file, "~" being expanded.
+func SaveHTML(turns []ConversationTurn, path string) error {
+	content, err := HTML(turns)
+	if err != nil {
+		return err
+	}
+
+	return save(content, path)
+}
@@ -0,0 +1,57 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTML(t *testing.T) {
+	t.Run("MarkdownToHTML", testMarkdownToHTML)
+	t.Run("HTML", testHTML)
+	t.Run("SaveHTML", testSaveHTML)
+}
+
+// testMarkdownToHTML tests the MarkdownToHTML function.
+func testMarkdownToHTML(t *testing.T) {
+	output, err := MarkdownToHTML("**bold** `ls -la`\n\n<script>alert(1)</script>")
+	require.NoError(t, err)
+	assert.Contains(t, output, "<strong>bold</strong>")
+	assert.Contains(t, output, "<code>ls -la</code>")
+	assert.NotContains(t, output, "<script>", "The raw HTML should be omitted.")
+}
+
+// testHTML tests the HTML function.
+func testHTML(t *testing.T) {
+	turns := []ConversationTurn{
+		NewConversationTurn(UserRole, "list <files>"),
+		NewConversationTurn(AssistantRole, "`ls -la`\n"),
+	}
+
+	output, err := HTML(turns)
+	require.NoError(t, err)
+	assert.Contains(t, output, "<title>terminal-assistant session</title>")
+	assert.Contains(t, output, "prefers-color-scheme: dark")
+	assert.Contains(t, output, `<section class="turn user">`)
+	assert.Contains(t, output, "list ")
+	assert.NotContains(t, output, "<files>", "The raw HTML of the content should be omitted.")
+	assert.Contains(t, output, `<section class="turn assistant">`)
+	assert.Contains(t, output, "<code>ls -la</code>")
+}
+
+// testSaveHTML tests the SaveHTML function.
+func testSaveHTML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.html")
+	turns := []ConversationTurn{NewConversationTurn(UserRole, "hello")}
+
+	require.NoError(t, SaveHTML(turns, path))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	expected, err := HTML(turns)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(content))
+}
@@ -0,0 +1,63 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+// Markdown renders the turns of a conversation as a markdown document.
+func Markdown(turns []ConversationTurn) string {
+	return "# terminal-assistant session\n" + markdownTurns(turns)
+}
+
+// markdownTurns renders the turns of a conversation as the sections of a markdown document.
+func markdownTurns(turns []ConversationTurn) string {
+	var builder strings.Builder
+	for _, turn := range turns {
+		builder.WriteString(fmt.Sprintf(
+			"\n### %s\n\n_%s_\n\n%s\n",
+			GetRoleLabel(turn.GetRole()),
+			formatTurnTime(turn),
+			strings.TrimSpace(turn.GetContent()),
+		))
+	}
+
+	return builder.String()
+}
+
+// SaveMarkdown writes the turns of a conversation as a markdown document to a file, "~" being expanded.
+func SaveMarkdown(turns []ConversationTurn, path string) error {
+	return save(Markdown(turns), path)
+}
+
+// AppendMarkdown appends the turns of a conversation to a markdown document saved by SaveMarkdown, "~" being expanded.
+func AppendMarkdown(turns []ConversationTurn, path string) error {
+	expanded, err := homedir.Expand(path)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(expanded, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = file.WriteString(markdownTurns(turns))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// save writes a document to a file, "~" being expanded.
+func save(content string, path string) error {
+	expanded, err := homedir.Expand(path)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(expanded, []byte(content), 0600)
+}
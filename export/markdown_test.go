@@ -0,0 +1,63 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkdown(t *testing.T) {
+	t.Run("Markdown", testMarkdown)
+	t.Run("SaveMarkdown", testSaveMarkdown)
+	t.Run("AppendMarkdown", testAppendMarkdown)
+}
+
+// testMarkdown tests the Markdown function.
+func testMarkdown(t *testing.T) {
+	turns := []ConversationTurn{
+		NewConversationTurn(UserRole, "list files"),
+		NewConversationTurn(AssistantRole, "`ls -la`\n"),
+	}
+
+	output := Markdown(turns)
+	assert.Contains(t, output, "# terminal-assistant session")
+	assert.Contains(t, output, "### You\n")
+	assert.Contains(t, output, "list files")
+	assert.Contains(t, output, "### Assistant\n")
+	assert.Contains(t, output, "`ls -la`")
+	assert.Less(t, len(Markdown(nil)), len(output), "An empty session should only contain the title.")
+}
+
+// testSaveMarkdown tests the SaveMarkdown function.
+func testSaveMarkdown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.md")
+	turns := []ConversationTurn{NewConversationTurn(UserRole, "hello")}
+
+	require.NoError(t, SaveMarkdown(turns, path))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, Markdown(turns), string(content))
+}
+
+// testAppendMarkdown tests the AppendMarkdown function.
+func testAppendMarkdown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.md")
+	turns := []ConversationTurn{
+		NewConversationTurn(UserRole, "hello"),
+		NewConversationTurn(AssistantRole, "[ok]").WithDuration(1500 * time.Millisecond),
+	}
+	require.NoError(t, SaveMarkdown(turns[:1], path))
+	require.NoError(t, AppendMarkdown(turns[1:], path))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, Markdown(turns), string(content), "The appended turns should follow the saved ones.")
+	assert.Contains(t, string(content), " · ran in 1.5s_", "The duration of the command should be written.")
+
+	assert.Error(t, AppendMarkdown(turns, filepath.Join(t.TempDir(), "missing.md")), "A missing document should not be created.")
+}
@@ -0,0 +1,78 @@
+package export
+
+import (
+	"fmt"
+	"time"
+)
+
+// Roles of the participants of a conversation.
+const (
+	UserRole      = "user"
+	AssistantRole = "assistant"
+)
+
+// ConversationTurn represents a message of a participant of the conversation.
+type ConversationTurn struct {
+	role     string        // The role of the participant, either UserRole or AssistantRole.
+	content  string        // The markdown content of the message.
+	time     time.Time     // The time of the message.
+	duration time.Duration // The duration of the command run in the message, 0 if none was run.
+}
+
+// NewConversationTurn creates a new ConversationTurn instance at the current time.
+func NewConversationTurn(role string, content string) ConversationTurn {
+	return ConversationTurn{
+		role:    role,
+		content: content,
+		time:    time.Now(),
+	}
+}
+
+// GetRole returns the role of the participant.
+func (t ConversationTurn) GetRole() string {
+	return t.role
+}
+
+// GetContent returns the markdown content of the message.
+func (t ConversationTurn) GetContent() string {
+	return t.content
+}
+
+// GetTime returns the time of the message.
+func (t ConversationTurn) GetTime() time.Time {
+	return t.time
+}
+
+// WithDuration returns the turn with the duration of the command run in the message.
+func (t ConversationTurn) WithDuration(duration time.Duration) ConversationTurn {
+	t.duration = duration
+
+	return t
+}
+
+// GetDuration returns the duration of the command run in the message, 0 if none was run.
+func (t ConversationTurn) GetDuration() time.Duration {
+	return t.duration
+}
+
+// formatTurnTime returns the time of a message, followed by the duration of the command run in it, if any.
+func formatTurnTime(turn ConversationTurn) string {
+	formatted := turn.GetTime().Format("2006-01-02 15:04:05")
+	if turn.GetDuration() > 0 {
+		formatted += fmt.Sprintf(" · ran in %s", turn.GetDuration().Round(100*time.Millisecond))
+	}
+
+	return formatted
+}
+
+// GetRoleLabel returns the label displayed for the role of the participant.
+func GetRoleLabel(role string) string {
+	switch role {
+	case UserRole:
+		return "You"
+	case AssistantRole:
+		return "Assistant"
+	default:
+		return role
+	}
+}
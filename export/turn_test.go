@@ -0,0 +1,35 @@
+package export
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConversationTurn(t *testing.T) {
+	t.Run("NewConversationTurn", testNewConversationTurn)
+	t.Run("GetRoleLabel", testGetRoleLabel)
+}
+
+// testNewConversationTurn tests the NewConversationTurn function.
+func testNewConversationTurn(t *testing.T) {
+	turn := NewConversationTurn(UserRole, "hello")
+
+	assert.Equal(t, UserRole, turn.GetRole())
+	assert.Equal(t, "hello", turn.GetContent())
+	assert.False(t, turn.GetTime().IsZero(), "The time of the turn should be set.")
+	assert.Equal(t, time.Duration(0), turn.GetDuration(), "No command should be run in the turn.")
+
+	turn = turn.WithDuration(3200 * time.Millisecond)
+	assert.Equal(t, 3200*time.Millisecond, turn.GetDuration())
+	assert.True(t, strings.HasSuffix(formatTurnTime(turn), " · ran in 3.2s"), "The duration should follow the time.")
+}
+
+// testGetRoleLabel tests the GetRoleLabel function.
+func testGetRoleLabel(t *testing.T) {
+	assert.Equal(t, "You", GetRoleLabel(UserRole))
+	assert.Equal(t, "Assistant", GetRoleLabel(AssistantRole))
+	assert.Equal(t, "system", GetRoleLabel("system"))
+}
@@ -1,60 +1,328 @@
 package history
 
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"time"
+)
+
+// PromptMode is the mode of the prompt an input was entered in, like "exec" or "chat", empty if unknown
+type PromptMode string
+
 // History is a struct that stores the history of user inputs
 type History struct {
-	inputs map[int]string // map of input history
-	cursor int            // current cursor position
+	inputs    map[int]string        // map of input history
+	modes     map[int]PromptMode    // map of the prompt modes of the inputs
+	durations map[int]time.Duration // map of the durations of the commands run for the inputs
+	cursor    int                   // current cursor position
+	maxSize   int                   // maximum number of inputs kept, 0 for no limit
+	source    *History              // the history filtered by this view, nil if it is not a view
+	mode      PromptMode            // the prompt mode of the inputs navigated by this view
+}
+
+// entry is an input of the history, its prompt mode and the duration of the command it ran, as saved in the history file
+type entry struct {
+	Input      string     `json:"input"`
+	Mode       PromptMode `json:"mode,omitempty"`
+	DurationMs int64      `json:"duration_ms,omitempty"`
 }
 
 // NewHistory returns a new History struct
 func NewHistory() *History {
 	return &History{
-		map[int]string{},
-		0,
+		inputs:    map[int]string{},
+		modes:     map[int]PromptMode{},
+		durations: map[int]time.Duration{},
+	}
+}
+
+// Filter returns a view of the history whose navigation skips the inputs entered in another prompt mode, the inputs
+// of unknown mode being kept. The view is not a copy: it shares the inputs and the cursor of the history, and
+// the other methods of the view apply to the history.
+func (h *History) Filter(mode PromptMode) *History {
+	return &History{
+		source: h.root(),
+		mode:   mode,
+	}
+}
+
+// root returns the history filtered by a view, or the history itself
+func (h *History) root() *History {
+	if h.source != nil {
+		return h.source
+	}
+
+	return h
+}
+
+// matches checks if the input at a position is navigated by the history, or by the view
+func (h *History) matches(position int) bool {
+	if h.source == nil {
+		return true
+	}
+
+	mode := h.source.modes[position]
+
+	return mode == "" || mode == h.mode
+}
+
+// SetMaxSize sets the maximum number of inputs kept in the history, 0 for no limit
+func (h *History) SetMaxSize(maxSize int) *History {
+	if h.source != nil {
+		h.source.SetMaxSize(maxSize)
+		return h
+	}
+
+	h.maxSize = maxSize
+	if maxSize > 0 {
+		h.Trim(maxSize)
 	}
+
+	return h
+}
+
+// GetMaxSize returns the maximum number of inputs kept in the history
+func (h *History) GetMaxSize() int {
+	return h.root().maxSize
 }
 
 // Reset resets the history
 func (h *History) Reset() *History {
-	h.inputs = map[int]string{}
-	h.cursor = 0
+	root := h.root()
+	root.inputs = map[int]string{}
+	root.modes = map[int]PromptMode{}
+	root.durations = map[int]time.Duration{}
+	root.cursor = 0
 
 	return h
 }
 
-// Add adds a new input to the history
+// Add adds a new input of unknown prompt mode to the history, evicting the oldest inputs if the history is full
 func (h *History) Add(input string) *History {
-	h.cursor = len(h.inputs)
-	h.inputs[h.cursor] = input
+	return h.AddWithMode(input, "")
+}
+
+// AddWithMode adds a new input entered in a prompt mode to the history, evicting the oldest inputs if the history is full
+func (h *History) AddWithMode(input string, mode PromptMode) *History {
+	root := h.root()
+	if root.maxSize > 0 && root.Len() >= root.maxSize {
+		root.Trim(root.maxSize - 1)
+	}
+
+	root.cursor = len(root.inputs)
+	root.inputs[root.cursor] = input
+	if mode != "" {
+		root.modes[root.cursor] = mode
+	}
 
 	return h
 }
 
+// SetDuration records the duration of the command run for the most recent input, saved with it
+func (h *History) SetDuration(duration time.Duration) *History {
+	root := h.root()
+	if len(root.inputs) == 0 || duration <= 0 {
+		return h
+	}
+
+	root.durations[len(root.inputs)-1] = duration
+
+	return h
+}
+
+// GetDuration returns the duration of the command run for the input at a position, 0 if none was run
+func (h *History) GetDuration(position int) time.Duration {
+	return h.root().durations[position]
+}
+
+// Len returns the number of inputs in the history
+func (h *History) Len() int {
+	return len(h.root().inputs)
+}
+
+// Trim keeps only the n most recent inputs and returns the number of inputs removed
+func (h *History) Trim(n int) int {
+	if h.source != nil {
+		return h.source.Trim(n)
+	}
+
+	if n < 0 {
+		n = 0
+	}
+
+	removed := len(h.inputs) - n
+	if removed <= 0 {
+		return 0
+	}
+
+	// Shift the most recent inputs to the start of the history
+	inputs := make(map[int]string, n)
+	modes := make(map[int]PromptMode, n)
+	durations := make(map[int]time.Duration, n)
+	for i := 0; i < n; i++ {
+		inputs[i] = h.inputs[i+removed]
+		if mode, ok := h.modes[i+removed]; ok {
+			modes[i] = mode
+		}
+		if duration, ok := h.durations[i+removed]; ok {
+			durations[i] = duration
+		}
+	}
+	h.inputs = inputs
+	h.modes = modes
+	h.durations = durations
+
+	h.cursor -= removed
+	if h.cursor < 0 {
+		h.cursor = 0
+	}
+
+	return removed
+}
+
 // GetAll returns all the inputs in the history
 func (h *History) GetAll() map[int]string {
-	return h.inputs
+	return h.root().inputs
 }
 
 // GetCursor returns the current cursor position
 func (h *History) GetCursor() int {
-	return h.cursor
+	return h.root().cursor
 }
 
-// GetPrevious returns the previous input
+// GetPrevious returns the previous input, skipping the inputs of another prompt mode in a view
 func (h *History) GetPrevious() *string {
-	if input, ok := h.inputs[h.cursor]; ok {
-		h.cursor--
-		return &input
+	return h.GetPreviousFiltered("")
+}
+
+// GetNext returns the next input, skipping the inputs of another prompt mode in a view
+func (h *History) GetNext() *string {
+	return h.GetNextFiltered("")
+}
+
+// GetPreviousFiltered returns the previous input starting with a prefix, like the history-search-backward of readline,
+// skipping the inputs of another prompt mode in a view
+func (h *History) GetPreviousFiltered(prefix string) *string {
+	root := h.root()
+	for position := root.cursor; position >= 0; position-- {
+		input, ok := root.inputs[position]
+		if !ok {
+			break
+		}
+		if h.matches(position) && strings.HasPrefix(input, prefix) {
+			root.cursor = position - 1
+			return &input
+		}
 	}
 
 	return nil
 }
 
-// GetNext returns the next input
-func (h *History) GetNext() *string {
-	if input, ok := h.inputs[h.cursor+1]; ok {
-		h.cursor++
-		return &input
+// GetNextFiltered returns the next input starting with a prefix, like the history-search-forward of readline,
+// skipping the inputs of another prompt mode in a view
+func (h *History) GetNextFiltered(prefix string) *string {
+	root := h.root()
+	for position := root.cursor + 1; ; position++ {
+		input, ok := root.inputs[position]
+		if !ok {
+			break
+		}
+		if h.matches(position) && strings.HasPrefix(input, prefix) {
+			root.cursor = position
+			return &input
+		}
+	}
+
+	return nil
+}
+
+// FindPrefix returns the most recent input starting with a prefix and longer than it, without moving the cursor,
+// skipping the inputs of another prompt mode in a view. It returns nil for an empty prefix
+func (h *History) FindPrefix(prefix string) *string {
+	if prefix == "" {
+		return nil
+	}
+
+	root := h.root()
+	for position := len(root.inputs) - 1; position >= 0; position-- {
+		input := root.inputs[position]
+		if h.matches(position) && len(input) > len(prefix) && strings.HasPrefix(input, prefix) {
+			return &input
+		}
+	}
+
+	return nil
+}
+
+// FindAllPrefix returns the distinct inputs starting with a prefix and longer than it, the most recent first and at
+// most limit of them, without moving the cursor, skipping the inputs of another prompt mode in a view
+func (h *History) FindAllPrefix(prefix string, limit int) []string {
+	if prefix == "" {
+		return nil
+	}
+
+	var found []string
+	seen := map[string]bool{}
+	root := h.root()
+	for position := len(root.inputs) - 1; position >= 0 && len(found) < limit; position-- {
+		input := root.inputs[position]
+		if h.matches(position) && len(input) > len(prefix) && strings.HasPrefix(input, prefix) && !seen[input] {
+			seen[input] = true
+			found = append(found, input)
+		}
+	}
+
+	return found
+}
+
+// Save writes the most recent inputs of the history to a file
+func (h *History) Save(path string) error {
+	root := h.root()
+	start := 0
+	if root.maxSize > 0 && len(root.inputs) > root.maxSize {
+		start = len(root.inputs) - root.maxSize
+	}
+
+	entries := make([]entry, 0, len(root.inputs)-start)
+	for i := start; i < len(root.inputs); i++ {
+		entries = append(entries, entry{Input: root.inputs[i], Mode: root.modes[i], DurationMs: root.durations[i].Milliseconds()})
+	}
+
+	content, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, content, 0600)
+}
+
+// Load adds the inputs saved in a file to the history, a missing file is not an error. The files saved before
+// the prompt modes were recorded, a list of inputs, are loaded with an unknown mode.
+func (h *History) Load(path string) error {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var entries []entry
+	if err := json.Unmarshal(content, &entries); err != nil {
+		var inputs []string
+		if json.Unmarshal(content, &inputs) != nil {
+			return err
+		}
+		entries = nil
+		for _, input := range inputs {
+			entries = append(entries, entry{Input: input})
+		}
+	}
+
+	for _, entry := range entries {
+		h.AddWithMode(entry.Input, entry.Mode).SetDuration(time.Duration(entry.DurationMs) * time.Millisecond)
 	}
 
 	return nil
@@ -1,9 +1,13 @@
 package history
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHistory(t *testing.T) {
@@ -63,6 +67,42 @@ func TestHistory(t *testing.T) {
 		assert.Equal(t, "input2", *next)
 	})
 
+	// TestGetFiltered tests the navigation of the inputs starting with a prefix.
+	t.Run("GetFiltered", func(t *testing.T) {
+		h := NewHistory()
+		h.Add("git status").Add("ls").Add("git log").Add("pwd")
+
+		assert.Equal(t, "git log", *h.GetPreviousFiltered("git"))
+		assert.Equal(t, "git status", *h.GetPreviousFiltered("git"), "The inputs without the prefix should be skipped.")
+		assert.Nil(t, h.GetPreviousFiltered("git"))
+		assert.Equal(t, "git status", *h.GetNextFiltered("git"))
+		assert.Equal(t, "git log", *h.GetNextFiltered("git"), "The inputs without the prefix should be skipped.")
+		assert.Nil(t, h.GetNextFiltered("git"))
+
+		exec := NewHistory().AddWithMode("git status", "exec").AddWithMode("git help", "chat").Filter("exec")
+		assert.Equal(t, "git status", *exec.GetPreviousFiltered("git"), "The inputs of another prompt mode should be skipped.")
+	})
+
+	// TestFindPrefix tests the lookup of the most recent input starting with a prefix.
+	t.Run("FindPrefix", func(t *testing.T) {
+		h := NewHistory()
+		h.AddWithMode("git status", "exec").AddWithMode("git log", "exec").AddWithMode("git help", "chat").AddWithMode("ls", "exec")
+		cursor := h.GetCursor()
+
+		assert.Equal(t, "git help", *h.FindPrefix("git"))
+		assert.Equal(t, "git log", *h.Filter("exec").FindPrefix("git"), "The inputs of another prompt mode should be skipped.")
+		assert.Equal(t, "git status", *h.FindPrefix("git s"))
+		assert.Nil(t, h.FindPrefix("ls"), "An input equal to the prefix should not be found.")
+		assert.Nil(t, h.FindPrefix("pwd"))
+		assert.Nil(t, h.FindPrefix(""))
+		assert.Equal(t, cursor, h.GetCursor(), "The cursor should not be moved.")
+
+		h.AddWithMode("git log", "exec")
+		assert.Equal(t, []string{"git log", "git help", "git status"}, h.FindAllPrefix("git", 10), "The duplicates should be skipped.")
+		assert.Equal(t, []string{"git log"}, h.Filter("exec").FindAllPrefix("git", 1))
+		assert.Empty(t, h.FindAllPrefix("", 10))
+	})
+
 	// TestGetOutOfBounds tests the GetOutOfBounds function.
 	t.Run("GetOutOfBounds", func(t *testing.T) {
 		h := NewHistory()
@@ -77,4 +117,105 @@ func TestHistory(t *testing.T) {
 		next := h.GetNext()
 		assert.Nil(t, next)
 	})
+
+	// TestMaxSize tests the eviction of the oldest inputs when the history is full.
+	t.Run("MaxSize", func(t *testing.T) {
+		h := NewHistory().SetMaxSize(2)
+		h.Add("input1").Add("input2").Add("input3")
+		assert.Equal(t, 2, h.Len())
+		assert.Equal(t, map[int]string{0: "input2", 1: "input3"}, h.GetAll())
+
+		prev := h.GetPrevious()
+		assert.NotNil(t, prev)
+		assert.Equal(t, "input3", *prev)
+	})
+
+	// TestTrim tests the Trim function.
+	t.Run("Trim", func(t *testing.T) {
+		h := NewHistory()
+		h.Add("input1").Add("input2").Add("input3")
+		assert.Equal(t, 2, h.Trim(1))
+		assert.Equal(t, map[int]string{0: "input3"}, h.GetAll())
+		assert.Equal(t, 0, h.Trim(5))
+		assert.Equal(t, 1, h.Trim(0))
+		assert.Equal(t, 0, h.Len())
+	})
+
+	// TestSaveLoad tests the Save and Load functions.
+	t.Run("SaveLoad", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "history.json")
+
+		h := NewHistory()
+		h.Add("input1").Add("input2").Add("input3")
+		h.SetMaxSize(5)
+		require.NoError(t, h.Save(path))
+
+		loaded := NewHistory().SetMaxSize(2)
+		require.NoError(t, loaded.Load(path))
+		assert.Equal(t, map[int]string{0: "input2", 1: "input3"}, loaded.GetAll())
+
+		require.NoError(t, NewHistory().Load(filepath.Join(t.TempDir(), "missing.json")))
+	})
+
+	// TestDuration tests that the durations of the commands are recorded for the most recent input, kept when the
+	// history is trimmed and saved with the inputs.
+	t.Run("Duration", func(t *testing.T) {
+		h := NewHistory()
+		h.SetDuration(time.Second)
+		assert.Equal(t, 0, h.Len(), "Nothing should be recorded without input.")
+
+		h.Add("input1").SetDuration(1500 * time.Millisecond).Add("input2").Add("input3").SetDuration(2 * time.Second)
+		assert.Equal(t, 1500*time.Millisecond, h.GetDuration(0))
+		assert.Equal(t, time.Duration(0), h.GetDuration(1), "The inputs that ran no command should have no duration.")
+
+		h.Trim(2)
+		assert.Equal(t, 2*time.Second, h.GetDuration(1), "The durations should follow their inputs.")
+
+		path := filepath.Join(t.TempDir(), "history.json")
+		require.NoError(t, h.Save(path))
+		loaded := NewHistory()
+		require.NoError(t, loaded.Load(path))
+		assert.Equal(t, time.Duration(0), loaded.GetDuration(0))
+		assert.Equal(t, 2*time.Second, loaded.GetDuration(1), "The durations should be saved.")
+
+		h.Reset()
+		assert.Equal(t, time.Duration(0), h.GetDuration(1))
+	})
+
+	// TestLoadInputs tests that the history files saved as a list of inputs are loaded with an unknown mode.
+	t.Run("LoadInputs", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "history.json")
+		require.NoError(t, os.WriteFile(path, []byte(`["input1","input2"]`), 0600))
+
+		h := NewHistory()
+		require.NoError(t, h.Load(path))
+		assert.Equal(t, map[int]string{0: "input1", 1: "input2"}, h.GetAll())
+		assert.Equal(t, "input2", *h.Filter("chat").GetPrevious(), "The inputs of unknown mode should be navigated in every mode.")
+	})
+
+	// TestFilter tests the navigation of a view of the history filtered by prompt mode.
+	t.Run("Filter", func(t *testing.T) {
+		h := NewHistory()
+		h.AddWithMode("ls", "exec").AddWithMode("what is ls?", "chat").AddWithMode("pwd", "exec").Add("/help")
+		exec := h.Filter("exec")
+
+		assert.Equal(t, "/help", *exec.GetPrevious())
+		assert.Equal(t, "pwd", *exec.GetPrevious())
+		assert.Equal(t, "ls", *exec.GetPrevious(), "The chat input should be skipped.")
+		assert.Nil(t, exec.GetPrevious())
+		assert.Equal(t, "ls", *exec.GetNext())
+		assert.Equal(t, "pwd", *exec.GetNext(), "The chat input should be skipped.")
+
+		h.AddWithMode("date", "exec")
+		assert.Equal(t, 5, exec.Len(), "The view should share the inputs of the history.")
+		assert.Equal(t, "date", *exec.GetPrevious(), "The view should share the cursor of the history.")
+
+		path := filepath.Join(t.TempDir(), "history.json")
+		require.NoError(t, exec.Save(path))
+		loaded := NewHistory()
+		require.NoError(t, loaded.Load(path))
+		chat := loaded.Filter("chat")
+		assert.Equal(t, "/help", *chat.GetPrevious())
+		assert.Equal(t, "what is ls?", *chat.GetPrevious(), "The modes should be saved.")
+	})
 }
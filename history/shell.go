@@ -0,0 +1,156 @@
+package history
+
+import (
+	"os"
+	"regexp"
+	"strings"
+)
+
+// zsh_meta is the byte escaping the bytes of the zsh history files that are special to zsh, the next byte being
+// xored with 32
+const zsh_meta = 0x83
+
+// zshExtendedLine matches the lines of the zsh history files saved with the EXTENDED_HISTORY option,
+// like ": 1700000000:0;ls -la"
+var zshExtendedLine = regexp.MustCompile(`^: *\d+:\d+;`)
+
+// bashTimestampLine matches the timestamps written before the commands in the bash history files when
+// HISTTIMEFORMAT is set, like "#1700000000"
+var bashTimestampLine = regexp.MustCompile(`^#\d+$`)
+
+// fish_command_prefix is the prefix of the commands in the fish history files, the other lines being their metadata
+const fish_command_prefix = "- cmd: "
+
+// ImportFromShell adds the commands of a bash, zsh or fish history file to the history, with an unknown prompt mode,
+// and returns the number of commands added. The format is detected from the content of the file: the fish history
+// files list the commands with their metadata, the zsh history files may prefix them with their timestamps, and
+// the bash history files list one command per line.
+func (h *History) ImportFromShell(shellHistFile string) (int, error) {
+	content, err := os.ReadFile(shellHistFile)
+	if err != nil {
+		return 0, err
+	}
+
+	commands := parseShellHistory(string(content))
+	for _, command := range commands {
+		h.Add(command)
+	}
+
+	return len(commands), nil
+}
+
+// parseShellHistory returns the commands of the content of a bash, zsh or fish history file, oldest first
+func parseShellHistory(content string) []string {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for _, line := range lines {
+		if strings.HasPrefix(line, fish_command_prefix) {
+			return parseFishHistory(lines)
+		}
+	}
+
+	return parseZshHistory(lines)
+}
+
+// parseFishHistory returns the commands of the lines of a fish history file, whose newlines and backslashes are escaped
+func parseFishHistory(lines []string) []string {
+	var commands []string
+	unescape := strings.NewReplacer(`\\`, `\`, `\n`, "\n")
+	for _, line := range lines {
+		if !strings.HasPrefix(line, fish_command_prefix) {
+			continue
+		}
+		if command := unescape.Replace(strings.TrimPrefix(line, fish_command_prefix)); strings.TrimSpace(command) != "" {
+			commands = append(commands, command)
+		}
+	}
+
+	return commands
+}
+
+// parseZshHistory returns the commands of the lines of a zsh or bash history file, the timestamps being skipped.
+// The lines of the multi-line commands of zsh end with a backslash.
+func parseZshHistory(lines []string) []string {
+	var commands []string
+	command := ""
+	continued := false
+	for _, line := range lines {
+		line = unmetafy(line)
+		if continued {
+			command += "\n" + line
+		} else {
+			if bashTimestampLine.MatchString(line) {
+				continue
+			}
+			command = zshExtendedLine.ReplaceAllString(line, "")
+		}
+
+		continued = strings.HasSuffix(command, `\`) && !strings.HasSuffix(command, `\\`)
+		if continued {
+			command = strings.TrimSuffix(command, `\`)
+			continue
+		}
+		if strings.TrimSpace(command) != "" {
+			commands = append(commands, command)
+		}
+	}
+	if continued && strings.TrimSpace(command) != "" {
+		commands = append(commands, command)
+	}
+
+	return commands
+}
+
+// unmetafy restores the bytes escaped in a line of a zsh history file, like the bytes of the non-ASCII characters
+func unmetafy(line string) string {
+	if strings.IndexByte(line, zsh_meta) < 0 {
+		return line
+	}
+
+	restored := make([]byte, 0, len(line))
+	for i := 0; i < len(line); i++ {
+		if line[i] == zsh_meta && i+1 < len(line) {
+			i++
+			restored = append(restored, line[i]^32)
+			continue
+		}
+		restored = append(restored, line[i])
+	}
+
+	return string(restored)
+}
+
+// Deduplicate removes the inputs entered again later from the history, keeping their most recent occurrence and its
+// prompt mode, and returns the number of inputs removed. The cursor is moved to the most recent input.
+func (h *History) Deduplicate() int {
+	if h.source != nil {
+		return h.source.Deduplicate()
+	}
+
+	seen := map[string]bool{}
+	var kept []int
+	for position := len(h.inputs) - 1; position >= 0; position-- {
+		if input := h.inputs[position]; !seen[input] {
+			seen[input] = true
+			kept = append(kept, position)
+		}
+	}
+
+	removed := len(h.inputs) - len(kept)
+	inputs := make(map[int]string, len(kept))
+	modes := make(map[int]PromptMode, len(kept))
+	for i := range kept {
+		position := kept[len(kept)-1-i]
+		inputs[i] = h.inputs[position]
+		if mode, ok := h.modes[position]; ok {
+			modes[i] = mode
+		}
+	}
+	h.inputs = inputs
+	h.modes = modes
+	h.cursor = 0
+	if len(inputs) > 0 {
+		h.cursor = len(inputs) - 1
+	}
+
+	return removed
+}
@@ -0,0 +1,69 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShellHistory(t *testing.T) {
+	t.Run("ParseShellHistory", testParseShellHistory)
+	t.Run("ImportFromShell", testImportFromShell)
+	t.Run("Deduplicate", testDeduplicate)
+}
+
+// testParseShellHistory tests that the commands of the bash, zsh and fish history files are parsed.
+func testParseShellHistory(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{"Bash", "ls -la\n\ncd /tmp\n", []string{"ls -la", "cd /tmp"}},
+		{"BashTimestamps", "#1700000000\nls -la\n#1700000001\n# a comment\n", []string{"ls -la", "# a comment"}},
+		{"Zsh", ": 1700000000:0;ls -la\n: 1700000001:12;git status\r\n", []string{"ls -la", "git status"}},
+		{"ZshMultiline", ": 1700000000:0;for f in *; do\\\n  echo $f\\\ndone\n: 1700000001:0;pwd\n", []string{"for f in *; do\n  echo $f\ndone", "pwd"}},
+		{"ZshMetafied", ": 1700000000:0;echo a \xe2\x83\xa6\x83\xb2 b\n", []string{"echo a → b"}},
+		{"Fish", "- cmd: ls -la\n  when: 1700000000\n- cmd: echo a\\nb \\\\\n  when: 1700000001\n  paths:\n    - a\n", []string{"ls -la", "echo a\nb \\"}},
+		{"Empty", "", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, parseShellHistory(tc.content))
+		})
+	}
+}
+
+// testImportFromShell tests that the commands of a shell history file are added to the history.
+func testImportFromShell(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".zsh_history")
+	require.NoError(t, os.WriteFile(path, []byte(": 1700000000:0;ls\n: 1700000001:0;pwd\n"), 0600))
+
+	h := NewHistory().Add("input1")
+	imported, err := h.ImportFromShell(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, imported)
+	assert.Equal(t, map[int]string{0: "input1", 1: "ls", 2: "pwd"}, h.GetAll())
+	assert.Equal(t, "pwd", *h.Filter("chat").GetPrevious(), "The commands should be navigated in every mode.")
+
+	_, err = h.ImportFromShell(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
+
+// testDeduplicate tests that the most recent occurrence of the inputs is kept, with its prompt mode.
+func testDeduplicate(t *testing.T) {
+	h := NewHistory()
+	h.AddWithMode("ls", "exec").Add("pwd").AddWithMode("ls", "chat").Add("date").Add("pwd")
+
+	assert.Equal(t, 2, h.Filter("exec").Deduplicate())
+	assert.Equal(t, map[int]string{0: "ls", 1: "date", 2: "pwd"}, h.GetAll())
+	assert.Equal(t, 2, h.GetCursor(), "The cursor should be moved to the most recent input.")
+	assert.Equal(t, "date", *h.Filter("exec").GetPreviousFiltered("d"))
+	assert.Nil(t, h.Filter("exec").GetPreviousFiltered("l"), "The chat input should be skipped.")
+
+	assert.Equal(t, 0, NewHistory().Deduplicate())
+}
@@ -1,13 +1,14 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"math/rand"
+	"os"
 	"time"
 
+	"github.com/akhilsharma90/terminal-assistant/completions"
 	"github.com/akhilsharma90/terminal-assistant/ui"
-
-	tea "github.com/charmbracelet/bubbletea"
 )
 
 func main() {
@@ -20,11 +21,27 @@ func main() {
 		log.Fatal(err)
 	}
 
-	// Create a new UI with the input
-	ui := ui.NewUi(input)
+	// Print the completion script of a shell, like to add it to the shell startup file
+	if input.GetCompletions() != "" {
+		script, err := completions.Generate(input.GetCompletions())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Print(script)
+		os.Exit(0)
+	}
 
-	// Run the tea program with the UI
-	if _, err := tea.NewProgram(ui).Run(); err != nil {
+	// Write only the answer as plain text, without the user interface, when quiet or when the output is redirected
+	if input.GetPlainOutput() {
+		os.Exit(ui.NewPlainRunner(input, os.Stdout, os.Stderr).Run())
+	}
+
+	// Run the tea program with a new UI in the main screen, keeping the printed output, then terminate the background jobs
+	program, u := ui.NewProgram(ui.WithUi(ui.NewUi(input)), ui.WithProgramOptions())
+	_, err = program.Run()
+	u.Shutdown()
+	if err != nil {
 		log.Fatal(err)
 	}
 }
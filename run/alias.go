@@ -0,0 +1,178 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// alias_max_depth is the maximum number of nested aliases expanded in a command.
+const alias_max_depth = 10
+
+// Aliases maps the names of the aliases of the user to their bodies.
+type Aliases map[string]string
+
+// LoadAliases lists the aliases of an interactive shell, like "bash" or "zsh", which loads the rc files of the user.
+// The shell and its children are stopped if it does not answer before the timeout.
+func LoadAliases(shell string, timeout time.Duration) (Aliases, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	c := prepareAliasCommand(shell)
+	stdout, _, _, err := runCaptured(ctx, c)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list the aliases of %s: %w", shell, err)
+	}
+
+	return ParseAliases(stdout), nil
+}
+
+// prepareAliasCommand prepares the command listing the aliases of an interactive shell.
+func prepareAliasCommand(shell string) *exec.Cmd {
+	return exec.Command(shell, "-ic", "alias")
+}
+
+// ParseAliases parses the output of the "alias" builtin, either in the bash format (alias ll='ls -alF')
+// or in the zsh format (ll='ls -alF' or ll=ls). The lines that are not aliases are ignored.
+func ParseAliases(output string) Aliases {
+	aliases := Aliases{}
+	for _, line := range strings.Split(output, "\n") {
+		commands := tokenize(strings.TrimSpace(line))
+		if len(commands) == 0 {
+			continue
+		}
+		words := commands[0]
+		if words[0] == "alias" {
+			words = words[1:]
+		}
+		if len(words) != 1 {
+			continue
+		}
+
+		name, body, ok := strings.Cut(words[0], "=")
+		if ok && name != "" && !strings.ContainsAny(name, " \t/$`") {
+			aliases[name] = body
+		}
+	}
+
+	return aliases
+}
+
+// Expand replaces the aliases used as the programs of the commands of a command line by their bodies,
+// like the shell does. The nested aliases are expanded as well, an alias never being expanded in its own body.
+func (a Aliases) Expand(cmd string) string {
+	if len(a) == 0 {
+		return cmd
+	}
+
+	return a.expand(cmd, nil)
+}
+
+// Summary returns the aliases sorted by name as name='body' separated by commas, limited to the first max aliases,
+// the long bodies being truncated.
+func (a Aliases) Summary(max int) string {
+	names := make([]string, 0, len(a))
+	for name := range a {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) > max {
+		names = names[:max]
+	}
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		body := a[name]
+		if len(body) > 60 {
+			body = body[:57] + "..."
+		}
+		parts = append(parts, fmt.Sprintf("%s=%s", name, Quote(body)))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// Definitions returns the shell lines defining the aliases sorted by name, enabling their expansion first,
+// so a non-interactive bash runs a command using them. It is empty when there are no aliases.
+func (a Aliases) Definitions() string {
+	if len(a) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(a))
+	for name := range a {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := []string{"shopt -s expand_aliases"}
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("alias %s=%s", name, Quote(a[name])))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// expand expands the aliases of a command line, except the ones already being expanded.
+func (a Aliases) expand(cmd string, expanding []string) string {
+	var (
+		builder      strings.Builder
+		quote        rune
+		commandStart = true
+		runes        = []rune(cmd)
+	)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if r == '\\' && quote == '"' && i+1 < len(runes) {
+				builder.WriteRune(r)
+				i++
+				r = runes[i]
+			}
+		case r == '\\':
+			commandStart = false
+			if i+1 < len(runes) {
+				builder.WriteRune(r)
+				i++
+				r = runes[i]
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			commandStart = false
+		case strings.ContainsRune("|&;(){}\n", r):
+			commandStart = true
+		case r == ' ' || r == '\t':
+		case commandStart:
+			// Read the first word of the command, up to a space, an operator or a quote
+			end := i
+			for end < len(runes) && !strings.ContainsRune(" \t|&;(){}\n<>'\"\\`$", runes[end]) {
+				end++
+			}
+			word := string(runes[i:end])
+			if body, ok := a[word]; ok && word != "" && !contains(expanding, word) && len(expanding) < alias_max_depth &&
+				(end == len(runes) || !strings.ContainsRune("'\"\\`$", runes[end])) {
+				builder.WriteString(a.expand(body, append(expanding, word)))
+				i = end - 1
+				commandStart = false
+				continue
+			}
+			// The assignments before the program are skipped
+			commandStart = assignmentPattern.MatchString(word) && end > i
+			if end > i {
+				builder.WriteString(word)
+				i = end - 1
+				continue
+			}
+		}
+		builder.WriteRune(r)
+	}
+
+	return builder.String()
+}
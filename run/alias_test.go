@@ -0,0 +1,118 @@
+package run
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAliases(t *testing.T) {
+	t.Run("ParseAliases", testParseAliases)
+	t.Run("Expand", testExpandAliases)
+	t.Run("Summary", testAliasesSummary)
+	t.Run("Definitions", testAliasesDefinitions)
+	t.Run("LoadAliases", testLoadAliases)
+}
+
+// testParseAliases tests the ParseAliases function with the bash and zsh output formats.
+func testParseAliases(t *testing.T) {
+	testCases := []struct {
+		name     string
+		output   string
+		expected Aliases
+	}{
+		{"Bash", "alias ll='ls -alF'\nalias gs='git status'\n", Aliases{"ll": "ls -alF", "gs": "git status"}},
+		{"BashEscapedQuote", `alias hi='echo '\''hello world'\'''`, Aliases{"hi": "echo 'hello world'"}},
+		{"BashOperators", "alias lg='ls | grep -i go && echo done'", Aliases{"lg": "ls | grep -i go && echo done"}},
+		{"Zsh", "ll='ls -alF'\nl=ls\n", Aliases{"ll": "ls -alF", "l": "ls"}},
+		{"ZshQuotedName", "'g++'='g++ -Wall'\n-='cd -'", Aliases{"g++": "g++ -Wall", "-": "cd -"}},
+		{"ZshDoubleQuotes", `now="date \"+%H:%M\""`, Aliases{"now": `date "+%H:%M"`}},
+		{"Noise", "bash: no job control in this shell\n\nalias ll='ls -l'", Aliases{"ll": "ls -l"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ParseAliases(tc.output), "The aliases should match.")
+		})
+	}
+}
+
+// testExpandAliases tests the Expand method of the Aliases type.
+func testExpandAliases(t *testing.T) {
+	aliases := Aliases{
+		"ll":    "ls -alF",
+		"ls":    "ls --color=auto",
+		"gs":    "git status",
+		"lg":    "ll | grep",
+		"loop":  "loop2",
+		"loop2": "loop",
+	}
+
+	testCases := []struct {
+		cmd      string
+		expected string
+	}{
+		{"ll -t", "ls --color=auto -alF -t"},
+		{"ls", "ls --color=auto"},
+		{"cd src && gs", "cd src && git status"},
+		{"echo ll | ll", "echo ll | ls --color=auto -alF"},
+		{"lg go", "ls --color=auto -alF | grep go"},
+		{"FOO=bar ll", "FOO=bar ls --color=auto -alF"},
+		{"echo 'a; ll' \"b | gs\"", "echo 'a; ll' \"b | gs\""},
+		{"\\ll", "\\ll"},
+		{"'ll'", "'ll'"},
+		{"(ll); $(gs)", "(ls --color=auto -alF); $(git status)"},
+		{"llama", "llama"},
+		{"loop", "loop"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.cmd, func(t *testing.T) {
+			assert.Equal(t, tc.expected, aliases.Expand(tc.cmd), "The expanded command should match.")
+		})
+	}
+
+	assert.Equal(t, "ll -t", Aliases(nil).Expand("ll -t"), "Nothing should be expanded without aliases.")
+}
+
+// testAliasesSummary tests the Summary method of the Aliases type.
+func testAliasesSummary(t *testing.T) {
+	aliases := Aliases{"ll": "ls -alF", "gs": "git status", "l": "ls"}
+	assert.Equal(t, "gs='git status', l=ls, ll='ls -alF'", aliases.Summary(10))
+	assert.Equal(t, "gs='git status'", aliases.Summary(1))
+}
+
+// testAliasesDefinitions tests the Definitions method of the Aliases type, bash expanding the aliases defined.
+func testAliasesDefinitions(t *testing.T) {
+	assert.Empty(t, Aliases(nil).Definitions(), "Nothing should be defined without aliases.")
+
+	aliases := Aliases{"ll": "echo listed", "hi": "echo 'hello world'"}
+	assert.Equal(t, "shopt -s expand_aliases\nalias hi="+Quote("echo 'hello world'")+"\nalias ll='echo listed'", aliases.Definitions())
+
+	stdout, _, _, err := RunCaptured(context.Background(), WithPreamble(aliases.Definitions(), "ll -t; hi"))
+	require.NoError(t, err)
+	assert.Equal(t, "listed -t\nhello world\n", stdout)
+}
+
+// testLoadAliases tests the LoadAliases function with a fake shell.
+func testLoadAliases(t *testing.T) {
+	aliases, err := LoadAliases("true", time.Second)
+	require.NoError(t, err)
+	assert.Empty(t, aliases)
+
+	_, err = LoadAliases("missing-shell", time.Second)
+	assert.Error(t, err)
+
+	// A shell hanging on its rc files is stopped after the timeout
+	shell := filepath.Join(t.TempDir(), "slow-shell")
+	require.NoError(t, os.WriteFile(shell, []byte("#!/bin/sh\nsleep 30\n"), 0700))
+	start := time.Now()
+	_, err = LoadAliases(shell, 100*time.Millisecond)
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second, "The shell should be stopped after the timeout.")
+}
@@ -0,0 +1,99 @@
+package run
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// interrupt_grace is the delay given to an interrupted command to stop before being killed.
+const interrupt_grace = 2 * time.Second
+
+// ErrInterrupted is returned when a captured command is interrupted by the cancellation of its context.
+var ErrInterrupted = errors.New("the command was interrupted")
+
+// ansiPattern matches ANSI escape sequences (CSI, OSC and single character escapes).
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+
+// leadingAnsiPattern matches an ANSI escape sequence at the start of a string.
+var leadingAnsiPattern = regexp.MustCompile("^(?:" + ansiPattern.String() + ")")
+
+// RunCaptured executes a shell command without a TTY and returns its stdout, stderr and exit code.
+// The returned error is not nil if the command could not be started, was cancelled, or exited with a non-zero code.
+// Cancelling the context interrupts the command and its children with SIGINT, then SIGKILL after a grace period.
+// Commands requiring elevated privileges are refused since the password prompt would hang without a TTY.
+func RunCaptured(ctx context.Context, cmd string) (string, string, int, error) {
+	if RequiresElevation(cmd) {
+		return "", "", -1, ErrElevationRequired
+	}
+
+	return runCaptured(ctx, prepareCapturedCommand(cmd))
+}
+
+// runCaptured executes a prepared command in its own process group and returns its stdout, stderr and exit code.
+// The command is interrupted when the context is done, ErrInterrupted being returned if the context was cancelled.
+func runCaptured(ctx context.Context, c *exec.Cmd) (string, string, int, error) {
+	var stdout, stderr bytes.Buffer
+
+	err := runWithWriters(ctx, c, &stdout, &stderr)
+
+	return stdout.String(), stderr.String(), exitCode(err), err
+}
+
+// runWithWriters executes a prepared command in its own process group, its stdout and stderr being written to writers.
+// The command is interrupted when the context is done, ErrInterrupted being returned if the context was cancelled.
+func runWithWriters(ctx context.Context, c *exec.Cmd, stdout io.Writer, stderr io.Writer) error {
+	c.Stdout = stdout
+	c.Stderr = stderr
+	setProcessGroup(c)
+
+	if err := c.Start(); err != nil {
+		return err
+	}
+
+	// Interrupt the command and its children when the context is done, then kill them if they are still running
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			_ = interruptProcessGroup(c)
+			select {
+			case <-done:
+			case <-time.After(interrupt_grace):
+				_ = killProcessGroup(c)
+			}
+		}
+	}()
+
+	err := c.Wait()
+	close(done)
+
+	if errors.Is(ctx.Err(), context.Canceled) {
+		err = ErrInterrupted
+	} else if ctx.Err() != nil {
+		err = ctx.Err()
+	}
+
+	return err
+}
+
+// prepareCapturedCommand prepares a bash command for execution without a TTY
+func prepareCapturedCommand(cmd string) *exec.Cmd {
+	return exec.Command("bash", "-c", strings.TrimRight(cmd, ";"))
+}
+
+// StripAnsi removes ANSI escape sequences from the given string.
+func StripAnsi(in string) string {
+	return ansiPattern.ReplaceAllString(in, "")
+}
+
+// LeadingAnsi returns the ANSI escape sequence at the start of the given string, empty if it does not start with one.
+func LeadingAnsi(in string) string {
+	return leadingAnsiPattern.FindString(in)
+}
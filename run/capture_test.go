@@ -0,0 +1,102 @@
+package run
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapture(t *testing.T) {
+	t.Run("RunCaptured", testRunCaptured)
+	t.Run("RunCapturedFailure", testRunCapturedFailure)
+	t.Run("RunCapturedInterrupted", testRunCapturedInterrupted)
+	t.Run("RunCapturedKilled", testRunCapturedKilled)
+	t.Run("RunCapturedTimeout", testRunCapturedTimeout)
+	t.Run("StripAnsi", testStripAnsi)
+	t.Run("LeadingAnsi", testLeadingAnsi)
+}
+
+// testRunCaptured is a unit test for the RunCaptured function.
+func testRunCaptured(t *testing.T) {
+	stdout, stderr, code, err := RunCaptured(context.Background(), "echo out; echo err >&2")
+	require.NoError(t, err)
+
+	assert.Equal(t, "out\n", stdout, "The stdout should be captured.")
+	assert.Equal(t, "err\n", stderr, "The stderr should be captured.")
+	assert.Equal(t, 0, code, "The exit code should be 0.")
+}
+
+// testRunCapturedFailure is a unit test for the RunCaptured function with a failing command.
+func testRunCapturedFailure(t *testing.T) {
+	_, stderr, code, err := RunCaptured(context.Background(), "echo failed >&2; exit 3")
+	require.Error(t, err)
+
+	assert.Equal(t, "failed\n", stderr, "The stderr should be captured.")
+	assert.Equal(t, 3, code, "The exit code should be forwarded.")
+}
+
+// testRunCapturedInterrupted is a unit test for the RunCaptured function with a cancelled context.
+func testRunCapturedInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	start := time.Now()
+	stdout, _, code, err := RunCaptured(ctx, "echo started; sleep 30; echo finished")
+
+	assert.ErrorIs(t, err, ErrInterrupted, "The command should be interrupted.")
+	assert.Equal(t, "started\n", stdout, "The output before the interruption should be captured.")
+	assert.Equal(t, -1, code, "The exit code should be -1.")
+	assert.Less(t, time.Since(start), interrupt_grace, "The command should stop on SIGINT.")
+}
+
+// testRunCapturedKilled is a unit test for the RunCaptured function with a command and its children ignoring SIGINT.
+func testRunCapturedKilled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, _, _, err := RunCaptured(ctx, "trap '' INT; sleep 30 & sleep 30; wait")
+
+	assert.ErrorIs(t, err, ErrInterrupted, "The command should be interrupted.")
+	assert.GreaterOrEqual(t, time.Since(start), interrupt_grace, "The command should be given a grace period.")
+	assert.Less(t, time.Since(start), interrupt_grace+3*time.Second, "The command and its children should be killed.")
+}
+
+// testRunCapturedTimeout is a unit test for the RunCaptured function with a context reaching its deadline.
+func testRunCapturedTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	_, _, _, err := RunCaptured(ctx, "sleep 30")
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "The timeout should be reported.")
+}
+
+// testStripAnsi is a unit test for the StripAnsi function.
+func testStripAnsi(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Plain", "hello", "hello"},
+		{"Color", "\x1b[31mred\x1b[0m", "red"},
+		{"Bold", "\x1b[1;32mbold green\x1b[m", "bold green"},
+		{"Title", "\x1b]0;title\x07text", "text"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, StripAnsi(tc.input), "The escape sequences should be stripped.")
+		})
+	}
+}
+
+// testLeadingAnsi is a unit test for the LeadingAnsi function.
+func testLeadingAnsi(t *testing.T) {
+	assert.Equal(t, "\x1b[1;32m", LeadingAnsi("\x1b[1;32mbold\x1b[0m"))
+	assert.Equal(t, "\x1b]0;title\x07", LeadingAnsi("\x1b]0;title\x07text"))
+	assert.Empty(t, LeadingAnsi("text\x1b[0m"), "A sequence after the text should not be returned.")
+}
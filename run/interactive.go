@@ -0,0 +1,160 @@
+package run
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// interactiveCommands is the list of known commands that always need a TTY to work properly.
+var interactiveCommands = []string{
+	"vi", "vim", "nvim", "nano", "emacs", "pico", "micro", "visudo", "vipw",
+	"less", "more", "most", "man",
+	"top", "htop", "btop", "atop", "watch",
+	"ssh", "telnet", "ftp", "sftp", "mosh",
+	"tmux", "screen",
+}
+
+// replCommands is the list of known commands that start an interactive session when called without arguments.
+var replCommands = []string{
+	"python", "python3", "ipython", "node", "irb", "ghci", "lua",
+	"bash", "sh", "zsh", "fish",
+}
+
+// clientCommands is the list of known database clients that are interactive unless a statement is given.
+var clientCommands = []string{
+	"mysql", "psql", "sqlite3", "mongo", "mongosh", "redis-cli",
+}
+
+// clientStatementFlags is the list of flags giving a statement to execute to a database client.
+var clientStatementFlags = []string{"-c", "-e", "--command", "--execute", "--eval"}
+
+// interactiveSubCommands maps the commands having sub-commands that need a TTY with one of the interactive flags,
+// an empty list of flags meaning the sub-command is always interactive.
+var interactiveSubCommands = map[string]map[string][]string{
+	"git": {
+		"rebase": {"-i", "--interactive"},
+		"add":    {"-i", "--interactive", "-p", "--patch"},
+		"commit": {"--interactive", "-p", "--patch"},
+	},
+	"docker": {
+		"run":    {"-i", "-t", "-it", "-ti", "--interactive", "--tty"},
+		"exec":   {"-i", "-t", "-it", "-ti", "--interactive", "--tty"},
+		"attach": {},
+	},
+	"podman": {
+		"run":    {"-i", "-t", "-it", "-ti", "--interactive", "--tty"},
+		"exec":   {"-i", "-t", "-it", "-ti", "--interactive", "--tty"},
+		"attach": {},
+	},
+	"kubectl": {
+		"exec":   {"-i", "-t", "-it", "-ti", "--stdin", "--tty"},
+		"run":    {"-i", "-t", "-it", "-ti", "--stdin", "--tty"},
+		"attach": {},
+		"edit":   {},
+	},
+	"crontab": {
+		"-e": {},
+	},
+	"systemctl": {
+		"edit": {},
+	},
+}
+
+// interactiveFlagCommands is the list of commands whose interactive flags ask for a confirmation on the TTY.
+var interactiveFlagCommands = []string{"rm", "cp", "mv", "ln"}
+
+// IsInteractive checks if a command line needs a TTY, because one of its programs is a known interactive command,
+// a REPL or database client started without statement, or a command called with an interactive flag or sub-command.
+func IsInteractive(cmd string) bool {
+	for _, command := range commands(cmd) {
+		if isInteractiveCommand(unwrapElevation(command)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isInteractiveCommand checks if a single command needs a TTY.
+func isInteractiveCommand(command []string) bool {
+	if len(command) == 0 {
+		return false
+	}
+
+	program, args := command[0], command[1:]
+
+	if contains(interactiveCommands, program) {
+		return true
+	}
+
+	if contains(replCommands, program) {
+		return len(args) == 0 || contains(args, "-i") || contains(args, "--interactive")
+	}
+
+	if contains(clientCommands, program) {
+		for _, arg := range args {
+			for _, flag := range clientStatementFlags {
+				if arg == flag || strings.HasPrefix(arg, flag+"=") {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	if contains(interactiveFlagCommands, program) {
+		return contains(args, "-i") || contains(args, "--interactive")
+	}
+
+	if subCommands, ok := interactiveSubCommands[program]; ok {
+		for i, arg := range args {
+			flags, ok := subCommands[arg]
+			if !ok {
+				continue
+			}
+			if len(flags) == 0 {
+				return true
+			}
+			for _, flag := range args[i+1:] {
+				if contains(flags, flag) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	for _, arg := range args {
+		if arg == "--interactive" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unwrapElevation returns the command run by sudo or doas, or the command itself.
+func unwrapElevation(command []string) []string {
+	for len(command) > 0 && contains(elevationCommands, command[0]) {
+		command = command[1:]
+		for len(command) > 0 && strings.HasPrefix(command[0], "-") {
+			command = command[1:]
+		}
+	}
+	if len(command) > 0 {
+		command[0] = filepath.Base(command[0])
+	}
+
+	return command
+}
+
+// contains checks if a list of strings contains a value.
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
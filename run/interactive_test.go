@@ -0,0 +1,58 @@
+package run
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestIsInteractive is a unit test for the IsInteractive function.
+func TestIsInteractive(t *testing.T) {
+	testCases := []struct {
+		cmd      string
+		expected bool
+	}{
+		{"ls -la", false},
+		{"git status", false},
+		{"git log --oneline", false},
+		{"grep -i foo file.txt", false},
+		{"sed -i 's/a/b/' file.txt", false},
+		{"vim file.txt", true},
+		{"/usr/bin/htop", true},
+		{"ssh host", true},
+		{"sudo vim /etc/hosts", true},
+		{"sudo -E nano file", true},
+		{"cat file | less", true},
+		{"cd /tmp && vim notes", true},
+		{"python3", true},
+		{"python3 script.py", false},
+		{"python3 -i script.py", true},
+		{"bash", true},
+		{"bash -c 'ls'", false},
+		{"psql mydb", true},
+		{"psql mydb -c 'select 1'", false},
+		{"mysql --execute='show tables'", false},
+		{"rm -i file", true},
+		{"rm -rf dir", false},
+		{"git rebase -i HEAD~3", true},
+		{"git rebase main", false},
+		{"git add -p", true},
+		{"git add .", false},
+		{"docker run -it ubuntu bash", true},
+		{"docker run -d nginx", false},
+		{"docker ps", false},
+		{"kubectl exec -it pod -- sh", true},
+		{"kubectl get pods", false},
+		{"kubectl edit deployment app", true},
+		{"crontab -e", true},
+		{"crontab -l", false},
+		{"some-tool --interactive", true},
+		{"", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.cmd, func(t *testing.T) {
+			assert.Equal(t, tc.expected, IsInteractive(tc.cmd), "The interactive detection should match.")
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package run
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// Job represents a command executed in the background.
+type Job struct {
+	id       int       // The number of the job.
+	command  string    // The command executed by the job.
+	logFile  string    // The file receiving the stdout and stderr of the job.
+	start    time.Time // The time the job started.
+	end      time.Time // The time the job finished.
+	running  bool      // Whether the job is still running.
+	exitCode int       // The exit code of the job once finished.
+	notified bool      // Whether the completion of the job has been reported.
+	cmd      *exec.Cmd // The underlying process.
+	done     chan struct{}
+}
+
+// GetId returns the number of the job.
+func (j *Job) GetId() int {
+	return j.id
+}
+
+// GetCommand returns the command executed by the job.
+func (j *Job) GetCommand() string {
+	return j.command
+}
+
+// Jobs keeps track of the commands executed in the background.
+type Jobs struct {
+	mutex sync.Mutex
+	jobs  []*Job
+}
+
+// NewJobs returns a new Jobs struct
+func NewJobs() *Jobs {
+	return &Jobs{
+		jobs: []*Job{},
+	}
+}
+
+// Start executes a command in the background after the optional preamble, its output being written to a log file.
+// The job keeps the command without the preamble.
+func (js *Jobs) Start(command string, preamble string) (*Job, error) {
+	if RequiresElevation(command) {
+		return nil, ErrElevationRequired
+	}
+
+	logFile, err := os.CreateTemp("", "terminal-assistant-job-*.log")
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := prepareCapturedCommand(WithPreamble(preamble, command))
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	setProcessGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		logFile.Close()
+		os.Remove(logFile.Name())
+		return nil, err
+	}
+
+	js.mutex.Lock()
+	job := &Job{
+		id:      len(js.jobs) + 1,
+		command: command,
+		logFile: logFile.Name(),
+		start:   time.Now(),
+		running: true,
+		cmd:     cmd,
+		done:    make(chan struct{}),
+	}
+	js.jobs = append(js.jobs, job)
+	js.mutex.Unlock()
+
+	go func() {
+		err := cmd.Wait()
+		logFile.Close()
+
+		js.mutex.Lock()
+		job.running = false
+		job.end = time.Now()
+		job.exitCode = exitCode(err)
+		js.mutex.Unlock()
+
+		close(job.done)
+	}()
+
+	return job, nil
+}
+
+// GetAll returns a snapshot of all the jobs.
+func (js *Jobs) GetAll() []JobStatus {
+	js.mutex.Lock()
+	defer js.mutex.Unlock()
+
+	statuses := make([]JobStatus, 0, len(js.jobs))
+	for _, job := range js.jobs {
+		statuses = append(statuses, job.status())
+	}
+
+	return statuses
+}
+
+// Get returns a snapshot of the job with the given number.
+func (js *Jobs) Get(id int) (JobStatus, bool) {
+	js.mutex.Lock()
+	defer js.mutex.Unlock()
+
+	if id < 1 || id > len(js.jobs) {
+		return JobStatus{}, false
+	}
+
+	return js.jobs[id-1].status(), true
+}
+
+// PopFinished returns the jobs that finished since the last call.
+func (js *Jobs) PopFinished() []JobStatus {
+	js.mutex.Lock()
+	defer js.mutex.Unlock()
+
+	finished := []JobStatus{}
+	for _, job := range js.jobs {
+		if !job.running && !job.notified {
+			job.notified = true
+			finished = append(finished, job.status())
+		}
+	}
+
+	return finished
+}
+
+// Terminate stops the running jobs and their children with SIGTERM, then SIGKILL if they are still running after the grace period.
+func (js *Jobs) Terminate(grace time.Duration) {
+	js.mutex.Lock()
+	running := []*Job{}
+	for _, job := range js.jobs {
+		if job.running {
+			running = append(running, job)
+		}
+	}
+	js.mutex.Unlock()
+
+	for _, job := range running {
+		_ = terminateProcessGroup(job.cmd)
+	}
+
+	// Once the grace period is over, every job still running is killed, then waited for
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+	killed := false
+	for _, job := range running {
+		if !killed {
+			select {
+			case <-job.done:
+				continue
+			case <-timer.C:
+				killed = true
+				for _, remaining := range running {
+					select {
+					case <-remaining.done:
+					default:
+						_ = killProcessGroup(remaining.cmd)
+					}
+				}
+			}
+		}
+		<-job.done
+	}
+}
+
+// Clean removes the log files of the finished jobs.
+func (js *Jobs) Clean() {
+	js.mutex.Lock()
+	defer js.mutex.Unlock()
+
+	for _, job := range js.jobs {
+		if !job.running {
+			os.Remove(job.logFile)
+		}
+	}
+}
+
+// JobStatus is a snapshot of the status of a job.
+type JobStatus struct {
+	id       int
+	command  string
+	logFile  string
+	running  bool
+	exitCode int
+	elapsed  time.Duration
+}
+
+// status returns a snapshot of the job, the caller must hold the jobs lock.
+func (j *Job) status() JobStatus {
+	end := j.end
+	if j.running {
+		end = time.Now()
+	}
+
+	return JobStatus{
+		id:       j.id,
+		command:  j.command,
+		logFile:  j.logFile,
+		running:  j.running,
+		exitCode: j.exitCode,
+		elapsed:  end.Sub(j.start),
+	}
+}
+
+// GetId returns the number of the job.
+func (s JobStatus) GetId() int {
+	return s.id
+}
+
+// GetCommand returns the command executed by the job.
+func (s JobStatus) GetCommand() string {
+	return s.command
+}
+
+// IsRunning returns whether the job is still running.
+func (s JobStatus) IsRunning() bool {
+	return s.running
+}
+
+// GetExitCode returns the exit code of the finished job.
+func (s JobStatus) GetExitCode() int {
+	return s.exitCode
+}
+
+// GetElapsed returns the time elapsed since the start of the job, or its duration once finished.
+func (s JobStatus) GetElapsed() time.Duration {
+	return s.elapsed
+}
+
+// Tail returns the last n lines of the output of the job.
+func (s JobStatus) Tail(n int) ([]string, error) {
+	file, err := os.Open(s.logFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lines := []string{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+
+	return lines, scanner.Err()
+}
+
+// exitCode returns the exit code corresponding to the error returned by a command.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) {
+		return exitError.ExitCode()
+	}
+
+	return -1
+}
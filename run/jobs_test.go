@@ -0,0 +1,109 @@
+package run
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobs(t *testing.T) {
+	t.Run("Start", testJobsStart)
+	t.Run("Terminate", testJobsTerminate)
+	t.Run("TerminateIgnored", testJobsTerminateIgnored)
+}
+
+// waitJob waits for the job with the given number to finish.
+func waitJob(t *testing.T, jobs *Jobs, id int) JobStatus {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		job, ok := jobs.Get(id)
+		return ok && !job.IsRunning()
+	}, 5*time.Second, 10*time.Millisecond)
+
+	job, _ := jobs.Get(id)
+
+	return job
+}
+
+// testJobsStart is a unit test for the Start method of the Jobs struct.
+func testJobsStart(t *testing.T) {
+	jobs := NewJobs()
+	defer jobs.Clean()
+
+	job, err := jobs.Start("echo one; echo two; echo three >&2; exit 2", "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, job.GetId(), "The first job should have the number 1.")
+
+	status := waitJob(t, jobs, 1)
+	assert.Equal(t, 2, status.GetExitCode(), "The exit code should be forwarded.")
+
+	lines, err := status.Tail(2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"two", "three"}, lines, "The tail should contain the last lines.")
+
+	finished := jobs.PopFinished()
+	require.Len(t, finished, 1, "The finished job should be reported.")
+	assert.Empty(t, jobs.PopFinished(), "The finished job should be reported only once.")
+
+	_, ok := jobs.Get(2)
+	assert.False(t, ok, "There should be no second job.")
+	assert.Len(t, jobs.GetAll(), 1, "There should be one job.")
+
+	job, err = jobs.Start("echo $GREETING", "export GREETING=hello")
+	require.NoError(t, err)
+	assert.Equal(t, "echo $GREETING", job.GetCommand(), "The job should keep the command without the preamble.")
+	lines, err = waitJob(t, jobs, 2).Tail(1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hello"}, lines, "The preamble should be executed first.")
+}
+
+// testJobsTerminate is a unit test for the Terminate method of the Jobs struct.
+func testJobsTerminate(t *testing.T) {
+	jobs := NewJobs()
+	defer jobs.Clean()
+
+	_, err := jobs.Start("sleep 30", "")
+	require.NoError(t, err)
+
+	jobs.Terminate(time.Second)
+
+	job, _ := jobs.Get(1)
+	assert.False(t, job.IsRunning(), "The job should be terminated.")
+	assert.Less(t, job.GetElapsed(), 30*time.Second, "The job should not run until completion.")
+}
+
+// testJobsTerminateIgnored tests that every job ignoring SIGTERM is killed once the grace period is over.
+func testJobsTerminateIgnored(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signals are not supported on windows")
+	}
+
+	jobs := NewJobs()
+	defer jobs.Clean()
+
+	for i := 0; i < 2; i++ {
+		_, err := jobs.Start("trap '' TERM; sleep 30", "")
+		require.NoError(t, err)
+	}
+	// Let the shells ignore SIGTERM before terminating them
+	time.Sleep(100 * time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		jobs.Terminate(100 * time.Millisecond)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("The jobs ignoring SIGTERM should be killed after the grace period.")
+	}
+
+	for _, job := range jobs.GetAll() {
+		assert.False(t, job.IsRunning(), "Every job should be terminated.")
+	}
+}
@@ -1,12 +1,28 @@
 package run
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// stderr_tail_lines is the number of lines of the standard error kept in the tail of a failed run.
+const stderr_tail_lines = 10
 
 // RunOutput struct holds the error, error message and success message of a run
 type RunOutput struct {
-	error          error  // error object if any error occurred during the run
-	errorMessage   string // custom error message
-	successMessage string // custom success message
+	error          error         // error object if any error occurred during the run
+	errorMessage   string        // custom error message
+	successMessage string        // custom success message
+	captured       bool          // whether the output of the run was captured
+	stdout         string        // captured standard output
+	stderr         string        // captured standard error
+	duration       time.Duration // duration of the run
 }
 
 // NewRunOutput is a constructor for RunOutput struct
@@ -18,18 +34,121 @@ func NewRunOutput(error error, errorMessage string, successMessage string) RunOu
 	}
 }
 
+// NewCapturedRunOutput is a constructor for RunOutput struct holding the captured output of a run
+func NewCapturedRunOutput(error error, errorMessage string, successMessage string, stdout string, stderr string) RunOutput {
+	return RunOutput{
+		error:          error,
+		errorMessage:   errorMessage,
+		successMessage: successMessage,
+		captured:       true,
+		stdout:         stdout,
+		stderr:         stderr,
+	}
+}
+
+// WithDuration returns a copy of the RunOutput with the duration of the run
+func (o RunOutput) WithDuration(duration time.Duration) RunOutput {
+	o.duration = duration
+
+	return o
+}
+
+// GetDuration returns the duration of the run
+func (o RunOutput) GetDuration() time.Duration {
+	return o.duration
+}
+
 // HasError checks if the run has an error
 func (o RunOutput) HasError() bool {
 	return o.error != nil // return true if error is not nil
 }
 
-// GetErrorMessage returns the error message of the run
+// GetError returns the error of the run
+func (o RunOutput) GetError() error {
+	return o.error
+}
+
+// GetErrorMessage returns the error message of the run, with the exit code if the command exited with an error,
+// the signal or the timeout that stopped the command, or only the error message if the command was interrupted
 func (o RunOutput) GetErrorMessage() string {
+	if errors.Is(o.error, ErrInterrupted) {
+		return o.errorMessage
+	}
+	if o.WasTimeout() {
+		return fmt.Sprintf("%s: timed out", o.errorMessage)
+	}
+	if signal := o.GetSignal(); signal != nil {
+		return fmt.Sprintf("%s: terminated by signal (%s)", o.errorMessage, signal)
+	}
+	if code := o.GetExitCode(); code > 0 {
+		return fmt.Sprintf("%s: %s", o.errorMessage, FormatExitCode(code))
+	}
+
 	// format and return the error message with the error
 	return fmt.Sprintf("%s: %s", o.errorMessage, o.error)
 }
 
+// GetExitCode returns the exit code of the run, 0 on success and -1 if the command did not exit normally
+func (o RunOutput) GetExitCode() int {
+	return exitCode(o.error)
+}
+
+// GetSignal returns the signal that terminated the command of the run, or nil if the command was not killed by a signal
+func (o RunOutput) GetSignal() os.Signal {
+	var exitError *exec.ExitError
+	if !errors.As(o.error, &exitError) {
+		return nil
+	}
+
+	status, ok := exitError.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return nil
+	}
+
+	return status.Signal()
+}
+
+// WasTimeout checks if the command of the run was stopped because its deadline was exceeded
+func (o RunOutput) WasTimeout() bool {
+	return errors.Is(o.error, context.DeadlineExceeded)
+}
+
+// FormatExitCode returns the text displaying the exit code of a command
+func FormatExitCode(code int) string {
+	return fmt.Sprintf("exit code %d", code)
+}
+
 // GetSuccessMessage returns the success message of the run
 func (o RunOutput) GetSuccessMessage() string {
 	return o.successMessage // return the success message
 }
+
+// IsCaptured checks if the output of the run was captured
+func (o RunOutput) IsCaptured() bool {
+	return o.captured
+}
+
+// GetStdout returns the captured standard output of the run
+func (o RunOutput) GetStdout() string {
+	return o.stdout
+}
+
+// GetStderr returns the captured standard error of the run
+func (o RunOutput) GetStderr() string {
+	return o.stderr
+}
+
+// GetStderrTail returns the last lines of the captured standard error of the run, without ANSI escape sequences
+func (o RunOutput) GetStderrTail() string {
+	stderr := strings.TrimRight(StripAnsi(o.stderr), "\n")
+	if stderr == "" {
+		return ""
+	}
+
+	lines := strings.Split(stderr, "\n")
+	if len(lines) > stderr_tail_lines {
+		lines = lines[len(lines)-stderr_tail_lines:]
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -1,8 +1,15 @@
 package run
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -11,6 +18,12 @@ func TestRunOutput(t *testing.T) {
 	t.Run("HasError", testHasError)
 	t.Run("GetErrorMessage", testGetErrorMessage)
 	t.Run("GetSuccessMessage", testGetSuccessMessage)
+	t.Run("CapturedOutput", testCapturedOutput)
+	t.Run("WithDuration", testWithDuration)
+	t.Run("ExitCode", testExitCode)
+	t.Run("Signal", testSignal)
+	t.Run("Timeout", testTimeout)
+	t.Run("GetStderrTail", testGetStderrTail)
 }
 
 func testHasError(t *testing.T) {
@@ -22,6 +35,7 @@ func testHasError(t *testing.T) {
 	runOutputWithoutError := NewRunOutput(nil, "Error occurred", "Success")
 
 	assert.True(t, runOutputWithError.HasError(), "RunOutput should have an error.")
+	assert.Equal(t, err, runOutputWithError.GetError(), "RunOutput should return the error.")
 	assert.False(t, runOutputWithoutError.HasError(), "RunOutput should not have an error.")
 }
 
@@ -45,3 +59,80 @@ func testGetSuccessMessage(t *testing.T) {
 
 	assert.Equal(t, expectedSuccessMessage, actualSuccessMessage, "The success messages should be the same.")
 }
+
+// testCapturedOutput is a unit test function that tests the captured output of the RunOutput.
+func testCapturedOutput(t *testing.T) {
+	runOutput := NewCapturedRunOutput(nil, "Error occurred", "Success", "out", "err")
+
+	assert.True(t, runOutput.IsCaptured(), "RunOutput should be captured.")
+	assert.Equal(t, "out", runOutput.GetStdout(), "The stdout should be the same.")
+	assert.Equal(t, "err", runOutput.GetStderr(), "The stderr should be the same.")
+	assert.False(t, NewRunOutput(nil, "", "").IsCaptured(), "RunOutput should not be captured.")
+}
+
+// testWithDuration is a unit test function that tests the duration of the RunOutput.
+func testWithDuration(t *testing.T) {
+	runOutput := NewRunOutput(nil, "Error occurred", "Success")
+	withDuration := runOutput.WithDuration(3 * time.Second)
+
+	assert.Equal(t, 3*time.Second, withDuration.GetDuration(), "The duration should be set.")
+	assert.Equal(t, time.Duration(0), runOutput.GetDuration(), "The original RunOutput should not be modified.")
+}
+
+// testExitCode is a unit test function that tests the exit code of the RunOutput.
+func testExitCode(t *testing.T) {
+	err := exec.Command("sh", "-c", "exit 2").Run()
+	runOutput := NewRunOutput(err, "[error]", "[ok]")
+
+	assert.Equal(t, 2, runOutput.GetExitCode(), "The exit code should be forwarded.")
+	assert.Equal(t, "[error]: exit code 2", runOutput.GetErrorMessage(), "The error message should contain the exit code.")
+	assert.Equal(t, 0, NewRunOutput(nil, "[error]", "[ok]").GetExitCode(), "The exit code should be 0 on success.")
+	assert.Equal(t, -1, NewRunOutput(errors.New("not started"), "[error]", "[ok]").GetExitCode(), "The exit code should be -1 without exit.")
+	assert.Equal(t, "[interrupted]", NewRunOutput(ErrInterrupted, "[interrupted]", "[ok]").GetErrorMessage(), "The interruption should not be detailed.")
+}
+
+// testSignal is a unit test function that tests the signal terminating the command of the RunOutput.
+func testSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signals are not supported on windows")
+	}
+
+	err := exec.Command("sh", "-c", "kill -TERM $$").Run()
+	runOutput := NewRunOutput(err, "[error]", "[ok]")
+
+	assert.Equal(t, syscall.SIGTERM, runOutput.GetSignal(), "The signal should be forwarded.")
+	assert.Equal(t, -1, runOutput.GetExitCode(), "The exit code should be -1 when the command is killed.")
+	assert.Equal(t, "[error]: terminated by signal (terminated)", runOutput.GetErrorMessage(), "The error message should contain the signal.")
+	assert.False(t, runOutput.WasTimeout(), "A killed command should not be timed out.")
+
+	exitErr := exec.Command("sh", "-c", "exit 2").Run()
+	assert.Nil(t, NewRunOutput(exitErr, "[error]", "[ok]").GetSignal(), "A command exiting should have no signal.")
+	assert.Nil(t, NewRunOutput(nil, "[error]", "[ok]").GetSignal(), "A successful run should have no signal.")
+}
+
+// testTimeout is a unit test function that tests the timeout of the command of the RunOutput.
+func testTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, _, _, err := runCaptured(ctx, prepareCapturedCommand("sleep 5"))
+	runOutput := NewRunOutput(err, "[error]", "[ok]")
+
+	assert.True(t, runOutput.WasTimeout(), "The run should be timed out.")
+	assert.Equal(t, "[error]: timed out", runOutput.GetErrorMessage(), "The error message should tell the timeout.")
+	assert.False(t, NewRunOutput(ErrInterrupted, "[error]", "[ok]").WasTimeout(), "An interrupted run should not be timed out.")
+	assert.False(t, NewRunOutput(errors.New("failed"), "[error]", "[ok]").WasTimeout(), "A failed run should not be timed out.")
+}
+
+// testGetStderrTail is a unit test function that tests the stderr tail of the RunOutput.
+func testGetStderrTail(t *testing.T) {
+	lines := []string{}
+	for i := 1; i <= 15; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	runOutput := NewCapturedRunOutput(errors.New("failed"), "[error]", "[ok]", "out", "\x1b[31m"+strings.Join(lines, "\n")+"\x1b[0m\n\n")
+
+	assert.Equal(t, strings.Join(lines[5:], "\n"), runOutput.GetStderrTail(), "The last lines of the stderr should be kept.")
+	assert.Equal(t, "", NewCapturedRunOutput(nil, "", "", "out", "").GetStderrTail(), "The tail should be empty without stderr.")
+	assert.Equal(t, "", NewRunOutput(errors.New("failed"), "", "").GetStderrTail(), "The tail should be empty without captured output.")
+}
@@ -0,0 +1,106 @@
+package run
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// wrapperCommands is the list of commands executing the command given as their arguments.
+var wrapperCommands = []string{"env", "nohup", "time", "nice", "exec", "command", "builtin", "xargs", "timeout", "stdbuf", "watch"}
+
+// wrapperArgumentPattern matches the numeric arguments of the wrapper commands, like durations or priorities.
+var wrapperArgumentPattern = regexp.MustCompile(`^[0-9.]+[smhd]?$`)
+
+// Policy restricts the programs that can be executed, whatever the AI proposes.
+type Policy struct {
+	allowlist []string // The programs allowed, all the programs being allowed if empty.
+	blocklist []string // The programs never executed.
+}
+
+// NewPolicy returns a new Policy struct
+func NewPolicy(allowlist []string, blocklist []string) *Policy {
+	return &Policy{
+		allowlist: baseNames(allowlist),
+		blocklist: baseNames(blocklist),
+	}
+}
+
+// GetAllowlist returns the programs allowed, all the programs being allowed if empty.
+func (p *Policy) GetAllowlist() []string {
+	return p.allowlist
+}
+
+// GetBlocklist returns the programs never executed.
+func (p *Policy) GetBlocklist() []string {
+	return p.blocklist
+}
+
+// Check checks if every program of a command line is allowed, and returns the first program that is not.
+// The programs run by sudo, doas and wrappers like env or xargs are checked as well.
+func (p *Policy) Check(cmd string) (string, bool) {
+	for _, program := range policyPrograms(cmd) {
+		if contains(p.blocklist, program) {
+			return program, false
+		}
+		if len(p.allowlist) > 0 && !contains(p.allowlist, program) {
+			return program, false
+		}
+	}
+
+	return "", true
+}
+
+// IsAllowed checks if every program of a command line is allowed.
+func (p *Policy) IsAllowed(cmd string) bool {
+	_, allowed := p.Check(cmd)
+
+	return allowed
+}
+
+// policyPrograms returns the programs of a command line, including the programs run by the elevation and wrapper commands.
+func policyPrograms(cmd string) []string {
+	names := []string{}
+	for _, command := range commands(cmd) {
+		for _, wrapped := range unwrap(command) {
+			names = append(names, wrapped[0])
+		}
+	}
+
+	return names
+}
+
+// unwrap returns a command followed by the commands run by its elevation and wrapper commands, like "sudo rm -rf /tmp/x"
+// followed by "rm -rf /tmp/x". The programs of the returned commands are base names.
+func unwrap(command []string) [][]string {
+	result := [][]string{}
+	for len(command) > 0 {
+		program := filepath.Base(command[0])
+		result = append(result, append([]string{program}, command[1:]...))
+		if !contains(elevationCommands, program) && !contains(wrapperCommands, program) {
+			break
+		}
+
+		// Skip the options and arguments of the elevation or wrapper command
+		command = command[1:]
+		for len(command) > 0 && (strings.HasPrefix(command[0], "-") ||
+			assignmentPattern.MatchString(command[0]) ||
+			wrapperArgumentPattern.MatchString(command[0])) {
+			command = command[1:]
+		}
+	}
+
+	return result
+}
+
+// baseNames returns the base name of each program of a list, ignoring the empty ones.
+func baseNames(programs []string) []string {
+	names := []string{}
+	for _, program := range programs {
+		if program = strings.TrimSpace(program); program != "" {
+			names = append(names, filepath.Base(program))
+		}
+	}
+
+	return names
+}
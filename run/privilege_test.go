@@ -0,0 +1,48 @@
+package run
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrivilege(t *testing.T) {
+	t.Run("RequiresElevation", testRequiresElevation)
+	t.Run("RunCapturedElevation", testRunCapturedElevation)
+}
+
+// testRequiresElevation is a unit test for the RequiresElevation function.
+func testRequiresElevation(t *testing.T) {
+	testCases := []struct {
+		cmd      string
+		expected bool
+	}{
+		{"sudo apt update", true},
+		{"doas reboot", true},
+		{"/usr/bin/sudo ls", true},
+		{"apt update && sudo apt upgrade", true},
+		{"cat file | sudo tee /etc/hosts", true},
+		{"FOO=1 sudo -E env", true},
+		{"(sudo ls)", true},
+		{"ls -la", false},
+		{"echo sudo", false},
+		{"pseudo-command", false},
+		{"", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.cmd, func(t *testing.T) {
+			assert.Equal(t, tc.expected, RequiresElevation(tc.cmd), "The elevation detection should match.")
+		})
+	}
+}
+
+// testRunCapturedElevation is a unit test for the RunCaptured function with a command requiring elevation.
+func testRunCapturedElevation(t *testing.T) {
+	_, _, _, err := RunCaptured(context.Background(), "sudo ls")
+	assert.ErrorIs(t, err, ErrElevationRequired, "The command should be refused.")
+
+	_, err = NewJobs().Start("sudo ls", "")
+	assert.ErrorIs(t, err, ErrElevationRequired, "The job should be refused.")
+}
@@ -0,0 +1,31 @@
+//go:build !windows
+
+package run
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup makes a command run in its own process group, so it can be signaled with its children.
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// interruptProcessGroup sends SIGINT to the process group of a started command.
+func interruptProcessGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGINT)
+}
+
+// terminateProcessGroup sends SIGTERM to the process group of a started command.
+func terminateProcessGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+}
+
+// killProcessGroup sends SIGKILL to the process group of a started command.
+func killProcessGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,32 @@
+//go:build windows
+
+package run
+
+import (
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// setProcessGroup makes a command run in its own process group, so it can be signaled with its children.
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= syscall.CREATE_NEW_PROCESS_GROUP
+}
+
+// interruptProcessGroup asks the process tree of a started command to stop.
+func interruptProcessGroup(cmd *exec.Cmd) error {
+	return exec.Command("taskkill", "/T", "/PID", strconv.Itoa(cmd.Process.Pid)).Run()
+}
+
+// terminateProcessGroup asks the process tree of a started command to stop.
+func terminateProcessGroup(cmd *exec.Cmd) error {
+	return interruptProcessGroup(cmd)
+}
+
+// killProcessGroup forcefully stops the process tree of a started command.
+func killProcessGroup(cmd *exec.Cmd) error {
+	return exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run()
+}
@@ -0,0 +1,148 @@
+package run
+
+import "strings"
+
+// readOnlyCommands is the curated list of commands that only read the system, whatever their arguments,
+// except for the flags listed in readOnlyForbiddenFlags.
+var readOnlyCommands = []string{
+	"ls", "pwd", "echo", "printf", "cat", "head", "tail", "wc", "grep", "egrep", "fgrep", "rg",
+	"tree", "file", "stat", "du", "df", "free", "uptime", "whoami", "id", "groups", "uname",
+	"date", "cal", "which", "type", "basename", "dirname", "realpath", "readlink", "printenv",
+	"ps", "nproc", "lscpu", "lsblk", "find", "sort", "uniq", "cut", "tr", "diff", "md5sum",
+	"sha1sum", "sha256sum", "true", "false",
+}
+
+// readOnlyNoArgsCommands is the list of commands that only read the system when called without arguments.
+var readOnlyNoArgsCommands = []string{"env", "hostname"}
+
+// readOnlyForbiddenFlags maps the read-only commands to the flags making them write, execute commands or run forever.
+var readOnlyForbiddenFlags = map[string][]string{
+	"tail": {"-f", "-F", "--follow", "--retry"},
+	"find": {"-delete", "-exec", "-execdir", "-ok", "-okdir", "-fprint", "-fprint0", "-fprintf", "-fls"},
+	"sort": {"-o", "--output", "--compress-program"},
+	"rg":   {"--pre"},
+	"date": {"-s", "--set"},
+	"tree": {"-o"},
+}
+
+// readOnlyMaxOperands maps the read-only commands writing to one of their operands to the number of operands they
+// only read, like uniq writing to its second operand.
+var readOnlyMaxOperands = map[string]int{
+	"uniq": 1,
+}
+
+// readOnlySubCommandForbiddenFlags maps the commands having read-only sub-commands to the flags making them write
+// files, persist settings or execute commands, whatever the sub-command.
+var readOnlySubCommandForbiddenFlags = map[string][]string{
+	"git": {"--output", "-c"},
+	"go":  {"-w", "-u"},
+}
+
+// readOnlySubCommands maps the commands having read-only sub-commands to these sub-commands.
+var readOnlySubCommands = map[string][]string{
+	"git":     {"status", "log", "diff", "show", "blame", "describe", "shortlog", "ls-files", "rev-parse"},
+	"docker":  {"ps", "images", "version", "info"},
+	"podman":  {"ps", "images", "version", "info"},
+	"kubectl": {"get", "describe", "version"},
+	"go":      {"version", "env", "list"},
+	"npm":     {"ls", "list", "view", "outdated"},
+}
+
+// IsReadOnly checks if a command line only reads the system, every one of its programs being a known read-only command
+// or sub-command without flags making it write. Command lines with output redirections, requiring elevated privileges
+// or needing a TTY are never read-only.
+func IsReadOnly(cmd string) bool {
+	commands := commands(cmd)
+	if len(commands) == 0 || hasOutputRedirection(cmd) || RequiresElevation(cmd) || IsInteractive(cmd) {
+		return false
+	}
+
+	for _, command := range commands {
+		if !isReadOnlyCommand(command) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsStrictlyReadOnly checks if a command line only reads the system like IsReadOnly, for the commands executed without
+// any confirmation. The environment assignments, which may load code like LD_PRELOAD, and the programs given by a
+// path instead of being looked up in the PATH are refused as well.
+func IsStrictlyReadOnly(cmd string) bool {
+	if !IsReadOnly(cmd) {
+		return false
+	}
+
+	for _, words := range tokenize(cmd) {
+		if len(words) > 0 && (assignmentPattern.MatchString(words[0]) || strings.ContainsRune(words[0], '/')) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isReadOnlyCommand checks if a single command only reads the system.
+func isReadOnlyCommand(command []string) bool {
+	program, args := command[0], command[1:]
+
+	if contains(readOnlyCommands, program) {
+		for _, flag := range readOnlyForbiddenFlags[program] {
+			if hasFlag(args, flag) {
+				return false
+			}
+		}
+		if max, ok := readOnlyMaxOperands[program]; ok && countOperands(args) > max {
+			return false
+		}
+		return true
+	}
+
+	if contains(readOnlyNoArgsCommands, program) {
+		return len(args) == 0
+	}
+
+	if subCommands, ok := readOnlySubCommands[program]; ok {
+		for _, flag := range readOnlySubCommandForbiddenFlags[program] {
+			if hasFlag(args, flag) {
+				return false
+			}
+		}
+		for _, arg := range args {
+			if !strings.HasPrefix(arg, "-") {
+				return contains(subCommands, arg)
+			}
+		}
+	}
+
+	return false
+}
+
+// hasFlag checks if a flag is given in the arguments of a command. Single letter flags are also searched
+// in the groups of short flags like -nf, and long flags in their --flag=value form.
+func hasFlag(args []string, flag string) bool {
+	for _, arg := range args {
+		if arg == flag || strings.HasPrefix(arg, flag+"=") {
+			return true
+		}
+		if len(flag) == 2 && flag[0] == '-' && flag[1] != '-' &&
+			len(arg) > 2 && arg[0] == '-' && arg[1] != '-' && strings.Contains(arg[1:], flag[1:]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// countOperands counts the operands of a command, the arguments which are not flags, "-" being the standard input.
+func countOperands(args []string) int {
+	count := 0
+	for _, arg := range args {
+		if arg == "-" || !strings.HasPrefix(arg, "-") {
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,93 @@
+package run
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestIsReadOnly is a unit test for the IsReadOnly function.
+func TestIsReadOnly(t *testing.T) {
+	testCases := []struct {
+		cmd      string
+		expected bool
+	}{
+		{"ls -la", true},
+		{"pwd", true},
+		{"echo 'hello > world'", true},
+		{"cat /etc/hosts | grep localhost", true},
+		{"git status", true},
+		{"git log --oneline -n 5", true},
+		{"git --no-pager diff", true},
+		{"docker ps -a", true},
+		{"kubectl get pods --all-namespaces", true},
+		{"du -sh * | sort -h", true},
+		{"tail -n 20 /var/log/syslog", true},
+		{"find . -name '*.go'", true},
+		{"env", true},
+		{"FOO=1 ls", true},
+		{"ls $(pwd)", true},
+		{"ls missing 2>&1 | wc -l", true},
+		{"", false},
+		{"rm -rf /tmp/dir", false},
+		{"echo hello > file", false},
+		{"cat file >> other", false},
+		{"ls 2>errors.log", false},
+		{"ls && rm file", false},
+		{"ls $(rm file)", false},
+		{"git push", false},
+		{"git branch -D main", false},
+		{"git", false},
+		{"docker run alpine", false},
+		{"tail -f /var/log/syslog", false},
+		{"tail -fn 20 /var/log/syslog", false},
+		{"tail --follow=name log", false},
+		{"find . -name '*.tmp' -delete", false},
+		{"find . -exec rm {} ;", false},
+		{"sort -o file file", false},
+		{"date -s '2024-01-01'", false},
+		{"env FOO=1 rm file", false},
+		{"hostname newname", false},
+		{"sudo ls /root", false},
+		{"cat file | less", false},
+		{"curl https://example.com", false},
+		{"sort file | uniq -c", true},
+		{"uniq /dev/null ~/.bashrc", false},
+		{"git log -1 --output=$HOME/.ssh/authorized_keys", false},
+		{"git diff --output /tmp/x", false},
+		{"git -c core.pager=sh log", false},
+		{"go env GOPATH", true},
+		{"go env -w GOFLAGS=-toolexec=/tmp/x", false},
+		{"go env -u GOFLAGS", false},
+		{"sort --compress-program=/tmp/x file", false},
+		{"rg --pre /tmp/x pattern", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.cmd, func(t *testing.T) {
+			assert.Equal(t, tc.expected, IsReadOnly(tc.cmd), "The read-only detection should match.")
+		})
+	}
+}
+
+// TestIsStrictlyReadOnly is a unit test for the IsStrictlyReadOnly function.
+func TestIsStrictlyReadOnly(t *testing.T) {
+	testCases := []struct {
+		cmd      string
+		expected bool
+	}{
+		{"ls -la | wc -l", true},
+		{"git log --oneline -n 5", true},
+		{"uniq /dev/null ~/.bashrc", false},
+		{"FOO=1 ls", false},
+		{"LD_PRELOAD=/tmp/x.so cat file", false},
+		{"/tmp/bin/ls", false},
+		{"ls $(/tmp/bin/cat file)", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.cmd, func(t *testing.T) {
+			assert.Equal(t, tc.expected, IsStrictlyReadOnly(tc.cmd), "The strict read-only detection should match.")
+		})
+	}
+}
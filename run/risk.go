@@ -0,0 +1,237 @@
+package run
+
+import (
+	"regexp"
+	"strings"
+)
+
+// RiskLevel is the assessment of the risk of executing a command line, from SafeRisk to CriticalRisk.
+type RiskLevel int
+
+// These are the constants representing the risk levels, in increasing order.
+const (
+	// SafeRisk is used when the command line only reads the system.
+	SafeRisk RiskLevel = iota
+	// LowRisk is used when the command line is not known to destroy data or to change the system.
+	LowRisk
+	// MediumRisk is used when the command line accesses the network or writes files with redirections.
+	MediumRisk
+	// HighRisk is used when the command line changes the system, the processes or the remote repositories.
+	HighRisk
+	// CriticalRisk is used when the command line destroys data or runs a script downloaded from the network.
+	CriticalRisk
+)
+
+// String is a method on the RiskLevel type that returns a string representation of the risk level.
+func (l RiskLevel) String() string {
+	switch l {
+	case SafeRisk:
+		return "safe"
+	case LowRisk:
+		return "low"
+	case MediumRisk:
+		return "medium"
+	case HighRisk:
+		return "high"
+	default:
+		return "critical"
+	}
+}
+
+// destructivePrograms is the list of the programs destroying data, whatever their arguments.
+var destructivePrograms = []string{"rm", "rmdir", "shred", "dd", "mkfs", "fdisk", "parted", "wipefs", "mkswap", "truncate"}
+
+// destructiveFlags maps the programs that are usually harmless to the flags making them destroy data.
+var destructiveFlags = map[string][]string{
+	"find":  {"-delete"},
+	"rsync": {"--delete"},
+}
+
+// destructiveSubCommands maps the commands having sub-commands destroying data to these sub-commands.
+var destructiveSubCommands = map[string][]string{
+	"git":     {"clean", "reset", "filter-branch"},
+	"docker":  {"rm", "rmi", "prune"},
+	"podman":  {"rm", "rmi", "prune"},
+	"kubectl": {"delete"},
+}
+
+// highRiskPrograms is the list of the programs changing the system or the processes, whatever their arguments.
+var highRiskPrograms = []string{
+	"shutdown", "reboot", "halt", "poweroff", "kill", "killall", "pkill", "chmod", "chown", "chgrp",
+	"crontab", "iptables", "useradd", "userdel", "usermod", "passwd", "systemctl", "mount", "umount",
+}
+
+// highRiskInterpreters is the list of the programs running arbitrary code.
+var highRiskInterpreters = []string{"sh", "bash", "zsh", "dash", "ksh", "fish", "eval", "source", "."}
+
+// highRiskFlags maps the programs that are usually harmless to the flags making them change files or run arbitrary commands.
+var highRiskFlags = map[string][]string{
+	"find": {"-exec", "-execdir", "-ok", "-okdir"},
+	"sed":  {"-i", "--in-place"},
+}
+
+// highRiskSubCommands maps the commands having sub-commands changing the system or the remote repositories to these sub-commands.
+var highRiskSubCommands = map[string][]string{
+	"git":     {"push", "rebase", "restore", "checkout", "rm"},
+	"docker":  {"kill", "stop", "system", "volume", "network"},
+	"podman":  {"kill", "stop", "system", "volume", "network"},
+	"kubectl": {"apply", "replace", "patch", "drain", "scale", "edit"},
+	"npm":     {"publish", "unpublish"},
+}
+
+// networkPrograms is the list of the programs accessing the network, whatever their arguments.
+var networkPrograms = []string{
+	"curl", "wget", "ssh", "scp", "sftp", "ftp", "telnet", "nc", "ncat", "netcat", "ping", "dig", "nslookup",
+	"host", "traceroute", "rsync",
+}
+
+// networkSubCommands maps the commands having sub-commands accessing the network to these sub-commands.
+var networkSubCommands = map[string][]string{
+	"git":    {"clone", "fetch", "pull", "push", "ls-remote"},
+	"docker": {"pull", "push", "login", "search"},
+	"podman": {"pull", "push", "login", "search"},
+	"npm":    {"install", "i", "publish", "update"},
+	"pip":    {"install", "download"},
+	"pip3":   {"install", "download"},
+	"go":     {"get", "install"},
+	"apt":    {"install", "update", "upgrade"},
+	"brew":   {"install", "update", "upgrade"},
+}
+
+// rootPaths is the list of the system directories whose changes can break the system.
+var rootPaths = []string{"/etc", "/boot", "/bin", "/sbin", "/lib", "/lib64", "/usr", "/dev", "/sys", "/proc", "/var/lib"}
+
+// pipeToShellPattern matches the scripts piped or substituted into an interpreter, like "curl ... | sh" or "bash <(curl ...)".
+var pipeToShellPattern = regexp.MustCompile(`\|\s*((sudo|doas)\s+(-\S+\s+)*)?(\S*/)?(sh|bash|zsh|dash|ksh|fish|python[0-9.]*|perl|ruby|node)(\s|$)|<\(\s*(curl|wget)\s`)
+
+// EstimateExecutionRisk assesses the risk of executing a command line before asking its confirmation. The most risky
+// part of the command line decides: destructive commands and scripts piped into a shell are critical, commands
+// requiring elevated privileges or changing the system directories are high, commands accessing the network or
+// writing files with redirections are medium, interactive commands are low, and read-only commands are safe.
+// The commands run by sudo and wrappers like xargs are assessed as well.
+func EstimateExecutionRisk(cmd string) RiskLevel {
+	if IsDestructive(cmd) || isPipeToShell(cmd) {
+		return CriticalRisk
+	}
+
+	if RequiresElevation(cmd) || (!IsReadOnly(cmd) && hasRootPath(cmd)) {
+		return HighRisk
+	}
+	for _, command := range commands(cmd) {
+		for _, wrapped := range unwrap(command) {
+			if isHighRiskCommand(wrapped) {
+				return HighRisk
+			}
+		}
+	}
+
+	if HasNetworkAccess(cmd) || hasOutputRedirection(cmd) {
+		return MediumRisk
+	}
+
+	if IsReadOnly(cmd) {
+		return SafeRisk
+	}
+
+	return LowRisk
+}
+
+// IsDestructive checks if a command line runs a program, a flag or a sub-command destroying data.
+func IsDestructive(cmd string) bool {
+	for _, command := range commands(cmd) {
+		for _, wrapped := range unwrap(command) {
+			program, args := wrapped[0], wrapped[1:]
+			if contains(destructivePrograms, program) || strings.HasPrefix(program, "mkfs.") ||
+				hasAnyFlag(args, destructiveFlags[program]) || hasSubCommand(args, destructiveSubCommands[program]) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// HasNetworkAccess checks if a command line runs a program or a sub-command accessing the network.
+func HasNetworkAccess(cmd string) bool {
+	for _, command := range commands(cmd) {
+		for _, wrapped := range unwrap(command) {
+			program, args := wrapped[0], wrapped[1:]
+			if contains(networkPrograms, program) || hasSubCommand(args, networkSubCommands[program]) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isHighRiskCommand checks if a single command changes the system, the processes or the remote repositories,
+// or runs arbitrary code.
+func isHighRiskCommand(command []string) bool {
+	program, args := command[0], command[1:]
+
+	return contains(highRiskPrograms, program) || contains(highRiskInterpreters, program) ||
+		hasAnyFlag(args, highRiskFlags[program]) || hasSubCommand(args, highRiskSubCommands[program])
+}
+
+// isPipeToShell checks if a command line runs a script downloaded from the network or piped into an interpreter.
+func isPipeToShell(cmd string) bool {
+	if pipeToShellPattern.MatchString(cmd) {
+		return true
+	}
+
+	// Scripts substituted into an interpreter, like eval "$(curl ...)"
+	for _, command := range commands(cmd) {
+		if !contains(highRiskInterpreters, command[0]) {
+			continue
+		}
+		for _, arg := range command[1:] {
+			if strings.ContainsAny(arg, "$`") && HasNetworkAccess(arg) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// hasRootPath checks if a command line refers to a system directory, like /etc or /boot.
+func hasRootPath(cmd string) bool {
+	for _, command := range commands(cmd) {
+		for _, arg := range command[1:] {
+			if arg == "/dev/null" {
+				continue
+			}
+			for _, path := range rootPaths {
+				if arg == path || strings.HasPrefix(arg, path+"/") {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// hasAnyFlag checks if any of the flags is given in the arguments of a command.
+func hasAnyFlag(args []string, flags []string) bool {
+	for _, flag := range flags {
+		if hasFlag(args, flag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasSubCommand checks if any of the sub-commands is given in the arguments of a command. Every argument is
+// compared to the sub-commands, to also catch the ones following global options.
+func hasSubCommand(args []string, subCommands []string) bool {
+	for _, arg := range args {
+		if contains(subCommands, arg) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -18,22 +18,41 @@ func RunCommand(cmd string, arg ...string) (string, error) {
 	return string(out), nil
 }
 
-// PrepareInteractiveCommand prepares a bash command for interactive execution
-func PrepareInteractiveCommand(input string) *exec.Cmd {
+// WithPreamble prepends a preamble to the input, like "source ~/.nvm/nvm.sh &&", on its own line so a preamble ending
+// with an operator chains the input. The input is returned untouched without a preamble.
+func WithPreamble(preamble string, input string) string {
+	preamble = strings.TrimSpace(preamble)
+	if preamble == "" {
+		return input
+	}
+
+	return fmt.Sprintf("%s\n%s", preamble, input)
+}
+
+// PrepareInteractiveCommand prepares a bash command for interactive execution, prepending the optional preamble.
+// The input is passed untouched to bash on its own line, so quotes, comments and trailing operators are preserved,
+// and the exit status of the input is kept.
+func PrepareInteractiveCommand(input string, preamble string) *exec.Cmd {
 	// Return a bash command that echoes a newline, executes the input command, and then echoes another newline
 	return exec.Command(
 		"bash",
 		"-c",
-		fmt.Sprintf("echo \"\n\";%s; echo \"\n\";", strings.TrimRight(input, ";")),
+		fmt.Sprintf("echo \"\n\"\n%s\nstatus=$?\necho \"\n\"\nexit $status", WithPreamble(preamble, input)),
 	)
 }
 
-// PrepareEditSettingsCommand prepares a bash command for editing settings
+// PrepareCommand prepares a bash command executing the input untouched, without separating its output,
+// for the non-interactive executions.
+func PrepareCommand(input string) *exec.Cmd {
+	return exec.Command("bash", "-c", input)
+}
+
+// PrepareEditSettingsCommand prepares a bash command for editing settings, keeping the exit status of the input
 func PrepareEditSettingsCommand(input string) *exec.Cmd {
 	// Return a bash command that executes the input command and then echoes a newline
 	return exec.Command(
 		"bash",
 		"-c",
-		fmt.Sprintf("%s; echo \"\n\";", strings.TrimRight(input, ";")),
+		fmt.Sprintf("%s\nstatus=$?\necho \"\n\"\nexit $status", input),
 	)
 }
@@ -1,6 +1,7 @@
 package run
 
 import (
+	"context"
 	"os/exec"
 	"testing"
 
@@ -12,6 +13,11 @@ func TestRun(t *testing.T) {
 	// TestRun is a unit test for the Run function.
 	t.Run("RunCommand", testRunCommand)
 	t.Run("PrepareInteractiveCommand", testPrepareInteractiveCommand)
+	t.Run("PrepareInteractiveCommandQuotes", testPrepareInteractiveCommandQuotes)
+	t.Run("PrepareInteractiveCommandExitStatus", testPrepareInteractiveCommandExitStatus)
+	t.Run("PrepareInteractiveCommandPreamble", testPrepareInteractiveCommandPreamble)
+	t.Run("WithPreamble", testWithPreamble)
+	t.Run("PrepareCommand", testPrepareCommand)
 	t.Run("PrepareEditSettingsCommand", testPrepareEditSettingsCommand)
 }
 
@@ -26,17 +32,69 @@ func testRunCommand(t *testing.T) {
 func testPrepareInteractiveCommand(t *testing.T) {
 	// testPrepareInteractiveCommand tests the PrepareInteractiveCommand function.
 	// It verifies that the generated command matches the expected command with the given input.
-	cmd := PrepareInteractiveCommand("echo 'Hello, World!'")
+	cmd := PrepareInteractiveCommand("echo 'Hello, World!'", "")
 
 	expectedCmd := exec.Command(
 		"bash",
 		"-c",
-		"echo \"\n\";echo 'Hello, World!'; echo \"\n\";",
+		"echo \"\n\"\necho 'Hello, World!'\nstatus=$?\necho \"\n\"\nexit $status",
 	)
 
 	assert.Equal(t, expectedCmd.Args, cmd.Args, "The command arguments should be the same.")
 }
 
+// testPrepareInteractiveCommandQuotes is a regression test executing a command containing both quote types,
+// a dollar and a trailing comment through the interactive and captured runners.
+func testPrepareInteractiveCommandQuotes(t *testing.T) {
+	input := `echo "it's" | awk '{print $1 "-" "\"ok\""}' # comment`
+
+	output, err := PrepareInteractiveCommand(input, "").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "\n\nit's-\"ok\"\n\n\n", string(output), "The command should be executed untouched.")
+
+	stdout, _, _, err := RunCaptured(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, "it's-\"ok\"\n", stdout, "The command should be executed untouched.")
+}
+
+// testPrepareInteractiveCommandExitStatus tests that the exit status of the command is kept.
+func testPrepareInteractiveCommandExitStatus(t *testing.T) {
+	err := PrepareInteractiveCommand("sleep 0 &", "").Run()
+	assert.NoError(t, err, "A trailing operator should not break the command.")
+
+	err = PrepareInteractiveCommand("false", "").Run()
+	assert.Equal(t, 1, exitCode(err), "The exit status should be kept.")
+}
+
+// testPrepareInteractiveCommandPreamble tests that the preamble is executed before the input, chaining it with
+// a trailing operator.
+func testPrepareInteractiveCommandPreamble(t *testing.T) {
+	output, err := PrepareInteractiveCommand("echo $GREETING", "export GREETING=hello && ").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "\n\nhello\n\n\n", string(output), "The preamble should be executed before the input.")
+
+	err = PrepareInteractiveCommand("true", "false &&").Run()
+	assert.Equal(t, 1, exitCode(err), "A failing preamble should prevent the execution of the input.")
+}
+
+// testWithPreamble tests that the WithPreamble function prepends the preamble on its own line.
+func testWithPreamble(t *testing.T) {
+	assert.Equal(t, "ls -la", WithPreamble("", "ls -la"), "The input should be untouched without a preamble.")
+	assert.Equal(t, "ls -la", WithPreamble("  ", "ls -la"), "The input should be untouched without a preamble.")
+	assert.Equal(t, "source ~/.nvm/nvm.sh &&\nnode -v", WithPreamble("source ~/.nvm/nvm.sh && ", "node -v"))
+}
+
+// testPrepareCommand tests that the PrepareCommand function executes the input untouched, keeping its exit status.
+func testPrepareCommand(t *testing.T) {
+	output, err := PrepareCommand(`echo "it's" | awk '{print $1 "-ok"}' # comment`).Output()
+	require.NoError(t, err)
+	assert.Equal(t, "it's-ok\n", string(output), "The output should not be separated.")
+
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, PrepareCommand("exit 3").Run(), &exitErr)
+	assert.Equal(t, 3, exitErr.ExitCode(), "The exit status should be kept.")
+}
+
 // testPrepareEditSettingsCommand is a unit test function that tests the PrepareEditSettingsCommand function.
 func testPrepareEditSettingsCommand(t *testing.T) {
 	cmd := PrepareEditSettingsCommand("nano yo.json")
@@ -44,7 +102,7 @@ func testPrepareEditSettingsCommand(t *testing.T) {
 	expectedCmd := exec.Command(
 		"bash",
 		"-c",
-		"nano yo.json; echo \"\n\";",
+		"nano yo.json\nstatus=$?\necho \"\n\"\nexit $status",
 	)
 
 	assert.Equal(t, expectedCmd.Args, cmd.Args, "The command arguments should be the same.")
@@ -0,0 +1,87 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// sandboxWorkdir is the directory of the sandbox where the working directory is mounted.
+const sandboxWorkdir = "/work"
+
+// ErrSandboxUnavailable is returned when the container runtime of the sandbox is not installed.
+var ErrSandboxUnavailable = errors.New("the container runtime of the sandbox is not available")
+
+// Sandbox executes commands inside throwaway containers, the working directory being mounted read-only.
+type Sandbox struct {
+	runtime string // The container runtime, like docker or podman.
+	image   string // The image of the containers.
+	workdir string // The directory mounted in the containers.
+}
+
+// NewSandbox returns a new Sandbox struct
+func NewSandbox(runtime string, image string, workdir string) *Sandbox {
+	return &Sandbox{
+		runtime: runtime,
+		image:   image,
+		workdir: workdir,
+	}
+}
+
+// GetRuntime returns the container runtime of the sandbox.
+func (s *Sandbox) GetRuntime() string {
+	return s.runtime
+}
+
+// GetImage returns the image of the sandbox containers.
+func (s *Sandbox) GetImage() string {
+	return s.image
+}
+
+// GetWorkdir returns the directory mounted in the sandbox containers.
+func (s *Sandbox) GetWorkdir() string {
+	return s.workdir
+}
+
+// Check verifies that the container runtime of the sandbox is installed.
+func (s *Sandbox) Check() error {
+	if _, err := exec.LookPath(s.runtime); err != nil {
+		return fmt.Errorf("%w: %s", ErrSandboxUnavailable, s.runtime)
+	}
+
+	return nil
+}
+
+// Args returns the arguments of the container runtime executing a command in the sandbox.
+// A TTY is only allocated for the interactive execution.
+func (s *Sandbox) Args(cmd string, interactive bool) []string {
+	args := []string{"run", "--rm"}
+	if interactive {
+		args = append(args, "-it")
+	}
+
+	return append(
+		args,
+		"-v", fmt.Sprintf("%s:%s:ro", s.workdir, sandboxWorkdir),
+		"-w", sandboxWorkdir,
+		s.image,
+		"sh", "-c", strings.TrimRight(cmd, ";"),
+	)
+}
+
+// PrepareCommand prepares the execution of a command in the sandbox.
+func (s *Sandbox) PrepareCommand(ctx context.Context, cmd string, interactive bool) *exec.Cmd {
+	return exec.CommandContext(ctx, s.runtime, s.Args(cmd, interactive)...)
+}
+
+// RunCaptured executes a command in the sandbox without a TTY and returns its stdout, stderr and exit code.
+// The exit code of the command is passed through by the container runtime.
+func (s *Sandbox) RunCaptured(ctx context.Context, cmd string) (string, string, int, error) {
+	if err := s.Check(); err != nil {
+		return "", "", -1, err
+	}
+
+	return runCaptured(ctx, exec.Command(s.runtime, s.Args(cmd, false)...))
+}
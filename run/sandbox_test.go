@@ -0,0 +1,65 @@
+package run
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSandbox(t *testing.T) {
+	t.Run("Args", testSandboxArgs)
+	t.Run("Check", testSandboxCheck)
+	t.Run("RunCaptured", testSandboxRunCaptured)
+}
+
+// testSandboxArgs is a unit test for the Args method of the Sandbox struct.
+func testSandboxArgs(t *testing.T) {
+	sandbox := NewSandbox("docker", "alpine:latest", "/home/user/project")
+
+	assert.Equal(
+		t,
+		[]string{"run", "--rm", "-v", "/home/user/project:/work:ro", "-w", "/work", "alpine:latest", "sh", "-c", "ls -la"},
+		sandbox.Args("ls -la;", false),
+		"The command should run in a throwaway container without a TTY.",
+	)
+	assert.Equal(
+		t,
+		[]string{"run", "--rm", "-it", "-v", "/home/user/project:/work:ro", "-w", "/work", "alpine:latest", "sh", "-c", "vi"},
+		sandbox.Args("vi", true),
+		"The interactive command should allocate a TTY.",
+	)
+}
+
+// testSandboxCheck is a unit test for the Check method of the Sandbox struct.
+func testSandboxCheck(t *testing.T) {
+	err := NewSandbox("terminal-assistant-missing-runtime", "alpine:latest", "/").Check()
+	assert.ErrorIs(t, err, ErrSandboxUnavailable, "A missing runtime should be reported.")
+
+	_, _, _, err = NewSandbox("terminal-assistant-missing-runtime", "alpine:latest", "/").RunCaptured(context.Background(), "ls")
+	assert.ErrorIs(t, err, ErrSandboxUnavailable, "A missing runtime should be reported.")
+}
+
+// testSandboxRunCaptured is a unit test for the RunCaptured method of the Sandbox struct, using a fake runtime
+// executing the command on the host.
+func testSandboxRunCaptured(t *testing.T) {
+	dir := t.TempDir()
+	runtime := filepath.Join(dir, "fake-runtime")
+	require.NoError(t, os.WriteFile(runtime, []byte("#!/bin/sh\nfor last; do :; done\nexec sh -c \"$last\"\n"), 0700))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	sandbox := NewSandbox("fake-runtime", "alpine:latest", dir)
+
+	stdout, _, code, err := sandbox.RunCaptured(context.Background(), "echo 'it works'")
+	require.NoError(t, err)
+	assert.Equal(t, "it works\n", stdout, "The stdout should be captured.")
+	assert.Equal(t, 0, code, "The exit code should be 0.")
+
+	_, stderr, code, err := sandbox.RunCaptured(context.Background(), "echo failed >&2; exit 4")
+	require.Error(t, err)
+	assert.Equal(t, "failed\n", stderr, "The stderr should be captured.")
+	assert.Equal(t, 4, code, "The exit code should be passed through.")
+}
@@ -0,0 +1,232 @@
+package run
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// safeWordPattern matches the words that don't need to be quoted for the shell.
+var safeWordPattern = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// assignmentPattern matches the environment assignments prefixing a command.
+var assignmentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
+// commands splits a command line into its commands, each command being the list of its words
+// without the leading environment assignments. The program of each command is reduced to its base name.
+func commands(cmd string) [][]string {
+	result := [][]string{}
+	for _, words := range tokenize(cmd) {
+		for len(words) > 0 && assignmentPattern.MatchString(words[0]) {
+			words = words[1:]
+		}
+		if len(words) == 0 {
+			continue
+		}
+		words[0] = filepath.Base(words[0])
+		result = append(result, words)
+	}
+
+	return result
+}
+
+// tokenize splits a command line into the words of its commands, separated by the shell operators
+// (pipes, lists, subshells, background and newlines). Quotes, escapes and comments are handled, redirections
+// like 2>&1 are kept in the words, and the commands of the command substitutions are added after their command.
+func tokenize(cmd string) [][]string {
+	var (
+		result      [][]string
+		command     []string
+		substituted [][]string
+		word        strings.Builder
+		inWord      bool
+		quote       rune
+		runes       = []rune(cmd)
+	)
+
+	flushWord := func() {
+		if inWord {
+			command = append(command, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+	flushCommand := func() {
+		flushWord()
+		if len(command) > 0 {
+			result = append(result, command)
+			command = nil
+		}
+		result = append(result, substituted...)
+		substituted = nil
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		// Single quotes preserve everything up to the closing quote
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		// Backslashes escape the next character, only some of them in double quotes
+		case r == '\\':
+			inWord = true
+			if i+1 == len(runes) {
+				word.WriteRune(r)
+				continue
+			}
+			i++
+			if quote == '"' && !strings.ContainsRune("$`\"\\\n", runes[i]) {
+				word.WriteRune(r)
+			}
+			if runes[i] != '\n' {
+				word.WriteRune(runes[i])
+			}
+		// Command substitutions are tokenized as separate commands, even in double quotes
+		case r == '`' || (r == '$' && i+1 < len(runes) && runes[i+1] == '('):
+			body, end, arithmetic := substitution(runes, i)
+			if !arithmetic {
+				substituted = append(substituted, tokenize(body)...)
+			}
+			word.WriteString(string(runes[i : end+1]))
+			inWord = true
+			i = end
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		// Comments run until the end of the line
+		case r == '#' && !inWord:
+			for i+1 < len(runes) && runes[i+1] != '\n' {
+				i++
+			}
+		case r == '\n':
+			flushCommand()
+		case unicode.IsSpace(r):
+			flushWord()
+		// Redirections like 2>&1, &> and >| are part of the words
+		case (r == '&' || r == '|') && inWord && strings.HasSuffix(word.String(), ">"),
+			r == '&' && i+1 < len(runes) && runes[i+1] == '>',
+			r == '&' && inWord && strings.HasSuffix(word.String(), "<"):
+			word.WriteRune(r)
+			inWord = true
+		case strings.ContainsRune(";&|()", r):
+			flushCommand()
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	flushCommand()
+
+	return result
+}
+
+// hasOutputRedirection checks if a command line redirects an output to a file, ignoring the quoted parts
+// and the redirections to other file descriptors like 2>&1.
+func hasOutputRedirection(cmd string) bool {
+	var quote rune
+	runes := []rune(cmd)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			}
+		case r == '\\':
+			i++
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '>':
+			if i+1 < len(runes) && runes[i+1] == '>' {
+				i++
+			}
+			if i+1 >= len(runes) || runes[i+1] != '&' {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// substitution returns the body of the command substitution starting at the given position of a command line,
+// the position of its end, and whether it is an arithmetic expansion. Unterminated substitutions run until the end.
+func substitution(runes []rune, start int) (string, int, bool) {
+	// Backticks end at the next unescaped backtick
+	if runes[start] == '`' {
+		for i := start + 1; i < len(runes); i++ {
+			if runes[i] == '\\' {
+				i++
+			} else if runes[i] == '`' {
+				return string(runes[start+1 : i]), i, false
+			}
+		}
+
+		return string(runes[start+1:]), len(runes) - 1, false
+	}
+
+	// $( ends at the matching parenthesis, skipping the quoted parts
+	arithmetic := start+2 < len(runes) && runes[start+2] == '('
+	depth := 0
+	var quote rune
+	for i := start + 1; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if r == '\\' && quote == '"' {
+				i++
+			}
+		case r == '\\':
+			i++
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+			if depth == 0 {
+				return string(runes[start+2 : i]), i, arithmetic
+			}
+		}
+	}
+
+	return string(runes[start+2:]), len(runes) - 1, arithmetic
+}
+
+// programs returns the name of the program of each command of a command line.
+func programs(cmd string) []string {
+	names := []string{}
+	for _, command := range commands(cmd) {
+		names = append(names, command[0])
+	}
+
+	return names
+}
+
+// Quote quotes a word for the shell, using single quotes unless the word only contains safe characters.
+// The quoted word is read back by the shell as the exact original word, including quotes, backslashes,
+// dollars, newlines and unicode characters.
+func Quote(word string) string {
+	if safeWordPattern.MatchString(word) {
+		return word
+	}
+
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
@@ -0,0 +1,142 @@
+package run
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShell(t *testing.T) {
+	t.Run("Tokenize", testTokenize)
+	t.Run("Commands", testCommands)
+	t.Run("HasOutputRedirection", testHasOutputRedirection)
+	t.Run("Quote", testQuote)
+	t.Run("QuoteRoundTrip", testQuoteRoundTrip)
+}
+
+// testTokenize is a unit test for the tokenize function.
+func testTokenize(t *testing.T) {
+	testCases := []struct {
+		name     string
+		cmd      string
+		expected [][]string
+	}{
+		{"Empty", "", nil},
+		{"Simple", "ls -la /tmp", [][]string{{"ls", "-la", "/tmp"}}},
+		{"ExtraSpaces", "  ls \t -la  ", [][]string{{"ls", "-la"}}},
+		{"Pipeline", "cat file | grep foo | wc -l", [][]string{{"cat", "file"}, {"grep", "foo"}, {"wc", "-l"}}},
+		{"Lists", "make && make install || echo failed; ls", [][]string{{"make"}, {"make", "install"}, {"echo", "failed"}, {"ls"}}},
+		{"Background", "sleep 10 & echo started", [][]string{{"sleep", "10"}, {"echo", "started"}}},
+		{"Newlines", "cd /tmp\nls", [][]string{{"cd", "/tmp"}, {"ls"}}},
+		{"Subshell", "(cd /tmp && rm -rf build)", [][]string{{"cd", "/tmp"}, {"rm", "-rf", "build"}}},
+		{"EnvPrefix", "FOO=1 BAR='a b' cmd arg", [][]string{{"FOO=1", "BAR=a b", "cmd", "arg"}}},
+		{"SingleQuotes", "echo 'a; b | c'", [][]string{{"echo", "a; b | c"}}},
+		{"DoubleQuotes", `echo "it's \"quoted\" \$HOME"`, [][]string{{"echo", `it's "quoted" $HOME`}}},
+		{"Escapes", `echo a\;b \| c`, [][]string{{"echo", "a;b", "|", "c"}}},
+		{"LineContinuation", "echo a \\\nb", [][]string{{"echo", "a", "b"}}},
+		{"Comment", "ls # rm -rf /\npwd", [][]string{{"ls"}, {"pwd"}}},
+		{"Hash", "echo a#b", [][]string{{"echo", "a#b"}}},
+		{"Redirections", "make 2>&1 >/dev/null | tee log &>out", [][]string{{"make", "2>&1", ">/dev/null"}, {"tee", "log", "&>out"}}},
+		{"CommandSubstitution", "echo $(rm -rf /) done", [][]string{{"echo", "$(rm -rf /)", "done"}, {"rm", "-rf", "/"}}},
+		{"QuotedSubstitution", `echo "$(whoami | tr a b)"`, [][]string{{"echo", "$(whoami | tr a b)"}, {"whoami"}, {"tr", "a", "b"}}},
+		{"Backticks", "echo `id -u`", [][]string{{"echo", "`id -u`"}, {"id", "-u"}}},
+		{"NestedSubstitution", "echo $(dirname $(which go))", [][]string{{"echo", "$(dirname $(which go))"}, {"dirname", "$(which go)"}, {"which", "go"}}},
+		{"Arithmetic", "echo $((1 + 2))", [][]string{{"echo", "$((1 + 2))"}}},
+		{"SingleQuotedSubstitution", "echo '$(rm -rf /)'", [][]string{{"echo", "$(rm -rf /)"}}},
+		{"Unterminated", "echo 'open", [][]string{{"echo", "open"}}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, tokenize(testCase.cmd), "The command line should be tokenized.")
+		})
+	}
+}
+
+// testCommands is a unit test for the commands function.
+func testCommands(t *testing.T) {
+	assert.Equal(
+		t,
+		[][]string{{"cmd", "--opt=1"}, {"ls"}},
+		commands("FOO=1 BAR=\"a b\" /usr/bin/cmd --opt=1 | A=1 ls"),
+		"The environment assignments should be removed and the programs reduced to their base name.",
+	)
+	assert.Equal(t, []string{"sudo", "grep"}, programs("sudo apt list | grep 'a|b'"), "The programs should be listed.")
+}
+
+// testHasOutputRedirection is a unit test for the hasOutputRedirection function.
+func testHasOutputRedirection(t *testing.T) {
+	testCases := []struct {
+		cmd      string
+		expected bool
+	}{
+		{"ls > files", true},
+		{"ls >> files", true},
+		{"ls 2>errors", true},
+		{"ls &>all", true},
+		{"ls >", true},
+		{"ls 2>&1 | wc -l", false},
+		{"echo 'a > b'", false},
+		{`echo "a >> b"`, false},
+		{`echo a \> b`, false},
+		{"ls", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.cmd, func(t *testing.T) {
+			assert.Equal(t, tc.expected, hasOutputRedirection(tc.cmd), "The redirection detection should match.")
+		})
+	}
+}
+
+// quoteTestCases are the words used to test the quoting helpers.
+var quoteTestCases = []struct {
+	name     string
+	word     string
+	expected string
+}{
+	{"Safe", "ls", "ls"},
+	{"SafePath", "/usr/local/bin:./bin", "/usr/local/bin:./bin"},
+	{"SafeAssignment", "key=value,other@host", "key=value,other@host"},
+	{"Empty", "", "''"},
+	{"Space", "hello world", "'hello world'"},
+	{"SingleQuote", "it's", `'it'\''s'`},
+	{"OnlySingleQuotes", "''", `''\'''\'''`},
+	{"DoubleQuote", `say "hi"`, `'say "hi"'`},
+	{"BothQuotes", `awk '{print "$1"}'`, `'awk '\''{print "$1"}'\'''`},
+	{"Backslash", `C:\path\n`, `'C:\path\n'`},
+	{"TrailingBackslash", `end\`, `'end\'`},
+	{"Dollar", "$HOME", "'$HOME'"},
+	{"CommandSubstitution", "$(rm -rf /)", "'$(rm -rf /)'"},
+	{"Backticks", "`whoami`", "'`whoami`'"},
+	{"Newline", "line1\nline2", "'line1\nline2'"},
+	{"Tab", "a\tb", "'a\tb'"},
+	{"Glob", "*.go", "'*.go'"},
+	{"Operators", "a && b; c | d > e", "'a && b; c | d > e'"},
+	{"Unicode", "héllo wörld 🚀", "'héllo wörld 🚀'"},
+	{"Tilde", "~/file", "'~/file'"},
+	{"Bang", "hello!", "'hello!'"},
+}
+
+// testQuote is a unit test for the Quote function.
+func testQuote(t *testing.T) {
+	for _, testCase := range quoteTestCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, Quote(testCase.word), "The word should be quoted.")
+		})
+	}
+}
+
+// testQuoteRoundTrip tests that the shell reads back the exact quoted word.
+func testQuoteRoundTrip(t *testing.T) {
+	for _, testCase := range quoteTestCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			output, err := exec.Command("sh", "-c", "printf %s "+Quote(testCase.word)).Output()
+			require.NoError(t, err)
+
+			assert.Equal(t, testCase.word, string(output), "The shell should read back the original word.")
+		})
+	}
+}
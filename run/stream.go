@@ -0,0 +1,170 @@
+package run
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// progress_interval is the interval at which the output lines of a streamed command are sent,
+// the lines written between two frames being coalesced into a single message.
+const progress_interval = 50 * time.Millisecond
+
+// progress_max_lines is the maximum number of lines waiting to be sent, the oldest ones being dropped
+// when the receiver is slower than the command.
+const progress_max_lines = 1000
+
+// RunProgressMsg is a batch of output lines of a streamed command.
+type RunProgressMsg struct {
+	lines   []string // The new lines written by the command, stdout and stderr being interleaved.
+	dropped int      // The number of lines dropped before these lines because the receiver was too slow.
+}
+
+// GetLines returns the new lines written by the command.
+func (m RunProgressMsg) GetLines() []string {
+	return m.lines
+}
+
+// GetDropped returns the number of lines dropped before these lines because the receiver was too slow.
+func (m RunProgressMsg) GetDropped() int {
+	return m.dropped
+}
+
+// RunStreamed executes a shell command without a TTY like RunCaptured, and sends its output lines to the progress
+// channel while it runs. The lines are sent at most every progress_interval, so a command writing megabytes per second
+// never floods the receiver, and the channel is closed once the command finished and its last lines were sent.
+func RunStreamed(ctx context.Context, cmd string, progress chan<- RunProgressMsg) (string, string, int, error) {
+	if RequiresElevation(cmd) {
+		close(progress)
+		return "", "", -1, ErrElevationRequired
+	}
+
+	return runStreamed(ctx, prepareCapturedCommand(cmd), progress)
+}
+
+// runStreamed executes a prepared command and sends its output lines to the progress channel while it runs.
+func runStreamed(ctx context.Context, c *exec.Cmd, progress chan<- RunProgressMsg) (string, string, int, error) {
+	var stdout, stderr bytes.Buffer
+
+	collector := &lineCollector{}
+	outWriter := &lineWriter{collector: collector}
+	errWriter := &lineWriter{collector: collector}
+	stop := make(chan struct{})
+	forwarded := make(chan struct{})
+	go func() {
+		collector.forward(progress, stop)
+		close(forwarded)
+	}()
+
+	err := runWithWriters(
+		ctx,
+		c,
+		io.MultiWriter(&stdout, outWriter),
+		io.MultiWriter(&stderr, errWriter),
+	)
+	outWriter.flush()
+	errWriter.flush()
+
+	close(stop)
+	<-forwarded
+
+	return stdout.String(), stderr.String(), exitCode(err), err
+}
+
+// lineCollector accumulates the output lines of a command until they are sent.
+type lineCollector struct {
+	mutex   sync.Mutex
+	pending []string
+	dropped int
+}
+
+// add adds lines to the pending lines, dropping the oldest ones beyond progress_max_lines.
+func (lc *lineCollector) add(lines ...string) {
+	lc.mutex.Lock()
+	defer lc.mutex.Unlock()
+
+	lc.pending = append(lc.pending, lines...)
+	if excess := len(lc.pending) - progress_max_lines; excess > 0 {
+		lc.pending = lc.pending[excess:]
+		lc.dropped += excess
+	}
+}
+
+// take returns the pending lines and resets them, false being returned if there is nothing to send.
+func (lc *lineCollector) take() (RunProgressMsg, bool) {
+	lc.mutex.Lock()
+	defer lc.mutex.Unlock()
+
+	if len(lc.pending) == 0 && lc.dropped == 0 {
+		return RunProgressMsg{}, false
+	}
+
+	msg := RunProgressMsg{lines: lc.pending, dropped: lc.dropped}
+	lc.pending = nil
+	lc.dropped = 0
+
+	return msg, true
+}
+
+// forward sends the pending lines to the progress channel every progress_interval until stop is closed,
+// then sends the last lines and closes the channel. The lines keep accumulating while a send is blocked.
+func (lc *lineCollector) forward(progress chan<- RunProgressMsg, stop <-chan struct{}) {
+	defer close(progress)
+
+	ticker := time.NewTicker(progress_interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if msg, ok := lc.take(); ok {
+				progress <- msg
+			}
+		case <-stop:
+			if msg, ok := lc.take(); ok {
+				progress <- msg
+			}
+			return
+		}
+	}
+}
+
+// lineWriter splits the output of a command into lines added to a collector, the last incomplete line
+// being kept until it is completed.
+type lineWriter struct {
+	collector *lineCollector
+	partial   bytes.Buffer
+}
+
+// Write splits the written bytes into lines, only the new bytes being scanned for the line breaks.
+func (lw *lineWriter) Write(p []byte) (int, error) {
+	written := len(p)
+	var lines []string
+	for {
+		end := bytes.IndexByte(p, '\n')
+		if end < 0 {
+			lw.partial.Write(p)
+			break
+		}
+		lw.partial.Write(p[:end])
+		lines = append(lines, lw.partial.String())
+		lw.partial.Reset()
+		p = p[end+1:]
+	}
+	if len(lines) > 0 {
+		lw.collector.add(lines...)
+	}
+
+	return written, nil
+}
+
+// flush adds the last incomplete line to the collector.
+func (lw *lineWriter) flush() {
+	if lw.partial.Len() > 0 {
+		lw.collector.add(lw.partial.String())
+		lw.partial.Reset()
+	}
+}
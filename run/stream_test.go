@@ -0,0 +1,113 @@
+package run
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStream(t *testing.T) {
+	t.Run("RunStreamed", testRunStreamed)
+	t.Run("RunStreamedElevation", testRunStreamedElevation)
+	t.Run("RunStreamedSlowReceiver", testRunStreamedSlowReceiver)
+	t.Run("LineWriter", testLineWriter)
+}
+
+// collectProgress reads the progress channel until it is closed, returning the received lines and the dropped count.
+func collectProgress(progress <-chan RunProgressMsg) ([]string, int) {
+	var lines []string
+	dropped := 0
+	for msg := range progress {
+		lines = append(lines, msg.GetLines()...)
+		dropped += msg.GetDropped()
+	}
+
+	return lines, dropped
+}
+
+// testRunStreamed is a unit test for the RunStreamed function.
+func testRunStreamed(t *testing.T) {
+	progress := make(chan RunProgressMsg)
+	received := make(chan []string)
+	go func() {
+		lines, _ := collectProgress(progress)
+		received <- lines
+	}()
+
+	stdout, stderr, code, err := RunStreamed(context.Background(), "echo one; sleep 0.1; echo two >&2; printf three", progress)
+	require.NoError(t, err)
+
+	assert.Equal(t, "one\nthree", stdout, "The stdout should be captured.")
+	assert.Equal(t, "two\n", stderr, "The stderr should be captured.")
+	assert.Equal(t, 0, code, "The exit code should be 0.")
+	assert.Equal(t, []string{"one", "two", "three"}, <-received, "The lines should be streamed, including the last incomplete one.")
+}
+
+// testRunStreamedElevation is a unit test for the RunStreamed function with a command requiring elevated privileges.
+func testRunStreamedElevation(t *testing.T) {
+	progress := make(chan RunProgressMsg)
+	_, _, _, err := RunStreamed(context.Background(), "sudo ls", progress)
+
+	assert.ErrorIs(t, err, ErrElevationRequired, "The command should be refused.")
+	_, open := <-progress
+	assert.False(t, open, "The progress channel should be closed.")
+}
+
+// testRunStreamedSlowReceiver is a unit test for the RunStreamed function with a command writing faster than the receiver.
+func testRunStreamedSlowReceiver(t *testing.T) {
+	progress := make(chan RunProgressMsg)
+	done := make(chan struct{})
+	var stdout string
+	go func() {
+		stdout, _, _, _ = RunStreamed(context.Background(), "seq 1 100000", progress)
+		close(done)
+	}()
+
+	// The command writes faster than the lines are sent, the pending lines being capped
+	lines, dropped := collectProgress(progress)
+	<-done
+
+	assert.Equal(t, 100000, len(strings.Split(strings.TrimSpace(stdout), "\n")), "The whole output should be captured.")
+	assert.Equal(t, 100000, len(lines)+dropped, "Every line should be received or counted as dropped.")
+	assert.Equal(t, "100000", lines[len(lines)-1], "The last lines should be received.")
+}
+
+// testLineWriter is a unit test for the lineWriter type.
+func testLineWriter(t *testing.T) {
+	collector := &lineCollector{}
+	writer := &lineWriter{collector: collector}
+
+	_, _ = writer.Write([]byte("first li"))
+	_, _ = writer.Write([]byte("ne\nsecond\nthi"))
+	msg, ok := collector.take()
+	require.True(t, ok)
+	assert.Equal(t, []string{"first line", "second"}, msg.GetLines(), "The complete lines should be collected.")
+
+	_, ok = collector.take()
+	assert.False(t, ok, "The incomplete line should be kept.")
+
+	writer.flush()
+	msg, _ = collector.take()
+	assert.Equal(t, []string{"thi"}, msg.GetLines(), "The incomplete line should be flushed.")
+
+	_, _ = writer.Write([]byte("\n\nlast\n"))
+	msg, _ = collector.take()
+	assert.Equal(t, []string{"", "", "last"}, msg.GetLines(), "The empty lines should be collected.")
+
+	chunk := []byte(strings.Repeat("x", 10))
+	for i := 0; i < 100000; i++ {
+		_, _ = writer.Write(chunk)
+	}
+	_, _ = writer.Write([]byte("\n"))
+	msg, _ = collector.take()
+	require.Len(t, msg.GetLines(), 1)
+	assert.Len(t, msg.GetLines()[0], 1000000, "A long line written in chunks should be collected whole.")
+
+	collector.add(make([]string, progress_max_lines+5)...)
+	msg, _ = collector.take()
+	assert.Equal(t, progress_max_lines, len(msg.GetLines()), "The pending lines should be capped.")
+	assert.Equal(t, 5, msg.GetDropped(), "The oldest lines should be counted as dropped.")
+}
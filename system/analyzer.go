@@ -3,8 +3,11 @@ package system
 import (
 	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 
 	"github.com/akhilsharma90/terminal-assistant/run"
 
@@ -13,6 +16,10 @@ import (
 
 const APPLICATION_NAME = "terminal-assistant"
 
+// editorCandidates are the editors probed, in order, when no editor is set, with the arguments making them wait for
+// the file to be closed, the graphical editors coming after the terminal ones.
+var editorCandidates = []string{"nano", "vim", "vi", "code --wait", "notepad.exe"}
+
 type Analysis struct {
 	operatingSystem OperatingSystem // The operating system type.
 	distribution    string          // The specific distribution of the OS.
@@ -20,7 +27,9 @@ type Analysis struct {
 	homeDirectory   string          // The home directory path.
 	username        string          // The username of the current user.
 	editor          string          // The default editor set.
+	editorOnce      sync.Once       // Guards the detection of the editor when none is set.
 	configFile      string          // The configuration file path.
+	historyFile     string          // The history file path.
 }
 
 // GetApplicationName is a method that returns the application name.
@@ -53,8 +62,15 @@ func (a *Analysis) GetUsername() string {
 	return a.username
 }
 
-// GetEditor is a method that returns the default editor set.
+// GetEditor is a method that returns the default editor set. When none is set, an installed editor is detected
+// at the first call and kept for the next ones.
 func (a *Analysis) GetEditor() string {
+	a.editorOnce.Do(func() {
+		if a.editor == "" {
+			a.editor = DetectEditor()
+		}
+	})
+
 	return a.editor
 }
 
@@ -63,6 +79,11 @@ func (a *Analysis) GetConfigFile() string {
 	return a.configFile
 }
 
+// GetHistoryFile is a method that returns the history file path.
+func (a *Analysis) GetHistoryFile() string {
+	return a.historyFile
+}
+
 // Analyse is a function that returns an Analysis object.
 func Analyse() *Analysis {
 	return &Analysis{
@@ -73,6 +94,7 @@ func Analyse() *Analysis {
 		username:        GetUsername(),
 		editor:          GetEditor(),
 		configFile:      GetConfigFile(),
+		historyFile:     GetHistoryFile(),
 	}
 }
 
@@ -147,6 +169,24 @@ func GetEditor() string {
 	return strings.Trim(name, "\n")
 }
 
+// DetectEditor is a function that returns the editor set by the VISUAL or EDITOR environment variables,
+// or else the first installed editor among the editor candidates, an empty string being returned if none is found.
+func DetectEditor() string {
+	for _, variable := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(variable)); editor != "" {
+			return editor
+		}
+	}
+
+	for _, candidate := range editorCandidates {
+		if _, err := exec.LookPath(strings.Fields(candidate)[0]); err == nil {
+			return candidate
+		}
+	}
+
+	return ""
+}
+
 // GetConfigFile is a function that returns the configuration file path.
 func GetConfigFile() string {
 	return fmt.Sprintf(
@@ -155,3 +195,57 @@ func GetConfigFile() string {
 		strings.ToLower(APPLICATION_NAME),
 	)
 }
+
+// GetHistoryFile is a function that returns the history file path.
+func GetHistoryFile() string {
+	return fmt.Sprintf(
+		"%s/.config/%s.history.json",
+		GetHomeDirectory(),
+		strings.ToLower(APPLICATION_NAME),
+	)
+}
+
+// GetCrashLogFile is a function that returns the crash log file path.
+func GetCrashLogFile() string {
+	return fmt.Sprintf(
+		"%s/.cache/%s/crash.log",
+		GetHomeDirectory(),
+		strings.ToLower(APPLICATION_NAME),
+	)
+}
+
+// GetTranscriptDirectory is a function that returns the directory path of the saved session transcripts.
+func GetTranscriptDirectory() string {
+	return fmt.Sprintf(
+		"%s/.local/share/%s/transcripts",
+		GetHomeDirectory(),
+		strings.ToLower(APPLICATION_NAME),
+	)
+}
+
+// GetShellHistoryFile is a function that returns the history file path of a shell among bash, zsh and fish: the one
+// set by the HISTFILE environment variable for the current shell, or else the default one of the shell.
+func GetShellHistoryFile(shell string) (string, error) {
+	if file := os.Getenv("HISTFILE"); file != "" && shell == GetShell() {
+		return file, nil
+	}
+
+	switch shell {
+	case "bash":
+		return filepath.Join(GetHomeDirectory(), ".bash_history"), nil
+	case "zsh":
+		dir := os.Getenv("ZDOTDIR")
+		if dir == "" {
+			dir = GetHomeDirectory()
+		}
+		return filepath.Join(dir, ".zsh_history"), nil
+	case "fish":
+		dir := os.Getenv("XDG_DATA_HOME")
+		if dir == "" {
+			dir = filepath.Join(GetHomeDirectory(), ".local", "share")
+		}
+		return filepath.Join(dir, "fish", "fish_history"), nil
+	default:
+		return "", fmt.Errorf("unknown shell %q, use one of bash, zsh, fish", shell)
+	}
+}
@@ -1,6 +1,9 @@
 package system
 
 import (
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -12,6 +15,9 @@ import (
 func TestSystem(t *testing.T) {
 	t.Run("GetOperatingSystem", testGetOperatingSystem)
 	t.Run("Analyse", testAnalyse)
+	t.Run("GetCrashLogFile", testGetCrashLogFile)
+	t.Run("DetectEditor", testDetectEditor)
+	t.Run("GetShellHistoryFile", testGetShellHistoryFile)
 }
 
 // testGetOperatingSystem tests the GetOperatingSystem function.
@@ -30,4 +36,73 @@ func testAnalyse(t *testing.T) {
 	assert.NotEmpty(t, analysis.GetHomeDirectory(), "Home directory should not be empty.")
 	assert.NotEmpty(t, analysis.GetUsername(), "Username should not be empty.")
 	assert.NotEmpty(t, analysis.GetConfigFile(), "Config file should not be empty.")
+	assert.NotEmpty(t, analysis.GetHistoryFile(), "History file should not be empty.")
+}
+
+// testGetCrashLogFile tests the GetCrashLogFile function.
+func testGetCrashLogFile(t *testing.T) {
+	assert.Equal(t, GetHomeDirectory()+"/.cache/terminal-assistant/crash.log", GetCrashLogFile(), "The crash log should be in the cache directory.")
+	assert.Equal(t, GetHomeDirectory()+"/.local/share/terminal-assistant/transcripts", GetTranscriptDirectory(), "The transcripts should be in the data directory.")
+}
+
+// testDetectEditor tests the detection of the editor when none is set.
+func testDetectEditor(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "myeditor")
+	assert.Equal(t, "myeditor", DetectEditor(), "The EDITOR environment variable should be used.")
+
+	t.Setenv("VISUAL", "myvisual")
+	assert.Equal(t, "myvisual", DetectEditor(), "The VISUAL environment variable should be preferred.")
+
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	t.Setenv("PATH", t.TempDir())
+	assert.Empty(t, DetectEditor(), "No editor should be detected when none is installed.")
+
+	if runtime.GOOS != "windows" {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "code"), []byte("#!/bin/sh\n"), 0700))
+		t.Setenv("PATH", dir)
+		assert.Equal(t, "code --wait", DetectEditor(), "The graphical editor should wait for the file to be closed.")
+		t.Setenv("PATH", t.TempDir())
+	}
+
+	t.Setenv("EDITOR", "myeditor")
+	analysis := &Analysis{}
+	assert.Equal(t, "myeditor", analysis.GetEditor(), "The editor should be detected at the first call.")
+	t.Setenv("EDITOR", "another")
+	assert.Equal(t, "myeditor", analysis.GetEditor(), "The detected editor should be kept.")
+
+	analysis = &Analysis{editor: "emacs"}
+	assert.Equal(t, "emacs", analysis.GetEditor(), "The editor set should not be replaced.")
+}
+
+// testGetShellHistoryFile tests the default history files of the shells, and the one set by HISTFILE for the current
+// shell.
+func testGetShellHistoryFile(t *testing.T) {
+	t.Setenv("HISTFILE", "")
+	t.Setenv("ZDOTDIR", "/zdotdir")
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("SHELL", "/bin/zsh")
+
+	file, err := GetShellHistoryFile("bash")
+	require.NoError(t, err)
+	assert.Equal(t, GetHomeDirectory()+"/.bash_history", file)
+	file, err = GetShellHistoryFile("zsh")
+	require.NoError(t, err)
+	assert.Equal(t, "/zdotdir/.zsh_history", file)
+	file, err = GetShellHistoryFile("fish")
+	require.NoError(t, err)
+	assert.Equal(t, GetHomeDirectory()+"/.local/share/fish/fish_history", file)
+
+	t.Setenv("HISTFILE", "/custom/history")
+	file, err = GetShellHistoryFile("zsh")
+	require.NoError(t, err)
+	assert.Equal(t, "/custom/history", file, "HISTFILE should be used for the current shell.")
+	file, err = GetShellHistoryFile("bash")
+	require.NoError(t, err)
+	assert.Equal(t, GetHomeDirectory()+"/.bash_history", file, "HISTFILE should not be used for another shell.")
+
+	_, err = GetShellHistoryFile("csh")
+	assert.Error(t, err)
 }
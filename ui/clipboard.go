@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+)
+
+// default_copy_key is the key copying the last answer to the clipboard when none is configured.
+const default_copy_key = "ctrl+y"
+
+// clipboardTerminal is the terminal the OSC52 sequences are written to, when the system clipboard cannot be used.
+var clipboardTerminal io.Writer = os.Stderr
+
+// copyToClipboard is a function that copies a text to the clipboard. The system clipboard is used locally, while
+// the text is sent to the clipboard of the terminal with an OSC52 sequence over SSH, where the system clipboard
+// would be the one of the remote host, or when the system clipboard is not available.
+func copyToClipboard(text string) error {
+	if !isRemoteSession() {
+		if err := clipboard.WriteAll(text); err == nil {
+			return nil
+		}
+	}
+
+	// Wrap the sequence so the terminal multiplexers pass it through to the terminal
+	sequence := osc52.New(text)
+	if os.Getenv("TMUX") != "" {
+		sequence = sequence.Tmux()
+	} else if strings.HasPrefix(os.Getenv("TERM"), "screen") {
+		sequence = sequence.Screen()
+	}
+
+	_, err := sequence.WriteTo(clipboardTerminal)
+
+	return err
+}
+
+// isRemoteSession is a function that checks if the program runs in an SSH session.
+func isRemoteSession() bool {
+	return os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != ""
+}
+
+// extractCodeBlocks is a function that returns the contents of the fenced code blocks of a markdown text.
+// A block is closed by a fence of the same character at least as long as its opening fence, and an unclosed block
+// runs until the end of the text.
+func extractCodeBlocks(markdown string) []string {
+	var blocks []string
+	var content []string
+	fence := ""
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence == "" {
+			if opening := codeFence(trimmed); opening != "" {
+				fence = opening
+				content = nil
+			}
+			continue
+		}
+
+		if closing := codeFence(trimmed); closing != "" && closing == trimmed && closing[0] == fence[0] && len(closing) >= len(fence) {
+			blocks = append(blocks, strings.Join(content, "\n"))
+			fence = ""
+			continue
+		}
+		content = append(content, line)
+	}
+
+	if fence != "" {
+		blocks = append(blocks, strings.Join(content, "\n"))
+	}
+
+	return blocks
+}
+
+// codeFence is a function that returns the fence starting a line, at least three backticks or tildes,
+// an empty string being returned if the line does not start with a fence.
+func codeFence(line string) string {
+	for _, char := range []string{"`", "~"} {
+		length := len(line) - len(strings.TrimLeft(line, char))
+		if length >= 3 {
+			return line[:length]
+		}
+	}
+
+	return ""
+}
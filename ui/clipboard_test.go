@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUIClipboard(t *testing.T) {
+	t.Run("CopyToClipboard", testCopyToClipboard)
+	t.Run("ExtractCodeBlocks", testExtractCodeBlocks)
+}
+
+// useTestTerminal is a helper capturing the OSC52 sequences written to the terminal.
+func useTestTerminal(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	terminal := &bytes.Buffer{}
+	previous := clipboardTerminal
+	clipboardTerminal = terminal
+	t.Cleanup(func() {
+		clipboardTerminal = previous
+	})
+
+	return terminal
+}
+
+// testCopyToClipboard tests that the text is sent to the terminal with an OSC52 sequence over SSH.
+func testCopyToClipboard(t *testing.T) {
+	terminal := useTestTerminal(t)
+	t.Setenv("SSH_TTY", "/dev/pts/0")
+	t.Setenv("TMUX", "")
+	t.Setenv("TERM", "xterm")
+
+	assert.NoError(t, copyToClipboard("hello"))
+	assert.Equal(t, "\x1b]52;c;"+base64.StdEncoding.EncodeToString([]byte("hello"))+"\x07", terminal.String())
+
+	terminal.Reset()
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+	assert.NoError(t, copyToClipboard("hello"))
+	assert.Contains(t, terminal.String(), "\x1bPtmux;", "The sequence should be passed through tmux.")
+}
+
+// testExtractCodeBlocks tests the extractCodeBlocks function.
+func testExtractCodeBlocks(t *testing.T) {
+	testCases := []struct {
+		name     string
+		markdown string
+		expected []string
+	}{
+		{"NoBlock", "just text", nil},
+		{"OneBlock", "Run:\n\n```sh\nls -la\ncd /tmp\n```\n\nDone.", []string{"ls -la\ncd /tmp"}},
+		{"TwoBlocks", "```\na\n```\ntext\n~~~\nb\n~~~", []string{"a", "b"}},
+		{"LongerFence", "````md\n```\ninner\n```\n````", []string{"```\ninner\n```"}},
+		{"Unclosed", "```go\nfmt.Println()", []string{"fmt.Println()"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, extractCodeBlocks(tc.markdown))
+		})
+	}
+}
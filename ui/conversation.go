@@ -0,0 +1,357 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/wrap"
+)
+
+// conversation_max_blocks is the maximum number of blocks kept in the conversation, the oldest ones being dropped.
+const conversation_max_blocks = 1000
+
+// conversation_max_cleared is the maximum number of screens cleared from the conversation kept to be restored, the
+// oldest ones being dropped.
+const conversation_max_cleared = 20
+
+// printMsg is a message appending a rendered content to the conversation.
+type printMsg string
+
+// printMarkdownMsg is a message appending a markdown content to the conversation, rendered at the width
+// of the terminal and followed by a rendered suffix.
+type printMarkdownMsg struct {
+	prefix   string // The rendered content displayed before the markdown content, like the marker of an answer.
+	markdown string // The markdown content, rendered again when the terminal is resized.
+	suffix   string // The rendered content displayed after the markdown content, like the metadata of an answer.
+	answer   bool   // Whether the markdown content is an answer, which can be displayed as raw markdown.
+}
+
+// conversationBlock is a struct that represents a block of the conversation, like an input, an answer or an output.
+type conversationBlock struct {
+	content  string // The rendered content of the block.
+	prefix   string // The rendered content preceding the markdown content.
+	markdown string // The markdown content of the block, if it can be rendered again.
+	suffix   string // The rendered content following the markdown content.
+	answer   bool   // Whether the block is an answer, which can be displayed as raw markdown.
+	raw      bool   // Whether the markdown content is displayed as raw markdown instead of being rendered.
+}
+
+// render is a method on the conversationBlock struct that renders the markdown content of the block with a renderer,
+// or as raw markdown, between its prefix and its suffix.
+func (b conversationBlock) render(renderer *Renderer) string {
+	if b.raw {
+		return b.prefix + renderer.RenderRawMarkdown(b.markdown) + b.suffix
+	}
+
+	return b.prefix + renderer.RenderContent(b.markdown) + b.suffix
+}
+
+// Conversation is a struct that represents the scrollable conversation displayed above the prompt in the REPL mode.
+// It follows the new content, unless the user scrolled up to read the previous one.
+type Conversation struct {
+	blocks   []conversationBlock   // The blocks of the conversation, like the inputs, answers and outputs.
+	cleared  [][]conversationBlock // The blocks of the screens cleared by Hide, oldest first, restored by Restore.
+	pending  string                // The rendered answer being streamed, displayed after the blocks.
+	follow   bool                  // Whether the conversation sticks to its bottom when content is added.
+	query    string                // The query searched in the conversation, its matches being highlighted.
+	matches  []searchMatch         // The matches of the query searched in the conversation.
+	current  int                   // The index of the current match of the query searched in the conversation.
+	viewport viewport.Model        // The viewport model.
+}
+
+// NewConversation is a function that creates a new Conversation instance.
+func NewConversation(width int, height int) *Conversation {
+	return &Conversation{
+		follow:   true,
+		viewport: viewport.New(width, height),
+	}
+}
+
+// Append is a method on the Conversation struct that adds a rendered block to the conversation.
+func (c *Conversation) Append(content string) *Conversation {
+	return c.appendBlock(conversationBlock{content: content})
+}
+
+// AppendMarkdown is a method on the Conversation struct that adds a markdown block to the conversation, rendered
+// with a renderer and followed by a rendered suffix. The block is rendered again by Rerender.
+func (c *Conversation) AppendMarkdown(markdown string, suffix string, renderer *Renderer) *Conversation {
+	block := conversationBlock{markdown: markdown, suffix: suffix}
+	block.content = block.render(renderer)
+
+	return c.appendBlock(block)
+}
+
+// AppendAnswer is a method on the Conversation struct that adds a markdown block to the conversation like
+// AppendMarkdown, preceded by a rendered prefix, like the marker of an answer. The answer can be displayed as raw
+// markdown with ToggleRaw.
+func (c *Conversation) AppendAnswer(prefix string, markdown string, suffix string, renderer *Renderer) *Conversation {
+	block := conversationBlock{prefix: prefix, markdown: markdown, suffix: suffix, answer: true}
+	block.content = block.render(renderer)
+
+	return c.appendBlock(block)
+}
+
+// ToggleRaw is a method on the Conversation struct that displays the last answer as raw markdown, or renders it
+// again if it is displayed as raw markdown. It returns false if the conversation has no answer.
+func (c *Conversation) ToggleRaw(renderer *Renderer) bool {
+	for i := len(c.blocks) - 1; i >= 0; i-- {
+		if c.blocks[i].answer {
+			c.blocks[i].raw = !c.blocks[i].raw
+			c.blocks[i].content = c.blocks[i].render(renderer)
+			c.refresh()
+			return true
+		}
+	}
+
+	return false
+}
+
+// Rerender is a method on the Conversation struct that renders the markdown blocks again with a renderer,
+// like when the terminal is resized, the other blocks being kept.
+func (c *Conversation) Rerender(renderer *Renderer) *Conversation {
+	for i, block := range c.blocks {
+		if block.markdown != "" {
+			c.blocks[i].content = block.render(renderer)
+		}
+	}
+	c.refresh()
+
+	return c
+}
+
+// appendBlock is a method on the Conversation struct that adds a block to the conversation,
+// the oldest blocks being dropped beyond conversation_max_blocks.
+func (c *Conversation) appendBlock(block conversationBlock) *Conversation {
+	c.blocks = append(c.blocks, block)
+	if excess := len(c.blocks) - conversation_max_blocks; excess > 0 {
+		c.blocks = c.blocks[excess:]
+	}
+	c.refresh()
+
+	return c
+}
+
+// SetPending is a method on the Conversation struct that sets the rendered answer being streamed.
+func (c *Conversation) SetPending(content string) *Conversation {
+	c.pending = content
+	c.refresh()
+
+	return c
+}
+
+// Clear is a method on the Conversation struct that removes all the content of the conversation, including the screens
+// cleared by Hide.
+func (c *Conversation) Clear() *Conversation {
+	c.blocks = nil
+	c.cleared = nil
+	c.pending = ""
+	c.follow = true
+	c.refresh()
+
+	return c
+}
+
+// Hide is a method on the Conversation struct that clears the screen of the conversation, its blocks being kept to be
+// restored by Restore. The answer being streamed is kept displayed.
+func (c *Conversation) Hide() *Conversation {
+	if len(c.blocks) > 0 {
+		c.cleared = append(c.cleared, c.blocks)
+		if excess := len(c.cleared) - conversation_max_cleared; excess > 0 {
+			c.cleared = c.cleared[excess:]
+		}
+	}
+	c.blocks = nil
+	c.follow = true
+	c.refresh()
+
+	return c
+}
+
+// Restore is a method on the Conversation struct that displays again the last screens cleared by Hide before the
+// current blocks, rendered again with a renderer at the current width, and returns the number of screens restored.
+func (c *Conversation) Restore(count int, renderer *Renderer) int {
+	if count > len(c.cleared) {
+		count = len(c.cleared)
+	}
+	if count < 1 {
+		return 0
+	}
+
+	var blocks []conversationBlock
+	for _, screen := range c.cleared[len(c.cleared)-count:] {
+		blocks = append(blocks, screen...)
+	}
+	c.cleared = c.cleared[:len(c.cleared)-count]
+	c.blocks = append(blocks, c.blocks...)
+	if excess := len(c.blocks) - conversation_max_blocks; excess > 0 {
+		c.blocks = c.blocks[excess:]
+	}
+	c.follow = true
+	c.Rerender(renderer)
+
+	return count
+}
+
+// GetClearedCount is a method on the Conversation struct that returns the number of screens cleared by Hide that can
+// be restored.
+func (c *Conversation) GetClearedCount() int {
+	return len(c.cleared)
+}
+
+// IsEmpty is a method on the Conversation struct that returns whether the conversation has no content.
+func (c *Conversation) IsEmpty() bool {
+	return len(c.blocks) == 0 && c.pending == ""
+}
+
+// IsFollowing is a method on the Conversation struct that returns whether the conversation sticks to its bottom.
+func (c *Conversation) IsFollowing() bool {
+	return c.follow
+}
+
+// Resize is a method on the Conversation struct that sets the width of the conversation.
+func (c *Conversation) Resize(width int) *Conversation {
+	c.viewport.Width = width
+	c.refresh()
+
+	return c
+}
+
+// Update is a method on the Conversation struct that scrolls the conversation with a message. The conversation stops
+// following the new content when it is scrolled up, and follows it again when it is scrolled back to the bottom.
+func (c *Conversation) Update(msg tea.Msg) (*Conversation, tea.Cmd) {
+	var updateCmd tea.Cmd
+	c.viewport, updateCmd = c.viewport.Update(msg)
+	c.follow = c.viewport.AtBottom()
+
+	return c, updateCmd
+}
+
+// View is a method on the Conversation struct that returns a string representation of the conversation,
+// filling the given height so the prompt stays at the bottom of the terminal.
+func (c *Conversation) View(height int) string {
+	if height < 1 {
+		return ""
+	}
+
+	c.viewport.Height = height
+	if c.follow {
+		c.viewport.GotoBottom()
+	}
+
+	return c.viewport.View()
+}
+
+// Search is a method on the Conversation struct that highlights the matches of a query in the conversation, ignoring
+// the case, and scrolls to the last one. The number of matches is returned.
+func (c *Conversation) Search(query string) int {
+	c.query = query
+	c.current = 0
+	c.refresh()
+	if len(c.matches) > 0 {
+		c.current = len(c.matches) - 1
+		c.refresh()
+		c.scrollToMatch()
+	}
+
+	return len(c.matches)
+}
+
+// NextMatch is a method on the Conversation struct that scrolls to the next match of the query searched, the first
+// match following the last one.
+func (c *Conversation) NextMatch() *Conversation {
+	return c.moveMatch(1)
+}
+
+// PreviousMatch is a method on the Conversation struct that scrolls to the previous match of the query searched, the
+// last match preceding the first one.
+func (c *Conversation) PreviousMatch() *Conversation {
+	return c.moveMatch(-1)
+}
+
+// moveMatch is a method on the Conversation struct that scrolls to the match of the query searched at a distance of
+// the current one.
+func (c *Conversation) moveMatch(distance int) *Conversation {
+	if len(c.matches) == 0 {
+		return c
+	}
+
+	c.current = (c.current + distance + len(c.matches)) % len(c.matches)
+	c.refresh()
+	c.scrollToMatch()
+
+	return c
+}
+
+// ClearSearch is a method on the Conversation struct that stops highlighting the matches of the query searched, the
+// conversation following the new content again.
+func (c *Conversation) ClearSearch() *Conversation {
+	c.query = ""
+	c.matches = nil
+	c.current = 0
+	c.follow = true
+	c.refresh()
+
+	return c
+}
+
+// GetMatchCount is a method on the Conversation struct that returns the number of matches of the query searched.
+func (c *Conversation) GetMatchCount() int {
+	return len(c.matches)
+}
+
+// GetCurrentMatch is a method on the Conversation struct that returns the index of the current match of the query
+// searched.
+func (c *Conversation) GetCurrentMatch() int {
+	return c.current
+}
+
+// scrollToMatch is a method on the Conversation struct that scrolls the conversation to center the current match,
+// which stops following the new content.
+func (c *Conversation) scrollToMatch() {
+	c.follow = false
+	c.viewport.SetYOffset(c.matches[c.current].start.line - c.viewport.Height/2)
+}
+
+// wrapCells is a function that wraps the lines of a rendered content wider than a width on the display cells, the
+// CJK characters and the emoji taking two cells, so the long inputs and code lines are not cut by the viewport.
+func wrapCells(content string, width int) string {
+	if width < 1 || lipgloss.Width(content) <= width {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if lipgloss.Width(line) > width {
+			lines[i] = wrap.String(line, width)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// refresh is a method on the Conversation struct that updates the viewport with the blocks and the pending answer,
+// the matches of the query searched being highlighted.
+func (c *Conversation) refresh() {
+	contents := make([]string, 0, len(c.blocks)+1)
+	for _, block := range c.blocks {
+		contents = append(contents, block.content)
+	}
+	if c.pending != "" {
+		contents = append(contents, c.pending)
+	}
+	raw := strings.Join(contents, "\n")
+	content := wrapCells(raw, c.viewport.Width)
+
+	c.matches = findMatches(raw, c.viewport.Width, c.query)
+	if c.current >= len(c.matches) {
+		c.current = 0
+	}
+	content = highlightMatches(content, c.matches, c.current)
+
+	c.viewport.SetContent(content)
+	if c.follow {
+		c.viewport.GotoBottom()
+	}
+}
@@ -0,0 +1,217 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/akhilsharma90/terminal-assistant/run"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUIConversation(t *testing.T) {
+	t.Run("Append", testConversationAppend)
+	t.Run("Pending", testConversationPending)
+	t.Run("Follow", testConversationFollow)
+	t.Run("Clear", testConversationClear)
+	t.Run("HideRestore", testConversationHideRestore)
+	t.Run("Rerender", testConversationRerender)
+	t.Run("WrapCells", testConversationWrapCells)
+	t.Run("Search", testConversationSearch)
+	t.Run("ToggleRaw", testConversationToggleRaw)
+}
+
+// appendBlocks is a helper appending numbered blocks to a conversation.
+func appendBlocks(c *Conversation, from int, to int) *Conversation {
+	for i := from; i <= to; i++ {
+		c.Append(fmt.Sprintf("block %d", i))
+	}
+
+	return c
+}
+
+// testConversationAppend tests the Append method of the Conversation struct.
+func testConversationAppend(t *testing.T) {
+	c := NewConversation(80, 10)
+	assert.True(t, c.IsEmpty(), "The conversation should be empty.")
+
+	appendBlocks(c, 1, 3)
+	assert.False(t, c.IsEmpty(), "The conversation should have content.")
+	view := c.View(5)
+	assert.Len(t, strings.Split(view, "\n"), 5, "The conversation should fill the height.")
+	assert.Contains(t, view, "block 3", "The conversation should display the blocks.")
+	assert.Empty(t, c.View(0), "The conversation should not be displayed without height.")
+}
+
+// testConversationPending tests the SetPending method of the Conversation struct.
+func testConversationPending(t *testing.T) {
+	c := appendBlocks(NewConversation(80, 10), 1, 1)
+	c.SetPending("streaming answer")
+	assert.Contains(t, c.View(5), "streaming answer", "The answer being streamed should be displayed.")
+
+	c.SetPending("").Append("complete answer")
+	assert.NotContains(t, c.View(5), "streaming answer", "The answer being streamed should be replaced.")
+	assert.Contains(t, c.View(5), "complete answer", "The complete answer should be displayed.")
+}
+
+// testConversationFollow tests that the conversation follows the new content unless it was scrolled up.
+func testConversationFollow(t *testing.T) {
+	c := appendBlocks(NewConversation(80, 10), 1, 50)
+	assert.Contains(t, c.View(10), "block 50", "The conversation should follow the new content.")
+
+	c, _ = c.Update(tea.KeyMsg{Type: tea.KeyPgUp})
+	assert.False(t, c.IsFollowing(), "The conversation should stop following when scrolled up.")
+	appendBlocks(c, 51, 55)
+	assert.NotContains(t, c.View(10), "block 55", "The conversation should stay scrolled up.")
+
+	for i := 0; i < 10; i++ {
+		c, _ = c.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	}
+	assert.True(t, c.IsFollowing(), "The conversation should follow again at the bottom.")
+	appendBlocks(c, 56, 60)
+	assert.Contains(t, c.View(10), "block 60", "The conversation should follow the new content again.")
+}
+
+// testConversationClear tests the Clear method of the Conversation struct.
+func testConversationClear(t *testing.T) {
+	c := appendBlocks(NewConversation(80, 10), 1, 3).SetPending("answer")
+	c.Clear()
+	assert.True(t, c.IsEmpty(), "The conversation should be empty.")
+	assert.NotContains(t, c.View(5), "block", "The conversation should not display the removed blocks.")
+}
+
+// testConversationHideRestore tests that the screens cleared by Hide are displayed again by Restore before the current
+// blocks, rendered again at the current width, and that Clear removes them.
+func testConversationHideRestore(t *testing.T) {
+	r := NewRenderer(glamour.WithStandardStyle("notty"), glamour.WithWordWrap(120))
+	c := appendBlocks(NewConversation(120, 50), 1, 2)
+	c.Hide()
+	assert.True(t, c.IsEmpty(), "The screen should be cleared.")
+	c.Hide()
+	assert.Equal(t, 1, c.GetClearedCount(), "An empty screen should not be kept.")
+
+	c.AppendMarkdown(strings.Repeat("wrap ", 40), "", r)
+	c.Hide()
+	appendBlocks(c, 3, 3)
+	assert.Equal(t, 2, c.GetClearedCount())
+
+	require.NoError(t, r.Resize(60))
+	c.Resize(60)
+	assert.Equal(t, 1, c.Restore(1, r), "The last screen should be restored.")
+	view := run.StripAnsi(c.View(50))
+	assert.NotContains(t, view, "block 1")
+	assert.Regexp(t, `(?s)wrap.*block 3`, view, "The screen should be restored before the current blocks.")
+	lines := 0
+	for _, line := range strings.Split(view, "\n") {
+		if strings.Contains(line, "wrap") {
+			lines++
+		}
+	}
+	assert.Equal(t, 4, lines, "The markdown should be wrapped at the current width.")
+
+	assert.Equal(t, 1, c.Restore(5, r), "Only the screens left should be restored.")
+	assert.Regexp(t, `(?s)block 1.*block 2.*wrap.*block 3`, run.StripAnsi(c.View(50)))
+	assert.Equal(t, 0, c.Restore(1, r))
+
+	c.Hide()
+	c.Clear()
+	assert.Equal(t, 0, c.GetClearedCount(), "The cleared screens should be removed with the conversation.")
+}
+
+// testConversationRerender tests that the markdown blocks are rendered again at the new width,
+// the rendered blocks being kept.
+func testConversationRerender(t *testing.T) {
+	r := NewRenderer(glamour.WithStandardStyle("notty"), glamour.WithWordWrap(120))
+	c := NewConversation(120, 50)
+	c.Append("plain block")
+	c.AppendMarkdown(strings.Repeat("wrap ", 40), "[metadata]\n", r)
+
+	countLines := func() int {
+		lines := 0
+		for _, line := range strings.Split(run.StripAnsi(c.View(50)), "\n") {
+			if strings.Contains(line, "wrap") {
+				lines++
+			}
+		}
+		return lines
+	}
+	assert.Equal(t, 2, countLines(), "The markdown should be wrapped at the width of the renderer.")
+
+	require.NoError(t, r.Resize(60))
+	c.Resize(60).Rerender(r)
+	assert.Equal(t, 4, countLines(), "The markdown should be wrapped at the new width.")
+	assert.Contains(t, c.View(50), "plain block", "The rendered blocks should be kept.")
+	assert.Contains(t, c.View(50), "[metadata]", "The suffix should be kept.")
+
+	c.AppendAnswer("  ┃ chat answer", "answer", "", r)
+	c.Rerender(r)
+	assert.Regexp(t, `┃ chat answer *\n  answer`, c.View(50), "The prefix should be kept before the rendered markdown.")
+}
+
+// testConversationWrapCells tests that the lines wider than the conversation are wrapped on the display cells instead
+// of being cut, the CJK characters and the emoji taking two cells.
+func testConversationWrapCells(t *testing.T) {
+	c := NewConversation(10, 10)
+	c.Append("\x1b[1m日本語のテキスト🎉🎉\x1b[0m")
+	c.SetPending("short")
+
+	view := c.View(10)
+	for _, line := range strings.Split(view, "\n") {
+		assert.LessOrEqual(t, lipgloss.Width(line), 10, "The lines should fit the conversation.")
+	}
+	assert.Equal(t, "日本語のテキスト🎉🎉short", strings.Join(strings.Fields(run.StripAnsi(view)), ""), "No character should be cut.")
+
+	assert.Equal(t, "ok", wrapCells("ok", 0))
+	assert.Equal(t, "日本語\nテキ", wrapCells("日本語テキ", 6))
+}
+
+// testConversationSearch tests that the matches of a query are highlighted, the conversation scrolling to the last one
+// and then to the next and previous ones.
+func testConversationSearch(t *testing.T) {
+	c := appendBlocks(NewConversation(80, 5), 1, 20)
+	c.View(5)
+
+	assert.Equal(t, 11, c.Search("BLOCK 1"), "The matches should ignore the case.")
+	assert.Equal(t, 10, c.GetCurrentMatch(), "The last match should be the current one.")
+	assert.False(t, c.IsFollowing(), "The conversation should stay on the match.")
+
+	c.NextMatch()
+	assert.Equal(t, 0, c.GetCurrentMatch(), "The first match should follow the last one.")
+	assert.Contains(t, run.StripAnsi(c.View(5)), "block 1\n", "The conversation should scroll to the match.")
+	c.PreviousMatch()
+	assert.Equal(t, 10, c.GetCurrentMatch(), "The last match should precede the first one.")
+
+	c.Append("block 1 again")
+	assert.Equal(t, 12, c.GetMatchCount(), "The new content should be searched.")
+
+	c.ClearSearch()
+	assert.Equal(t, 0, c.GetMatchCount())
+	assert.True(t, c.IsFollowing(), "The conversation should follow the new content again.")
+	assert.Equal(t, 0, c.Search("missing"))
+}
+
+// testConversationToggleRaw tests that the last answer is toggled between its rendered and raw markdown, and kept raw
+// when the conversation is rendered again.
+func testConversationToggleRaw(t *testing.T) {
+	r := NewRenderer(glamour.WithStandardStyle("notty"), glamour.WithWordWrap(80))
+	c := NewConversation(80, 20)
+	c.AppendMarkdown("**help**", "", r)
+	assert.False(t, c.ToggleRaw(r), "A markdown block which is not an answer should not be toggled.")
+
+	c.AppendAnswer("", "| a | b |\n|---|---|\n| 1 | 2 |", "", r)
+	c.Append("[ok]")
+	require.True(t, c.ToggleRaw(r))
+	assert.Equal(t, "```markdown\n| a | b |\n|---|---|\n| 1 | 2 |\n```\n", c.blocks[1].content, "The last answer should be raw.")
+	assert.Contains(t, c.View(20), "**help**", "The other blocks should be kept.")
+
+	c.Rerender(r)
+	assert.Contains(t, c.View(20), "| a | b |", "The answer should stay raw once rendered again.")
+
+	require.True(t, c.ToggleRaw(r))
+	assert.NotContains(t, c.View(20), "```markdown", "The answer should be rendered again.")
+}
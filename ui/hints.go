@@ -0,0 +1,28 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// keyHint is a struct that represents a key of the user interface and the action it triggers,
+// displayed in the hints under the prompt in the REPL mode.
+type keyHint struct {
+	key    string // The key, like "ctrl+c".
+	action string // The action triggered by the key, like "quit".
+}
+
+// String is a method on the keyHint struct that returns the key followed by its action, like "ctrl+c: quit".
+func (h keyHint) String() string {
+	return fmt.Sprintf("%s: %s", h.key, h.action)
+}
+
+// formatKeyHints is a function that joins key hints in a single line, like "tab: mode · ctrl+c: quit".
+func formatKeyHints(hints []keyHint) string {
+	formatted := make([]string, len(hints))
+	for i, hint := range hints {
+		formatted[i] = hint.String()
+	}
+
+	return strings.Join(formatted, status_bar_separator)
+}
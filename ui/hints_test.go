@@ -0,0 +1,18 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUIKeyHints(t *testing.T) {
+	t.Run("FormatKeyHints", testFormatKeyHints)
+}
+
+// testFormatKeyHints tests that the key hints are joined in a single line.
+func testFormatKeyHints(t *testing.T) {
+	assert.Equal(t, "tab: mode · ctrl+c: quit", formatKeyHints([]keyHint{{"tab", "mode"}, {"ctrl+c", "quit"}}))
+	assert.Equal(t, "ctrl+c: cancel", formatKeyHints([]keyHint{{"ctrl+c", "cancel"}}))
+	assert.Empty(t, formatKeyHints(nil), "No key should give an empty line.")
+}
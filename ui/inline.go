@@ -0,0 +1,180 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+)
+
+// inlineKind is the kind of a span of an inline markdown text.
+type inlineKind int
+
+// Kinds of the spans of an inline markdown text.
+const (
+	plainSpan inlineKind = iota
+	strongSpan
+	emphasisSpan
+	codeSpan
+)
+
+// inlineSpan is a span of an inline markdown text, without its delimiters.
+type inlineSpan struct {
+	kind inlineKind
+	text string
+}
+
+// markdownEscaped is the replacer escaping the characters delimiting the inline markdown spans.
+var markdownEscaped = strings.NewReplacer(`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`)
+
+// escapeMarkdown is a function that escapes the characters of a text delimiting the inline markdown spans, like a
+// command or a path, so it is rendered verbatim in a help message.
+func escapeMarkdown(text string) string {
+	return markdownEscaped.Replace(text)
+}
+
+// parseInlineMarkdown is a function that splits an inline markdown text into its plain, strong (**text**), emphasized
+// (*text* or _text_) and code (`text`) spans, like the help messages. The backslash escapes the punctuation, the
+// delimiters without a closing one are kept as is, and the spans are not nested.
+func parseInlineMarkdown(markdown string) []inlineSpan {
+	var spans []inlineSpan
+	var plain strings.Builder
+	add := func(kind inlineKind, text string) {
+		if plain.Len() > 0 {
+			spans = append(spans, inlineSpan{kind: plainSpan, text: plain.String()})
+			plain.Reset()
+		}
+		spans = append(spans, inlineSpan{kind: kind, text: text})
+	}
+
+	runes := []rune(markdown)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case isEscape(runes, i):
+			i++
+			plain.WriteRune(runes[i])
+		case r == '`':
+			fence := runLength(runes, i, '`')
+			if end := findFence(runes, i+fence, fence); end >= 0 {
+				add(codeSpan, trimCodeSpan(string(runes[i+fence:end])))
+				i = end + fence - 1
+				continue
+			}
+			plain.WriteString(string(runes[i : i+fence]))
+			i += fence - 1
+		case r == '*' && i+1 < len(runes) && runes[i+1] == '*':
+			if end := findClosing(runes, i+2, "**"); end >= 0 && opensSpan(runes, i+2) {
+				add(strongSpan, unescapeMarkdown(string(runes[i+2:end])))
+				i = end + 1
+				continue
+			}
+			plain.WriteString("**")
+			i++
+		case r == '*' || r == '_':
+			if end := findClosing(runes, i+1, string(r)); end >= 0 && opensSpan(runes, i+1) && (r == '*' || outsideWords(runes, i, end)) {
+				add(emphasisSpan, unescapeMarkdown(string(runes[i+1:end])))
+				i = end
+				continue
+			}
+			plain.WriteRune(r)
+		default:
+			plain.WriteRune(r)
+		}
+	}
+	if plain.Len() > 0 {
+		spans = append(spans, inlineSpan{kind: plainSpan, text: plain.String()})
+	}
+
+	return spans
+}
+
+// runLength is a function that returns the number of consecutive runes equal to a rune from a position.
+func runLength(runes []rune, start int, r rune) int {
+	length := 0
+	for start+length < len(runes) && runes[start+length] == r {
+		length++
+	}
+
+	return length
+}
+
+// findFence is a function that returns the position of the next run of backticks of a length, or -1.
+func findFence(runes []rune, start int, length int) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] != '`' {
+			continue
+		}
+		run := runLength(runes, i, '`')
+		if run == length {
+			return i
+		}
+		i += run - 1
+	}
+
+	return -1
+}
+
+// trimCodeSpan is a function that removes the space padding a code span on both sides, like around a backtick.
+func trimCodeSpan(code string) string {
+	if len(code) > 2 && strings.HasPrefix(code, " ") && strings.HasSuffix(code, " ") && strings.TrimSpace(code) != "" {
+		return code[1 : len(code)-1]
+	}
+
+	return code
+}
+
+// findClosing is a function that returns the position of the next unescaped closing delimiter, following a non-space
+// rune, or -1.
+func findClosing(runes []rune, start int, delimiter string) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(string(runes[i:]), delimiter) && i > start && !unicode.IsSpace(runes[i-1]) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// opensSpan is a function that checks if the rune following an opening delimiter starts a span, not being a space.
+func opensSpan(runes []rune, start int) bool {
+	return start < len(runes) && !unicode.IsSpace(runes[start])
+}
+
+// outsideWords is a function that checks if the underscores delimiting a span are not inside words, unlike in
+// a snake_case_name, so they delimit an emphasis.
+func outsideWords(runes []rune, start int, end int) bool {
+	before := start == 0 || !isWordRune(runes[start-1])
+	after := end+1 >= len(runes) || !isWordRune(runes[end+1])
+
+	return before && after
+}
+
+// isWordRune is a function that checks if a rune is a letter or a digit.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// isEscape is a function that checks if the rune at a position is a backslash escaping a punctuation character.
+func isEscape(runes []rune, i int) bool {
+	return runes[i] == '\\' && i+1 < len(runes) && (unicode.IsPunct(runes[i+1]) || unicode.IsSymbol(runes[i+1]))
+}
+
+// unescapeMarkdown is a function that removes the backslashes escaping the punctuation of a span.
+func unescapeMarkdown(text string) string {
+	if !strings.Contains(text, `\`) {
+		return text
+	}
+
+	var unescaped strings.Builder
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		if isEscape(runes, i) {
+			i++
+		}
+		unescaped.WriteRune(runes[i])
+	}
+
+	return unescaped.String()
+}
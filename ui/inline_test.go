@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInlineMarkdown(t *testing.T) {
+	t.Run("ParseInlineMarkdown", testParseInlineMarkdown)
+	t.Run("EscapeMarkdown", testEscapeMarkdown)
+}
+
+// testParseInlineMarkdown tests that the strong, emphasized and code spans of an inline markdown text are parsed,
+// the unclosed and the intraword delimiters being kept as is.
+func testParseInlineMarkdown(t *testing.T) {
+	testCases := []struct {
+		name     string
+		markdown string
+		expected []inlineSpan
+	}{
+		{"Plain", "press enter", []inlineSpan{{plainSpan, "press enter"}}},
+		{"Empty", "", nil},
+		{"Strong", "press **ctrl+h** for help", []inlineSpan{{plainSpan, "press "}, {strongSpan, "ctrl+h"}, {plainSpan, " for help"}}},
+		{"Emphasis", "*tab* or _tab_", []inlineSpan{{emphasisSpan, "tab"}, {plainSpan, " or "}, {emphasisSpan, "tab"}}},
+		{"Code", "switch to `exec`", []inlineSpan{{plainSpan, "switch to "}, {codeSpan, "exec"}}},
+		{"CodeBackticks", "run `` echo `date` ``", []inlineSpan{{plainSpan, "run "}, {codeSpan, "echo `date`"}}},
+		{"CodeNotParsed", "`**not strong**`", []inlineSpan{{codeSpan, "**not strong**"}}},
+		{"Unclosed", "a **b and `c", []inlineSpan{{plainSpan, "a **b and `c"}}},
+		{"Spaces", "rm *.log && ls * here", []inlineSpan{{plainSpan, "rm *.log && ls * here"}}},
+		{"Intraword", "user_editing_mode", []inlineSpan{{plainSpan, "user_editing_mode"}}},
+		{"Escaped", `\*\*not strong\*\* \_x\_`, []inlineSpan{{plainSpan, "**not strong** _x_"}}},
+		{"WindowsPath", `C:\Users\me`, []inlineSpan{{plainSpan, `C:\Users\me`}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, parseInlineMarkdown(tc.markdown))
+		})
+	}
+}
+
+// testEscapeMarkdown tests that an escaped text is parsed verbatim.
+func testEscapeMarkdown(t *testing.T) {
+	for _, text := range []string{"rm *.log *.tmp", "~/my_notes/_draft_.md", "echo `date` **", `C:\tmp\*`} {
+		assert.Equal(t, []inlineSpan{{plainSpan, text}}, parseInlineMarkdown(escapeMarkdown(text)), text)
+	}
+}
@@ -1,32 +1,118 @@
 package ui
 
 import (
-	"bufio"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"golang.org/x/term"
+)
+
+// Formats of the answer written by the CLI mode without the terminal user interface.
+const (
+	output_format_text = "text"
+	output_format_json = "json"
 )
 
+// pipe_read_chunk_size is the size of the chunks the piped input is read by, its progress being reported after each.
+const pipe_read_chunk_size = 64 * 1024
+
+// pipe_truncation_marker is the marker replacing the middle of the truncated pipes, telling how many bytes were truncated.
+const pipe_truncation_marker = "\n[... %d bytes truncated ...]\n"
+
 type UiInput struct {
-	runMode    RunMode
-	promptMode PromptMode
-	args       string
-	pipe       string
+	runMode     RunMode
+	promptMode  PromptMode
+	args        string
+	pipe        string
+	stdin       io.Reader
+	keepJobs    bool
+	mouse       bool
+	images      []string
+	yes         bool
+	plain       bool
+	output      string
+	codeOnly    bool
+	force       bool
+	format      string
+	completions string
+	style       string
+	noColor     bool
 }
 
-// NewUIInput is a function that creates a new UiInput instance.
-func NewUIInput() (*UiInput, error) {
-	// Create a new flag set with the application's name and an error handling.
+// stringsFlag is a flag that can be repeated, every value being kept.
+type stringsFlag []string
+
+// String is a method that returns the values of the flag separated by commas.
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+// Set is a method that adds a value to the flag.
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+
+	return nil
+}
+
+// uiFlags is a struct that holds the values of the command-line flags.
+type uiFlags struct {
+	exec        bool
+	chat        bool
+	keepJobs    bool
+	noColor     bool
+	noMouse     bool
+	yes         bool
+	quiet       bool
+	output      string
+	codeOnly    bool
+	force       bool
+	format      string
+	completions string
+	images      stringsFlag
+	style       string
+}
+
+// register is a method that registers the command-line flags with a flag set.
+func (f *uiFlags) register(flagSet *flag.FlagSet) {
+	flagSet.BoolVar(&f.exec, "e", false, "exec prompt mode")
+	flagSet.BoolVar(&f.chat, "c", false, "chat prompt mode")
+	flagSet.BoolVar(&f.keepJobs, "keep-jobs", false, "keep background jobs running on exit")
+	flagSet.BoolVar(&f.noColor, "no-color", false, "disable colors, like the NO_COLOR environment variable")
+	flagSet.BoolVar(&f.noMouse, "no-mouse", false, "disable the mouse support")
+	flagSet.BoolVar(&f.yes, "yes", false, "execute the generated command when the output is redirected")
+	flagSet.BoolVar(&f.quiet, "quiet", false, "print only the answer, like when the output is redirected")
+	flagSet.StringVar(&f.output, "o", "", "write the answer, or the generated command, to a file")
+	flagSet.StringVar(&f.output, "output", "", "write the answer, or the generated command, to a file")
+	flagSet.BoolVar(&f.codeOnly, "code-only", false, "write only the first code block of the answer with --output")
+	flagSet.BoolVar(&f.force, "force", false, "overwrite the existing file with --output")
+	flagSet.StringVar(&f.format, "format", output_format_text, "format of the answer without the user interface, text or json")
+	flagSet.StringVar(&f.completions, "completions", "", "print the completion script of a shell, bash, zsh, fish or powershell")
+	flagSet.Var(&f.images, "image", "attach an image to the first message, can be repeated")
+	flagSet.StringVar(&f.style, "style", "", "style of the markdown content for this run, auto, dark, light or the path of a glamour style")
+}
+
+// NewFlagSet is a function that returns a flag set with the command-line flags of the program, like to complete them.
+func NewFlagSet() *flag.FlagSet {
 	flagSet := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	(&uiFlags{}).register(flagSet)
 
-	// Declare boolean variables for the exec and chat flags.
-	var exec, chat bool
+	return flagSet
+}
 
-	// Register the exec and chat flags with the flag set.
-	flagSet.BoolVar(&exec, "e", false, "exec prompt mode")
-	flagSet.BoolVar(&chat, "c", false, "chat prompt mode")
+// NewUIInput is a function that creates a new UiInput instance.
+func NewUIInput() (*UiInput, error) {
+	// Create a new flag set with the application's name and an error handling, and register the flags with it.
+	flagSet := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	var flags uiFlags
+	flags.register(flagSet)
 
 	// Parse the command-line arguments starting from the second argument.
 	err := flagSet.Parse(os.Args[1:])
@@ -35,6 +121,22 @@ func NewUIInput() (*UiInput, error) {
 		return nil, err
 	}
 
+	// Print only the completion script, without the user interface
+	if flags.completions != "" {
+		return &UiInput{completions: flags.completions}, nil
+	}
+
+	if flags.format != output_format_text && flags.format != output_format_json {
+		err := fmt.Errorf("unknown format %q, use text or json", flags.format)
+		fmt.Println("Error parsing flags:", err)
+		return nil, err
+	}
+
+	// Disable the colors of the styles of every component, the environment of the commands being left unchanged
+	if flags.noColor {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+
 	args := flagSet.Args()
 
 	// Get the file info for the standard input.
@@ -44,32 +146,11 @@ func NewUIInput() (*UiInput, error) {
 		return nil, err
 	}
 
-	// Declare a variable for the pipe input.
-	pipe := ""
-	// Check if the standard input is not a named pipe and is empty.
+	// Keep the standard input to read it later if it is not a named pipe and is empty, so a large input is read
+	// while the progress is displayed instead of before the program starts.
+	var stdin io.Reader
 	if !(stat.Mode()&os.ModeNamedPipe == 0 && stat.Size() == 0) {
-		// Create a new reader for the standard input.
-		reader := bufio.NewReader(os.Stdin)
-		// Create a new string builder.
-		var builder strings.Builder
-
-		// Read runes from the reader until EOF is reached.
-		for {
-			r, _, err := reader.ReadRune()
-			if err != nil && err == io.EOF {
-				break
-			}
-			// Write the rune to the string builder.
-			_, err = builder.WriteRune(r)
-			if err != nil {
-				// Print an error message and return if there is an error writing the rune.
-				fmt.Println("Error getting input:", err)
-				return nil, err
-			}
-		}
-
-		// Trim the whitespace from the string builder's string and assign it to the pipe variable.
-		pipe = strings.TrimSpace(builder.String())
+		stdin = os.Stdin
 	}
 
 	// Set the run mode to REPL mode by default.
@@ -81,18 +162,32 @@ func NewUIInput() (*UiInput, error) {
 
 	// Set the prompt mode to default mode by default.
 	promptMode := DefaultPromptMode
-	if exec && !chat {
+	if flags.exec && !flags.chat {
 		promptMode = ExecPromptMode
-	} else if !exec && chat {
+	} else if !flags.exec && flags.chat {
 		promptMode = ChatPromptMode
 	}
 
-	// Return a new UiInput instance with the run mode, prompt mode, arguments, and pipe input.
+	// Return a new UiInput instance with the run mode, prompt mode, arguments, and the standard input to read.
 	return &UiInput{
 		runMode:    runMode,
 		promptMode: promptMode,
 		args:       strings.Join(args, " "),
-		pipe:       pipe,
+		stdin:      stdin,
+		keepJobs:   flags.keepJobs,
+		mouse:      !flags.noMouse,
+		images:     flags.images,
+		yes:        flags.yes,
+		// Write only the answer as plain text when asked, saved to a file, formatted as JSON or when the output of the CLI
+		// mode is redirected
+		plain:    runMode == CliMode && (flags.quiet || flags.output != "" || flags.format == output_format_json || !term.IsTerminal(int(os.Stdout.Fd()))),
+		output:   flags.output,
+		codeOnly: flags.codeOnly,
+		force:    flags.force,
+		format:   flags.format,
+		style:    flags.style,
+		// Disable the colors like the NO_COLOR environment variable, without changing the environment of the commands
+		noColor: flags.noColor,
 	}, nil
 }
 
@@ -111,7 +206,150 @@ func (i *UiInput) GetArgs() string {
 	return i.args
 }
 
-// GetPipe is a method that returns the pipe input of the UiInput instance.
+// GetPipe is a method that returns the pipe input of the UiInput instance, empty until it is read by ReadPipe.
 func (i *UiInput) GetPipe() string {
 	return i.pipe
 }
+
+// ReadPipe is a method that reads the piped standard input once, stopping when the context is cancelled, and
+// returns it without its surrounding whitespaces. The number of bytes read so far is reported to the progress
+// function, if any.
+func (i *UiInput) ReadPipe(ctx context.Context, progress func(int)) (string, error) {
+	if i.stdin == nil {
+		return i.pipe, nil
+	}
+
+	pipe, err := readPipe(ctx, i.stdin, progress)
+	i.stdin = nil
+	if err != nil {
+		return "", err
+	}
+	i.pipe = pipe
+
+	return pipe, nil
+}
+
+// GetKeepJobs is a method that returns whether the background jobs should keep running on exit.
+func (i *UiInput) GetKeepJobs() bool {
+	return i.keepJobs
+}
+
+// GetMouseEnabled is a method that returns whether the mouse can be used to scroll the viewport.
+func (i *UiInput) GetMouseEnabled() bool {
+	return i.mouse
+}
+
+// GetImages is a method that returns the paths of the images attached to the first message.
+func (i *UiInput) GetImages() []string {
+	return i.images
+}
+
+// GetYes is a method that returns whether the generated command is executed when the output is redirected.
+func (i *UiInput) GetYes() bool {
+	return i.yes
+}
+
+// GetPlainOutput is a method that returns whether only the answer is written as plain text, without the terminal
+// user interface, because the quiet mode was asked or the output of the CLI mode is redirected.
+func (i *UiInput) GetPlainOutput() bool {
+	return i.plain
+}
+
+// GetOutput is a method that returns the path of the file the answer, or the generated command, is written to,
+// empty to write it to the output.
+func (i *UiInput) GetOutput() string {
+	return i.output
+}
+
+// GetCodeOnly is a method that returns whether only the first code block of the answer is written to the file.
+func (i *UiInput) GetCodeOnly() bool {
+	return i.codeOnly
+}
+
+// GetForce is a method that returns whether an existing file is overwritten by the answer.
+func (i *UiInput) GetForce() bool {
+	return i.force
+}
+
+// GetFormat is a method that returns the format of the answer written without the terminal user interface,
+// "text" or "json".
+func (i *UiInput) GetFormat() string {
+	return i.format
+}
+
+// GetCompletions is a method that returns the shell whose completion script is printed instead of running the
+// program, empty if none.
+func (i *UiInput) GetCompletions() string {
+	return i.completions
+}
+
+// GetStyle is a method that returns the style of the markdown content overriding the configured one, empty if none.
+func (i *UiInput) GetStyle() string {
+	return i.style
+}
+
+// GetNoColor is a method that returns whether the colors are disabled by the --no-color flag or the NO_COLOR
+// environment variable.
+func (i *UiInput) GetNoColor() bool {
+	return i.noColor || IsNoColor()
+}
+
+// readPipe is a function that reads a piped input by chunks until its end, reporting the number of bytes read so far
+// to the progress function, if any, after each chunk. The reading stops when the context is cancelled.
+func readPipe(ctx context.Context, reader io.Reader, progress func(int)) (string, error) {
+	var builder strings.Builder
+	chunk := make([]byte, pipe_read_chunk_size)
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		n, err := reader.Read(chunk)
+		builder.Write(chunk[:n])
+		if n > 0 && progress != nil {
+			progress(builder.Len())
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return strings.TrimSpace(builder.String()), nil
+}
+
+// truncatePipe is a function that limits the size of a piped input to a maximum size in bytes, keeping its start
+// and its end around a marker telling how many bytes were truncated. The size is unlimited if the maximum is not
+// positive, and only the start is kept if the maximum is too small for the marker.
+func truncatePipe(pipe string, max int) string {
+	if max <= 0 || len(pipe) <= max {
+		return pipe
+	}
+
+	// Keep room for the marker, the number of truncated bytes having at most the digits of the size of the input
+	budget := max - len(fmt.Sprintf(pipe_truncation_marker, len(pipe)))
+	if budget < 2 {
+		return pipe[:runeStartBefore(pipe, max)]
+	}
+
+	// Cut on rune boundaries to keep the input valid UTF-8
+	head := runeStartBefore(pipe, budget/2)
+	tail := len(pipe) - (budget - budget/2)
+	for tail < len(pipe) && !utf8.RuneStart(pipe[tail]) {
+		tail++
+	}
+
+	return pipe[:head] + fmt.Sprintf(pipe_truncation_marker, tail-head) + pipe[tail:]
+}
+
+// runeStartBefore is a function that returns the start of the rune of a text at an index,
+// so the text can be cut there without splitting a rune.
+func runeStartBefore(text string, index int) int {
+	for index > 0 && !utf8.RuneStart(text[index]) {
+		index--
+	}
+
+	return index
+}
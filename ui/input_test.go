@@ -1,9 +1,14 @@
 package ui
 
 import (
+	"context"
 	"os"
+	"strings"
 	"testing"
+	"unicode/utf8"
 
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -13,6 +18,14 @@ func TestUiInput(t *testing.T) {
 	t.Run("GetRunMode", testGetRunMode)
 	t.Run("GetPromptMode", testGetPromptMode)
 	t.Run("GetArgs", testGetArgs)
+	t.Run("GetKeepJobs", testGetKeepJobs)
+	t.Run("NoColor", testNoColor)
+	t.Run("GetMouseEnabled", testGetMouseEnabled)
+	t.Run("GetImages", testGetImages)
+	t.Run("GetYes", testGetYes)
+	t.Run("GetCompletions", testGetCompletions)
+	t.Run("TruncatePipe", testTruncatePipe)
+	t.Run("ReadPipe", testReadPipe)
 }
 
 // testNewUIInput is a unit test function that tests the NewUIInput function.
@@ -60,3 +73,140 @@ func testGetArgs(t *testing.T) {
 	uiInput, _ := NewUIInput()
 	assert.Equal(t, "arg1 arg2", uiInput.GetArgs(), "Args should be 'arg1 arg2'.")
 }
+
+// testGetKeepJobs is a unit test function that tests the GetKeepJobs method of the UIInput struct.
+func testGetKeepJobs(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+
+	os.Args = []string{"cmd", "--keep-jobs"}
+	uiInput, _ := NewUIInput()
+	assert.True(t, uiInput.GetKeepJobs(), "KeepJobs should be true.")
+
+	os.Args = []string{"cmd"}
+	uiInput, _ = NewUIInput()
+	assert.False(t, uiInput.GetKeepJobs(), "KeepJobs should be false.")
+}
+
+// testNoColor is a unit test function that tests that the --no-color flag disables the colors, without setting the
+// NO_COLOR environment variable inherited by the commands.
+func testNoColor(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+	profile := lipgloss.ColorProfile()
+	t.Cleanup(func() { lipgloss.SetColorProfile(profile) })
+
+	t.Setenv("NO_COLOR", "")
+
+	os.Args = []string{"cmd"}
+	uiInput, _ := NewUIInput()
+	assert.False(t, uiInput.GetNoColor(), "The colors should be enabled.")
+
+	os.Args = []string{"cmd", "--no-color"}
+	uiInput, _ = NewUIInput()
+	assert.True(t, uiInput.GetNoColor(), "The colors should be disabled.")
+	assert.Empty(t, os.Getenv("NO_COLOR"), "The environment of the commands should not be changed.")
+	assert.Equal(t, termenv.Ascii, lipgloss.ColorProfile(), "The styles of the components should not be colored.")
+
+	assert.True(t, NewUi(uiInput).components.renderer.noColor, "The renderer should not be colored.")
+}
+
+// testGetMouseEnabled is a unit test function that tests the GetMouseEnabled method of the UIInput struct.
+func testGetMouseEnabled(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+
+	os.Args = []string{"cmd"}
+	uiInput, _ := NewUIInput()
+	assert.True(t, uiInput.GetMouseEnabled(), "The mouse should be enabled by default.")
+
+	os.Args = []string{"cmd", "--no-mouse"}
+	uiInput, _ = NewUIInput()
+	assert.False(t, uiInput.GetMouseEnabled(), "The mouse should be disabled.")
+}
+
+// testGetImages is a unit test function that tests that the --image flag can be repeated.
+func testGetImages(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+
+	os.Args = []string{"cmd", "--image", "a.png", "--image", "b.png", "what is this?"}
+	uiInput, _ := NewUIInput()
+	assert.Equal(t, []string{"a.png", "b.png"}, uiInput.GetImages(), "Images should be a.png and b.png.")
+	assert.Equal(t, "what is this?", uiInput.GetArgs(), "Args should be the question.")
+}
+
+// testGetYes is a unit test function that tests the GetYes and GetPlainOutput methods of the UIInput struct.
+// It verifies that the --yes flag is parsed and that the REPL mode never writes plain text.
+func testGetYes(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+
+	os.Args = []string{"cmd", "--yes", "list files"}
+	uiInput, _ := NewUIInput()
+	assert.True(t, uiInput.GetYes(), "Yes should be true.")
+
+	os.Args = []string{"cmd"}
+	uiInput, _ = NewUIInput()
+	assert.False(t, uiInput.GetYes(), "Yes should be false by default.")
+	assert.False(t, uiInput.GetPlainOutput(), "The REPL mode should not write plain text.")
+}
+
+// testGetCompletions is a unit test function that tests the GetCompletions method of the UIInput struct.
+// It verifies that the shell of --completions is returned, the other flags and arguments being ignored.
+func testGetCompletions(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+
+	os.Args = []string{"cmd", "--completions", "zsh"}
+	uiInput, err := NewUIInput()
+	assert.NoError(t, err, "NewUIInput should not return an error.")
+	assert.Equal(t, "zsh", uiInput.GetCompletions(), "Completions should be the shell.")
+
+	os.Args = []string{"cmd"}
+	uiInput, _ = NewUIInput()
+	assert.Empty(t, uiInput.GetCompletions(), "Completions should be empty by default.")
+}
+
+// testTruncatePipe is a unit test function that tests the truncatePipe function.
+// It verifies that the small inputs are kept and that the large inputs keep their start and their end around a marker,
+// without exceeding the maximum size or splitting a rune.
+func testTruncatePipe(t *testing.T) {
+	assert.Equal(t, "small input", truncatePipe("small input", 1024), "Small inputs should be kept.")
+
+	pipe := "start" + strings.Repeat("é", 4096) + "end"
+	assert.Equal(t, pipe, truncatePipe(pipe, 0), "The inputs should be kept without maximum.")
+
+	truncated := truncatePipe(pipe, 1024)
+	assert.LessOrEqual(t, len(truncated), 1024, "Large inputs should be truncated to the maximum.")
+	assert.True(t, strings.HasPrefix(truncated, "start"), "The start of the input should be kept.")
+	assert.True(t, strings.HasSuffix(truncated, "end"), "The end of the input should be kept.")
+	assert.Contains(t, truncated, "bytes truncated ...]", "The truncation should be marked.")
+	assert.True(t, utf8.ValidString(truncated), "The truncated input should be valid UTF-8.")
+
+	truncated = truncatePipe(pipe, 10)
+	assert.Equal(t, "startéé", truncated, "Only the start should be kept when the marker does not fit.")
+}
+
+// testReadPipe tests that the piped input is read once without its surrounding whitespaces, the bytes read so far
+// being reported, and that the reading stops when the context is cancelled.
+func testReadPipe(t *testing.T) {
+	input := &UiInput{stdin: strings.NewReader("  " + strings.Repeat("x", pipe_read_chunk_size) + "\n")}
+	var progress []int
+	pipe, err := input.ReadPipe(context.Background(), func(bytes int) {
+		progress = append(progress, bytes)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Repeat("x", pipe_read_chunk_size), pipe)
+	assert.Equal(t, []int{pipe_read_chunk_size, pipe_read_chunk_size + 3}, progress, "The bytes read should be reported after each chunk.")
+	assert.Equal(t, pipe, input.GetPipe())
+
+	pipe, err = input.ReadPipe(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Repeat("x", pipe_read_chunk_size), pipe, "The piped input should be read once.")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = (&UiInput{stdin: strings.NewReader("input")}).ReadPipe(ctx, nil)
+	assert.ErrorIs(t, err, context.Canceled, "The reading should stop when the context is cancelled.")
+}
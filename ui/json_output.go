@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/akhilsharma90/terminal-assistant/ai"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// jsonUsage is the usage of the tokens in the JSON output of the CLI mode.
+type jsonUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// jsonExecOutput is the JSON output of the CLI mode in the exec prompt mode.
+type jsonExecOutput struct {
+	Command     string    `json:"command"`
+	Explanation string    `json:"explanation"`
+	Executable  bool      `json:"executable"`
+	Model       string    `json:"model"`
+	Usage       jsonUsage `json:"usage"`
+}
+
+// jsonChatOutput is the JSON output of the CLI mode in the chat prompt mode.
+type jsonChatOutput struct {
+	Content string    `json:"content"`
+	Model   string    `json:"model"`
+	Usage   jsonUsage `json:"usage"`
+}
+
+// jsonErrorOutput is the JSON output of the CLI mode when an error occurs.
+type jsonErrorOutput struct {
+	Error jsonError `json:"error"`
+}
+
+// jsonError is the error of the JSON output, with the type and the HTTP status of the errors of the OpenAI API.
+type jsonError struct {
+	Message string `json:"message"`
+	Type    string `json:"type,omitempty"`
+	Status  int    `json:"status,omitempty"`
+}
+
+// newJSONUsage is a function that creates the JSON usage of the tokens used by an engine.
+func newJSONUsage(usage ai.EngineUsage) jsonUsage {
+	return jsonUsage{
+		PromptTokens:     usage.GetPromptTokens(),
+		CompletionTokens: usage.GetCompletionTokens(),
+		TotalTokens:      usage.GetTotalTokens(),
+	}
+}
+
+// newJSONError is a function that creates the JSON output of an error, telling the type and the HTTP status of
+// the errors of the OpenAI API.
+func newJSONError(err error) jsonErrorOutput {
+	output := jsonErrorOutput{Error: jsonError{Message: err.Error()}}
+
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		output.Error.Message = apiErr.Message
+		output.Error.Type = apiErr.Type
+		output.Error.Status = apiErr.HTTPStatusCode
+	}
+
+	return output
+}
+
+// formatJSON is a function that formats an output of the CLI mode as an indented JSON document ending with a newline.
+func formatJSON(output interface{}) string {
+	content, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		// The outputs are plain structs, this cannot happen
+		return "{}\n"
+	}
+
+	return string(content) + "\n"
+}
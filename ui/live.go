@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/akhilsharma90/terminal-assistant/run"
+)
+
+// live_help is the help displayed under the live output.
+const live_help = "pgup/pgdn: scroll · ctrl+c: interrupt"
+
+// live_max_lines is the maximum number of lines kept in the live output, the oldest ones being dropped.
+const live_max_lines = 1000
+
+// LiveOutput is a struct that represents the scrollable tail of the output of the command being executed.
+type LiveOutput struct {
+	lines    []string       // The last lines written by the command.
+	dropped  int            // The number of lines dropped from the top of the live output.
+	height   int            // The maximum height of the live output, including its help.
+	follow   bool           // Whether the live output sticks to its bottom when new lines are added.
+	viewport viewport.Model // The viewport model.
+}
+
+// NewLiveOutput is a function that creates a new LiveOutput instance.
+func NewLiveOutput(width int, height int) *LiveOutput {
+	return &LiveOutput{
+		height:   height,
+		follow:   true,
+		viewport: viewport.New(width, viewportHeight(height)),
+	}
+}
+
+// Append is a method on the LiveOutput struct that adds the lines of a progress message to the live output,
+// without their ANSI escape sequences.
+func (l *LiveOutput) Append(msg run.RunProgressMsg) *LiveOutput {
+	l.dropped += msg.GetDropped()
+	for _, line := range msg.GetLines() {
+		l.lines = append(l.lines, strings.TrimRight(run.StripAnsi(line), "\r"))
+	}
+	if excess := len(l.lines) - live_max_lines; excess > 0 {
+		l.lines = l.lines[excess:]
+		l.dropped += excess
+	}
+
+	l.refresh()
+
+	return l
+}
+
+// Reset is a method on the LiveOutput struct that clears the live output.
+func (l *LiveOutput) Reset() *LiveOutput {
+	l.lines = nil
+	l.dropped = 0
+	l.follow = true
+	l.refresh()
+
+	return l
+}
+
+// HasLines is a method on the LiveOutput struct that returns whether the command wrote any line yet.
+func (l *LiveOutput) HasLines() bool {
+	return len(l.lines) > 0 || l.dropped > 0
+}
+
+// Resize is a method on the LiveOutput struct that sets the dimensions of the live output.
+func (l *LiveOutput) Resize(width int, height int) *LiveOutput {
+	l.height = height
+	l.viewport.Width = width
+	l.refresh()
+
+	return l
+}
+
+// Update is a method on the LiveOutput struct that scrolls the live output with a message. The live output stops
+// following the new lines when it is scrolled up, and follows them again when it is scrolled back to the bottom.
+func (l *LiveOutput) Update(msg tea.Msg) (*LiveOutput, tea.Cmd) {
+	var updateCmd tea.Cmd
+	l.viewport, updateCmd = l.viewport.Update(msg)
+	l.follow = l.viewport.AtBottom()
+
+	return l, updateCmd
+}
+
+// View is a method on the LiveOutput struct that returns a string representation of the live output and its help.
+func (l *LiveOutput) View(renderer *Renderer) string {
+	help := live_help
+	if l.dropped > 0 {
+		help = fmt.Sprintf("[… %d earlier lines] · %s", l.dropped, help)
+	}
+
+	return fmt.Sprintf("%s\n%s", l.viewport.View(), renderer.RenderHelp(help))
+}
+
+// refresh is a method on the LiveOutput struct that updates the viewport with the lines, its height growing
+// with the lines up to the maximum height.
+func (l *LiveOutput) refresh() {
+	l.viewport.Height = viewportHeight(l.height)
+	if len(l.lines) < l.viewport.Height {
+		l.viewport.Height = len(l.lines)
+	}
+	if l.viewport.Height < 1 {
+		l.viewport.Height = 1
+	}
+
+	l.viewport.SetContent(strings.Join(l.lines, "\n"))
+	if l.follow {
+		l.viewport.GotoBottom()
+	}
+}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/akhilsharma90/terminal-assistant/run"
+)
+
+func TestUILiveOutput(t *testing.T) {
+	t.Run("Append", testLiveOutputAppend)
+	t.Run("Follow", testLiveOutputFollow)
+	t.Run("Reset", testLiveOutputReset)
+}
+
+// appendLines is a helper appending numbered lines to a live output, streamed by RunStreamed.
+func appendLines(t *testing.T, l *LiveOutput, from int, to int) *LiveOutput {
+	progress := make(chan run.RunProgressMsg)
+	go func() {
+		_, _, _, err := run.RunStreamed(context.Background(), fmt.Sprintf("seq %d %d", from, to), progress)
+		assert.NoError(t, err)
+	}()
+
+	for msg := range progress {
+		l.Append(msg)
+	}
+
+	return l
+}
+
+// testLiveOutputAppend tests the Append method of the LiveOutput struct.
+func testLiveOutputAppend(t *testing.T) {
+	r := NewRenderer(glamour.WithAutoStyle())
+	l := NewLiveOutput(80, 10)
+	assert.False(t, l.HasLines(), "The live output should be empty.")
+
+	appendLines(t, l, 1, 3)
+	assert.True(t, l.HasLines(), "The live output should have lines.")
+	assert.Contains(t, l.View(r), "3", "The live output should display the lines.")
+	assert.Contains(t, l.View(r), live_help, "The live output should display its help.")
+
+	appendLines(t, l, 1, live_max_lines+10)
+	assert.Contains(t, l.View(r), "[… 13 earlier lines]", "The dropped lines should be counted.")
+}
+
+// testLiveOutputFollow tests the scrolling of the LiveOutput struct.
+func testLiveOutputFollow(t *testing.T) {
+	r := NewRenderer(glamour.WithAutoStyle())
+	l := appendLines(t, NewLiveOutput(80, 10), 1, 50)
+	assert.Contains(t, l.View(r), "50", "The live output should follow the last lines.")
+
+	l, _ = l.Update(tea.KeyMsg{Type: tea.KeyPgUp})
+	appendLines(t, l, 51, 60)
+	assert.NotContains(t, l.View(r), "60", "The live output should stay scrolled up.")
+
+	for i := 0; i < 10; i++ {
+		l, _ = l.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	}
+	appendLines(t, l, 61, 70)
+	assert.Contains(t, l.View(r), "70", "The live output should follow the last lines again.")
+}
+
+// testLiveOutputReset tests the Reset method of the LiveOutput struct.
+func testLiveOutputReset(t *testing.T) {
+	l := appendLines(t, NewLiveOutput(80, 10), 1, 3)
+	l.Reset()
+	assert.False(t, l.HasLines(), "The live output should be empty.")
+}
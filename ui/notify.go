@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/akhilsharma90/terminal-assistant/run"
+)
+
+// notify_title is the title of the desktop notifications.
+const notify_title = "terminal-assistant"
+
+// notify_summary_length is the maximum number of characters of the summary of an answer in a desktop notification.
+const notify_summary_length = 80
+
+// notifyTerminal is the terminal the bell and the desktop notifications are written to.
+var notifyTerminal io.Writer = os.Stderr
+
+// notifyAnswer is a function that rings the bell of the terminal and sends a desktop notification with the summary
+// of an answer, with the OSC 777 sequence of the terminals like foot, kitty or urxvt and the OSC 9 sequence of the
+// terminals like iTerm2 or Windows Terminal, the others ignoring them.
+func notifyAnswer(summary string) error {
+	sequences := []string{
+		fmt.Sprintf("\x1b]777;notify;%s;%s\x07", notify_title, summary),
+		fmt.Sprintf("\x1b]9;%s\x07", summary),
+	}
+
+	// Wrap the sequences so the terminal multiplexers pass them through to the terminal
+	if os.Getenv("TMUX") != "" {
+		for i, sequence := range sequences {
+			sequences[i] = "\x1bPtmux;" + strings.ReplaceAll(sequence, "\x1b", "\x1b\x1b") + "\x1b\\"
+		}
+	} else if strings.HasPrefix(os.Getenv("TERM"), "screen") {
+		for i, sequence := range sequences {
+			sequences[i] = "\x1bP" + sequence + "\x1b\\"
+		}
+	}
+
+	_, err := io.WriteString(notifyTerminal, "\a"+strings.Join(sequences, ""))
+
+	return err
+}
+
+// notificationSummary is a function that returns the first line of an answer, without control characters so it
+// cannot end the notification sequence, truncated to notify_summary_length characters.
+func notificationSummary(answer string) string {
+	summary := ""
+	for _, line := range strings.Split(run.StripAnsi(answer), "\n") {
+		if strings.TrimSpace(line) != "" && codeFence(strings.TrimSpace(line)) == "" {
+			summary = strings.TrimSpace(line)
+			break
+		}
+	}
+	summary = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, summary)
+
+	if runes := []rune(summary); len(runes) > notify_summary_length {
+		summary = string(runes[:notify_summary_length-1]) + "…"
+	}
+	if summary == "" {
+		return "answer ready"
+	}
+
+	return summary
+}
@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akhilsharma90/terminal-assistant/ai"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotify(t *testing.T) {
+	t.Run("NotifyAnswer", testNotifyAnswer)
+	t.Run("NotificationSummary", testNotificationSummary)
+	t.Run("NotifyLongQuery", testNotifyLongQuery)
+}
+
+// useTestNotifyTerminal replaces the terminal the notifications are written to by a buffer for testing purposes.
+func useTestNotifyTerminal(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	terminal := &bytes.Buffer{}
+	previous := notifyTerminal
+	notifyTerminal = terminal
+	t.Cleanup(func() {
+		notifyTerminal = previous
+	})
+
+	return terminal
+}
+
+// testNotifyAnswer tests that the bell is rung and the desktop notification sequences are written, wrapped for tmux.
+func testNotifyAnswer(t *testing.T) {
+	terminal := useTestNotifyTerminal(t)
+	t.Setenv("TMUX", "")
+	t.Setenv("TERM", "xterm")
+
+	require.NoError(t, notifyAnswer("ls -la"))
+	assert.Equal(t, "\a\x1b]777;notify;terminal-assistant;ls -la\x07\x1b]9;ls -la\x07", terminal.String())
+
+	terminal.Reset()
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+	require.NoError(t, notifyAnswer("ls -la"))
+	assert.True(t, strings.HasPrefix(terminal.String(), "\a\x1bPtmux;\x1b\x1b]777;notify;"), "The sequences should pass through tmux.")
+}
+
+// testNotificationSummary tests that the summary of an answer is its first line of text, without control characters
+// and truncated.
+func testNotificationSummary(t *testing.T) {
+	testCases := []struct {
+		name     string
+		answer   string
+		expected string
+	}{
+		{"FirstLine", "\nUse ls.\nIt lists the files.", "Use ls."},
+		{"CodeFence", "```sh\nls -la\n```", "ls -la"},
+		{"ControlCharacters", "done\x07\x1b]9;spoofed", "done9;spoofed"},
+		{"Long", strings.Repeat("a", 100), strings.Repeat("a", notify_summary_length-1) + "…"},
+		{"Empty", "", "answer ready"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, notificationSummary(tc.answer))
+		})
+	}
+}
+
+// testNotifyLongQuery tests that only the answers of the queries longer than the configured duration are notified.
+func testNotifyLongQuery(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	terminal := useTestNotifyTerminal(t)
+	t.Setenv("TMUX", "")
+	t.Setenv("TERM", "xterm")
+	u := newTestUi(t)
+	u.config = newTestPlainConfig(t)
+	output := ai.EngineExecOutput{Command: "ls -la", Executable: true}
+
+	u.components.spinner.Start()
+	assert.Nil(t, u.notifyLongQuery(output), "A short query should not be notified.")
+
+	u.components.spinner.start = time.Now().Add(-time.Minute)
+	cmd := u.notifyLongQuery(output)
+	require.NotNil(t, cmd, "A long query should be notified.")
+	cmd()
+	assert.Contains(t, terminal.String(), ";ls -la\x07", "The command should be the summary.")
+	assert.Nil(t, u.notifyLongQuery(ai.EngineChatStreamOutput{}), "Only the last output of a chat stream should be notified.")
+
+	viper.Set("USER_NOTIFY_AFTER_SECONDS", 0)
+	u.config = newTestPlainConfig(t)
+	assert.Nil(t, u.notifyLongQuery(output), "The notifications should be disabled by a zero duration.")
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// output_file_mode is the permissions of the files created with --output, the overwritten files keeping theirs.
+const output_file_mode = 0644
+
+// selectOutput is a function that returns the content of an answer saved with --output: the whole answer, or the
+// content of its first code block with --code-only, an error being returned if it has none.
+func selectOutput(answer string, codeOnly bool) (string, error) {
+	if !codeOnly {
+		return answer, nil
+	}
+
+	blocks := extractCodeBlocks(answer)
+	if len(blocks) == 0 {
+		return "", errors.New("no code block in the answer")
+	}
+
+	// The block still open at the end of the answer ends with its last newline
+	return strings.TrimRight(blocks[0], "\n") + "\n", nil
+}
+
+// checkOutputFile is a function that returns an error if the file of --output exists and overwriting it is not forced,
+// so the user knows before the request is sent.
+func checkOutputFile(path string, force bool) error {
+	if force {
+		return nil
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists, use --force to overwrite it", path)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// writeOutputFile is a function that atomically writes a content to the file of --output, by writing a temporary
+// file in the same directory and renaming it, an existing file being only overwritten if forced. It returns the number
+// of bytes written.
+func writeOutputFile(path string, content string, force bool) (int, error) {
+	if err := checkOutputFile(path, force); err != nil {
+		return 0, err
+	}
+
+	mode := os.FileMode(output_file_mode)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), fmt.Sprintf(".%s.*.tmp", filepath.Base(path)))
+	if err != nil {
+		return 0, err
+	}
+	tmpPath := tmp.Name()
+	written, err := tmp.WriteString(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpPath, mode)
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return 0, err
+	}
+
+	return written, nil
+}
@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputFile(t *testing.T) {
+	t.Run("SelectOutput", testSelectOutput)
+	t.Run("WriteOutputFile", testWriteOutputFile)
+}
+
+// testSelectOutput tests that the whole answer is saved, or the content of its first code block with --code-only.
+func testSelectOutput(t *testing.T) {
+	testCases := []struct {
+		name     string
+		answer   string
+		codeOnly bool
+		expected string
+		err      bool
+	}{
+		{"Answer", "Some *text*\n```sh\nls\n```\n", false, "Some *text*\n```sh\nls\n```\n", false},
+		{"FirstBlock", "First:\n```sh\nls -la\npwd\n```\nThen:\n```\nrm x\n```\n", true, "ls -la\npwd\n", false},
+		{"TildeBlock", "~~~python\nprint(1)\n~~~\n", true, "print(1)\n", false},
+		{"UnclosedBlock", "```go\nfunc main() {}\n", true, "func main() {}\n", false},
+		{"NoBlock", "No code here.", true, "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output, err := selectOutput(tc.answer, tc.codeOnly)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, output)
+		})
+	}
+}
+
+// testWriteOutputFile tests that the content is written to the file, an existing file being only overwritten
+// if forced, with its permissions.
+func testWriteOutputFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.sh")
+
+	written, err := writeOutputFile(path, "echo hello\n", false)
+	require.NoError(t, err)
+	assert.Equal(t, 11, written)
+
+	_, err = writeOutputFile(path, "echo bye\n", false)
+	assert.ErrorContains(t, err, "use --force to overwrite it")
+
+	require.NoError(t, os.Chmod(path, 0700))
+	_, err = writeOutputFile(path, "echo bye\n", true)
+	require.NoError(t, err)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "echo bye\n", string(content))
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "The temporary file should be renamed.")
+	if info, err := os.Stat(path); assert.NoError(t, err) && filepath.Separator == '/' {
+		assert.Equal(t, os.FileMode(0700), info.Mode().Perm(), "The permissions of the overwritten file should be kept.")
+	}
+}
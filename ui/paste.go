@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// paste_confirm_size is the size in bytes above which a paste is confirmed before being inserted into the prompt.
+const paste_confirm_size = 8 * 1024
+
+// sanitizePaste is a function that normalizes the line endings of a pasted text to newlines and removes its control
+// characters, except the newlines and the tabs, so it can be inserted in the prompt as it was copied.
+func sanitizePaste(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.ReplaceAll(content, "\r", "\n")
+
+	return strings.Map(func(r rune) rune {
+		if r != '\n' && r != '\t' && unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, content)
+}
+
+// formatPasteSize is a function that returns a size in bytes in a human readable form, like "512B", "14KB" or "12MB".
+func formatPasteSize(size int) string {
+	if size < 1024 {
+		return fmt.Sprintf("%dB", size)
+	}
+	if size >= 1024*1024 {
+		return fmt.Sprintf("%dMB", (size+512*1024)/(1024*1024))
+	}
+
+	return fmt.Sprintf("%dKB", (size+512)/1024)
+}
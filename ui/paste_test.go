@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUIPaste(t *testing.T) {
+	t.Run("SanitizePaste", testSanitizePaste)
+	t.Run("FormatPasteSize", testFormatPasteSize)
+}
+
+// testSanitizePaste tests that the line endings of a paste are normalized and its control characters removed.
+func testSanitizePaste(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"Plain", "echo hello", "echo hello"},
+		{"CarriageReturns", "echo one\recho two\r\necho three\n", "echo one\necho two\necho three\n"},
+		{"Tabs", "if true; then\n\techo yes\nfi", "if true; then\n\techo yes\nfi"},
+		{"ControlCharacters", "echo\x07 bell\x1b[31m\x00", "echo bell[31m"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, sanitizePaste(tc.content))
+		})
+	}
+}
+
+// testFormatPasteSize tests that the size of a paste is displayed in bytes, kilobytes or megabytes.
+func testFormatPasteSize(t *testing.T) {
+	assert.Equal(t, "512B", formatPasteSize(512))
+	assert.Equal(t, "1KB", formatPasteSize(1024))
+	assert.Equal(t, "14KB", formatPasteSize(14*1024+100))
+	assert.Equal(t, "12MB", formatPasteSize(12*1024*1024+100))
+}
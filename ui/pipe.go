@@ -0,0 +1,111 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/akhilsharma90/terminal-assistant/config"
+)
+
+// pipe_reading_status is the status of the spinner while the piped input is read.
+const pipe_reading_status = "reading piped input"
+
+// pipeProgressMsg is a message telling the number of bytes of the piped input read so far.
+type pipeProgressMsg int
+
+// pipeIngestedMsg is a message telling the piped input was read, carrying the configuration loaded before reading it
+// so the program can start.
+type pipeIngestedMsg struct {
+	pipe      string         // The piped input, truncated to the maximum size if enabled.
+	warning   string         // The warning telling the piped input was truncated, if it was.
+	err       error          // The error preventing the piped input from being read, if any.
+	config    *config.Config // The configuration loaded before reading the piped input, nil if it failed.
+	configErr error          // The error of the loading of the configuration, if any.
+}
+
+// ingestPipe is a method of the Ui struct that reads the piped input of the input in the background, the spinner displaying the
+// bytes read so far, before starting with the configuration loaded by Init. The input is truncated to the maximum
+// size when it is too large and the truncation is enabled, the configuration being created later otherwise.
+func (u *Ui) ingestPipe(input *UiInput, config *config.Config, configErr error) tea.Cmd {
+	u.state.ingesting = true
+	progress := make(chan pipeProgressMsg, 1)
+	u.state.pipeProgress = progress
+	u.components.spinner.Start().SetStatus(pipe_reading_status)
+
+	max, truncate := 0, false
+	if configErr == nil {
+		max = config.GetUserConfig().GetMaxPipeSizeBytes()
+		truncate = config.GetUserConfig().GetTruncatePipe()
+	}
+
+	ctx := u.state.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return tea.Batch(
+		u.components.spinner.Tick,
+		safeCmd(func() tea.Msg {
+			defer close(progress)
+			pipe, err := input.ReadPipe(ctx, func(bytes int) {
+				// Keep only the last count when the previous one was not displayed yet, never blocking the reading
+				select {
+				case <-progress:
+				default:
+				}
+				progress <- pipeProgressMsg(bytes)
+			})
+
+			msg := pipeIngestedMsg{pipe: pipe, err: err, config: config, configErr: configErr}
+			if err == nil && truncate && max > 0 && len(pipe) > max {
+				msg.pipe = truncatePipe(pipe, max)
+				msg.warning = fmt.Sprintf("[pipe truncated to %d bytes]", max)
+			}
+
+			return msg
+		}),
+		u.awaitPipeProgress(),
+	)
+}
+
+// awaitPipeProgress is a method of the Ui struct that waits for the next number of bytes of the piped input read so
+// far, until the reading is finished.
+func (u *Ui) awaitPipeProgress() tea.Cmd {
+	progress := u.state.pipeProgress
+	if progress == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		msg, ok := <-progress
+		if !ok {
+			return nil
+		}
+
+		return msg
+	}
+}
+
+// finishPipe is a method of the Ui struct that starts the program once the piped input was read, quitting if it could
+// not be read or if the reading was interrupted.
+func (u *Ui) finishPipe(msg pipeIngestedMsg) tea.Cmd {
+	u.state.ingesting = false
+	u.state.pipeProgress = nil
+	u.components.spinner.SetBytes(0)
+
+	if errors.Is(msg.err, context.Canceled) {
+		return tea.Quit
+	}
+	if msg.err != nil {
+		u.setFatalError(fmt.Errorf("cannot read the piped input: %w", msg.err))
+		return tea.Quit
+	}
+
+	u.state.pipe = msg.pipe
+	u.state.pipeWarning = msg.warning
+
+	return u.start(msg.config, msg.configErr)
+}
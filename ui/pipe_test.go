@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/akhilsharma90/terminal-assistant/config"
+	"github.com/akhilsharma90/terminal-assistant/run"
+)
+
+func TestUIPipe(t *testing.T) {
+	t.Run("IngestPipe", testIngestPipe)
+	t.Run("IngestPipeKeys", testIngestPipeKeys)
+	t.Run("FinishPipe", testFinishPipe)
+}
+
+// runIngestPipe is a function that runs the commands of the ingestion of a piped input, returning the progress and
+// the message telling the input was read.
+func runIngestPipe(t *testing.T, cmd tea.Cmd) ([]pipeProgressMsg, pipeIngestedMsg) {
+	t.Helper()
+
+	batch, ok := cmd().(tea.BatchMsg)
+	require.True(t, ok, "The ingestion should batch the spinner, the reading and its progress.")
+
+	var progress []pipeProgressMsg
+	var ingested *pipeIngestedMsg
+	for _, c := range batch {
+		switch msg := c().(type) {
+		case pipeProgressMsg:
+			progress = append(progress, msg)
+		case pipeIngestedMsg:
+			ingested = &msg
+		}
+	}
+	require.NotNil(t, ingested, "The reading should tell the input was read.")
+
+	return progress, *ingested
+}
+
+// testIngestPipe tests that the piped input is read in the background with the spinner displaying the bytes read so
+// far, and truncated to the maximum size when enabled.
+func testIngestPipe(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		filepath.Join(dir, "terminal-assistant.json"),
+		[]byte(`{"openai_key": "test_key", "user_max_pipe_size_bytes": 100}`),
+		0600,
+	))
+	viper.AddConfigPath(dir)
+	cfg, err := config.NewConfig()
+	require.NoError(t, err)
+
+	u := newTestUi(t)
+	u.layout(80, 24)
+	pipe := strings.Repeat("x", 2*pipe_read_chunk_size)
+	cmd := u.ingestPipe(&UiInput{stdin: strings.NewReader(" " + pipe + "\n")}, cfg, nil)
+	assert.True(t, u.state.ingesting, "The piped input should be read before starting.")
+	assert.Contains(t, run.StripAnsi(u.View()), "reading piped input...", "The spinner should tell the input is read.")
+
+	progress, ingested := runIngestPipe(t, cmd)
+	require.NotEmpty(t, progress, "The progress of the reading should be reported.")
+	assert.Equal(t, "[pipe truncated to 100 bytes]", ingested.warning)
+	assert.LessOrEqual(t, len(ingested.pipe), 100, "The piped input should be truncated to the maximum size.")
+	assert.Equal(t, cfg, ingested.config, "The configuration should be kept to start.")
+
+	u.Update(progress[len(progress)-1])
+	assert.Contains(t, run.StripAnsi(u.View()), "reading piped input... 128KB", "The bytes read so far should be displayed.")
+
+	u = newTestUi(t)
+	_, ingested = runIngestPipe(t, u.ingestPipe(&UiInput{stdin: strings.NewReader(" " + pipe + "\n")}, nil, errors.New("no config")))
+	assert.Equal(t, pipe, ingested.pipe, "The piped input should be kept whole without configuration.")
+	assert.Empty(t, ingested.warning)
+}
+
+// testIngestPipeKeys tests that the keys are ignored while the piped input is read, ctrl+c quitting the program.
+func testIngestPipeKeys(t *testing.T) {
+	u := newTestUi(t)
+	u.state.ctx, u.state.stop = context.WithCancel(context.Background())
+	u.ingestPipe(&UiInput{stdin: strings.NewReader("input")}, nil, errors.New("no config"))
+
+	_, cmd := u.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	assert.Nil(t, cmd)
+	assert.Empty(t, u.components.prompt.GetValue(), "The prompt should not be edited while the input is read.")
+
+	_, cmd = u.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	require.NotNil(t, cmd)
+	assert.IsType(t, tea.QuitMsg{}, cmd(), "Ctrl+c should quit while the input is read.")
+	assert.ErrorIs(t, u.state.ctx.Err(), context.Canceled, "The reading should be stopped.")
+}
+
+// testFinishPipe tests that the program quits when the piped input could not be read, silently when the reading
+// was interrupted.
+func testFinishPipe(t *testing.T) {
+	u := newTestUi(t)
+	u.state.ingesting = true
+	cmd := u.finishPipe(pipeIngestedMsg{err: context.Canceled})
+	assert.False(t, u.state.ingesting)
+	assert.IsType(t, tea.QuitMsg{}, cmd())
+	assert.Nil(t, u.state.error, "An interrupted reading should not be reported.")
+
+	u = newTestUi(t)
+	u.state.ingesting = true
+	cmd = u.finishPipe(pipeIngestedMsg{err: errors.New("broken pipe")})
+	assert.IsType(t, tea.QuitMsg{}, cmd())
+	assert.True(t, u.state.fatal)
+	assert.Contains(t, u.View(), "cannot read the piped input: broken pipe")
+}
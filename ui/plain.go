@@ -0,0 +1,267 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/akhilsharma90/terminal-assistant/ai"
+	"github.com/akhilsharma90/terminal-assistant/config"
+	"github.com/akhilsharma90/terminal-assistant/run"
+
+	"github.com/spf13/viper"
+)
+
+// plainEngine is the part of the engine used by the PlainRunner, so it can be replaced in the tests.
+type plainEngine interface {
+	ExecCompletion(input string) (*ai.EngineExecOutput, error)
+	ChatStreamCompletion(input string) error
+	ChatStreamCompletionWriter(input string, w io.Writer) error
+	GetChannel() chan ai.EngineChatStreamOutput
+	GetUsage() ai.EngineUsage
+}
+
+// PlainRunner is a struct that runs the CLI mode quietly, without the terminal user interface, when the output is
+// redirected or --quiet is given: only the answer is written as raw text, or as JSON with --format json, to the output,
+// without help, spinner or status, the errors to the error output, and an exit code is returned. The generated commands are never confirmed,
+// they are only executed if allowed upfront.
+type PlainRunner struct {
+	input   *UiInput    // The input of the command line.
+	stdout  io.Writer   // The output the answers are written to.
+	stderr  io.Writer   // The output the errors are written to.
+	aliases run.Aliases // The aliases of the shell of the user, expanded in the generated commands.
+}
+
+// NewPlainRunner is a function that creates a new PlainRunner instance.
+func NewPlainRunner(input *UiInput, stdout io.Writer, stderr io.Writer) *PlainRunner {
+	return &PlainRunner{
+		input:  input,
+		stdout: stdout,
+		stderr: stderr,
+	}
+}
+
+// Run is a method on the PlainRunner struct that loads the configuration, sends the prompt to the AI and writes
+// its answer. It returns the exit code of the program: the one of the executed command, 0 for an answer,
+// and 1 for an error or when no command could be generated in the exec prompt mode.
+func (p *PlainRunner) Run() int {
+	config, err := config.NewConfig()
+	if err != nil {
+		if errors.As(err, &viper.ConfigFileNotFoundError{}) {
+			return p.fail(errors.New("no configuration found, run the assistant in a terminal to configure it"))
+		}
+		return p.fail(err)
+	}
+
+	// Refuse to overwrite the file of the answer before sending the request
+	if p.input.GetOutput() != "" {
+		if err := checkOutputFile(p.input.GetOutput(), p.input.GetForce()); err != nil {
+			return p.fail(err)
+		}
+	}
+
+	promptMode, err := resolvePromptMode(p.input.GetPromptMode(), config)
+	if err != nil {
+		return p.fail(err)
+	}
+	engineMode := ai.ExecEngineMode
+	if promptMode == ChatPromptMode {
+		engineMode = ai.ChatEngineMode
+	}
+
+	// Resolve the aliases on a best effort basis, the command being generated anyway
+	p.aliases, _ = loadAliases(config)
+
+	// Read the piped input at once, no progress being displayed without the user interface
+	pipe, err := p.input.ReadPipe(context.Background(), nil)
+	if err != nil {
+		return p.fail(fmt.Errorf("cannot read the piped input: %w", err))
+	}
+
+	engine, err := p.newEngine(engineMode, config, pipe)
+	var sizeErr *ai.PipeSizeError
+	if errors.As(err, &sizeErr) && config.GetUserConfig().GetTruncatePipe() {
+		engine, err = p.newEngine(engineMode, config, truncatePipe(pipe, sizeErr.GetMax()))
+	}
+	if err != nil {
+		return p.fail(err)
+	}
+	for _, image := range p.input.GetImages() {
+		if err := engine.AttachImage(image); err != nil {
+			return p.fail(fmt.Errorf("cannot attach %s: %w", image, err))
+		}
+	}
+	// The missing context files are not reported, only the answer being written
+	_ = injectContextFiles(engine, config)
+
+	if engineMode == ai.ChatEngineMode {
+		return p.runChat(engine, config)
+	}
+
+	return p.runExec(engine, config)
+}
+
+// newEngine is a method on the PlainRunner struct that creates an engine telling the pipe and the aliases of the user.
+func (p *PlainRunner) newEngine(mode ai.EngineMode, config *config.Config, pipe string) (*ai.Engine, error) {
+	opts := []ai.EngineOption{ai.WithAliases(p.aliases)}
+	if pipe != "" {
+		opts = append(opts, ai.WithPipe(pipe))
+	}
+
+	return ai.NewEngine(context.Background(), mode, config, opts...)
+}
+
+// runExec is a method on the PlainRunner struct that writes the command generated by the AI, or executes it instead
+// if the --yes flag is set and the command is not blocked, its output being written.
+func (p *PlainRunner) runExec(engine plainEngine, config *config.Config) int {
+	output, err := engine.ExecCompletion(p.input.GetArgs())
+	if err != nil {
+		return p.fail(err)
+	}
+
+	if p.input.GetFormat() == output_format_json {
+		// The command formatted as JSON is never executed, and the exit code tells whether there is one
+		command := ""
+		if output.IsExecutable() {
+			command = p.aliases.Expand(output.GetCommand())
+		}
+		code := p.write(formatJSON(jsonExecOutput{
+			Command:     command,
+			Explanation: output.GetExplanation(),
+			Executable:  output.IsExecutable(),
+			Model:       config.GetAiConfig().GetModel(),
+			Usage:       newJSONUsage(engine.GetUsage()),
+		}))
+		if code == 0 && !output.IsExecutable() {
+			return 1
+		}
+		return code
+	}
+
+	if !output.IsExecutable() {
+		return p.fail(fmt.Errorf("no command generated: %s", output.GetExplanation()))
+	}
+
+	// Expand the aliases of the user, so the command is checked and executed as the shell would run it
+	command := p.aliases.Expand(output.GetCommand())
+	if p.input.GetOutput() != "" {
+		// The command saved to a file is never executed
+		return p.save(command + "\n")
+	}
+	if !p.input.GetYes() {
+		fmt.Fprintln(p.stdout, command)
+		return 0
+	}
+	if config.GetUserConfig().GetBlockElevation() && run.RequiresElevation(command) {
+		return p.fail(errors.New("blocked: commands requiring elevated privileges are not allowed"))
+	}
+	policy := run.NewPolicy(config.GetUserConfig().GetExecAllowlist(), config.GetUserConfig().GetExecBlocklist())
+	if program, allowed := policy.Check(command); !allowed {
+		return p.fail(fmt.Errorf("blocked by policy: %s is not allowed", program))
+	}
+
+	cmd := run.PrepareCommand(run.WithPreamble(config.GetUserConfig().GetCommandPreamble(), command))
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = p.stdout
+	cmd.Stderr = p.stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode()
+		}
+		return p.fail(err)
+	}
+
+	return 0
+}
+
+// runChat is a method on the PlainRunner struct that writes the answer of the AI as it is streamed, or once complete
+// when it is formatted as JSON or saved to the file of --output.
+func (p *PlainRunner) runChat(engine plainEngine, config *config.Config) int {
+	if p.input.GetOutput() == "" && p.input.GetFormat() != output_format_json {
+		return p.streamChat(engine)
+	}
+
+	var answer strings.Builder
+	if err := engine.ChatStreamCompletionWriter(p.input.GetArgs(), &answer); err != nil {
+		return p.fail(err)
+	}
+	content, err := selectOutput(answer.String(), p.input.GetCodeOnly())
+	if err != nil {
+		return p.fail(err)
+	}
+	if p.input.GetFormat() == output_format_json {
+		content = formatJSON(jsonChatOutput{
+			Content: content,
+			Model:   config.GetAiConfig().GetModel(),
+			Usage:   newJSONUsage(engine.GetUsage()),
+		})
+	}
+
+	return p.write(content)
+}
+
+// streamChat is a method on the PlainRunner struct that writes the answer of the AI as it is streamed.
+func (p *PlainRunner) streamChat(engine plainEngine) int {
+	done := make(chan error, 1)
+	go func() {
+		done <- engine.ChatStreamCompletion(p.input.GetArgs())
+	}()
+
+	answer := ""
+	for {
+		select {
+		case output := <-engine.GetChannel():
+			answer += output.GetContent()
+			fmt.Fprint(p.stdout, output.GetContent())
+		case err := <-done:
+			// The outputs are sent before the completion returns, so the answer is complete
+			if answer != "" && !strings.HasSuffix(answer, "\n") {
+				fmt.Fprintln(p.stdout)
+			}
+			if err != nil {
+				return p.fail(err)
+			}
+			return 0
+		}
+	}
+}
+
+// write is a method on the PlainRunner struct that writes a complete answer to the output, or saves it to the file
+// of --output.
+func (p *PlainRunner) write(content string) int {
+	if p.input.GetOutput() != "" {
+		return p.save(content)
+	}
+	fmt.Fprint(p.stdout, content)
+
+	return 0
+}
+
+// save is a method on the PlainRunner struct that writes a content to the file of --output, telling how many bytes
+// were written.
+func (p *PlainRunner) save(content string) int {
+	written, err := writeOutputFile(p.input.GetOutput(), content, p.input.GetForce())
+	if err != nil {
+		return p.fail(err)
+	}
+	fmt.Fprintf(p.stderr, "[saved %d bytes to %s]\n", written, p.input.GetOutput())
+
+	return 0
+}
+
+// fail is a method on the PlainRunner struct that writes an error to the error output, or as JSON to the output with
+// --format json, and returns the exit code 1.
+func (p *PlainRunner) fail(err error) int {
+	if p.input.GetFormat() == output_format_json {
+		fmt.Fprint(p.stdout, formatJSON(newJSONError(err)))
+		return 1
+	}
+	fmt.Fprintf(p.stderr, "[error] %s\n", err)
+
+	return 1
+}
@@ -0,0 +1,248 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akhilsharma90/terminal-assistant/ai"
+	"github.com/akhilsharma90/terminal-assistant/config"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// update_golden tells the tests to write the golden files of the JSON outputs instead of comparing them.
+var update_golden = flag.Bool("update", false, "update the golden files of the tests")
+
+// fakePlainEngine is an engine answering the exec requests with a fixed output.
+type fakePlainEngine struct {
+	output  ai.EngineExecOutput
+	answer  string
+	err     error
+	channel chan ai.EngineChatStreamOutput
+}
+
+// ExecCompletion returns the fixed output of the engine.
+func (e *fakePlainEngine) ExecCompletion(input string) (*ai.EngineExecOutput, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	return &e.output, nil
+}
+
+// ChatStreamCompletion returns the fixed error of the engine without streaming anything.
+func (e *fakePlainEngine) ChatStreamCompletion(input string) error {
+	return e.err
+}
+
+// ChatStreamCompletionWriter writes the fixed answer of the engine, unless it fails.
+func (e *fakePlainEngine) ChatStreamCompletionWriter(input string, w io.Writer) error {
+	if e.err != nil {
+		return e.err
+	}
+	_, err := io.WriteString(w, e.answer)
+
+	return err
+}
+
+// GetChannel returns the channel of the engine.
+func (e *fakePlainEngine) GetChannel() chan ai.EngineChatStreamOutput {
+	return e.channel
+}
+
+// GetUsage returns no usage of the tokens.
+func (e *fakePlainEngine) GetUsage() ai.EngineUsage {
+	return ai.EngineUsage{}
+}
+
+func TestPlainRunner(t *testing.T) {
+	t.Run("Exec", testPlainRunnerExec)
+	t.Run("ChatError", testPlainRunnerChatError)
+	t.Run("MissingConfig", testPlainRunnerMissingConfig)
+	t.Run("Output", testPlainRunnerOutput)
+	t.Run("JSON", testPlainRunnerJSON)
+}
+
+// newTestPlainConfig creates a configuration blocking a program for testing purposes.
+func newTestPlainConfig(t *testing.T) *config.Config {
+	t.Helper()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		filepath.Join(dir, "terminal-assistant.json"),
+		[]byte(`{"openai_key": "test_key", "openai_model": "gpt-4o-mini", "user_exec_blocklist": ["rm"]}`),
+		0600,
+	))
+	viper.AddConfigPath(dir)
+	cfg, err := config.NewConfig()
+	require.NoError(t, err)
+
+	return cfg
+}
+
+// testPlainRunnerExec tests that only the generated command is written as plain text, or its output once executed
+// with --yes, the exit code telling the errors from the failures of the command.
+func testPlainRunnerExec(t *testing.T) {
+	cfg := newTestPlainConfig(t)
+
+	testCases := []struct {
+		name     string
+		output   ai.EngineExecOutput
+		err      error
+		yes      bool
+		code     int
+		stdout   string
+		stderr   string
+		executed bool
+	}{
+		{"NotExecuted", ai.EngineExecOutput{Command: "echo hello", Explanation: "Prints hello.", Executable: true}, nil, false, 0, "echo hello\n", "", false},
+		{"Executed", ai.EngineExecOutput{Command: "echo hello", Executable: true}, nil, true, 0, "hello\n", "", true},
+		{"ExitCode", ai.EngineExecOutput{Command: "exit 3", Executable: true}, nil, true, 3, "", "", true},
+		{"Blocked", ai.EngineExecOutput{Command: "rm -rf tmp", Executable: true}, nil, true, 1, "", "[error] blocked by policy: rm is not allowed\n", false},
+		{"NotExecutable", ai.EngineExecOutput{Explanation: "I cannot do that."}, nil, true, 1, "", "[error] no command generated: I cannot do that.\n", false},
+		{"Error", ai.EngineExecOutput{}, errors.New("request failed"), false, 1, "", "[error] request failed\n", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			p := NewPlainRunner(&UiInput{runMode: CliMode, args: "say hello", yes: tc.yes}, &stdout, &stderr)
+
+			code := p.runExec(&fakePlainEngine{output: tc.output, err: tc.err}, cfg)
+
+			assert.Equal(t, tc.code, code, "The exit code should be returned.")
+			assert.Equal(t, tc.stdout, stdout.String(), "Only the answer should be written as plain text.")
+			assert.Equal(t, tc.stderr, stderr.String(), "The errors should be written to the error output.")
+		})
+	}
+}
+
+// testPlainRunnerChatError tests that a failed chat request is written to the error output.
+func testPlainRunnerChatError(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	p := NewPlainRunner(&UiInput{runMode: CliMode, args: "hello"}, &stdout, &stderr)
+
+	code := p.runChat(&fakePlainEngine{err: errors.New("request failed"), channel: make(chan ai.EngineChatStreamOutput)}, nil)
+
+	assert.Equal(t, 1, code)
+	assert.Empty(t, stdout.String())
+	assert.Equal(t, "[error] request failed\n", stderr.String())
+}
+
+// testPlainRunnerMissingConfig tests that the configuration is not started without a terminal.
+func testPlainRunnerMissingConfig(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	t.Setenv("HOME", t.TempDir())
+
+	var stdout, stderr bytes.Buffer
+	p := NewPlainRunner(&UiInput{runMode: CliMode, args: "hello"}, &stdout, &stderr)
+
+	assert.Equal(t, 1, p.Run())
+	assert.Empty(t, stdout.String())
+	assert.Contains(t, stderr.String(), "no configuration found")
+}
+
+// testPlainRunnerOutput tests that the answer, its first code block or the generated command is saved to the file
+// of --output, an existing file being only overwritten with --force.
+func testPlainRunnerOutput(t *testing.T) {
+	cfg := newTestPlainConfig(t)
+	dir := t.TempDir()
+
+	var stdout, stderr bytes.Buffer
+	path := filepath.Join(dir, "x.service")
+	p := NewPlainRunner(&UiInput{runMode: CliMode, args: "write a unit", output: path, codeOnly: true}, &stdout, &stderr)
+	require.Equal(t, 0, p.runChat(&fakePlainEngine{answer: "Here it is:\n```ini\n[Unit]\n```\n"}, cfg))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "[Unit]\n", string(content), "Only the first code block should be saved.")
+	assert.Empty(t, stdout.String())
+	assert.Equal(t, "[saved 7 bytes to "+path+"]\n", stderr.String())
+
+	stderr.Reset()
+	p = NewPlainRunner(&UiInput{runMode: CliMode, args: "say hello", output: path, force: true}, &stdout, &stderr)
+	require.Equal(t, 0, p.runExec(&fakePlainEngine{output: ai.EngineExecOutput{Command: "echo hello", Executable: true}}, cfg))
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "echo hello\n", string(content), "The generated command should be saved, not executed.")
+	assert.Empty(t, stdout.String())
+
+	stderr.Reset()
+	p = NewPlainRunner(&UiInput{runMode: CliMode, args: "say hello", output: path}, &stdout, &stderr)
+	assert.Equal(t, 1, p.runChat(&fakePlainEngine{answer: "hello"}, cfg))
+	assert.Contains(t, stderr.String(), "use --force to overwrite it")
+}
+
+// assertGolden asserts that an output equals the content of a golden file of the testdata directory, the file being
+// written instead with -update.
+func assertGolden(t *testing.T, name string, actual string) {
+	path := filepath.Join("testdata", name)
+	if *update_golden {
+		require.NoError(t, os.MkdirAll("testdata", 0755))
+		require.NoError(t, os.WriteFile(path, []byte(actual), 0644))
+	}
+
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), actual)
+}
+
+// testPlainRunnerJSON tests that the generated command, the answer and the errors are written as JSON with
+// --format json, the command being never executed.
+func testPlainRunnerJSON(t *testing.T) {
+	cfg := newTestPlainConfig(t)
+
+	testCases := []struct {
+		name   string
+		golden string
+		run    func(p *PlainRunner) int
+		code   int
+	}{
+		{
+			"Exec", "exec.json",
+			func(p *PlainRunner) int {
+				return p.runExec(&fakePlainEngine{output: ai.EngineExecOutput{Command: "ls -la", Explanation: "Lists the files.", Executable: true}}, cfg)
+			},
+			0,
+		},
+		{
+			"NotExecutable", "exec_not_executable.json",
+			func(p *PlainRunner) int {
+				return p.runExec(&fakePlainEngine{output: ai.EngineExecOutput{Explanation: "Ask something else."}}, cfg)
+			},
+			1,
+		},
+		{
+			"Chat", "chat.json",
+			func(p *PlainRunner) int {
+				return p.runChat(&fakePlainEngine{answer: "Use `ls -la`.\n"}, cfg)
+			},
+			0,
+		},
+		{
+			"Error", "error.json",
+			func(p *PlainRunner) int {
+				return p.runChat(&fakePlainEngine{err: &openai.APIError{Type: "invalid_request_error", Message: "invalid key", HTTPStatusCode: 401}}, cfg)
+			},
+			1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			p := NewPlainRunner(&UiInput{runMode: CliMode, args: "list the files", format: output_format_json}, &stdout, &stderr)
+			assert.Equal(t, tc.code, tc.run(p))
+			assertGolden(t, tc.golden, stdout.String())
+			assert.Empty(t, stderr.String(), "Nothing should be written to the error output.")
+		})
+	}
+}
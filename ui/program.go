@@ -0,0 +1,67 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// UiOption is a function that customizes the program running the user interface, created by NewProgram.
+type UiOption func(*uiProgram)
+
+// uiProgram holds the user interface and the options of the program running it.
+type uiProgram struct {
+	ui      *Ui                 // The user interface run by the program.
+	options []tea.ProgramOption // The options of the program, nil for the default ones.
+}
+
+// WithUi is a UiOption that sets the user interface run by the program, created from the input of the caller.
+// By default, a user interface in the REPL mode is created.
+func WithUi(ui *Ui) UiOption {
+	return func(p *uiProgram) {
+		p.ui = ui
+	}
+}
+
+// WithProgramOptions is a UiOption that replaces the default options of the program, the alternate screen
+// and the mouse support, by the given ones.
+func WithProgramOptions(opts ...tea.ProgramOption) UiOption {
+	return func(p *uiProgram) {
+		p.options = append([]tea.ProgramOption{}, opts...)
+	}
+}
+
+// NewProgram is a function that creates a program ready to run the user interface, to embed it in a larger
+// application. The program runs in the alternate screen, with the mouse support unless disabled in the input of
+// the user interface, and these defaults can be replaced with WithProgramOptions. The bracketed paste mode is enabled
+// whatever the options, unless tea.WithoutBracketedPaste is given, so the pastes are inserted as a whole in the prompt.
+// The user interface run by the program is returned with it, the default one included, and its Shutdown method
+// should be called once the program is finished.
+func NewProgram(opts ...UiOption) (*tea.Program, *Ui) {
+	p := newUiProgram(opts...)
+
+	return tea.NewProgram(p.ui, p.options...), p.ui
+}
+
+// newUiProgram is a function that applies the options of a program to its default user interface and options.
+func newUiProgram(opts ...UiOption) *uiProgram {
+	p := &uiProgram{}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if p.ui == nil {
+		p.ui = NewUi(&UiInput{
+			runMode:    ReplMode,
+			promptMode: DefaultPromptMode,
+			mouse:      true,
+		})
+	}
+
+	if p.options == nil {
+		p.options = []tea.ProgramOption{tea.WithAltScreen()}
+		if p.ui.components.viewport.IsMouseWheelEnabled() {
+			p.options = append(p.options, tea.WithMouseCellMotion())
+		}
+	}
+
+	return p
+}
@@ -0,0 +1,205 @@
+package ui
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akhilsharma90/terminal-assistant/run"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUIProgram(t *testing.T) {
+	t.Run("NewProgram", testNewProgram)
+	t.Run("Defaults", testProgramDefaults)
+	t.Run("Options", testProgramOptions)
+	t.Run("Headless", testProgramHeadless)
+}
+
+// test_timeout is the maximum duration of the headless program run by Test, like when an awaited message never comes.
+const test_timeout = 5 * time.Second
+
+// testModel is a model running the user interface in the headless program of Test, typing the input once the REPL
+// mode is started and quitting once the input and the awaited messages are handled.
+type testModel struct {
+	ui      *Ui                  // The user interface run by the program.
+	keys    []tea.KeyMsg         // The keys typing the input.
+	typing  bool                 // Whether the keys are being typed.
+	typed   int                  // The number of keys handled since the input started being typed.
+	until   []func(tea.Msg) bool // The conditions matching the messages awaited after the input is typed.
+	matched []bool               // Whether each condition matched a handled message.
+}
+
+// Init is a method of the testModel struct that initializes the user interface.
+func (m *testModel) Init() tea.Cmd {
+	return m.ui.Init()
+}
+
+// Update is a method of the testModel struct that updates the user interface with a message, types the input once
+// the connection to the model was attempted and quits once the keys and the awaited messages are handled.
+func (m *testModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	_, cmd := m.ui.Update(msg)
+
+	switch msg.(type) {
+	case modelReadyMsg:
+		if !m.typing {
+			m.typing = true
+			keys := make([]tea.Cmd, len(m.keys))
+			for i, key := range m.keys {
+				key := key
+				keys[i] = func() tea.Msg { return key }
+			}
+			cmd = tea.Batch(cmd, tea.Sequence(keys...))
+		}
+	case tea.KeyMsg:
+		if m.typing {
+			m.typed++
+		}
+	}
+	if !m.typing {
+		return m, cmd
+	}
+
+	done := m.typed >= len(m.keys)
+	for i, until := range m.until {
+		if until(msg) {
+			m.matched[i] = true
+		}
+		done = done && m.matched[i]
+	}
+	if done {
+		return m, tea.Batch(cmd, tea.Quit)
+	}
+
+	return m, cmd
+}
+
+// View is a method of the testModel struct that returns the view of the user interface.
+func (m *testModel) View() string {
+	return m.ui.View()
+}
+
+// Test is a test helper that runs the user interface in a headless program, without terminal, types an input as
+// a sequence of keys, a newline pressing enter and a tab switching the prompt mode, and returns the view of the user
+// interface. The input is typed once the REPL mode is started and the connection to the model attempted, and the
+// program is quit once the keys are handled and every condition matched a handled message, like a printed output.
+// An error is returned if the program does not quit before test_timeout.
+func (u *Ui) Test(input string, until ...func(tea.Msg) bool) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), test_timeout)
+	defer cancel()
+
+	p := newUiProgram(WithUi(u), WithProgramOptions(
+		tea.WithContext(ctx),
+		tea.WithInput(nil),
+		tea.WithOutput(io.Discard),
+		tea.WithoutRenderer(),
+		tea.WithoutSignalHandler(),
+	))
+	model := &testModel{ui: p.ui, keys: testKeyMsgs(input), until: until, matched: make([]bool, len(until))}
+
+	_, err := tea.NewProgram(model, p.options...).Run()
+	u.Shutdown()
+
+	return u.View(), err
+}
+
+// testPrinted is a function that returns a condition of Test matching the printed output containing a text.
+func testPrinted(text string) func(tea.Msg) bool {
+	return func(msg tea.Msg) bool {
+		printed, ok := msg.(printMsg)
+		return ok && strings.Contains(run.StripAnsi(string(printed)), text)
+	}
+}
+
+// testKeyMsgs is a function that returns the keys typing an input, a newline pressing enter and a tab pressing tab.
+func testKeyMsgs(input string) []tea.KeyMsg {
+	var msgs []tea.KeyMsg
+	for _, r := range input {
+		switch r {
+		case '\n', '\r':
+			msgs = append(msgs, tea.KeyMsg{Type: tea.KeyEnter})
+		case '\t':
+			msgs = append(msgs, tea.KeyMsg{Type: tea.KeyTab})
+		default:
+			msgs = append(msgs, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	}
+
+	return msgs
+}
+
+// testNewProgram tests that the program is created with the default user interface, which is returned so it can be
+// shut down, or with the given one.
+func testNewProgram(t *testing.T) {
+	program, u := NewProgram()
+	assert.NotNil(t, program, "The program should be created.")
+	require.NotNil(t, u, "The default user interface should be returned.")
+	assert.Equal(t, ReplMode, u.state.runMode, "The REPL mode should be started by default.")
+	u.Shutdown()
+
+	given := newTestUi(t)
+	_, u = NewProgram(WithUi(given))
+	assert.Same(t, given, u, "The given user interface should be returned.")
+}
+
+// testProgramDefaults tests that the user interface runs in the REPL mode by default, in the alternate screen and
+// with the mouse support unless disabled.
+func testProgramDefaults(t *testing.T) {
+	p := newUiProgram()
+	require.NotNil(t, p.ui)
+	assert.Equal(t, ReplMode, p.ui.state.runMode, "The REPL mode should be started by default.")
+	assert.Len(t, p.options, 2, "The alternate screen and the mouse support should be enabled.")
+
+	p = newUiProgram(WithUi(NewUi(&UiInput{runMode: ReplMode, mouse: false})))
+	assert.Len(t, p.options, 1, "The mouse support should not be enabled when disabled in the input.")
+}
+
+// testProgramOptions tests that the given user interface is run, and that the program options replace the defaults.
+func testProgramOptions(t *testing.T) {
+	u := NewUi(&UiInput{runMode: CliMode, promptMode: ExecPromptMode})
+
+	p := newUiProgram(WithUi(u), WithProgramOptions(tea.WithInput(nil)))
+	assert.Same(t, u, p.ui, "The given user interface should be run.")
+	assert.Len(t, p.options, 1, "The program options should replace the defaults.")
+
+	p = newUiProgram(WithUi(u), WithProgramOptions())
+	assert.NotNil(t, p.options, "No program option should replace the defaults.")
+	assert.Empty(t, p.options, "No program option should replace the defaults.")
+}
+
+// testProgramHeadless tests that the user interface runs without terminal, the typed input being handled like in the
+// REPL mode.
+func testProgramHeadless(t *testing.T) {
+	// Keep the history saved on exit and the aliases away from the ones of the user
+	homedir.DisableCache = true
+	t.Cleanup(func() { homedir.DisableCache = false })
+	t.Setenv("HOME", t.TempDir())
+	t.Cleanup(viper.Reset)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		filepath.Join(dir, "terminal-assistant.json"),
+		// The proxy refuses the connections, so the model is never reached
+		[]byte(`{"openai_key": "test_key", "openai_proxy": "http://127.0.0.1:1", "user_welcome_message": ""}`),
+		0600,
+	))
+	viper.AddConfigPath(dir)
+	u := NewUi(&UiInput{runMode: ReplMode, promptMode: ExecPromptMode})
+
+	view, err := u.Test("/confirm on\n\tok", testPrinted("[confirmation enabled]"))
+	require.NoError(t, err)
+
+	view = run.StripAnsi(view)
+	assert.Contains(t, view, "/confirm on", "The input should be echoed.")
+	assert.Contains(t, view, "[confirmation enabled]", "The slash command should be run.")
+	assert.Equal(t, ChatPromptMode, u.state.promptMode, "The tab should switch the prompt mode.")
+	assert.Equal(t, "ok", u.components.prompt.GetValue(), "The input typed after enter should be in the prompt.")
+}
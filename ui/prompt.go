@@ -2,10 +2,16 @@ package ui
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/akhilsharma90/terminal-assistant/config"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
 )
 
 const (
@@ -17,32 +23,69 @@ const (
 	chat_placeholder   = "Ask me something..."
 )
 
-// Prompt is a struct that represents a prompt in the user interface.
+// prompt_default_width is the width of the multi-line input before the size of the terminal is known.
+const prompt_default_width = 150
+
+// prompt_symbol_max_width is the maximum width of a configured prompt symbol, the wider ones being replaced by an
+// ASCII symbol since terminals disagree on the width of the wide glyphs, which would misalign the cursor.
+const prompt_symbol_max_width = 1
+
+// The one-cell symbols replacing the configured prompt symbols that are too wide, by prompt mode.
+const (
+	exec_ascii_symbol   = "$"
+	config_ascii_symbol = "*"
+	chat_ascii_symbol   = "?"
+)
+
+// vi_indicator_width is the width of the mode of the vi keymap displayed before the prompt, like "[NORMAL] ".
+const vi_indicator_width = 9
+
+// prompt_default_max_height is the maximum number of lines of the multi-line input, unless configured.
+const prompt_default_max_height = 6
+
+// Prompt is a struct that represents a prompt in the user interface. The configuration prompt is a masked
+// single-line input, the exec and chat prompts are multi-line inputs growing with their content.
 type Prompt struct {
-	mode  PromptMode      // The mode of the prompt.
-	input textinput.Model // The text input model of the prompt.
+	mode            PromptMode                            // The mode of the prompt.
+	input           textinput.Model                       // The text input model of the configuration prompt.
+	area            textarea.Model                        // The text area model of the exec and chat prompts.
+	maxHeight       int                                   // The maximum number of lines of the text area.
+	indicators      map[PromptMode]config.PromptIndicator // The configured symbols and colors of the prompt modes.
+	width           int                                   // The width of the prompt, including the vi mode indicator.
+	vi              *viKeymap                             // The vi keymap of the multi-line input, nil for the default keys.
+	suggestions     []string                              // The inputs completing the typed text, the first one suggested.
+	suggestionIndex int                                   // The index of the suggested input among the completing ones.
+	suggestionValue string                                // The typed text the suggestions were cycled for.
 }
 
 // NewPrompt is a function that creates a new Prompt instance.
 func NewPrompt(mode PromptMode) *Prompt {
 	input := textinput.New()
-	// Set the placeholder, text style, and prompt of the text input model based on the prompt mode.
-	input.Placeholder = getPromptPlaceholder(mode)
-	input.TextStyle = getPromptStyle(mode)
-	input.Prompt = getPromptIcon(mode)
 
-	// If the prompt mode is configuration, set the echo mode of the text input model to password.
+	// If the prompt mode is configuration, mask the input with the echo character.
 	if mode == ConfigPromptMode {
 		input.EchoMode = textinput.EchoPassword
+		input.EchoCharacter = '*'
 	}
 
 	// Focus the text input model.
 	input.Focus()
 
-	return &Prompt{
-		mode:  mode,
-		input: input,
+	p := &Prompt{
+		mode:       mode,
+		input:      input,
+		area:       newPromptArea(),
+		maxHeight:  prompt_default_max_height,
+		indicators: map[PromptMode]config.PromptIndicator{},
+		width:      prompt_default_width,
+	}
+	// Set the placeholder, text style, and prompt of the input models based on the prompt mode.
+	p.applyStyle()
+	if p.isMultiline() {
+		p.area.Focus()
 	}
+
+	return p
 }
 
 // GetMode is a method on the Prompt struct that returns the prompt mode.
@@ -50,86 +93,536 @@ func (p *Prompt) GetMode() PromptMode {
 	return p.mode
 }
 
-// SetMode is a method on the Prompt struct that sets the prompt mode and updates the text input model accordingly.
+// SetMode is a method on the Prompt struct that sets the prompt mode and updates the input models accordingly.
+// The value is kept when switching between the single-line and the multi-line inputs.
 func (p *Prompt) SetMode(mode PromptMode) *Prompt {
+	value, multiline, focused := p.GetValue(), p.isMultiline(), p.isFocused()
 	p.mode = mode
+	p.applyStyle()
+
+	if multiline != p.isMultiline() {
+		p.SetValue(value)
+		p.Blur()
+		if focused {
+			p.Focus()
+		}
+	}
+
+	return p
+}
+
+// SetIndicator is a method on the Prompt struct that sets the symbol and the color of a prompt mode, the default ones
+// being used when they are empty. A symbol wider than one cell is replaced by an ASCII symbol.
+func (p *Prompt) SetIndicator(mode PromptMode, indicator config.PromptIndicator) *Prompt {
+	p.indicators[mode] = indicator
+	if mode == p.mode {
+		p.applyStyle()
+	}
+
+	return p
+}
+
+// GetIcon is a method on the Prompt struct that returns the rendered icon displayed before the input.
+func (p *Prompt) GetIcon() string {
+	return p.style().Render(p.symbol())
+}
+
+// SetWidth is a method on the Prompt struct that sets the width of the multi-line input, the vi mode indicator
+// being displayed before it if enabled.
+func (p *Prompt) SetWidth(width int) *Prompt {
+	p.width = width
+	if p.vi != nil {
+		width -= vi_indicator_width
+	}
+	p.area.SetWidth(width)
+	p.fit()
+
+	return p
+}
+
+// SetEditingMode is a method on the Prompt struct that sets the editing mode of the multi-line input: "vi" enables
+// the vi keymap, starting in the insert mode, any other mode keeping the default emacs-like keys.
+func (p *Prompt) SetEditingMode(mode string) *Prompt {
+	p.vi = nil
+	if mode == editing_mode_vi {
+		p.vi = &viKeymap{}
+	}
+
+	return p.SetWidth(p.width)
+}
+
+// GetViMode is a method on the Prompt struct that returns the mode of the vi keymap, "INSERT" or "NORMAL",
+// empty if the vi keymap is disabled.
+func (p *Prompt) GetViMode() string {
+	if p.vi == nil || !p.isMultiline() {
+		return ""
+	}
+
+	return p.vi.getMode()
+}
+
+// HandleViKey is a method on the Prompt struct that applies a key to the multi-line input with the vi keymap.
+// It returns whether the key was handled, the other keys keeping their role in the input and the user interface.
+func (p *Prompt) HandleViKey(msg tea.KeyMsg) bool {
+	if p.vi == nil || !p.isMultiline() {
+		return false
+	}
+
+	value := p.area.Value()
+	info := p.area.LineInfo()
+	buffer := &viBuffer{
+		lines: strings.Split(value, "\n"),
+		row:   p.area.Line(),
+		col:   info.StartColumn + info.ColumnOffset,
+	}
+	if !p.vi.handle(msg, buffer) {
+		return false
+	}
 
-	p.input.TextStyle = getPromptStyle(mode)
-	p.input.Prompt = getPromptIcon(mode)
-	p.input.Placeholder = getPromptPlaceholder(mode)
+	if edited := strings.Join(buffer.lines, "\n"); edited != value {
+		p.area.SetValue(edited)
+		p.fit()
+	}
+	for p.area.Line() > buffer.row {
+		p.area.CursorUp()
+	}
+	for p.area.Line() < buffer.row {
+		p.area.CursorDown()
+	}
+	p.area.SetCursor(buffer.col)
+
+	return true
+}
+
+// SetMaxHeight is a method on the Prompt struct that sets the maximum number of lines of the multi-line input,
+// the default maximum being used if it is not positive.
+func (p *Prompt) SetMaxHeight(maxHeight int) *Prompt {
+	p.maxHeight = maxHeight
+	if p.maxHeight < 1 {
+		p.maxHeight = prompt_default_max_height
+	}
+	p.fit()
 
 	return p
 }
 
-// SetValue is a method on the Prompt struct that sets the value of the text input model.
+// GetMaxHeight is a method on the Prompt struct that returns the maximum number of lines of the multi-line input.
+func (p *Prompt) GetMaxHeight() int {
+	return p.maxHeight
+}
+
+// IsFirstLine is a method on the Prompt struct that returns whether the cursor is on the first line of the input.
+func (p *Prompt) IsFirstLine() bool {
+	if !p.isMultiline() {
+		return true
+	}
+
+	return p.area.Line() == 0 && p.area.LineInfo().RowOffset == 0
+}
+
+// IsLastLine is a method on the Prompt struct that returns whether the cursor is on the last line of the input.
+func (p *Prompt) IsLastLine() bool {
+	if !p.isMultiline() {
+		return true
+	}
+
+	info := p.area.LineInfo()
+
+	return p.area.Line() == p.area.LineCount()-1 && info.RowOffset >= info.Height-1
+}
+
+// SetEchoMode is a method on the Prompt struct that sets the echo mode of the text input model.
+// The value returned by GetValue is never affected by the echo mode.
+func (p *Prompt) SetEchoMode(mode textinput.EchoMode) *Prompt {
+	p.input.EchoMode = mode
+
+	return p
+}
+
+// GetEchoMode is a method on the Prompt struct that returns the echo mode of the text input model.
+func (p *Prompt) GetEchoMode() textinput.EchoMode {
+	return p.input.EchoMode
+}
+
+// SetValue is a method on the Prompt struct that sets the value of the input model, the cursor being at its end.
 func (p *Prompt) SetValue(value string) *Prompt {
-	p.input.SetValue(value)
+	if p.isMultiline() {
+		p.area.SetValue(value)
+		p.fit()
+	} else {
+		p.input.SetValue(value)
+	}
+
+	return p
+}
+
+// Insert is a method on the Prompt struct that inserts a text at the cursor position as a single edit, like a paste.
+// The newlines are kept by the multi-line input.
+func (p *Prompt) Insert(text string) *Prompt {
+	if p.isMultiline() {
+		p.area.InsertString(text)
+		p.fit()
+		return p
+	}
+
+	value := []rune(p.input.Value())
+	position := p.input.Position()
+	p.input.SetValue(string(value[:position]) + text + string(value[position:]))
+	p.input.SetCursor(position + len([]rune(text)))
 
 	return p
 }
 
-// GetValue is a method on the Prompt struct that returns the value of the text input model.
+// WithSuggestions is a method on the Prompt struct that sets the inputs completing the typed text, the rest of
+// the first one starting with it being displayed dimmed while the cursor is at the end of a single line of
+// the multi-line input, and NextSuggestion cycling through the others.
+func (p *Prompt) WithSuggestions(suggestions []string) *Prompt {
+	p.suggestions = suggestions
+
+	return p
+}
+
+// GetSuggestion is a method on the Prompt struct that returns the text suggested after the input, empty if none is
+// displayed.
+func (p *Prompt) GetSuggestion() string {
+	matches := p.matchingSuggestions()
+	if len(matches) == 0 {
+		return ""
+	}
+
+	index := 0
+	if p.suggestionValue == p.GetValue() {
+		index = p.suggestionIndex % len(matches)
+	}
+
+	return strings.TrimPrefix(matches[index], p.GetValue())
+}
+
+// HasSuggestions is a method on the Prompt struct that returns whether several inputs complete the typed text,
+// so NextSuggestion has others to cycle through.
+func (p *Prompt) HasSuggestions() bool {
+	return len(p.matchingSuggestions()) > 1
+}
+
+// NextSuggestion is a method on the Prompt struct that suggests the next input completing the typed text, back to
+// the first one after the last one. It returns whether there was one.
+func (p *Prompt) NextSuggestion() bool {
+	matches := p.matchingSuggestions()
+	if len(matches) == 0 {
+		return false
+	}
+
+	if value := p.GetValue(); p.suggestionValue != value {
+		p.suggestionValue = value
+		p.suggestionIndex = 0
+	}
+	p.suggestionIndex = (p.suggestionIndex + 1) % len(matches)
+
+	return true
+}
+
+// AcceptSuggestion is a method on the Prompt struct that appends the displayed suggestion to the input.
+// It returns whether a suggestion was accepted.
+func (p *Prompt) AcceptSuggestion() bool {
+	suggestion := p.GetSuggestion()
+	if suggestion == "" {
+		return false
+	}
+
+	p.Insert(suggestion)
+
+	return true
+}
+
+// GetValue is a method on the Prompt struct that returns the value of the input model.
 func (p *Prompt) GetValue() string {
+	if p.isMultiline() {
+		return p.area.Value()
+	}
+
 	return p.input.Value()
 }
 
-// Blur is a method on the Prompt struct that unfocuses the text input model.
+// Blur is a method on the Prompt struct that unfocuses the input model.
 func (p *Prompt) Blur() *Prompt {
 	p.input.Blur()
+	p.area.Blur()
 
 	return p
 }
 
-// Focus is a method on the Prompt struct that focuses the text input model.
+// Focus is a method on the Prompt struct that focuses the input model.
 func (p *Prompt) Focus() *Prompt {
-	p.input.Focus()
+	if p.isMultiline() {
+		p.area.Focus()
+	} else {
+		p.input.Focus()
+	}
 
 	return p
 }
 
-// Update is a method on the Prompt struct that updates the text input model with a message.
+// Update is a method on the Prompt struct that updates the input model with a message.
+// The multi-line input grows with its content up to the maximum height.
 func (p *Prompt) Update(msg tea.Msg) (*Prompt, tea.Cmd) {
 	var updateCmd tea.Cmd
-	p.input, updateCmd = p.input.Update(msg)
+	if p.isMultiline() {
+		// Edit the input at its maximum height, so it is only scrolled when its rows do not fit
+		p.area.SetHeight(p.maxHeight)
+		p.area, updateCmd = p.area.Update(msg)
+		p.fit()
+	} else {
+		p.input, updateCmd = p.input.Update(msg)
+	}
 
 	return p, updateCmd
 }
 
-// View is a method on the Prompt struct that returns a string representation of the text input model.
+// View is a method on the Prompt struct that returns a string representation of the input model.
+// The mode of the vi keymap is displayed before the multi-line input, if enabled.
 func (p *Prompt) View() string {
+	if mode := p.GetViMode(); mode != "" {
+		indicator := p.style().Copy().Faint(mode == vi_insert_mode).Width(vi_indicator_width).Render(fmt.Sprintf("[%s]", mode))
+		return lipgloss.JoinHorizontal(lipgloss.Top, indicator, p.areaView())
+	}
+	if p.isMultiline() {
+		return p.areaView()
+	}
+
 	return p.input.View()
 }
 
 // AsString is a method on the Prompt struct that returns a string representation of the prompt.
+// The lines of a multi-line value are aligned under the first one.
 func (p *Prompt) AsString() string {
-	style := getPromptStyle(p.mode)
+	style := p.style()
+	icon := p.GetIcon()
+	indent := strings.Repeat(" ", lipgloss.Width(icon))
+
+	lines := strings.Split(p.GetValue(), "\n")
+	for i, line := range lines {
+		if i == 0 {
+			lines[i] = fmt.Sprintf("%s%s", icon, style.Render(line))
+		} else {
+			lines[i] = fmt.Sprintf("%s%s", indent, style.Render(line))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// areaView is a method on the Prompt struct that returns the view of the multi-line input, followed by the dimmed
+// suggestion whose first character is under the cursor, like the placeholder of the text area.
+func (p *Prompt) areaView() string {
+	suggestion := []rune(p.GetSuggestion())
+	if len(suggestion) == 0 || !p.area.Focused() {
+		return p.area.View()
+	}
+
+	value := p.area.Value()
+	available := p.area.Width() - runewidth.StringWidth(value)
+	suggestion = []rune(runewidth.Truncate(string(suggestion), available, ""))
+	if len(suggestion) == 0 {
+		return p.area.View()
+	}
+
+	style := p.area.FocusedStyle.Placeholder
+	cursor := p.area.Cursor
+	cursor.TextStyle = style
+	cursor.SetChar(string(suggestion[0]))
+
+	return p.GetIcon() + p.style().Render(value) + cursor.View() + style.Render(string(suggestion[1:]))
+}
+
+// matchingSuggestions is a method on the Prompt struct that returns the suggestions starting with the input and
+// longer than it, none being displayed unless the input is a single line of the multi-line input, fitting in
+// its width, with the cursor at its end.
+func (p *Prompt) matchingSuggestions() []string {
+	if len(p.suggestions) == 0 || !p.isMultiline() {
+		return nil
+	}
+
+	value := p.area.Value()
+	if value == "" || strings.Contains(value, "\n") || runewidth.StringWidth(value) >= p.area.Width() {
+		return nil
+	}
+	if info := p.area.LineInfo(); info.StartColumn+info.ColumnOffset != len([]rune(value)) {
+		return nil
+	}
+
+	var matches []string
+	for _, suggestion := range p.suggestions {
+		if len(suggestion) > len(value) && strings.HasPrefix(suggestion, value) && !strings.Contains(suggestion, "\n") {
+			matches = append(matches, suggestion)
+		}
+	}
+
+	return matches
+}
+
+// isMultiline is a method on the Prompt struct that returns whether the prompt uses the multi-line input.
+func (p *Prompt) isMultiline() bool {
+	return p.mode != ConfigPromptMode
+}
+
+// isFocused is a method on the Prompt struct that returns whether the input model is focused.
+func (p *Prompt) isFocused() bool {
+	if p.isMultiline() {
+		return p.area.Focused()
+	}
 
-	return fmt.Sprintf("%s%s", style.Render(getPromptIcon(p.mode)), style.Render(p.input.Value()))
+	return p.input.Focused()
+}
+
+// fit is a method on the Prompt struct that sets the height of the multi-line input to its number of rows,
+// up to the maximum height.
+func (p *Prompt) fit() {
+	height := p.visualLines()
+	if height > p.maxHeight {
+		height = p.maxHeight
+	}
+	p.area.SetHeight(height)
+	// Scroll the input to the cursor, like at the end of an input recalled from the history
+	if p.area.Focused() {
+		p.area, _ = p.area.Update(nil)
+	}
+}
+
+// visualLines is a method on the Prompt struct that returns the number of rows of the multi-line input, its long
+// lines being soft-wrapped at its width on the display cells, the CJK characters and the emoji taking two cells.
+func (p *Prompt) visualLines() int {
+	// Move the cursor of a copy of the input through its lines, the rows of a line being only known to the input
+	area := p.area
+	for area.Line() > 0 {
+		area.CursorStart()
+		area.CursorUp()
+	}
+
+	rows := 0
+	for {
+		area.CursorEnd()
+		rows += area.LineInfo().Height
+		if area.Line() >= area.LineCount()-1 {
+			break
+		}
+		area.CursorDown()
+	}
+
+	return rows
+}
+
+// style is a method on the Prompt struct that returns the style of the current prompt mode, in the configured color.
+func (p *Prompt) style() lipgloss.Style {
+	return p.GetModeStyle(p.mode)
+}
+
+// GetModeStyle is a method on the Prompt struct that returns the style of a prompt mode, in its configured color
+// or its default one, like to mark the inputs and the answers of the mode.
+func (p *Prompt) GetModeStyle(mode PromptMode) lipgloss.Style {
+	if color := p.indicators[mode].GetColor(); color != "" {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(color))
+	}
+
+	return getPromptStyle(mode)
+}
+
+// symbol is a method on the Prompt struct that returns the unstyled icon of the current prompt mode, the configured
+// symbol followed by a space if it fits in a single cell, an ASCII symbol if it is wider, or the default icon.
+func (p *Prompt) symbol() string {
+	symbol := p.indicators[p.mode].GetSymbol()
+	width := runewidth.StringWidth(symbol)
+	if width == 0 {
+		return getPromptSymbol(p.mode)
+	}
+	if width > prompt_symbol_max_width {
+		return getPromptAsciiSymbol(p.mode) + " "
+	}
+
+	return symbol + " "
+}
+
+// applyStyle is a method on the Prompt struct that sets the placeholder, the style and the icon of the input models
+// based on the prompt mode.
+func (p *Prompt) applyStyle() {
+	p.input.Placeholder = getPromptPlaceholder(p.mode)
+	p.input.TextStyle = p.style()
+	p.input.Prompt = p.GetIcon()
+	stylePromptArea(&p.area, p.mode, p.style(), p.GetIcon())
+}
+
+// newPromptArea is a function that creates the text area of the exec and chat prompts, where enter is left to the
+// user interface to submit the value and alt+enter or ctrl+j inserts a newline.
+func newPromptArea() textarea.Model {
+	area := textarea.New()
+	area.ShowLineNumbers = false
+	area.CharLimit = 0
+	area.MaxHeight = 0
+	area.EndOfBufferCharacter = ' '
+	area.KeyMap.InsertNewline = key.NewBinding(key.WithKeys("alt+enter", "ctrl+j"))
+	area.SetWidth(prompt_default_width)
+	area.SetHeight(1)
+
+	return area
+}
+
+// stylePromptArea is a function that sets the style, icon and placeholder of a text area based on the prompt mode.
+// The icon is displayed on the first line, the next lines being aligned under it.
+func stylePromptArea(area *textarea.Model, mode PromptMode, style lipgloss.Style, icon string) {
+	area.Placeholder = getPromptPlaceholder(mode)
+	area.FocusedStyle.Text = style
+	area.FocusedStyle.CursorLine = style
+	area.FocusedStyle.Prompt = lipgloss.NewStyle()
+	area.BlurredStyle.Text = style
+	area.BlurredStyle.CursorLine = style
+	area.BlurredStyle.Prompt = lipgloss.NewStyle()
+
+	area.SetPromptFunc(lipgloss.Width(icon), func(line int) string {
+		if line == 0 {
+			return icon
+		}
+
+		return ""
+	})
 }
 
 // getPromptStyle is a function that returns the style of the prompt based on the prompt mode.
 func getPromptStyle(mode PromptMode) lipgloss.Style {
 	switch mode {
 	case ExecPromptMode:
-		return lipgloss.NewStyle().Foreground(lipgloss.Color(exec_color))
+		return lipgloss.NewStyle().Foreground(execColor)
 	case ConfigPromptMode:
-		return lipgloss.NewStyle().Foreground(lipgloss.Color(config_color))
+		return lipgloss.NewStyle().Foreground(configColor)
 	default:
-		return lipgloss.NewStyle().Foreground(lipgloss.Color(chat_color))
+		return lipgloss.NewStyle().Foreground(chatColor)
 	}
 }
 
 // getPromptIcon is a function that returns the icon of the prompt based on the prompt mode.
 func getPromptIcon(mode PromptMode) string {
-	style := getPromptStyle(mode)
+	return getPromptStyle(mode).Render(getPromptSymbol(mode))
+}
+
+// getPromptSymbol is a function that returns the unstyled default icon of the prompt based on the prompt mode.
+func getPromptSymbol(mode PromptMode) string {
+	switch mode {
+	case ExecPromptMode:
+		return exec_icon
+	case ConfigPromptMode:
+		return config_icon
+	default:
+		return chat_icon
+	}
+}
 
+// getPromptAsciiSymbol is a function that returns the one-cell ASCII symbol of the prompt based on the prompt mode.
+func getPromptAsciiSymbol(mode PromptMode) string {
 	switch mode {
 	case ExecPromptMode:
-		return style.Render(exec_icon)
+		return exec_ascii_symbol
 	case ConfigPromptMode:
-		return style.Render(config_icon)
+		return config_ascii_symbol
 	default:
-		return style.Render(chat_icon)
+		return chat_ascii_symbol
 	}
 }
 
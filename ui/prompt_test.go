@@ -1,14 +1,32 @@
 package ui
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/akhilsharma90/terminal-assistant/config"
+	"github.com/akhilsharma90/terminal-assistant/history"
+	"github.com/akhilsharma90/terminal-assistant/run"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUIPrompt(t *testing.T) {
 	t.Run("Prompt", testPrompt)
+	t.Run("PromptEchoMode", testPromptEchoMode)
+	t.Run("PromptMultiline", testPromptMultiline)
+	t.Run("PromptLines", testPromptLines)
+	t.Run("PromptInsert", testPromptInsert)
+	t.Run("PromptWideCharacters", testPromptWideCharacters)
+	t.Run("PromptViKeymap", testPromptViKeymap)
+	t.Run("PromptSuggestion", testPromptSuggestion)
+	t.Run("PromptIndicator", testPromptIndicator)
+	t.Run("PromptIndicatorWidth", testPromptIndicatorWidth)
 	t.Run("PromptStyle", testPromptStyle)
 	t.Run("PromptIcon", testPromptIcon)
 	t.Run("PromptPlaceholder", testPromptPlaceholder)
@@ -36,6 +54,223 @@ func testPrompt(t *testing.T) {
 	}
 }
 
+// testPromptEchoMode tests that the config prompt masks its input while GetValue returns the raw value.
+func testPromptEchoMode(t *testing.T) {
+	p := NewPrompt(ConfigPromptMode)
+	assert.Equal(t, textinput.EchoPassword, p.GetEchoMode(), "The config prompt should be masked.")
+
+	p.SetValue("sk-secret")
+	assert.Equal(t, "sk-secret", p.GetValue(), "The prompt value should not be masked.")
+	assert.False(t, strings.Contains(p.View(), "sk-secret"), "The prompt view should not reveal the value.")
+
+	p.SetEchoMode(textinput.EchoNormal)
+	assert.Equal(t, textinput.EchoNormal, p.GetEchoMode(), "The echo mode should be updated.")
+	assert.Equal(t, "sk-secret", p.GetValue(), "The prompt value should not change with the echo mode.")
+
+	assert.Equal(t, textinput.EchoNormal, NewPrompt(ExecPromptMode).GetEchoMode(), "The exec prompt should not be masked.")
+}
+
+// testPromptMultiline tests that alt+enter inserts a newline, the prompt growing up to its maximum height,
+// and that the multi-line value is echoed with its lines aligned.
+func testPromptMultiline(t *testing.T) {
+	p := NewPrompt(ExecPromptMode).SetMaxHeight(2)
+	p.SetValue("first")
+	p, _ = p.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, "first", p.GetValue(), "Enter should not insert a newline.")
+
+	p, _ = p.Update(tea.KeyMsg{Type: tea.KeyEnter, Alt: true})
+	p, _ = p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("second")})
+	assert.Equal(t, "first\nsecond", p.GetValue(), "Alt+enter should insert a newline.")
+	assert.Equal(t, 2, strings.Count(p.View(), "\n")+1, "The prompt should grow with its lines.")
+
+	p, _ = p.Update(tea.KeyMsg{Type: tea.KeyCtrlJ})
+	assert.Equal(t, 2, strings.Count(p.View(), "\n")+1, "The prompt should not grow beyond its maximum height.")
+
+	lines := strings.Split(NewPrompt(ChatPromptMode).SetValue("first\nsecond").AsString(), "\n")
+	assert.Len(t, lines, 2, "The echo should keep the lines.")
+	assert.Contains(t, lines[0], chat_icon, "The first line should start with the icon.")
+	assert.True(t, strings.HasPrefix(lines[1], "     "), "The next lines should be aligned under the first one.")
+	assert.Contains(t, lines[1], "second", "The echo should contain the next lines.")
+
+	p = NewPrompt(ChatPromptMode).SetValue("kept")
+	p.SetMode(ConfigPromptMode)
+	assert.Equal(t, "kept", p.GetValue(), "The value should be kept when switching to the single-line input.")
+}
+
+// testPromptLines tests the position of the cursor used to navigate the history.
+func testPromptLines(t *testing.T) {
+	p := NewPrompt(ExecPromptMode).SetValue("single")
+	assert.True(t, p.IsFirstLine(), "A single line should be the first line.")
+	assert.True(t, p.IsLastLine(), "A single line should be the last line.")
+
+	p.SetValue("first\nsecond")
+	assert.False(t, p.IsFirstLine(), "The cursor should be on the last line.")
+	assert.True(t, p.IsLastLine(), "The cursor should be on the last line.")
+
+	p, _ = p.Update(tea.KeyMsg{Type: tea.KeyUp})
+	assert.True(t, p.IsFirstLine(), "The cursor should move to the first line.")
+	assert.False(t, p.IsLastLine(), "The cursor should move to the first line.")
+
+	assert.True(t, NewPrompt(ConfigPromptMode).IsFirstLine(), "The single-line input should always be on its first line.")
+}
+
+// testPromptInsert tests that a text is inserted at the cursor position, the multi-line input keeping its newlines.
+func testPromptInsert(t *testing.T) {
+	p := NewPrompt(ExecPromptMode).SetMaxHeight(3)
+	p.SetValue("echo ")
+	p.Insert("one\necho two")
+	assert.Equal(t, "echo one\necho two", p.GetValue())
+	assert.Equal(t, 2, strings.Count(p.View(), "\n")+1, "The prompt should grow with the inserted lines.")
+
+	p = NewPrompt(ConfigPromptMode).SetValue("sk-")
+	p.Insert("secret")
+	assert.Equal(t, "sk-secret", p.GetValue())
+}
+
+// testPromptWideCharacters tests that the inputs mixing ASCII, CJK characters and emoji are kept intact, recalled
+// from the history, echoed and soft-wrapped on the display cells, every row being displayed.
+func testPromptWideCharacters(t *testing.T) {
+	testCases := []struct {
+		name  string
+		value string
+	}{
+		{"Ascii", "list the files"},
+		{"Cjk", "日本語のファイルを一覧表示して"},
+		{"Emoji", "deploy 🚀 then celebrate 🎉🎉"},
+		{"Mixed", "find 写真 in ~/Pictures 📷\n然后 zip them"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := history.NewHistory().Add(tc.value)
+			p := NewPrompt(ExecPromptMode).SetWidth(20)
+			p.Focus()
+			p.SetValue(*h.GetPrevious())
+			assert.Equal(t, tc.value, p.GetValue(), "The recalled input should be intact.")
+
+			p.SetValue("")
+			for _, r := range tc.value {
+				if r == '\n' {
+					p.Insert("\n")
+					continue
+				}
+				p, _ = p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+			}
+			assert.Equal(t, tc.value, p.GetValue(), "The typed input should be intact.")
+
+			view := run.StripAnsi(p.View())
+			for _, line := range strings.Split(view, "\n") {
+				assert.LessOrEqual(t, lipgloss.Width(line), 20, "The input should be wrapped on the display cells.")
+			}
+			assert.Equal(t, strings.Join(strings.Fields(tc.value), ""), strings.Join(strings.Fields(strings.ReplaceAll(view, p.GetIcon(), "")), ""),
+				"Every row of the input should be displayed.")
+
+			echo := strings.Split(run.StripAnsi(p.AsString()), "\n")
+			assert.Equal(t, p.GetIcon()+strings.Split(tc.value, "\n")[0], echo[0], "The echo should not be truncated.")
+			for _, line := range echo[1:] {
+				assert.True(t, strings.HasPrefix(line, strings.Repeat(" ", lipgloss.Width(p.GetIcon()))), "The lines should be aligned under the first one.")
+			}
+		})
+	}
+}
+
+// testPromptSuggestion tests that the rest of the first input completing a single-line input is displayed dimmed
+// after it when the cursor is at its end, cycled through and appended to the input when accepted.
+func testPromptSuggestion(t *testing.T) {
+	p := NewPrompt(ExecPromptMode).SetValue("git")
+	p.WithSuggestions([]string{"git status", "ls", "git log"})
+	assert.Equal(t, " status", p.GetSuggestion())
+	assert.True(t, p.HasSuggestions())
+	assert.Contains(t, p.View(), "status", "The suggestion should be displayed after the input.")
+
+	require.True(t, p.NextSuggestion())
+	assert.Equal(t, " log", p.GetSuggestion(), "The inputs without the typed text should be skipped.")
+	p.NextSuggestion()
+	assert.Equal(t, " status", p.GetSuggestion(), "The first input should be suggested after the last one.")
+
+	p.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	assert.Empty(t, p.GetSuggestion(), "The suggestion should be hidden when the cursor is not at the end.")
+	assert.False(t, p.AcceptSuggestion())
+	assert.NotContains(t, p.View(), "status")
+
+	p.Update(tea.KeyMsg{Type: tea.KeyRight})
+	require.True(t, p.AcceptSuggestion())
+	assert.Equal(t, "git status", p.GetValue())
+	assert.Empty(t, p.GetSuggestion(), "The accepted input should not be suggested again.")
+	assert.False(t, p.NextSuggestion())
+
+	p.SetValue("first\nsecond").WithSuggestions([]string{"first\nsecond line"})
+	assert.Empty(t, p.GetSuggestion(), "Nothing should be suggested after several lines.")
+	config := NewPrompt(ConfigPromptMode).SetValue("sk")
+	config.WithSuggestions([]string{"sk-key"})
+	assert.Empty(t, config.GetSuggestion(), "Nothing should be suggested in the configuration prompt.")
+}
+
+// testPromptViKeymap tests that the vi keymap edits the multi-line input and moves its cursor,
+// its mode being displayed before the input.
+func testPromptViKeymap(t *testing.T) {
+	p := NewPrompt(ExecPromptMode)
+	assert.False(t, p.HandleViKey(tea.KeyMsg{Type: tea.KeyEsc}), "The vi keymap should be disabled by default.")
+	assert.Empty(t, p.GetViMode())
+
+	p.SetEditingMode("vi").SetValue("first line\nsecond line")
+	assert.Equal(t, vi_insert_mode, p.GetViMode(), "The vi keymap should start in the insert mode.")
+	assert.Contains(t, p.View(), "[INSERT]")
+
+	require.True(t, p.HandleViKey(tea.KeyMsg{Type: tea.KeyEsc}))
+	assert.Contains(t, p.View(), "[NORMAL]")
+	for _, msg := range viKeys("bcw") {
+		p.HandleViKey(msg)
+	}
+	p, _ = p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("last")})
+	assert.Equal(t, "first line\nsecond last", p.GetValue(), "The changed word should be replaced at the cursor.")
+
+	p.HandleViKey(tea.KeyMsg{Type: tea.KeyEsc})
+	p, _ = p.Update(tea.KeyMsg{Type: tea.KeyUp})
+	for _, msg := range viKeys("ddx") {
+		p.HandleViKey(msg)
+	}
+	assert.Equal(t, "econd last", p.GetValue(), "The first line should be deleted, then the first character of the next one.")
+
+	assert.Empty(t, NewPrompt(ConfigPromptMode).SetEditingMode("vi").GetViMode(), "The configuration prompt should keep the default keys.")
+}
+
+// testPromptIndicator tests that the configured symbols are displayed before the input and echoed with it,
+// the empty symbols being replaced by the default icon.
+func testPromptIndicator(t *testing.T) {
+	p := NewPrompt(ExecPromptMode).
+		SetIndicator(ExecPromptMode, config.NewPromptIndicator("❯", "#00ff00")).
+		SetIndicator(ChatPromptMode, config.NewPromptIndicator("?", "")).
+		SetValue("first\nsecond")
+
+	assert.Equal(t, "❯ ", run.StripAnsi(p.GetIcon()), "The configured symbol should be displayed.")
+	assert.True(t, strings.HasPrefix(run.StripAnsi(p.View()), "❯ first"), "The input should follow the configured symbol.")
+	assert.Equal(t, "❯ first\n  second", run.StripAnsi(p.AsString()), "The echoed input should use the configured symbol.")
+
+	p.SetMode(ChatPromptMode)
+	assert.Equal(t, "? ", run.StripAnsi(p.GetIcon()), "The symbol of the new mode should be displayed.")
+
+	p = NewPrompt(ConfigPromptMode).SetIndicator(ConfigPromptMode, config.NewPromptIndicator("", ""))
+	assert.Equal(t, config_icon, run.StripAnsi(p.GetIcon()), "The default icon should replace an empty symbol.")
+}
+
+// testPromptIndicatorWidth tests that the configured symbols wider than the maximum width of one cell are replaced
+// by a one-cell ASCII symbol, and that the icon of the echoed input is rendered once.
+func testPromptIndicatorWidth(t *testing.T) {
+	for _, symbol := range []string{"🚀", ">>"} {
+		p := NewPrompt(ConfigPromptMode).SetIndicator(ConfigPromptMode, config.NewPromptIndicator(symbol, ""))
+		icon := run.StripAnsi(p.GetIcon())
+		assert.Equal(t, config_ascii_symbol+" ", icon, "An ASCII symbol should replace %q.", symbol)
+		assert.Equal(t, prompt_symbol_max_width+1, lipgloss.Width(icon), "The symbol should fit in a single cell.")
+	}
+
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	t.Cleanup(func() { lipgloss.SetColorProfile(profile) })
+	p := NewPrompt(ExecPromptMode).SetIndicator(ExecPromptMode, config.NewPromptIndicator("❯", "#00ff00")).SetValue("ls")
+	assert.True(t, strings.HasPrefix(p.AsString(), p.GetIcon()), "The echoed icon should be rendered once.")
+}
+
 // testPromptStyle tests the prompt style for different prompt modes.
 // It verifies that the prompt style is not nil for each mode.
 func testPromptStyle(t *testing.T) {
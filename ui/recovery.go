@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// RecoveryMsg is a message reporting a panic recovered in a command, so the program can quit gracefully.
+type RecoveryMsg struct {
+	Stack string // The recovered value and the stack trace of the panic.
+}
+
+// safeCmd is a function that wraps a command, converting its panics into a RecoveryMsg.
+// The commands run in their own goroutines, where a panic would crash without restoring the terminal.
+func safeCmd(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+
+	return func() (msg tea.Msg) {
+		defer func() {
+			if r := recover(); r != nil {
+				msg = RecoveryMsg{
+					Stack: fmt.Sprintf("%v\n%s", r, debug.Stack()),
+				}
+			}
+		}()
+
+		return cmd()
+	}
+}
+
+// writeCrashLog is a function that appends the stack of a recovered panic to the crash log file.
+func writeCrashLog(path string, stack string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "[%s] panic: %s\n", time.Now().Format(time.RFC3339), stack)
+
+	return err
+}
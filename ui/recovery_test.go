@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUIRecovery(t *testing.T) {
+	t.Run("SafeCmd", testSafeCmd)
+	t.Run("SafeCmdPanic", testSafeCmdPanic)
+	t.Run("WriteCrashLog", testWriteCrashLog)
+}
+
+// testSafeCmd tests that safeCmd forwards the message of the wrapped command.
+func testSafeCmd(t *testing.T) {
+	assert.Nil(t, safeCmd(nil), "A nil command should stay nil.")
+
+	cmd := safeCmd(func() tea.Msg {
+		return "message"
+	})
+	assert.Equal(t, "message", cmd(), "The message should be forwarded.")
+}
+
+// testSafeCmdPanic tests that safeCmd converts the panics into a RecoveryMsg.
+func testSafeCmdPanic(t *testing.T) {
+	cmd := safeCmd(func() tea.Msg {
+		var engine *struct{ name string }
+		return engine.name
+	})
+
+	msg, ok := cmd().(RecoveryMsg)
+	require.True(t, ok, "The panic should be converted into a RecoveryMsg.")
+	assert.Contains(t, msg.Stack, "nil pointer dereference", "The recovered value should be kept.")
+	assert.Contains(t, msg.Stack, "testSafeCmdPanic", "The stack trace should be kept.")
+}
+
+// testWriteCrashLog tests the writeCrashLog function.
+func testWriteCrashLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "crash.log")
+
+	require.NoError(t, writeCrashLog(path, "first"))
+	require.NoError(t, writeCrashLog(path, "second"))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "panic: first\n", "The crash should be logged.")
+	assert.Contains(t, string(content), "panic: second\n", "The crashes should be appended.")
+}
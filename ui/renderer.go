@@ -1,51 +1,435 @@
 package ui
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/akhilsharma90/terminal-assistant/export"
+	"github.com/akhilsharma90/terminal-assistant/run"
+
+	"github.com/alecthomas/chroma/styles"
 	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/ansi"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/mitchellh/go-homedir"
+)
+
+// Colors used for rendering different types of content, on a dark or a light background.
+var (
+	execColor      = lipgloss.AdaptiveColor{Dark: "#ffa657", Light: "#bc4c00"}
+	configColor    = lipgloss.AdaptiveColor{Dark: "#ffffff", Light: "#24292f"}
+	chatColor      = lipgloss.AdaptiveColor{Dark: "#66b3ff", Light: "#0969da"}
+	helpColor      = lipgloss.AdaptiveColor{Dark: "#aaaaaa", Light: "#57606a"}
+	errorColor     = lipgloss.AdaptiveColor{Dark: "#cc3333", Light: "#cf222e"}
+	warningColor   = lipgloss.AdaptiveColor{Dark: "#ffcc00", Light: "#9a6700"}
+	successColor   = lipgloss.AdaptiveColor{Dark: "#46b946", Light: "#1a7f37"}
+	signalColor    = lipgloss.AdaptiveColor{Dark: "#cc66cc", Light: "#8250df"}
+	userColor      = lipgloss.AdaptiveColor{Dark: "#66b3ff", Light: "#0969da"}
+	assistantColor = lipgloss.AdaptiveColor{Dark: "#46b946", Light: "#1a7f37"}
+	badgeColor     = lipgloss.AdaptiveColor{Dark: "#ffffff", Light: "#ffffff"}
+	statusColor    = lipgloss.AdaptiveColor{Dark: "#dddddd", Light: "#24292f"}
+	statusBgColor  = lipgloss.AdaptiveColor{Dark: "#333333", Light: "#eaeef2"}
 )
 
-// Colors used for rendering different types of content.
+// Styles of the markdown content and color schemes of the user interface, besides the paths of the glamour styles.
 const (
-	exec_color    = "#ffa657"
-	config_color  = "#ffffff"
-	chat_color    = "#66b3ff"
-	help_color    = "#aaaaaa"
-	error_color   = "#cc3333"
-	warning_color = "#ffcc00"
-	success_color = "#46b946"
+	auto_style  = "auto"
+	dark_style  = "dark"
+	light_style = "light"
+)
+
+// key_val_min_width is the minimum width of the keys of the key-value pairs, so the short keys align their values.
+const key_val_min_width = 12
+
+// Themes of the conversation.
+const (
+	default_theme = "default"
+	minimal_theme = "minimal"
 )
 
 // Renderer is a struct that represents a renderer for different types of content.
 type Renderer struct {
-	contentRenderer *glamour.TermRenderer
-	successRenderer lipgloss.Style
-	warningRenderer lipgloss.Style
-	errorRenderer   lipgloss.Style
-	helpRenderer    lipgloss.Style
+	contentRenderer        *glamour.TermRenderer
+	contentOptions         []glamour.TermRendererOption
+	width                  int
+	codeStyle              string
+	markdownStyle          string
+	markdownStyles         *ansi.StyleConfig
+	theme                  string
+	successRenderer        lipgloss.Style
+	warningRenderer        lipgloss.Style
+	errorRenderer          lipgloss.Style
+	signalRenderer         lipgloss.Style
+	exitCodeRenderer       lipgloss.Style
+	warningExitRenderer    lipgloss.Style
+	failedExitRenderer     lipgloss.Style
+	signalExitRenderer     lipgloss.Style
+	helpRenderer           lipgloss.Style
+	helpStrongRenderer     lipgloss.Style
+	helpEmphasisRenderer   lipgloss.Style
+	helpCodeRenderer       lipgloss.Style
+	hintRenderer           lipgloss.Style
+	keyRenderer            lipgloss.Style
+	stderrRenderer         lipgloss.Style
+	userBadgeRenderer      lipgloss.Style
+	assistantBadgeRenderer lipgloss.Style
+	autoBadgeRenderer      lipgloss.Style
+	commandRenderer        lipgloss.Style
+	statusBarRenderer      lipgloss.Style
+	confirmationRenderers  map[run.RiskLevel]lipgloss.Style
+	choiceRenderer         lipgloss.Style
+	choiceHighlight        lipgloss.Style
+	yesChoiceRenderers     map[run.RiskLevel]lipgloss.Style
+	noColor                bool
 }
 
 // NewRenderer is a function that creates a new Renderer instance.
+// The colors are disabled when the NO_COLOR environment variable is set.
 func NewRenderer(options ...glamour.TermRendererOption) *Renderer {
+	return NewColorRenderer(!IsNoColor(), options...)
+}
+
+// NewColorRenderer is a function that creates a new Renderer instance with the colors enabled or disabled, like by the
+// --no-color flag, whatever the NO_COLOR environment variable.
+func NewColorRenderer(color bool, options ...glamour.TermRendererOption) *Renderer {
+	if !color {
+		// Override the style of the content with a style without ANSI escape codes.
+		options = append(options, glamour.WithStyles(glamour.NoTTYStyleConfig))
+	}
+
 	// Create a new terminal renderer with the provided options.
 	contentRenderer, err := glamour.NewTermRenderer(options...)
 	if err != nil {
 		return nil
 	}
 
-	// Create new styles for rendering success, warning, error, and help messages.
-	successRenderer := lipgloss.NewStyle().Foreground(lipgloss.Color(success_color))
-	warningRenderer := lipgloss.NewStyle().Foreground(lipgloss.Color(warning_color))
-	errorRenderer := lipgloss.NewStyle().Foreground(lipgloss.Color(error_color))
-	helpRenderer := lipgloss.NewStyle().Foreground(lipgloss.Color(help_color)).Italic(true)
+	if !color {
+		return &Renderer{
+			contentRenderer:        contentRenderer,
+			contentOptions:         options,
+			successRenderer:        lipgloss.NewStyle(),
+			warningRenderer:        lipgloss.NewStyle(),
+			errorRenderer:          lipgloss.NewStyle(),
+			signalRenderer:         lipgloss.NewStyle(),
+			exitCodeRenderer:       lipgloss.NewStyle(),
+			warningExitRenderer:    lipgloss.NewStyle(),
+			failedExitRenderer:     lipgloss.NewStyle(),
+			signalExitRenderer:     lipgloss.NewStyle(),
+			helpRenderer:           lipgloss.NewStyle(),
+			helpStrongRenderer:     lipgloss.NewStyle(),
+			helpEmphasisRenderer:   lipgloss.NewStyle(),
+			helpCodeRenderer:       lipgloss.NewStyle(),
+			hintRenderer:           lipgloss.NewStyle(),
+			keyRenderer:            lipgloss.NewStyle(),
+			stderrRenderer:         lipgloss.NewStyle().Border(lipgloss.NormalBorder(), false, false, false, true).PaddingLeft(1),
+			userBadgeRenderer:      lipgloss.NewStyle(),
+			assistantBadgeRenderer: lipgloss.NewStyle(),
+			autoBadgeRenderer:      lipgloss.NewStyle(),
+			commandRenderer:        lipgloss.NewStyle().Border(lipgloss.NormalBorder(), false, false, false, true).PaddingLeft(1),
+			statusBarRenderer:      lipgloss.NewStyle().Padding(0, 1),
+			confirmationRenderers:  map[run.RiskLevel]lipgloss.Style{},
+			choiceRenderer:         lipgloss.NewStyle(),
+			choiceHighlight:        lipgloss.NewStyle(),
+			yesChoiceRenderers:     map[run.RiskLevel]lipgloss.Style{},
+			noColor:                true,
+		}
+	}
+
+	// Create new styles for rendering success, warning, error, and help messages, and the conversation badges.
+	successRenderer := lipgloss.NewStyle().Foreground(successColor)
+	warningRenderer := lipgloss.NewStyle().Foreground(warningColor)
+	errorRenderer := lipgloss.NewStyle().Foreground(errorColor)
+	signalRenderer := lipgloss.NewStyle().Foreground(signalColor)
+	helpRenderer := lipgloss.NewStyle().Foreground(helpColor).Italic(true)
+	stderrRenderer := lipgloss.NewStyle().Faint(true).Border(lipgloss.NormalBorder(), false, false, false, true).PaddingLeft(1)
+	badgeRenderer := lipgloss.NewStyle().Bold(true).Padding(0, 1).Foreground(badgeColor)
+	confirmationRenderer := confirmationStyle()
+	choiceRenderer := lipgloss.NewStyle().Bold(true)
 
 	return &Renderer{
-		contentRenderer: contentRenderer,
-		successRenderer: successRenderer,
-		warningRenderer: warningRenderer,
-		errorRenderer:   errorRenderer,
-		helpRenderer:    helpRenderer,
+		contentRenderer:        contentRenderer,
+		contentOptions:         options,
+		successRenderer:        successRenderer,
+		warningRenderer:        warningRenderer,
+		errorRenderer:          errorRenderer,
+		signalRenderer:         signalRenderer,
+		exitCodeRenderer:       successRenderer.Copy().Bold(true),
+		warningExitRenderer:    warningRenderer.Copy().Bold(true),
+		failedExitRenderer:     errorRenderer.Copy().Bold(true),
+		signalExitRenderer:     signalRenderer.Copy().Bold(true),
+		helpRenderer:           helpRenderer,
+		helpStrongRenderer:     helpRenderer.Copy().Bold(true),
+		helpEmphasisRenderer:   helpRenderer.Copy().Italic(false),
+		helpCodeRenderer:       lipgloss.NewStyle().Foreground(configColor),
+		hintRenderer:           lipgloss.NewStyle().Foreground(helpColor).Faint(true),
+		keyRenderer:            lipgloss.NewStyle().Bold(true),
+		stderrRenderer:         stderrRenderer,
+		userBadgeRenderer:      badgeRenderer.Copy().Background(userColor),
+		assistantBadgeRenderer: badgeRenderer.Copy().Background(assistantColor),
+		autoBadgeRenderer:      badgeRenderer.Copy().Background(execColor),
+		commandRenderer:        lipgloss.NewStyle().Bold(true).Border(lipgloss.NormalBorder(), false, false, false, true).BorderForeground(execColor).PaddingLeft(1),
+		statusBarRenderer:      lipgloss.NewStyle().Padding(0, 1).Foreground(statusColor).Background(statusBgColor),
+		// The border of the confirmation is green for the safe commands, yellow for the low and medium risks,
+		// and red for the high and critical risks.
+		confirmationRenderers: map[run.RiskLevel]lipgloss.Style{
+			run.SafeRisk:     confirmationRenderer.Copy().BorderForeground(successColor),
+			run.LowRisk:      confirmationRenderer.Copy().BorderForeground(warningColor),
+			run.MediumRisk:   confirmationRenderer.Copy().BorderForeground(warningColor),
+			run.HighRisk:     confirmationRenderer.Copy().BorderForeground(errorColor),
+			run.CriticalRisk: confirmationRenderer.Copy().BorderForeground(errorColor),
+		},
+		choiceRenderer:  choiceRenderer,
+		choiceHighlight: lipgloss.NewStyle().Reverse(true),
+		// The Yes choice of the confirmation is colored like its border.
+		yesChoiceRenderers: map[run.RiskLevel]lipgloss.Style{
+			run.SafeRisk:     choiceRenderer.Copy().Foreground(successColor),
+			run.LowRisk:      choiceRenderer.Copy().Foreground(warningColor),
+			run.MediumRisk:   choiceRenderer.Copy().Foreground(warningColor),
+			run.HighRisk:     choiceRenderer.Copy().Foreground(errorColor),
+			run.CriticalRisk: choiceRenderer.Copy().Foreground(errorColor),
+		},
+	}
+}
+
+// confirmationStyle is a function that returns the style of the confirmation prompt, without border color.
+func confirmationStyle() lipgloss.Style {
+	return lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1).MarginLeft(2)
+}
+
+// Resize is a method on the Renderer struct that wraps the rendered content at a new width. Only the content renderer
+// is reconfigured, the styles being kept, and nothing is done if the width did not change. The current content
+// renderer is kept if the new one cannot be created.
+func (r *Renderer) Resize(width int) error {
+	if width == r.width {
+		return nil
+	}
+
+	contentRenderer, err := r.newContentRenderer(width, r.codeStyle, r.markdownStyles)
+	if err != nil {
+		return err
+	}
+
+	r.contentRenderer = contentRenderer
+	r.width = width
+
+	return nil
+}
+
+// SetCodeStyle is a method on the Renderer struct that highlights the code blocks with a chroma style, like "monokai"
+// or "dracula", an empty name restoring the default highlighting. The style replaces the style of the content with
+// the standard dark or light style, depending on the background of the terminal, and is ignored when the colors are
+// disabled. An error is returned if the style does not exist, the current highlighting being kept.
+func (r *Renderer) SetCodeStyle(name string) error {
+	if name == r.codeStyle {
+		return nil
+	}
+	if _, ok := styles.Registry[name]; name != "" && !ok {
+		return fmt.Errorf("unknown code style %q", name)
+	}
+
+	contentRenderer, err := r.newContentRenderer(r.width, name, r.markdownStyles)
+	if err != nil {
+		return err
+	}
+
+	r.contentRenderer = contentRenderer
+	r.codeStyle = name
+
+	return nil
+}
+
+// SetMarkdownStyle is a method on the Renderer struct that sets the style of the markdown content: "auto", or an
+// empty name, detects the background of the terminal, "dark" and "light" select the standard styles, and any other
+// name is the path of a glamour style JSON file. The style is ignored when the colors are disabled. An error is
+// returned if the file cannot be read or is not a glamour style, the current style being kept.
+func (r *Renderer) SetMarkdownStyle(name string) error {
+	if name == r.markdownStyle {
+		return nil
+	}
+
+	markdownStyles, err := loadMarkdownStyle(name)
+	if err != nil {
+		return err
+	}
+	contentRenderer, err := r.newContentRenderer(r.width, r.codeStyle, markdownStyles)
+	if err != nil {
+		return err
+	}
+
+	r.contentRenderer = contentRenderer
+	r.markdownStyle = name
+	r.markdownStyles = markdownStyles
+
+	return nil
+}
+
+// GetMarkdownStyle is a method on the Renderer struct that returns the style of the markdown content.
+func (r *Renderer) GetMarkdownStyle() string {
+	if r.markdownStyle == "" {
+		return auto_style
+	}
+
+	return r.markdownStyle
+}
+
+// loadMarkdownStyle is a function that returns the glamour style of a markdown style name, nil for the style detected
+// from the background of the terminal. The style files must only have the fields of a glamour style.
+func loadMarkdownStyle(name string) (*ansi.StyleConfig, error) {
+	switch strings.ToLower(name) {
+	case "", auto_style:
+		return nil, nil
+	case dark_style:
+		style := glamour.DarkStyleConfig
+		return &style, nil
+	case light_style:
+		style := glamour.LightStyleConfig
+		return &style, nil
+	}
+
+	path, err := homedir.Expand(name)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid markdown style %q: %w", name, err)
+	}
+	var style ansi.StyleConfig
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&style); err != nil {
+		return nil, fmt.Errorf("invalid markdown style %q: %w", name, err)
+	}
+
+	return &style, nil
+}
+
+// SetColorScheme is a function that sets the colors of the prompt, the status bar and the messages for a dark or a
+// light background, "auto", or an empty name, detecting the background of the terminal. The standard markdown style
+// picked for a code style follows it, while the "auto" markdown style keeps detecting the background of the terminal
+// by itself. The detection cannot be restored once a color scheme is set, until the next start. An error is returned
+// if the color scheme does not exist.
+func SetColorScheme(name string) error {
+	switch strings.ToLower(name) {
+	case "", auto_style:
+	case dark_style:
+		lipgloss.SetHasDarkBackground(true)
+	case light_style:
+		lipgloss.SetHasDarkBackground(false)
+	default:
+		return fmt.Errorf("unknown color scheme %q", name)
+	}
+
+	return nil
+}
+
+// GetCodeStyle is a method on the Renderer struct that returns the chroma style of the code blocks,
+// empty for the default highlighting.
+func (r *Renderer) GetCodeStyle() string {
+	return r.codeStyle
+}
+
+// SetTheme is a method on the Renderer struct that sets the theme of the conversation: "default" marks the echoed
+// inputs and the answers with their prompt mode, while "minimal" leaves them unmarked, an empty name restoring the
+// default theme. An error is returned if the theme does not exist, the current theme being kept.
+func (r *Renderer) SetTheme(name string) error {
+	switch name {
+	case "", default_theme:
+		r.theme = default_theme
+	case minimal_theme:
+		r.theme = minimal_theme
+	default:
+		return fmt.Errorf("unknown theme %q", name)
+	}
+
+	return nil
+}
+
+// GetTheme is a method on the Renderer struct that returns the theme of the conversation.
+func (r *Renderer) GetTheme() string {
+	if r.theme == "" {
+		return default_theme
+	}
+
+	return r.theme
+}
+
+// newContentRenderer is a method on the Renderer struct that creates a content renderer from the options of the
+// renderer, wrapping the content at a width, if any, with a markdown style, if any, and highlighting the code blocks
+// with a chroma style, if any.
+func (r *Renderer) newContentRenderer(width int, codeStyle string, markdownStyles *ansi.StyleConfig) (*glamour.TermRenderer, error) {
+	options := make([]glamour.TermRendererOption, 0, len(r.contentOptions)+2)
+	options = append(options, r.contentOptions...)
+	if (codeStyle != "" || markdownStyles != nil) && !r.noColor {
+		style := glamour.LightStyleConfig
+		if lipgloss.HasDarkBackground() {
+			style = glamour.DarkStyleConfig
+		}
+		if markdownStyles != nil {
+			style = *markdownStyles
+		}
+		if codeStyle != "" {
+			style.CodeBlock.Theme = codeStyle
+			style.CodeBlock.Chroma = nil
+		}
+		options = append(options, glamour.WithStyles(style))
+	}
+	if width > 0 {
+		options = append(options, glamour.WithWordWrap(width))
+	}
+
+	return glamour.NewTermRenderer(options...)
+}
+
+// GetWidth is a method on the Renderer struct that returns the width the content is wrapped at, 0 if it was
+// not resized yet.
+func (r *Renderer) GetWidth() int {
+	return r.width
+}
+
+// IsNoColor is a function that checks if the colors are disabled by the NO_COLOR environment variable.
+func IsNoColor() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// RenderEcho is a method on the Renderer struct that renders an input echoed in the conversation, its first line being
+// followed by the label of its prompt mode in the faint style of the mode, unless the theme is minimal.
+func (r *Renderer) RenderEcho(echo string, label string, style lipgloss.Style) string {
+	if r.GetTheme() == minimal_theme {
+		return echo
 	}
+
+	first, rest, multiline := strings.Cut(echo, "\n")
+	echo = first + r.modeMarkerStyle(style).Render(" · "+label)
+	if multiline {
+		echo += "\n" + rest
+	}
+
+	return echo
+}
+
+// RenderAnswerMarker is a method on the Renderer struct that renders the line marking an answer with the label of its
+// prompt mode in the faint style of the mode, empty if the theme is minimal.
+func (r *Renderer) RenderAnswerMarker(label string, style lipgloss.Style) string {
+	if r.GetTheme() == minimal_theme {
+		return ""
+	}
+
+	return r.modeMarkerStyle(style).Render("  ┃ " + label)
+}
+
+// modeMarkerStyle is a method on the Renderer struct that returns the faint style of the markers of a prompt mode,
+// without colors when they are disabled.
+func (r *Renderer) modeMarkerStyle(style lipgloss.Style) lipgloss.Style {
+	if r.noColor {
+		return lipgloss.NewStyle()
+	}
+
+	return style.Copy().Faint(true)
 }
 
 // RenderContent is a method on the Renderer struct that renders general content.
@@ -55,6 +439,17 @@ func (r *Renderer) RenderContent(in string) string {
 	return out
 }
 
+// RenderRawMarkdown is a method on the Renderer struct that displays a markdown content as is, in a fenced block longer
+// than the fences it contains, so it can be copied exactly.
+func (r *Renderer) RenderRawMarkdown(in string) string {
+	fence := "```"
+	for strings.Contains(in, fence) {
+		fence += "`"
+	}
+
+	return fmt.Sprintf("%smarkdown\n%s\n%s\n", fence, strings.TrimRight(in, "\n"), fence)
+}
+
 // Renders a success message.
 func (r *Renderer) RenderSuccess(in string) string {
 	return r.successRenderer.Render(in)
@@ -70,9 +465,228 @@ func (r *Renderer) RenderError(in string) string {
 	return r.errorRenderer.Render(in)
 }
 
-// Renders a help message.
+// RenderOutput is a method on the Renderer struct that renders the status of a command colored by its exit code:
+// green for a success, yellow for the exit code 1, which often only tells that nothing matched like with grep, red
+// for the other exit codes and magenta for a negative exit code, the command being killed by a signal.
+func (r *Renderer) RenderOutput(output string, exitCode int) string {
+	switch {
+	case exitCode == 0:
+		return r.successRenderer.Render(output)
+	case exitCode == 1:
+		return r.warningRenderer.Render(output)
+	case exitCode < 0:
+		return r.signalRenderer.Render(output)
+	default:
+		return r.errorRenderer.Render(output)
+	}
+}
+
+// RenderExitCode is a method on the Renderer struct that renders the exit code of a command in bold, colored like
+// the status of the command by RenderOutput.
+func (r *Renderer) RenderExitCode(code int) string {
+	switch {
+	case code == 0:
+		return r.exitCodeRenderer.Render(run.FormatExitCode(code))
+	case code == 1:
+		return r.warningExitRenderer.Render(run.FormatExitCode(code))
+	case code < 0:
+		return r.signalExitRenderer.Render(run.FormatExitCode(code))
+	default:
+		return r.failedExitRenderer.Render(run.FormatExitCode(code))
+	}
+}
+
+// RenderHelp is a method on the Renderer struct that renders a help message written in inline markdown, the **strong**
+// text being bold, the *emphasized* text upright and the `code` spans highlighted, on a single line unless it has
+// newlines. The raw values of a message, like the commands and the paths, are escaped with escapeMarkdown.
 func (r *Renderer) RenderHelp(in string) string {
-	return r.helpRenderer.Render(in)
+	spans := parseInlineMarkdown(in)
+	if len(spans) == 0 {
+		return r.helpRenderer.Render(in)
+	}
+	if len(spans) == 1 && spans[0].kind == plainSpan {
+		return r.helpRenderer.Render(spans[0].text)
+	}
+
+	var rendered strings.Builder
+	for _, span := range spans {
+		style := r.helpRenderer
+		switch span.kind {
+		case strongSpan:
+			style = r.helpStrongRenderer
+		case emphasisSpan:
+			style = r.helpEmphasisRenderer
+		case codeSpan:
+			style = r.helpCodeRenderer
+		}
+		rendered.WriteString(style.Render(span.text))
+	}
+
+	return rendered.String()
+}
+
+// RenderHint is a method on the Renderer struct that renders the dim line of the keys available under the prompt.
+func (r *Renderer) RenderHint(in string) string {
+	return r.hintRenderer.Render(in)
+}
+
+// RenderKeyValList is a method on the Renderer struct that renders key-value pairs on consecutive lines, the keys in
+// bold being padded to the widest of them, at least key_val_min_width, so all the values are aligned.
+func (r *Renderer) RenderKeyValList(pairs [][2]string) string {
+	width := key_val_min_width
+	for _, pair := range pairs {
+		if w := runewidth.StringWidth(pair[0]); w > width {
+			width = w
+		}
+	}
+
+	lines := make([]string, len(pairs))
+	for i, pair := range pairs {
+		lines[i] = r.renderKeyVal(pair[0], pair[1], width)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// renderKeyVal is a method on the Renderer struct that renders a key-value pair, the key being padded to a width.
+func (r *Renderer) renderKeyVal(key string, value string, width int) string {
+	padding := ""
+	if w := runewidth.StringWidth(key); w < width {
+		padding = strings.Repeat(" ", width-w)
+	}
+
+	return fmt.Sprintf("%s%s %s", r.keyRenderer.Render(key), padding, value)
+}
+
+// RenderCapturedOutput is a method on the Renderer struct that renders the captured output of a command as a code block.
+func (r *Renderer) RenderCapturedOutput(stdout string, stderr string) string {
+	output := strings.TrimRight(run.StripAnsi(stdout+stderr), "\n")
+	if output == "" {
+		return ""
+	}
+
+	return r.RenderContent(fmt.Sprintf("```\n%s\n```", output))
+}
+
+// RenderCollapsedOutput is a method on the Renderer struct that renders the last lines of the captured output
+// of a command, the earlier lines being replaced by a dim marker counting them.
+func (r *Renderer) RenderCollapsedOutput(stdout string, stderr string, maxLines int) string {
+	output := strings.TrimRight(run.StripAnsi(stdout+stderr), "\n")
+	lines := strings.Split(output, "\n")
+	if maxLines < 1 || len(lines) <= maxLines {
+		return r.RenderCapturedOutput(stdout, stderr)
+	}
+
+	marker := r.RenderHelp(fmt.Sprintf("[… %d earlier lines]", len(lines)-maxLines))
+
+	return fmt.Sprintf("%s\n%s", marker, r.RenderCapturedOutput(strings.Join(lines[len(lines)-maxLines:], "\n"), ""))
+}
+
+// RenderStderrTail is a method on the Renderer struct that renders the tail of the standard error of a failed command
+// as a dim block, truncating the lines longer than the given width.
+func (r *Renderer) RenderStderrTail(tail string, width int) string {
+	if tail == "" {
+		return ""
+	}
+
+	lines := strings.Split(tail, "\n")
+	for i, line := range lines {
+		lines[i] = truncateLine(line, width-r.stderrRenderer.GetHorizontalFrameSize())
+	}
+
+	return r.stderrRenderer.Render(strings.Join(lines, "\n"))
+}
+
+// RenderConversationTurn is a method on the Renderer struct that renders a turn of the conversation
+// as a colored role badge followed by its markdown content.
+func (r *Renderer) RenderConversationTurn(role string, content string) string {
+	badge := r.assistantBadgeRenderer.Render(export.GetRoleLabel(role))
+	if role == export.UserRole {
+		badge = r.userBadgeRenderer.Render(export.GetRoleLabel(role))
+	}
+
+	return fmt.Sprintf("%s\n%s", badge, r.RenderContent(content))
+}
+
+// RenderMarkdownToHTMLString is a method on the Renderer struct that converts a markdown content to an HTML string,
+// the same way as the exported sessions.
+func (r *Renderer) RenderMarkdownToHTMLString(content string) (string, error) {
+	return export.MarkdownToHTML(content)
+}
+
+// RenderConfirmation is a method on the Renderer struct that renders the confirmation prompt of a command
+// in a border colored according to the risk level of the command.
+func (r *Renderer) RenderConfirmation(in string, level run.RiskLevel) string {
+	style, ok := r.confirmationRenderers[level]
+	if !ok {
+		style = confirmationStyle()
+	}
+
+	return style.Render(in)
+}
+
+// RenderConfirmationChoices is a method on the Renderer struct that renders the Yes and No choices of the confirmation
+// of a command, the Yes choice being colored according to the risk level of the command. The highlighted choice is
+// framed by brackets and reversed.
+func (r *Renderer) RenderConfirmationChoices(yes bool, level run.RiskLevel) string {
+	yesStyle, ok := r.yesChoiceRenderers[level]
+	if !ok {
+		yesStyle = r.choiceRenderer
+	}
+	noStyle := r.choiceRenderer
+
+	yesLabel, noLabel := "[ Yes ]", "  No  "
+	if yes {
+		yesStyle = yesStyle.Copy().Inherit(r.choiceHighlight)
+	} else {
+		yesLabel, noLabel = "  Yes  ", "[ No ]"
+		noStyle = noStyle.Copy().Inherit(r.choiceHighlight)
+	}
+
+	return fmt.Sprintf("%s  %s", yesStyle.Render(yesLabel), noStyle.Render(noLabel))
+}
+
+// RenderConfirmationPrompt is a method on the Renderer struct that renders the confirmation of a command as plain
+// lines, when the selector of the choices cannot be displayed: the command in a block, its explanation, its risk level
+// colored like the Yes choice, and the answer expected, like [y/N] for the confirmation word "y", No being the default.
+func (r *Renderer) RenderConfirmationPrompt(cmd string, explanation string, level run.RiskLevel, word string) string {
+	if strings.TrimSpace(cmd) == "" {
+		cmd = "(empty command)"
+	}
+	if strings.TrimSpace(explanation) == "" {
+		explanation = "(no explanation)"
+	}
+
+	return fmt.Sprintf(
+		"%s\n%s\n%s",
+		r.commandRenderer.Render(cmd),
+		r.helpRenderer.Render(explanation),
+		r.RenderConfirmationQuestion(level, fmt.Sprintf("run this command? [%s/N]", word)),
+	)
+}
+
+// RenderConfirmationQuestion is a method on the Renderer struct that renders the line asking to confirm a command, its
+// risk level colored like the Yes choice followed by the question, shared by the confirmation under the command and
+// its plain version.
+func (r *Renderer) RenderConfirmationQuestion(level run.RiskLevel, question string) string {
+	riskStyle, ok := r.yesChoiceRenderers[level]
+	if !ok {
+		riskStyle = r.choiceRenderer
+	}
+
+	return fmt.Sprintf("%s · %s", riskStyle.Render(fmt.Sprintf("%s risk", level)), question)
+}
+
+// RenderAutoBadge is a method on the Renderer struct that renders the badge shown in the prompt area
+// while the low risk commands are executed without confirmation.
+func (r *Renderer) RenderAutoBadge() string {
+	return r.autoBadgeRenderer.Render("auto")
+}
+
+// RenderStatusBar is a method on the Renderer struct that renders the status bar, its background filling the width
+// of the terminal.
+func (r *Renderer) RenderStatusBar(in string, width int) string {
+	return r.statusBarRenderer.Copy().Width(width).Render(in)
 }
 
 // RenderConfigMessage is a method on the Renderer struct that renders a configuration message.
@@ -84,16 +698,42 @@ func (r *Renderer) RenderConfigMessage() string {
 	return welcome
 }
 
-// RenderHelpMessage is a method on the Renderer struct that renders a help message.
+// RenderHelpMessage is a method on the Renderer struct that renders a help message, the keys being bold and
+// the prompt modes, the settings and the slash commands being in code style.
 func (r *Renderer) RenderHelpMessage() string {
 	help := "**Help**\n"
-	help += "- `↑`/`↓` : navigate in history\n"
-	help += "- `tab`   : switch between `🚀 exec` and `💬 chat` prompt modes\n"
-	help += "- `ctrl+h`: show help\n"
-	help += "- `ctrl+s`: edit settings\n"
-	help += "- `ctrl+r`: clear terminal and reset discussion history\n"
-	help += "- `ctrl+l`: clear terminal but keep discussion history\n"
-	help += "- `ctrl+c`: exit or interrupt command execution\n"
+	help += "- **↑**/**↓**: navigate in history\n"
+	help += "- **alt+enter**/**ctrl+j**: insert a newline in the input\n"
+	help += "- **esc**: enter the normal mode of the prompt, if `user_editing_mode` is `vi`\n"
+	help += "- **pgup**/**pgdn**: scroll the conversation\n"
+	help += "- **tab**: switch between the `🚀 exec` and `💬 chat` prompt modes\n"
+	help += "- **ctrl+h**: show help\n"
+	help += "- **ctrl+s**: edit settings\n"
+	help += "- **ctrl+r**: clear terminal and reset discussion history\n"
+	help += "- **ctrl+l**: clear terminal but keep discussion history, `/unclear [n]` to display the last cleared screens again\n"
+	help += "- **ctrl+f**: search in the conversation, `enter` then `n`/`N` to jump between the matches, `esc` to close\n"
+	help += "- **ctrl+y**: copy the last answer, or its only code block, to the clipboard\n"
+	help += "- **ctrl+x**: display the last answer as raw markdown, press again to render it\n"
+	help += "- **ctrl+t**: save the transcript of the session, press again to append the new exchanges\n"
+	help += "- **ctrl+o**: release the mouse to select text, press again to scroll with the mouse wheel\n"
+	help += "- **ctrl+c**: exit, or interrupt the answer or the command being executed\n"
+	help += "- `/jobs`: list background jobs, `/jobs tail <n>` to show the output of a job\n"
+	help += "- `/history`: replay the session, **q**/**esc** to close\n"
+	help += "- `/export session [html] <path>`: export the session as markdown, or as html\n"
+	help += "- `/attach <path>`: attach an image to the next message\n"
+	help += "- `/import history bash|zsh|fish`: import the history of your shell\n"
+	help += "- `/confirm on`: ask again to confirm the commands allowed for the session\n"
+	help += "- `/redo`: execute the last command again without asking the AI\n"
 
 	return help
 }
+
+// truncateLine is a function that shortens a line to a maximum number of cells, ending it with an ellipsis, the wide
+// characters like the CJK characters and the emoji taking two cells and being never cut.
+func truncateLine(line string, width int) string {
+	if width < 1 || runewidth.StringWidth(line) <= width {
+		return line
+	}
+
+	return runewidth.Truncate(line, width, "…")
+}
@@ -1,21 +1,52 @@
 package ui
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/akhilsharma90/terminal-assistant/export"
+	"github.com/akhilsharma90/terminal-assistant/run"
+
 	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUIRenderer(t *testing.T) {
 	t.Run("Renderer", testRenderer)
 	t.Run("RenderContent", testRenderContent)
+	t.Run("Resize", testRendererResize)
+	t.Run("SetCodeStyle", testRendererSetCodeStyle)
+	t.Run("SetMarkdownStyle", testRendererSetMarkdownStyle)
+	t.Run("SetColorScheme", testSetColorScheme)
+	t.Run("SetTheme", testRendererSetTheme)
+	t.Run("RenderEcho", testRenderEcho)
+	t.Run("RenderAnswerMarker", testRenderAnswerMarker)
 	t.Run("RenderSuccess", testRenderSuccess)
 	t.Run("RenderWarning", testRenderWarning)
 	t.Run("RenderError", testRenderError)
+	t.Run("RenderExitCode", testRenderExitCode)
+	t.Run("RenderOutput", testRenderOutput)
 	t.Run("RenderHelp", testRenderHelp)
+	t.Run("RenderCapturedOutput", testRenderCapturedOutput)
+	t.Run("RenderCollapsedOutput", testRenderCollapsedOutput)
+	t.Run("RenderStderrTail", testRenderStderrTail)
+	t.Run("RenderAutoBadge", testRenderAutoBadge)
+	t.Run("RenderConfirmation", testRenderConfirmation)
+	t.Run("RenderConfirmationChoices", testRenderConfirmationChoices)
+	t.Run("RenderConfirmationPrompt", testRenderConfirmationPrompt)
+	t.Run("RenderConfirmationQuestion", testRenderConfirmationQuestion)
+	t.Run("RenderMarkdownToHTMLString", testRenderMarkdownToHTMLString)
+	t.Run("RenderConversationTurn", testRenderConversationTurn)
 	t.Run("RenderConfigMessage", testRenderConfigMessage)
 	t.Run("RenderHelpMessage", testRenderHelpMessage)
+	t.Run("NoColor", testRendererNoColor)
+	t.Run("RenderRawMarkdown", testRenderRawMarkdown)
+	t.Run("RenderKeyValList", testRenderKeyValList)
 }
 
 // testRenderer tests the NewRenderer function.
@@ -32,6 +63,130 @@ func testRenderContent(t *testing.T) {
 	assert.NotEmpty(t, output, "Rendered content should not be empty.")
 }
 
+// testRendererResize tests that the content is wrapped at the new width after a resize.
+func testRendererResize(t *testing.T) {
+	r := NewRenderer(glamour.WithStandardStyle("notty"), glamour.WithWordWrap(150))
+	content := strings.Repeat("word ", 20)
+	assert.Len(t, strings.Split(strings.TrimSpace(r.RenderContent(content)), "\n"), 1, "The content should fit in a line.")
+
+	require.NoError(t, r.Resize(40))
+	assert.Equal(t, 40, r.GetWidth())
+	assert.Greater(t, len(strings.Split(strings.TrimSpace(r.RenderContent(content)), "\n")), 1, "The content should be wrapped at the new width.")
+
+	contentRenderer := r.contentRenderer
+	require.NoError(t, r.Resize(40))
+	assert.Same(t, contentRenderer, r.contentRenderer, "The content renderer should be kept if the width did not change.")
+}
+
+// testRendererSetTheme tests that the themes of the conversation are set, an unknown theme keeping the current one.
+func testRendererSetTheme(t *testing.T) {
+	r := NewRenderer()
+	assert.Equal(t, default_theme, r.GetTheme(), "The default theme should be used until one is set.")
+
+	require.NoError(t, r.SetTheme(minimal_theme))
+	assert.Equal(t, minimal_theme, r.GetTheme())
+	assert.EqualError(t, r.SetTheme("unknown"), `unknown theme "unknown"`)
+	assert.Equal(t, minimal_theme, r.GetTheme(), "The current theme should be kept.")
+
+	require.NoError(t, r.SetTheme(""))
+	assert.Equal(t, default_theme, r.GetTheme(), "The default theme should be restored.")
+}
+
+// testRenderEcho tests that the echoed inputs are followed by the label of their prompt mode in its faint color,
+// unless the theme is minimal.
+func testRenderEcho(t *testing.T) {
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	t.Cleanup(func() { lipgloss.SetColorProfile(termenv.Ascii) })
+	r := NewRenderer()
+	style := lipgloss.NewStyle().Foreground(execColor)
+
+	echo := r.RenderEcho("🚀 > first\n     second", "exec", style)
+	assert.Equal(t, "🚀 > first · exec\n     second", run.StripAnsi(echo), "The label should follow the first line.")
+	assert.Contains(t, echo, style.Copy().Faint(true).Render(" · exec"), "The label should be faint in the color of the mode.")
+
+	require.NoError(t, r.SetTheme(minimal_theme))
+	assert.Equal(t, "🚀 > first", r.RenderEcho("🚀 > first", "exec", style), "The minimal theme should not label the inputs.")
+}
+
+// testRenderAnswerMarker tests that the answers are marked with the label of their prompt mode, unless the theme
+// is minimal.
+func testRenderAnswerMarker(t *testing.T) {
+	r := NewRenderer()
+	style := lipgloss.NewStyle().Foreground(chatColor)
+
+	assert.Equal(t, "  ┃ chat answer", run.StripAnsi(r.RenderAnswerMarker("chat answer", style)))
+
+	require.NoError(t, r.SetTheme(minimal_theme))
+	assert.Empty(t, r.RenderAnswerMarker("chat answer", style), "The minimal theme should not mark the answers.")
+}
+
+// testRendererSetCodeStyle tests that the code blocks are highlighted with the configured style.
+func testRendererSetCodeStyle(t *testing.T) {
+	code := "```go\nfunc main() {}\n```"
+	r := NewRenderer(glamour.WithColorProfile(termenv.TrueColor), glamour.WithStandardStyle("dark"))
+	defaultOutput := r.RenderContent(code)
+
+	require.NoError(t, r.SetCodeStyle("monokai"))
+	assert.Equal(t, "monokai", r.GetCodeStyle())
+	monokaiOutput := r.RenderContent(code)
+	assert.Contains(t, monokaiOutput, "\x1b", "The code block should be highlighted.")
+	assert.NotEqual(t, defaultOutput, monokaiOutput, "The code block should be highlighted with the configured style.")
+
+	assert.EqualError(t, r.SetCodeStyle("unknown"), `unknown code style "unknown"`)
+	assert.Equal(t, "monokai", r.GetCodeStyle(), "The current style should be kept.")
+	assert.Equal(t, monokaiOutput, r.RenderContent(code))
+
+	require.NoError(t, r.SetCodeStyle(""))
+	assert.Equal(t, defaultOutput, r.RenderContent(code), "The default highlighting should be restored.")
+
+	require.NoError(t, r.SetCodeStyle("monokai"))
+	require.NoError(t, r.Resize(40))
+	assert.Equal(t, "monokai", r.GetCodeStyle())
+	narrowOutput := NewRenderer(glamour.WithColorProfile(termenv.TrueColor), glamour.WithStandardStyle("dark"), glamour.WithWordWrap(40)).RenderContent(code)
+	assert.NotEqual(t, narrowOutput, r.RenderContent(code), "The style should be kept once resized.")
+
+	t.Setenv("NO_COLOR", "1")
+	r = NewRenderer(glamour.WithAutoStyle())
+	require.NoError(t, r.SetCodeStyle("monokai"))
+	assert.NotContains(t, r.RenderContent(code), "\x1b", "The code block should not be colored.")
+}
+
+// testRendererSetMarkdownStyle tests that the markdown content is rendered with the standard dark or light style or
+// with a glamour style file, the invalid files being refused.
+func testRendererSetMarkdownStyle(t *testing.T) {
+	markdown := "# Title"
+	r := NewRenderer(glamour.WithColorProfile(termenv.TrueColor), glamour.WithStandardStyle("dark"))
+	assert.Equal(t, "auto", r.GetMarkdownStyle())
+
+	require.NoError(t, r.SetMarkdownStyle("light"))
+	lightOutput := r.RenderContent(markdown)
+	require.NoError(t, r.SetMarkdownStyle("dark"))
+	assert.NotEqual(t, lightOutput, r.RenderContent(markdown), "The standard styles should differ.")
+
+	dir := t.TempDir()
+	custom := filepath.Join(dir, "custom.json")
+	require.NoError(t, os.WriteFile(custom, []byte(`{"h1": {"prefix": "=> "}}`), 0600))
+	require.NoError(t, r.SetMarkdownStyle(custom))
+	assert.Equal(t, custom, r.GetMarkdownStyle())
+	assert.Contains(t, r.RenderContent(markdown), "=> Title", "The style file should be used.")
+	require.NoError(t, r.Resize(40))
+	assert.Contains(t, r.RenderContent(markdown), "=> Title", "The style should be kept once resized.")
+
+	invalid := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalid, []byte(`{"heading1": {"prefix": "=> "}}`), 0600))
+	assert.ErrorContains(t, r.SetMarkdownStyle(invalid), `unknown field "heading1"`)
+	assert.ErrorContains(t, r.SetMarkdownStyle(filepath.Join(dir, "missing.json")), "invalid markdown style")
+	assert.Equal(t, custom, r.GetMarkdownStyle(), "The current style should be kept.")
+	assert.Contains(t, r.RenderContent(markdown), "=> Title")
+}
+
+// testSetColorScheme tests that only the known color schemes are accepted.
+func testSetColorScheme(t *testing.T) {
+	assert.NoError(t, SetColorScheme(""))
+	assert.NoError(t, SetColorScheme("auto"))
+	assert.EqualError(t, SetColorScheme("sepia"), `unknown color scheme "sepia"`)
+}
+
 // testRenderSuccess tests the RenderSuccess function.
 func testRenderSuccess(t *testing.T) {
 	r := NewRenderer(glamour.WithAutoStyle())
@@ -56,12 +211,157 @@ func testRenderError(t *testing.T) {
 	assert.NotEmpty(t, output, "Rendered error message should not be empty.")
 }
 
-// testRenderHelp tests the RenderHelp function.
+// testRenderExitCode tests the RenderExitCode function.
+func testRenderExitCode(t *testing.T) {
+	r := NewRenderer(glamour.WithAutoStyle())
+	assert.Contains(t, r.RenderExitCode(2), "exit code 2", "Rendered exit code should contain the code.")
+	assert.Contains(t, r.RenderExitCode(0), "exit code 0", "Rendered exit code should contain the code.")
+}
+
+// testRenderOutput tests that the status of a command is colored by its exit code.
+func testRenderOutput(t *testing.T) {
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	t.Cleanup(func() { lipgloss.SetColorProfile(profile) })
+
+	r := NewRenderer(glamour.WithAutoStyle())
+	assert.Equal(t, r.RenderSuccess("[ok]"), r.RenderOutput("[ok]", 0), "A success should be green.")
+	assert.Equal(t, r.RenderWarning("[error]"), r.RenderOutput("[error]", 1), "The exit code 1 should be yellow.")
+	assert.Equal(t, r.RenderError("[error]"), r.RenderOutput("[error]", 2), "The other exit codes should be red.")
+	assert.Equal(t, r.RenderError("[error]"), r.RenderOutput("[error]", 127), "The other exit codes should be red.")
+	killed := r.RenderOutput("[error]", -1)
+	assert.Contains(t, killed, "[error]")
+	assert.NotEqual(t, r.RenderError("[error]"), killed, "A command killed by a signal should be magenta.")
+	assert.NotEqual(t, r.RenderExitCode(1), r.RenderExitCode(2), "The exit codes should be colored like the status.")
+}
+
+// testRenderHelp tests that the strong, emphasized and code spans of a help message are styled on a single line.
 func testRenderHelp(t *testing.T) {
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	t.Cleanup(func() { lipgloss.SetColorProfile(termenv.Ascii) })
+	r := NewRenderer(glamour.WithStandardStyle("dark"))
+	assert.NotEmpty(t, r.RenderHelp("Help message"), "Rendered help message should not be empty.")
+
+	assert.Equal(t, r.helpRenderer.Render("[no jobs]"), r.RenderHelp("[no jobs]"), "A plain message should be rendered as is.")
+	rendered := r.RenderHelp("press **ctrl+h** in `exec` mode")
+	assert.Contains(t, rendered, r.helpStrongRenderer.Render("ctrl+h"), "The key should be bold.")
+	assert.Contains(t, rendered, r.helpCodeRenderer.Render("exec"), "The mode should be in code style.")
+	assert.NotContains(t, rendered, "\n")
+	assert.Equal(t, "press ctrl+h in exec mode", run.StripAnsi(rendered))
+}
+
+// testRenderAutoBadge tests the RenderAutoBadge function.
+func testRenderAutoBadge(t *testing.T) {
 	r := NewRenderer(glamour.WithAutoStyle())
-	input := "Help message"
-	output := r.RenderHelp(input)
-	assert.NotEmpty(t, output, "Rendered help message should not be empty.")
+	assert.Contains(t, r.RenderAutoBadge(), "auto", "Rendered badge should contain its label.")
+}
+
+// testRenderMarkdownToHTMLString tests the RenderMarkdownToHTMLString function.
+func testRenderMarkdownToHTMLString(t *testing.T) {
+	r := NewRenderer(glamour.WithAutoStyle())
+	output, err := r.RenderMarkdownToHTMLString("# Title\n\n`ls`")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "<h1>Title</h1>", "Rendered HTML should contain the title.")
+	assert.Contains(t, output, "<code>ls</code>", "Rendered HTML should contain the code.")
+}
+
+// testRenderConfirmation tests that the border of the RenderConfirmation function is colored according to the risk level.
+func testRenderConfirmation(t *testing.T) {
+	r := NewRenderer(glamour.WithAutoStyle())
+	safe := r.RenderConfirmation("confirm?", run.SafeRisk)
+	assert.Contains(t, run.StripAnsi(safe), "confirm?", "Rendered confirmation should contain the prompt.")
+	assert.Contains(t, run.StripAnsi(safe), "╭", "Rendered confirmation should have a border.")
+	assert.Equal(t, r.RenderConfirmation("confirm?", run.LowRisk), r.RenderConfirmation("confirm?", run.MediumRisk))
+	assert.Equal(t, r.RenderConfirmation("confirm?", run.HighRisk), r.RenderConfirmation("confirm?", run.CriticalRisk))
+}
+
+// testRenderConfirmationChoices tests that the RenderConfirmationChoices function frames the highlighted choice.
+func testRenderConfirmationChoices(t *testing.T) {
+	r := NewRenderer(glamour.WithAutoStyle())
+	assert.Equal(t, "  Yes    [ No ]", run.StripAnsi(r.RenderConfirmationChoices(false, run.HighRisk)), "No should be highlighted.")
+	assert.Equal(t, "[ Yes ]    No  ", run.StripAnsi(r.RenderConfirmationChoices(true, run.HighRisk)), "Yes should be highlighted.")
+	assert.Equal(t, r.RenderConfirmationChoices(true, run.HighRisk), r.RenderConfirmationChoices(true, run.CriticalRisk))
+
+	t.Setenv("NO_COLOR", "1")
+	r = NewRenderer(glamour.WithAutoStyle())
+	assert.Equal(t, "  Yes    [ No ]", r.RenderConfirmationChoices(false, run.HighRisk), "The choices should not be colored.")
+}
+
+// testRenderConfirmationPrompt tests that the RenderConfirmationPrompt function renders the command, its explanation,
+// its risk level and the answer expected, even when the command or the explanation is empty.
+func testRenderConfirmationPrompt(t *testing.T) {
+	r := NewRenderer(glamour.WithAutoStyle())
+
+	testCases := []struct {
+		name        string
+		cmd         string
+		explanation string
+		level       run.RiskLevel
+		expected    []string
+	}{
+		{"Complete", "rm -rf build", "Removes the build directory.", run.HighRisk, []string{"│ rm -rf build", "Removes the build directory.", "high risk", "[y/N]"}},
+		{"EmptyExplanation", "ls", "", run.SafeRisk, []string{"│ ls", "(no explanation)", "safe risk", "[y/N]"}},
+		{"EmptyCommand", "", "Nothing to run.", run.LowRisk, []string{"│ (empty command)", "Nothing to run.", "low risk", "[y/N]"}},
+		{"Empty", " ", "", run.CriticalRisk, []string{"│ (empty command)", "(no explanation)", "critical risk", "[y/N]"}},
+		{"Word", "reboot", "Restarts.", run.HighRisk, []string{"│ reboot", "Restarts.", "high risk", "[execute/N]"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			word := strings.TrimSuffix(strings.TrimPrefix(tc.expected[3], "["), "/N]")
+			lines := strings.Split(run.StripAnsi(r.RenderConfirmationPrompt(tc.cmd, tc.explanation, tc.level, word)), "\n")
+
+			require.Len(t, lines, 3, "The command, the explanation and the risk should be on their own lines.")
+			for i, component := range tc.expected[:2] {
+				assert.Equal(t, component, strings.TrimSpace(lines[i]))
+			}
+			assert.Contains(t, lines[2], tc.expected[2], "The risk level should be displayed.")
+			assert.Contains(t, lines[2], tc.expected[3], "The answer expected should be displayed.")
+		})
+	}
+}
+
+// testRenderConfirmationQuestion tests that the RenderConfirmationQuestion function renders the risk level before the
+// question, the plain confirmation of a command ending with the same line.
+func testRenderConfirmationQuestion(t *testing.T) {
+	r := NewRenderer(glamour.WithAutoStyle())
+	question := r.RenderConfirmationQuestion(run.HighRisk, "run this command? [y/N]")
+	assert.Equal(t, "high risk · run this command? [y/N]", run.StripAnsi(question))
+
+	prompt := r.RenderConfirmationPrompt("reboot", "Restarts.", run.HighRisk, "y")
+	assert.True(t, strings.HasSuffix(prompt, question), "The plain confirmation should end with the question.")
+}
+
+// testRenderCapturedOutput tests the RenderCapturedOutput function.
+func testRenderCapturedOutput(t *testing.T) {
+	r := NewRenderer(glamour.WithStandardStyle("notty"))
+	output := r.RenderCapturedOutput("\x1b[31mhello\x1b[0m\n", "")
+	assert.Contains(t, output, "hello", "Rendered captured output should contain the output.")
+	assert.NotContains(t, output, "\x1b[31m", "Rendered captured output should not contain the escape sequences.")
+	assert.Empty(t, r.RenderCapturedOutput("", ""), "Rendered empty captured output should be empty.")
+}
+
+// testRenderCollapsedOutput tests the RenderCollapsedOutput function.
+func testRenderCollapsedOutput(t *testing.T) {
+	r := NewRenderer(glamour.WithStandardStyle("notty"))
+	output := r.RenderCollapsedOutput("first\nsecond\nthird\n", "fourth\n", 2)
+	assert.Contains(t, output, "[… 2 earlier lines]", "Rendered collapsed output should count the earlier lines.")
+	assert.Contains(t, output, "fourth", "Rendered collapsed output should contain the last lines.")
+	assert.NotContains(t, output, "second", "Rendered collapsed output should not contain the earlier lines.")
+	assert.Equal(t, r.RenderCapturedOutput("short\n", ""), r.RenderCollapsedOutput("short\n", "", 2), "Short output should not be collapsed.")
+}
+
+// testRenderConversationTurn tests the RenderConversationTurn function.
+func testRenderConversationTurn(t *testing.T) {
+	r := NewRenderer(glamour.WithStandardStyle("notty"))
+
+	user := r.RenderConversationTurn(export.UserRole, "list files")
+	assert.Contains(t, user, "You", "Rendered user turn should contain the user badge.")
+	assert.Contains(t, user, "list files", "Rendered user turn should contain the content.")
+
+	assistant := r.RenderConversationTurn(export.AssistantRole, "`ls`")
+	assert.Contains(t, assistant, "Assistant", "Rendered assistant turn should contain the assistant badge.")
+	assert.Contains(t, assistant, "ls", "Rendered assistant turn should contain the content.")
 }
 
 // testRenderConfigMessage tests the RenderConfigMessage function.
@@ -76,4 +376,67 @@ func testRenderHelpMessage(t *testing.T) {
 	r := NewRenderer(glamour.WithAutoStyle())
 	output := r.RenderHelpMessage()
 	assert.NotEmpty(t, output, "Rendered help message should not be empty.")
+	assert.Contains(t, output, "- **ctrl+h**: show help", "The keys should be bold.")
+	assert.Contains(t, output, "`🚀 exec`", "The prompt modes should be in code style.")
+}
+
+// testRendererNoColor tests that the NO_COLOR environment variable, or a renderer without colors, disables the ANSI
+// escape codes.
+func testRendererNoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	r := NewRenderer(glamour.WithAutoStyle())
+	assert.NotContains(t, r.RenderContent("**Hello**, `World`!"), "\x1b", "Rendered content should not be colored.")
+	assert.Equal(t, "[ok]", r.RenderSuccess("[ok]"), "Rendered success message should not be colored.")
+	assert.Equal(t, "[warning]", r.RenderWarning("[warning]"), "Rendered warning message should not be colored.")
+	assert.Equal(t, "[error]", r.RenderError("[error]"), "Rendered error message should not be colored.")
+	assert.Equal(t, "help", r.RenderHelp("help"), "Rendered help message should not be colored.")
+	assert.Equal(t, "press ctrl+h in exec mode", r.RenderHelp("press **ctrl+h** in `exec` mode"), "Rendered help message should not show the markdown.")
+	assert.Equal(t, "exit code 2", r.RenderExitCode(2), "Rendered exit code should not be colored.")
+	assert.Equal(t, "tab: mode", r.RenderHint("tab: mode"), "Rendered hint should not be colored.")
+	assert.NotContains(t, r.RenderConversationTurn(export.UserRole, "list files"), "\x1b", "Rendered turn should not be colored.")
+
+	t.Setenv("NO_COLOR", "")
+	r = NewColorRenderer(false, glamour.WithColorProfile(termenv.TrueColor), glamour.WithStandardStyle("dark"))
+	assert.NotContains(t, r.RenderContent("**Hello**, `World`!"), "\x1b", "Rendered content should not be colored without the environment variable.")
+}
+
+// testRenderStderrTail tests the RenderStderrTail function.
+func testRenderStderrTail(t *testing.T) {
+	r := NewRenderer(glamour.WithAutoStyle())
+	assert.Empty(t, r.RenderStderrTail("", 80), "Rendered empty tail should be empty.")
+
+	output := run.StripAnsi(r.RenderStderrTail("short\n"+strings.Repeat("x", 100), 20))
+	assert.Contains(t, output, "short", "Rendered tail should contain the lines.")
+	assert.Contains(t, output, strings.Repeat("x", 17)+"…", "Rendered tail should truncate the long lines.")
+	assert.NotContains(t, output, strings.Repeat("x", 18), "Rendered tail should fit the width.")
+}
+
+// testRenderRawMarkdown tests that the raw markdown is displayed as is in a fence longer than the fences it contains.
+func testRenderRawMarkdown(t *testing.T) {
+	r := NewRenderer()
+
+	assert.Equal(t, "```markdown\n| a | b |\n```\n", r.RenderRawMarkdown("| a | b |\n"))
+	assert.Equal(t, "````markdown\n```go\nx := 1\n```\n````\n", r.RenderRawMarkdown("```go\nx := 1\n```"))
+}
+
+// testRenderKeyValList tests that the values of the key-value pairs are aligned after the widest key.
+func testRenderKeyValList(t *testing.T) {
+	r := NewRenderer(glamour.WithAutoStyle())
+	assert.Empty(t, r.RenderKeyValList(nil))
+
+	output := run.StripAnsi(r.RenderKeyValList([][2]string{
+		{"prompt tokens", "120"},
+		{"total", "150"},
+	}))
+	assert.Equal(t, "prompt tokens 120\ntotal         150", output)
+
+	long := strings.Repeat("k", key_val_min_width+3)
+	assert.Equal(t, long+" value", run.StripAnsi(r.RenderKeyValList([][2]string{{long, "value"}})), "Long keys should not be truncated.")
+
+	output = run.StripAnsi(r.RenderKeyValList([][2]string{{"a", "1"}, {"bb", "2"}}))
+	lines := strings.Split(output, "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, strings.Index(lines[0], "1"), strings.Index(lines[1], "2"), "The values should be aligned.")
+	assert.Equal(t, key_val_min_width+1, strings.Index(lines[0], "1"), "The keys should be padded to the minimum width.")
 }
@@ -0,0 +1,267 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/akhilsharma90/terminal-assistant/run"
+)
+
+// search_prompt is the prompt of the input of the search in the conversation.
+const search_prompt = "/"
+
+// The styles highlighting the matches of the search in the conversation, the current match standing out.
+var (
+	searchMatchStyle   = lipgloss.NewStyle().Reverse(true)
+	searchCurrentStyle = lipgloss.NewStyle().Bold(true).Foreground(statusBgColor).Background(warningColor)
+)
+
+// searchPosition is a struct that represents a position in the lines of the conversation displayed by its viewport.
+type searchPosition struct {
+	line   int // The line of the viewport, wrapped lines included.
+	column int // The rune of the line, without its ANSI escape sequences.
+}
+
+// searchMatch is a struct that represents a match of the search in the conversation, which may span wrapped lines.
+type searchMatch struct {
+	start searchPosition // The position of the first rune of the match.
+	end   searchPosition // The position of the last rune of the match.
+}
+
+// Search is a struct that represents the search of a text in the conversation, its matches being highlighted.
+// The query is typed in its input, then confirmed to jump between the matches with n and N.
+type Search struct {
+	active  bool            // Whether the search is displayed instead of the prompt.
+	editing bool            // Whether the query is being typed.
+	input   textinput.Model // The input of the query.
+}
+
+// NewSearch is a function that creates a new Search instance.
+func NewSearch() *Search {
+	input := textinput.New()
+	input.Prompt = search_prompt
+	input.Placeholder = "search the conversation"
+
+	return &Search{input: input}
+}
+
+// Open is a method on the Search struct that displays the search with an empty query being typed.
+func (s *Search) Open() tea.Cmd {
+	s.active = true
+	s.input.Reset()
+
+	return s.Edit()
+}
+
+// Edit is a method on the Search struct that focuses the input to type the query again.
+func (s *Search) Edit() tea.Cmd {
+	s.editing = true
+
+	return s.input.Focus()
+}
+
+// Confirm is a method on the Search struct that stops typing the query, to jump between its matches.
+func (s *Search) Confirm() *Search {
+	s.editing = false
+	s.input.Blur()
+
+	return s
+}
+
+// Close is a method on the Search struct that stops displaying the search.
+func (s *Search) Close() *Search {
+	s.active = false
+	s.editing = false
+	s.input.Blur()
+	s.input.Reset()
+
+	return s
+}
+
+// IsActive is a method on the Search struct that returns whether the search is displayed.
+func (s *Search) IsActive() bool {
+	return s.active
+}
+
+// IsEditing is a method on the Search struct that returns whether the query is being typed.
+func (s *Search) IsEditing() bool {
+	return s.editing
+}
+
+// GetQuery is a method on the Search struct that returns the query typed in the input.
+func (s *Search) GetQuery() string {
+	return s.input.Value()
+}
+
+// Update is a method on the Search struct that updates the input of the query with a message.
+func (s *Search) Update(msg tea.Msg) (*Search, tea.Cmd) {
+	var updateCmd tea.Cmd
+	s.input, updateCmd = s.input.Update(msg)
+
+	return s, updateCmd
+}
+
+// View is a method on the Search struct that returns a string representation of the input of the query, followed by
+// the current match and the number of matches.
+func (s *Search) View(renderer *Renderer, current int, count int) string {
+	matches := "no match"
+	if count > 0 {
+		matches = fmt.Sprintf("%d/%d", current+1, count)
+	}
+	if s.GetQuery() == "" {
+		matches = ""
+	}
+
+	return strings.TrimRight(fmt.Sprintf("%s %s", s.input.View(), renderer.RenderHelp(matches)), " ")
+}
+
+// findMatches is a function that returns the matches of a query in the raw text of a rendered content, ignoring
+// the case, mapped to the positions of the lines of the content wrapped at a width like the conversation wraps them.
+func findMatches(content string, width int, query string) []searchMatch {
+	needle := []rune(strings.Map(unicode.ToLower, query))
+	if len(needle) == 0 {
+		return nil
+	}
+
+	var matches []searchMatch
+	offset := 0
+	for _, line := range strings.Split(content, "\n") {
+		raw := []rune(run.StripAnsi(line))
+		wrapped := strings.Split(run.StripAnsi(wrapCells(line, width)), "\n")
+		positions := mapWrappedPositions(raw, wrapped, offset)
+		lower := []rune(strings.Map(unicode.ToLower, string(raw)))
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if string(lower[i:i+len(needle)]) == string(needle) {
+				matches = append(matches, searchMatch{start: positions[i], end: positions[i+len(needle)-1]})
+				i += len(needle) - 1
+			}
+		}
+		offset += len(wrapped)
+	}
+
+	return matches
+}
+
+// mapWrappedPositions is a function that returns the position of each rune of a raw line in its wrapped lines, the
+// first of them being at the given line of the viewport. The spaces dropped where the line was wrapped are mapped to
+// the end of the line they were dropped from.
+func mapWrappedPositions(raw []rune, wrapped []string, offset int) []searchPosition {
+	positions := make([]searchPosition, len(raw))
+	line, column := 0, 0
+	current := []rune(wrapped[0])
+	for i, r := range raw {
+		// Move to the next wrapped line once the current one is consumed, unless the rune was dropped by the wrapping
+		if column >= len(current) && line < len(wrapped)-1 && strings.HasPrefix(wrapped[line+1], string(r)) {
+			line++
+			column = 0
+			current = []rune(wrapped[line])
+		}
+		positions[i] = searchPosition{line: offset + line, column: column}
+		if column < len(current) && current[column] == r {
+			column++
+		}
+	}
+
+	return positions
+}
+
+// highlightMatches is a function that highlights the matches of the search in the lines of a wrapped content, the
+// highlighted lines keeping their own styles around the matches.
+func highlightMatches(content string, matches []searchMatch, current int) string {
+	if len(matches) == 0 {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	segments := map[int][][3]int{}
+	for i, match := range matches {
+		for line := match.start.line; line <= match.end.line && line < len(lines); line++ {
+			length := len([]rune(run.StripAnsi(lines[line])))
+			from, to := 0, length
+			if line == match.start.line {
+				from = match.start.column
+			}
+			if line == match.end.line && match.end.column+1 < length {
+				to = match.end.column + 1
+			}
+			// Skip the segments overlapping the previous one
+			ranges := segments[line]
+			if from < to && (len(ranges) == 0 || from >= ranges[len(ranges)-1][1]) {
+				segments[line] = append(ranges, [3]int{from, to, i})
+			}
+		}
+	}
+
+	for line, ranges := range segments {
+		lines[line] = highlightLine(lines[line], ranges, current)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// highlightLine is a function that highlights the segments of a line, given as runes of the line without its ANSI
+// escape sequences, the match with the current index standing out. The escape sequences are mapped across the
+// segments: the styles changed inside a segment take effect after it, and the styles of the line are restored after
+// each segment, the highlight resetting them.
+func highlightLine(line string, ranges [][3]int, current int) string {
+	var (
+		builder, match strings.Builder
+		active         []string // The SGR sequences styling the text since the last reset.
+		held           []string // The other sequences met inside the segment, written after it.
+		column, next   int
+		matching       bool
+	)
+
+	for line != "" {
+		if sequence := run.LeadingAnsi(line); sequence != "" {
+			line = line[len(sequence):]
+			isStyle := strings.HasPrefix(sequence, "\x1b[") && strings.HasSuffix(sequence, "m")
+			switch {
+			case isStyle && (sequence == "\x1b[m" || sequence == "\x1b[0m"):
+				active = nil
+			case isStyle:
+				active = append(active, sequence)
+			case matching:
+				held = append(held, sequence)
+			}
+			if !matching {
+				builder.WriteString(sequence)
+			}
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(line)
+		line = line[size:]
+		if next < len(ranges) && column == ranges[next][0] {
+			matching = true
+		}
+		column++
+		if !matching {
+			builder.WriteRune(r)
+			continue
+		}
+
+		match.WriteRune(r)
+		if column == ranges[next][1] {
+			style := searchMatchStyle
+			if ranges[next][2] == current {
+				style = searchCurrentStyle
+			}
+			builder.WriteString(style.Render(match.String()))
+			builder.WriteString(strings.Join(held, ""))
+			builder.WriteString(strings.Join(active, ""))
+			match.Reset()
+			held = nil
+			matching = false
+			next++
+		}
+	}
+
+	return builder.String()
+}
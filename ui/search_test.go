@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/akhilsharma90/terminal-assistant/run"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUISearch(t *testing.T) {
+	t.Run("FindMatches", testFindMatches)
+	t.Run("FindMatchesWrapped", testFindMatchesWrapped)
+	t.Run("FindMatchesDroppedSpace", testFindMatchesDroppedSpace)
+	t.Run("HighlightMatches", testHighlightMatches)
+	t.Run("Search", testSearch)
+}
+
+// testFindMatches tests that the matches are found in the raw text ignoring the case, the ANSI escape sequences being
+// ignored.
+func testFindMatches(t *testing.T) {
+	content := "first line\n\x1b[1mSecond\x1b[0m line"
+
+	assert.Equal(t, []searchMatch{
+		{start: searchPosition{0, 6}, end: searchPosition{0, 9}},
+		{start: searchPosition{1, 7}, end: searchPosition{1, 10}},
+	}, findMatches(content, 80, "LINE"))
+	assert.Equal(t, []searchMatch{
+		{start: searchPosition{1, 0}, end: searchPosition{1, 5}},
+	}, findMatches(content, 80, "second"), "The escape sequences should not be matched.")
+	assert.Empty(t, findMatches(content, 80, ""))
+	assert.Empty(t, findMatches(content, 80, "third"))
+	assert.Len(t, findMatches("aaaa", 80, "aa"), 2, "The matches should not overlap.")
+}
+
+// testFindMatchesWrapped tests that the matches are mapped to the lines of the content wrapped at the width, a match
+// spanning two wrapped lines.
+func testFindMatchesWrapped(t *testing.T) {
+	content := "hello world foo\nworld"
+
+	assert.Equal(t, "hello wo\nrld foo\nworld", wrapCells(content, 8))
+	assert.Equal(t, []searchMatch{
+		{start: searchPosition{0, 6}, end: searchPosition{1, 2}},
+		{start: searchPosition{2, 0}, end: searchPosition{2, 4}},
+	}, findMatches(content, 8, "world"), "The lines following a wrapped line should be shifted.")
+	assert.Equal(t, []searchMatch{
+		{start: searchPosition{1, 4}, end: searchPosition{1, 6}},
+	}, findMatches(content, 8, "foo"))
+}
+
+// testFindMatchesDroppedSpace tests that the space dropped where a line was wrapped is mapped to the end of the line
+// it was dropped from.
+func testFindMatchesDroppedSpace(t *testing.T) {
+	content := "abcdefghij klm"
+
+	assert.Equal(t, "abcde\nfghij\nklm", wrapCells(content, 5))
+	assert.Equal(t, []searchMatch{
+		{start: searchPosition{2, 0}, end: searchPosition{2, 2}},
+	}, findMatches(content, 5, "klm"))
+	assert.Equal(t, []searchMatch{
+		{start: searchPosition{1, 4}, end: searchPosition{2, 0}},
+	}, findMatches(content, 5, "j k"))
+}
+
+// testHighlightMatches tests that the matches are highlighted in the wrapped lines, the current one standing out.
+func testHighlightMatches(t *testing.T) {
+	content := "hello wo\nrld foo\nworld"
+	matches := findMatches("hello world foo\nworld", 8, "world")
+
+	highlighted := highlightMatches(content, matches, 1)
+	assert.Equal(t, content, run.StripAnsi(highlighted), "The text should be kept.")
+	assert.Contains(t, highlighted, "hello "+searchMatchStyle.Render("wo")+"\n"+searchMatchStyle.Render("rld")+" foo")
+	assert.Contains(t, highlighted, searchCurrentStyle.Render("world"))
+	assert.Equal(t, content, highlightMatches(content, nil, 0))
+
+	styled := "\x1b[1mhello \x1b[32mworld\x1b[0m foo"
+	highlighted = highlightMatches(styled, findMatches("hello world foo", 80, "lo wor"), 0)
+	assert.Equal(t, "hello world foo", run.StripAnsi(highlighted), "The text should be kept.")
+	assert.Equal(t, "\x1b[1mhel"+searchCurrentStyle.Render("lo wor")+"\x1b[1m\x1b[32mld\x1b[0m foo", highlighted,
+		"The style of the line should be restored after the match.")
+}
+
+// testSearch tests that the query is typed in the search until it is confirmed, and cleared when it is closed.
+func testSearch(t *testing.T) {
+	s := NewSearch()
+	assert.False(t, s.IsActive())
+
+	s.Open()
+	assert.True(t, s.IsActive())
+	assert.True(t, s.IsEditing(), "The query should be typed once opened.")
+	s.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("foo")})
+	assert.Equal(t, "foo", s.GetQuery())
+
+	s.Confirm()
+	assert.False(t, s.IsEditing())
+	view := run.StripAnsi(s.View(NewRenderer(), 0, 2))
+	assert.Contains(t, view, "/foo")
+	assert.Contains(t, view, "1/2", "The current match should be displayed.")
+	assert.Contains(t, run.StripAnsi(s.View(NewRenderer(), 0, 0)), "no match")
+
+	s.Close()
+	assert.False(t, s.IsActive())
+	s.Open()
+	assert.Equal(t, "", s.GetQuery(), "The query should be cleared once closed.")
+}
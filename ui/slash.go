@@ -0,0 +1,270 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akhilsharma90/terminal-assistant/export"
+	"github.com/akhilsharma90/terminal-assistant/system"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// slash_prefix is the prefix of the slash commands typed in the prompt.
+const slash_prefix = "/"
+
+// slashCommands are the names of the slash commands, completed in the prompt.
+var slashCommands = []string{"attach", "confirm", "export", "history", "import", "jobs", "redo", "unclear"}
+
+// SlashCommands is a function that returns the names of the slash commands, like to complete them in the shell.
+func SlashCommands() []string {
+	return append([]string{}, slashCommands...)
+}
+
+// jobs_tail_lines is the default number of lines shown by the "/jobs tail" command.
+const jobs_tail_lines = 10
+
+// parseSlashCommand is a function that splits a slash command input into its name and arguments.
+// It returns false if the input is not a slash command.
+func parseSlashCommand(input string) (string, []string, bool) {
+	if !strings.HasPrefix(input, slash_prefix) {
+		return "", nil, false
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(input, slash_prefix))
+	if len(fields) == 0 {
+		return "", nil, false
+	}
+
+	return strings.ToLower(fields[0]), fields[1:], true
+}
+
+// runSlashCommand is a method of the Ui struct that executes a slash command and prints its output.
+func (u *Ui) runSlashCommand(name string, args []string) tea.Cmd {
+	var output string
+	if !u.isFeatureEnabled(name) {
+		return tea.Sequence(
+			u.print(u.components.renderer.RenderError(fmt.Sprintf("[/%s is disabled]\n", name))),
+			textinput.Blink,
+		)
+	}
+
+	switch name {
+	case "history":
+		return u.historyCommand()
+	case "jobs":
+		output = u.jobsCommand(args)
+	case "export":
+		output = u.exportCommand(args)
+	case "confirm":
+		output = u.confirmCommand(args)
+	case "attach":
+		output = u.attachCommand(args)
+	case "import":
+		output = u.importCommand(args)
+	case "unclear":
+		output = u.unclearCommand(args)
+	case "redo":
+		return u.redoCommand()
+	default:
+		output = u.components.renderer.RenderError(fmt.Sprintf("[unknown command: /%s]\n", name))
+	}
+
+	return tea.Sequence(
+		u.print(output),
+		textinput.Blink,
+	)
+}
+
+// jobsCommand is a method of the Ui struct that handles the "/jobs" slash command.
+func (u *Ui) jobsCommand(args []string) string {
+	// Show the last lines of the output of a job
+	if len(args) > 0 && args[0] == "tail" {
+		if len(args) < 2 {
+			return u.components.renderer.RenderError("[usage: /jobs tail <n>]\n")
+		}
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			return u.components.renderer.RenderError(fmt.Sprintf("[invalid job number: %s]\n", args[1]))
+		}
+		job, ok := u.jobs.Get(id)
+		if !ok {
+			return u.components.renderer.RenderError(fmt.Sprintf("[no job %d]\n", id))
+		}
+		lines, err := job.Tail(jobs_tail_lines)
+		if err != nil {
+			return u.components.renderer.RenderError(fmt.Sprintf("[job %d output error: %s]\n", id, err))
+		}
+
+		return u.components.renderer.RenderCapturedOutput(strings.Join(lines, "\n"), "") +
+			u.components.renderer.RenderHelp(fmt.Sprintf("[job %d: %s]\n", id, formatJobState(job.IsRunning(), job.GetExitCode(), job.GetElapsed())))
+	}
+
+	// List all the jobs
+	jobs := u.jobs.GetAll()
+	if len(jobs) == 0 {
+		return u.components.renderer.RenderHelp("[no jobs]\n")
+	}
+
+	list := ""
+	for _, job := range jobs {
+		list += fmt.Sprintf(
+			"- **[%d]** %s `%s`\n",
+			job.GetId(),
+			formatJobState(job.IsRunning(), job.GetExitCode(), job.GetElapsed()),
+			job.GetCommand(),
+		)
+	}
+
+	return u.components.renderer.RenderContent(list)
+}
+
+// historyCommand is a method of the Ui struct that handles the "/history" slash command,
+// replaying the conversation of the session in the viewport.
+func (u *Ui) historyCommand() tea.Cmd {
+	if len(u.state.turns) == 0 {
+		return tea.Sequence(
+			u.print(u.components.renderer.RenderWarning("[no history]\n")),
+			textinput.Blink,
+		)
+	}
+
+	content := ""
+	for _, turn := range u.state.turns {
+		content += u.components.renderer.RenderConversationTurn(turn.GetRole(), turn.GetContent())
+	}
+
+	return u.showViewport(content)
+}
+
+// exportCommand is a method of the Ui struct that handles the "/export session [md|html] <path>" slash command.
+func (u *Ui) exportCommand(args []string) string {
+	if len(args) > 0 && args[0] == "session" {
+		args = args[1:]
+	} else {
+		return u.components.renderer.RenderError("[usage: /export session [md|html] <path>]\n")
+	}
+	save := export.SaveMarkdown
+	if len(args) > 1 && args[0] == "md" {
+		args = args[1:]
+	} else if len(args) > 1 && args[0] == "html" {
+		save = export.SaveHTML
+		args = args[1:]
+	}
+	if len(args) != 1 {
+		return u.components.renderer.RenderError("[usage: /export session [md|html] <path>]\n")
+	}
+
+	if err := save(u.state.turns, args[0]); err != nil {
+		return u.components.renderer.RenderError(fmt.Sprintf("[export error] %s\n", err))
+	}
+
+	return u.components.renderer.RenderSuccess(fmt.Sprintf("[session exported to %s]\n", args[0]))
+}
+
+// confirmCommand is a method of the Ui struct that handles the "/confirm on" slash command,
+// asking again for the confirmation of every command after it was allowed for the session.
+func (u *Ui) confirmCommand(args []string) string {
+	if len(args) != 1 || args[0] != "on" {
+		return u.components.renderer.RenderError("[usage: /confirm on]\n")
+	}
+
+	u.state.autoConfirm = false
+
+	return u.components.renderer.RenderSuccess("[confirmation enabled]\n")
+}
+
+// attachCommand is a method of the Ui struct that handles the "/attach <path>" slash command,
+// attaching an image to the next message.
+func (u *Ui) attachCommand(args []string) string {
+	if len(args) != 1 {
+		return u.components.renderer.RenderError("[usage: /attach <path>]\n")
+	}
+
+	if err := u.engine.AttachImage(args[0]); err != nil {
+		return u.components.renderer.RenderError(fmt.Sprintf("[attach error] %s\n", err))
+	}
+	u.state.pendingImages = append(u.state.pendingImages, args[0])
+
+	return u.components.renderer.RenderSuccess(fmt.Sprintf("[image attached to the next message: %s]\n", args[0]))
+}
+
+// importCommand is a method of the Ui struct that handles the "/import history <shell>" slash command,
+// adding the commands of the history file of bash, zsh or fish to the history of the prompt.
+func (u *Ui) importCommand(args []string) string {
+	if len(args) != 2 || args[0] != "history" {
+		return u.components.renderer.RenderError("[usage: /import history bash|zsh|fish]\n")
+	}
+
+	path, err := system.GetShellHistoryFile(strings.ToLower(args[1]))
+	if err != nil {
+		return u.components.renderer.RenderError(fmt.Sprintf("[import error] %s\n", err))
+	}
+	imported, err := u.history.ImportFromShell(path)
+	if err != nil {
+		return u.components.renderer.RenderError(fmt.Sprintf("[import error] %s\n", err))
+	}
+	duplicates := u.history.Deduplicate()
+
+	return u.components.renderer.RenderSuccess(fmt.Sprintf("[%d commands imported from %s, %d duplicates removed]\n", imported, path, duplicates))
+}
+
+// unclearCommand is a method of the Ui struct that handles the "/unclear [n]" slash command, displaying again the
+// last screens of the conversation cleared with ctrl+l, the last one by default.
+func (u *Ui) unclearCommand(args []string) string {
+	count := 1
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 || len(args) > 1 {
+			return u.components.renderer.RenderError("[usage: /unclear [n]]\n")
+		}
+		count = n
+	}
+
+	restored := u.components.conversation.Restore(count, u.components.renderer)
+	switch restored {
+	case 0:
+		return u.components.renderer.RenderWarning("[no cleared screen]\n")
+	case 1:
+		return u.components.renderer.RenderHelp("[1 screen restored]\n")
+	}
+
+	return u.components.renderer.RenderHelp(fmt.Sprintf("[%d screens restored]\n", restored))
+}
+
+// redoCommand is a method of the Ui struct that handles the "/redo" slash command, executing again the last command
+// executed without asking the AI, its output being captured like when it was confirmed.
+func (u *Ui) redoCommand() tea.Cmd {
+	command := u.state.lastExecutedCommand
+	if command == "" {
+		return tea.Sequence(
+			u.print(u.components.renderer.RenderWarning("[no previous command]\n")),
+			textinput.Blink,
+		)
+	}
+
+	u.state.command = command
+	u.state.buffer = ""
+	u.components.prompt.Blur()
+	execCmd := u.execCommand(command)
+	if u.canCapture(command) {
+		execCmd = u.captureCommand(command)
+	}
+
+	return tea.Sequence(
+		u.print(u.components.renderer.RenderHelp(fmt.Sprintf("[re-executing: %s]\n", escapeMarkdown(command)))),
+		execCmd,
+	)
+}
+
+// formatJobState is a function that returns a short description of the state of a job.
+func formatJobState(running bool, exitCode int, elapsed time.Duration) string {
+	if running {
+		return fmt.Sprintf("running · %s", elapsed.Round(100*time.Millisecond))
+	}
+
+	return fmt.Sprintf("exit %d · %s", exitCode, elapsed.Round(100*time.Millisecond))
+}
@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUISlash(t *testing.T) {
+	t.Run("ParseSlashCommand", testParseSlashCommand)
+	t.Run("FormatJobState", testFormatJobState)
+}
+
+// testParseSlashCommand tests the parseSlashCommand function.
+func testParseSlashCommand(t *testing.T) {
+	testCases := []struct {
+		input string
+		name  string
+		args  []string
+		ok    bool
+	}{
+		{"/jobs", "jobs", []string{}, true},
+		{"/JOBS tail 2", "jobs", []string{"tail", "2"}, true},
+		{"  /jobs", "", nil, false},
+		{"/", "", nil, false},
+		{"list files", "", nil, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			name, args, ok := parseSlashCommand(tc.input)
+			assert.Equal(t, tc.ok, ok, "The slash command detection should match.")
+			assert.Equal(t, tc.name, name, "The slash command name should match.")
+			assert.Equal(t, tc.args, args, "The slash command arguments should match.")
+		})
+	}
+}
+
+// testFormatJobState tests the formatJobState function.
+func testFormatJobState(t *testing.T) {
+	assert.Equal(t, "running · 1.5s", formatJobState(true, 0, 1500*time.Millisecond))
+	assert.Equal(t, "exit 1 · 2s", formatJobState(false, 1, 2*time.Second))
+}
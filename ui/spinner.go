@@ -3,6 +3,8 @@ package ui
 import (
 	"fmt"
 	"math/rand"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
@@ -20,9 +22,33 @@ var loadingMessages = []string{
 	"please wait",
 }
 
+// spinnerStyles are the character sets of the spinner, by name.
+var spinnerStyles = map[string]spinner.Spinner{
+	"line":      spinner.Line,
+	"dot":       spinner.Dot,
+	"minidot":   spinner.MiniDot,
+	"jump":      spinner.Jump,
+	"pulse":     spinner.Pulse,
+	"points":    spinner.Points,
+	"globe":     spinner.Globe,
+	"moon":      spinner.Moon,
+	"monkey":    spinner.Monkey,
+	"meter":     spinner.Meter,
+	"hamburger": spinner.Hamburger,
+	"ellipsis":  spinner.Ellipsis,
+}
+
+// default_spinner_style is the name of the character set of the spinner when none is configured.
+const default_spinner_style = "minidot"
+
 // Spinner is a struct that represents a spinner in the user interface.
 type Spinner struct {
 	message string        // The message to display while the spinner is spinning.
+	label   string        // The configured message, a random loading message being displayed if empty.
+	status  string        // The status replacing the message, like the retries of a request, if any.
+	tokens  int           // The estimated number of tokens of the answer being streamed, if any.
+	bytes   int           // The number of bytes of the piped input read so far, if any.
+	start   time.Time     // The start of the request the spinner is spinning for.
 	spinner spinner.Model // The spinner model.
 }
 
@@ -30,16 +56,81 @@ type Spinner struct {
 func NewSpinner() *Spinner {
 	// Create a new spinner model.
 	spin := spinner.New()
-	// Set the spinner style to MiniDot.
-	spin.Spinner = spinner.MiniDot
+	// Set the default spinner style.
+	spin.Spinner = spinnerStyles[default_spinner_style]
 
 	// Return a new Spinner instance with a random loading message and the spinner model.
 	return &Spinner{
 		message: loadingMessages[rand.Intn(len(loadingMessages))],
+		start:   time.Now(),
 		spinner: spin,
 	}
 }
 
+// SetStyle is a method on the Spinner struct that sets the character set of the spinner by name,
+// the default one being kept if the name is unknown.
+func (s *Spinner) SetStyle(name string) *Spinner {
+	if style, ok := spinnerStyles[strings.ToLower(name)]; ok {
+		s.spinner.Spinner = style
+	}
+
+	return s
+}
+
+// SetLabel is a method on the Spinner struct that sets the message displayed while the spinner is spinning,
+// a random loading message being displayed if empty.
+func (s *Spinner) SetLabel(label string) *Spinner {
+	s.label = label
+	if label != "" {
+		s.message = label
+	}
+
+	return s
+}
+
+// SetStatus is a method on the Spinner struct that sets a status replacing the message, like the retries
+// of a request, an empty status restoring the message.
+func (s *Spinner) SetStatus(status string) *Spinner {
+	s.status = status
+
+	return s
+}
+
+// SetTokens is a method on the Spinner struct that sets the estimated number of tokens of the answer being streamed,
+// displayed after the message.
+func (s *Spinner) SetTokens(tokens int) *Spinner {
+	s.tokens = tokens
+
+	return s
+}
+
+// SetBytes is a method on the Spinner struct that sets the number of bytes of the piped input read so far,
+// displayed after the message.
+func (s *Spinner) SetBytes(bytes int) *Spinner {
+	s.bytes = bytes
+
+	return s
+}
+
+// Start is a method on the Spinner struct that resets the elapsed time, the status, the number of tokens and the
+// number of bytes for a new request.
+func (s *Spinner) Start() *Spinner {
+	s.start = time.Now()
+	s.status = ""
+	s.tokens = 0
+	s.bytes = 0
+	if s.label == "" {
+		s.message = loadingMessages[rand.Intn(len(loadingMessages))]
+	}
+
+	return s
+}
+
+// GetElapsed is a method on the Spinner struct that returns the time elapsed since the start of the request.
+func (s *Spinner) GetElapsed() time.Duration {
+	return time.Since(s.start)
+}
+
 // Update is a method on the Spinner struct that updates the spinner model with a message.
 func (s *Spinner) Update(msg tea.Msg) (*Spinner, tea.Cmd) {
 	var updateCmd tea.Cmd
@@ -51,11 +142,27 @@ func (s *Spinner) Update(msg tea.Msg) (*Spinner, tea.Cmd) {
 
 // View is a method on the Spinner struct that returns a string representation of the spinner.
 func (s *Spinner) View() string {
-	// Return a string representation of the spinner with the spinner view and the message.
+	message := s.message
+	if s.status != "" {
+		message = s.status
+	}
+
+	// Return a string representation of the spinner with the spinner view, the message, the estimated number of
+	// tokens of the answer or the bytes of the piped input read so far and the elapsed seconds.
+	tokens := ""
+	if s.tokens > 0 {
+		tokens = fmt.Sprintf(" (~%d tokens)", s.tokens)
+	}
+	if s.bytes > 0 {
+		tokens = " " + formatPasteSize(s.bytes)
+	}
+
 	return fmt.Sprintf(
-		"\n  %s %s...",
+		"\n  %s %s...%s %ds",
 		s.spinner.View(),
-		s.spinner.Style.Render(s.message),
+		s.spinner.Style.Render(message),
+		tokens,
+		int(s.GetElapsed().Seconds()),
 	)
 }
 
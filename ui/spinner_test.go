@@ -2,6 +2,9 @@ package ui
 
 import (
 	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -10,6 +13,9 @@ func TestUISpinner(t *testing.T) {
 	// Test cases
 	t.Run("NewSpinner", testNewSpinner)
 	t.Run("View", testSpinnerView)
+	t.Run("Elapsed", testSpinnerElapsed)
+	t.Run("Tokens", testSpinnerTokens)
+	t.Run("Style", testSpinnerStyle)
 }
 
 // testNewSpinner tests the NewSpinner function.
@@ -24,3 +30,36 @@ func testSpinnerView(t *testing.T) {
 	view := s.View()
 	assert.NotEmpty(t, view, "Spinner view should not be empty.")
 }
+
+// testSpinnerElapsed tests that the spinner displays the seconds elapsed since the start of the request.
+func testSpinnerElapsed(t *testing.T) {
+	s := NewSpinner().SetLabel("thinking")
+	s.start = time.Now().Add(-7 * time.Second)
+	assert.Contains(t, s.View(), "thinking... 7s", "The elapsed seconds should be displayed next to the message.")
+
+	s.SetStatus("retrying in 2s")
+	assert.Contains(t, s.View(), "retrying in 2s...", "The status should replace the message.")
+	assert.NotContains(t, s.View(), "thinking")
+
+	s.Start()
+	assert.Contains(t, s.View(), "thinking... 0s", "The elapsed time and the status should be reset for a new request.")
+}
+
+// testSpinnerTokens tests that the spinner displays the estimated number of tokens of the answer being streamed.
+func testSpinnerTokens(t *testing.T) {
+	s := NewSpinner().SetLabel("thinking").SetStatus("responding").SetTokens(45)
+	s.start = time.Now()
+	assert.Contains(t, s.View(), "responding... (~45 tokens) 0s", "The tokens should be displayed after the message.")
+
+	s.Start()
+	assert.NotContains(t, s.View(), "tokens", "The tokens should be reset for a new request.")
+}
+
+// testSpinnerStyle tests the SetStyle method of the Spinner struct.
+func testSpinnerStyle(t *testing.T) {
+	s := NewSpinner().SetStyle("Line")
+	assert.Equal(t, spinner.Line.Frames, s.spinner.Spinner.Frames, "The style should be set by name.")
+
+	s.SetStyle("unknown")
+	assert.Equal(t, spinner.Line.Frames, s.spinner.Spinner.Frames, "An unknown style should be ignored.")
+}
@@ -0,0 +1,151 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/akhilsharma90/terminal-assistant/ai"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/mitchellh/go-homedir"
+)
+
+// status_bar_min_height is the minimum height of the terminal for the status bar to be displayed.
+const status_bar_min_height = 15
+
+// status_bar_separator is the separator of the segments of the status bar.
+const status_bar_separator = " · "
+
+// status_bar_selection is the segment of the status bar telling the mouse is released so the terminal selects text.
+const status_bar_selection = "select"
+
+// StatusBar is a struct that represents the line displayed under the prompt in the REPL mode, showing the prompt mode,
+// the model, the current directory and the tokens used in the session.
+type StatusBar struct {
+	mode      PromptMode     // The mode of the prompt.
+	model     string         // The model of the engine.
+	dir       string         // The directory the commands are executed in.
+	usage     ai.EngineUsage // The tokens used in the session.
+	selecting bool           // Whether the mouse is released so the terminal selects text.
+	width     int            // The width of the terminal.
+}
+
+// NewStatusBar is a function that creates a new StatusBar instance.
+func NewStatusBar(width int) *StatusBar {
+	return &StatusBar{
+		width: width,
+	}
+}
+
+// SetMode is a method on the StatusBar struct that sets the mode of the prompt.
+func (s *StatusBar) SetMode(mode PromptMode) *StatusBar {
+	s.mode = mode
+
+	return s
+}
+
+// SetModel is a method on the StatusBar struct that sets the model of the engine.
+func (s *StatusBar) SetModel(model string) *StatusBar {
+	s.model = model
+
+	return s
+}
+
+// SetDir is a method on the StatusBar struct that sets the directory the commands are executed in,
+// the home directory being shortened to "~".
+func (s *StatusBar) SetDir(dir string) *StatusBar {
+	if home, err := homedir.Dir(); err == nil && home != "" {
+		if rel, err := filepath.Rel(home, dir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			dir = filepath.Join("~", rel)
+		}
+	}
+	s.dir = dir
+
+	return s
+}
+
+// SetUsage is a method on the StatusBar struct that sets the tokens used in the session.
+func (s *StatusBar) SetUsage(usage ai.EngineUsage) *StatusBar {
+	s.usage = usage
+
+	return s
+}
+
+// GetUsage is a method on the StatusBar struct that returns the tokens used in the session.
+func (s *StatusBar) GetUsage() ai.EngineUsage {
+	return s.usage
+}
+
+// SetSelecting is a method on the StatusBar struct that sets whether the mouse is released so the terminal selects text.
+func (s *StatusBar) SetSelecting(selecting bool) *StatusBar {
+	s.selecting = selecting
+
+	return s
+}
+
+// SetWidth is a method on the StatusBar struct that sets the width of the terminal.
+func (s *StatusBar) SetWidth(width int) *StatusBar {
+	s.width = width
+
+	return s
+}
+
+// View is a method on the StatusBar struct that renders the status bar on a single line. On narrow terminals,
+// the directory is shortened from its beginning first, then the line is truncated.
+func (s *StatusBar) View(renderer *Renderer) string {
+	usage := fmt.Sprintf("%s tokens", formatTokens(s.usage.GetTotalTokens()))
+	if cost, ok := s.usage.EstimateCost(s.model); ok {
+		usage += status_bar_separator + formatCost(cost)
+	}
+
+	// The padding of the bar takes one column on each side
+	available := s.width - 2
+	segments := []string{s.mode.String()}
+	if s.selecting {
+		segments = append(segments, status_bar_selection)
+	}
+	segments = append(segments, s.model)
+
+	fixed := append(append([]string{}, segments...), usage)
+	dirWidth := available - runewidth.StringWidth(strings.Join(fixed, status_bar_separator)) - runewidth.StringWidth(status_bar_separator)
+
+	if dir := truncateLineStart(s.dir, dirWidth); dir != "" && dirWidth > 1 {
+		segments = append(segments, dir)
+	}
+	segments = append(segments, usage)
+
+	return renderer.RenderStatusBar(truncateLine(strings.Join(segments, status_bar_separator), available), s.width)
+}
+
+// formatTokens is a function that formats a number of tokens, the thousands and millions being abbreviated.
+func formatTokens(tokens int) string {
+	switch {
+	case tokens >= 1000000:
+		return fmt.Sprintf("%.1fM", float64(tokens)/1000000)
+	case tokens >= 1000:
+		return fmt.Sprintf("%.1fk", float64(tokens)/1000)
+	default:
+		return fmt.Sprintf("%d", tokens)
+	}
+}
+
+// formatCost is a function that formats a cost in US dollars, the costs under a cent being approximated.
+func formatCost(cost float64) string {
+	if cost > 0 && cost < 0.01 {
+		return "<$0.01"
+	}
+
+	return fmt.Sprintf("$%.2f", cost)
+}
+
+// truncateLineStart is a function that shortens a line to a maximum number of cells, keeping its end and starting it
+// with an ellipsis, a wide character cut in half being replaced by a space.
+func truncateLineStart(line string, width int) string {
+	lineWidth := runewidth.StringWidth(line)
+	if width < 1 || lineWidth <= width {
+		return line
+	}
+
+	return runewidth.TruncateLeft(line, lineWidth-width+1, "…")
+}
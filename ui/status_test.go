@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/akhilsharma90/terminal-assistant/ai"
+)
+
+func TestUIStatusBar(t *testing.T) {
+	t.Run("View", testStatusBarView)
+	t.Run("Truncate", testStatusBarTruncate)
+	t.Run("TruncateWideCharacters", testTruncateWideCharacters)
+	t.Run("FormatTokens", testFormatTokens)
+}
+
+// testStatusBarView tests the View method of the StatusBar struct.
+func testStatusBarView(t *testing.T) {
+	r := NewRenderer(glamour.WithAutoStyle())
+	s := NewStatusBar(80).SetMode(ChatPromptMode).SetModel("gpt-4o").SetDir("/tmp/project").SetUsage(ai.EngineUsage{})
+
+	view := s.View(r)
+	assert.Contains(t, view, "chat", "The status bar should display the prompt mode.")
+	assert.Contains(t, view, "gpt-4o", "The status bar should display the model.")
+	assert.Contains(t, view, "/tmp/project", "The status bar should display the directory.")
+	assert.Contains(t, view, "0 tokens · $0.00", "The status bar should display the tokens and their cost.")
+	assert.Equal(t, 1, lipgloss.Height(view), "The status bar should fit on a single line.")
+
+	assert.NotContains(t, view, status_bar_selection, "The selection mode should not be displayed by default.")
+	assert.Contains(t, s.SetSelecting(true).View(r), "chat · select · gpt-4o", "The selection mode should follow the prompt mode.")
+
+	s.SetModel("llama3")
+	assert.NotContains(t, s.View(r), "$", "The cost of an unknown model should not be displayed.")
+}
+
+// testStatusBarTruncate tests the rendering of the StatusBar struct on narrow terminals.
+func testStatusBarTruncate(t *testing.T) {
+	r := NewRenderer(glamour.WithAutoStyle())
+	s := NewStatusBar(50).SetMode(ExecPromptMode).SetModel("gpt-4o").SetDir("/tmp/" + strings.Repeat("a", 40) + "/project")
+
+	view := s.View(r)
+	assert.Contains(t, view, "…", "The directory should be shortened.")
+	assert.Contains(t, view, "project", "The end of the directory should be kept.")
+	assert.Contains(t, view, "tokens", "The tokens should be kept.")
+	assert.LessOrEqual(t, lipgloss.Width(view), 50, "The status bar should fit the terminal.")
+
+	s.SetWidth(20)
+	assert.NotContains(t, s.View(r), "project", "The directory should be dropped on a very narrow terminal.")
+	assert.LessOrEqual(t, lipgloss.Width(s.View(r)), 20, "The status bar should fit the terminal.")
+
+	s.SetWidth(50).SetDir("/tmp/" + strings.Repeat("写真", 20) + "/プロジェクト")
+	view = s.View(r)
+	assert.Contains(t, view, "プロジェクト", "The end of the directory should be kept.")
+	assert.Equal(t, 50, lipgloss.Width(view), "The wide characters should take two cells.")
+}
+
+// testTruncateWideCharacters tests that the lines are shortened on the display cells, the wide characters being
+// never cut.
+func testTruncateWideCharacters(t *testing.T) {
+	assert.Equal(t, "日本…", truncateLine("日本語テキスト", 6))
+	assert.Equal(t, "ok 🎉", truncateLine("ok 🎉", 5))
+	assert.Equal(t, "ab…", truncateLine("ab🎉🎉", 4))
+	assert.Equal(t, "…テキスト", truncateLineStart("日本語テキスト", 9))
+	assert.Equal(t, "… キスト", truncateLineStart("日本語テキスト", 8), "A wide character cut in half should be replaced by a space.")
+	assert.Equal(t, "…🎉", truncateLineStart("ab🎉🎉", 3))
+}
+
+// testFormatTokens tests the formatTokens function.
+func testFormatTokens(t *testing.T) {
+	assert.Equal(t, "999", formatTokens(999), "The small numbers should not be abbreviated.")
+	assert.Equal(t, "1.5k", formatTokens(1500), "The thousands should be abbreviated.")
+	assert.Equal(t, "2.0M", formatTokens(2000000), "The millions should be abbreviated.")
+}
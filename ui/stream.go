@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// stream_render_interval is the minimum delay between two renderings of the answer being streamed.
+const stream_render_interval = 80 * time.Millisecond
+
+// stream_render_bytes is the size of the content received since the last rendering of the answer being streamed
+// that renders it again without waiting for the delay.
+const stream_render_bytes = 2048
+
+// streamFlushMsg is a message rendering the content of the answer being streamed received since the last rendering.
+type streamFlushMsg struct{}
+
+// StreamPreview is a struct that renders the markdown answer being streamed, at most every stream_render_interval so
+// the long answers keep the user interface responsive. The code block still open is closed for the display, so its
+// content is rendered as code until the answer is complete.
+type StreamPreview struct {
+	rendered string           // The last rendering of the answer.
+	size     int              // The size of the answer at the last rendering.
+	last     time.Time        // The time of the last rendering.
+	flushing bool             // Whether a rendering of the content received since the last one is scheduled.
+	now      func() time.Time // The clock of the renderings.
+}
+
+// NewStreamPreview is a function that creates a new StreamPreview instance.
+func NewStreamPreview() *StreamPreview {
+	return &StreamPreview{
+		now: time.Now,
+	}
+}
+
+// Update is a method on the StreamPreview struct that renders the answer received so far if the delay since the last
+// rendering elapsed, or if enough content was received, and returns whether it was rendered. Otherwise, a command
+// rendering it once the delay elapses is returned, unless one is already scheduled.
+func (s *StreamPreview) Update(answer string, renderer *Renderer) (bool, tea.Cmd) {
+	elapsed := s.now().Sub(s.last)
+	if elapsed >= stream_render_interval || len(answer)-s.size >= stream_render_bytes {
+		s.Render(answer, renderer)
+		return true, nil
+	}
+	if s.flushing {
+		return false, nil
+	}
+
+	s.flushing = true
+	return false, tea.Tick(stream_render_interval-elapsed, func(time.Time) tea.Msg {
+		return streamFlushMsg{}
+	})
+}
+
+// Flush is a method on the StreamPreview struct that renders the content received since the last rendering, once
+// the scheduled delay elapsed, and returns whether it was rendered.
+func (s *StreamPreview) Flush(answer string, renderer *Renderer) bool {
+	s.flushing = false
+	if len(answer) == s.size {
+		return false
+	}
+	s.Render(answer, renderer)
+
+	return true
+}
+
+// Render is a method on the StreamPreview struct that renders the answer received so far at once, like when
+// the terminal is resized, and returns its rendering.
+func (s *StreamPreview) Render(answer string, renderer *Renderer) string {
+	s.rendered = renderer.RenderContent(closeCodeFence(answer))
+	s.size = len(answer)
+	s.last = s.now()
+
+	return s.rendered
+}
+
+// View is a method on the StreamPreview struct that returns the last rendering of the answer.
+func (s *StreamPreview) View() string {
+	return s.rendered
+}
+
+// Reset is a method on the StreamPreview struct that forgets the answer, once it is complete.
+func (s *StreamPreview) Reset() *StreamPreview {
+	s.rendered = ""
+	s.size = 0
+	s.last = time.Time{}
+	s.flushing = false
+
+	return s
+}
+
+// closeCodeFence is a function that closes the code block left open at the end of a markdown content, like an answer
+// being streamed, so its content is not rendered as markdown. A block opened with a fence of backticks or tildes is
+// closed by a fence of the same character at least as long.
+func closeCodeFence(markdown string) string {
+	open := ""
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		fence := codeFence(trimmed)
+		switch {
+		case fence == "":
+		case open == "":
+			open = fence
+		case fence == trimmed && fence[0] == open[0] && len(fence) >= len(open):
+			open = ""
+		}
+	}
+	if open == "" {
+		return markdown
+	}
+	if !strings.HasSuffix(markdown, "\n") {
+		markdown += "\n"
+	}
+
+	return markdown + open
+}
@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamPreview(t *testing.T) {
+	t.Run("Throttle", testStreamPreviewThrottle)
+	t.Run("CloseCodeFence", testCloseCodeFence)
+}
+
+// newTestStreamPreview creates a StreamPreview whose clock is advanced by the tests.
+func newTestStreamPreview(clock *time.Time) *StreamPreview {
+	preview := NewStreamPreview()
+	preview.now = func() time.Time {
+		return *clock
+	}
+
+	return preview
+}
+
+// testStreamPreviewThrottle tests that the answer is rendered at most every stream_render_interval, unless enough
+// content was received, the content received in between being rendered by the scheduled flush.
+func testStreamPreviewThrottle(t *testing.T) {
+	renderer := NewRenderer(glamour.WithStandardStyle("notty"))
+	clock := time.Now()
+	preview := newTestStreamPreview(&clock)
+
+	rendered, cmd := preview.Update("Hello", renderer)
+	assert.True(t, rendered, "The first content should be rendered at once.")
+	assert.Nil(t, cmd)
+	assert.Contains(t, preview.View(), "Hello")
+
+	clock = clock.Add(stream_render_interval / 4)
+	rendered, cmd = preview.Update("Hello world", renderer)
+	assert.False(t, rendered, "The content should not be rendered before the delay.")
+	require.NotNil(t, cmd, "A flush should be scheduled.")
+	assert.NotContains(t, preview.View(), "world")
+	_, cmd = preview.Update("Hello world!", renderer)
+	assert.Nil(t, cmd, "A single flush should be scheduled.")
+
+	assert.True(t, preview.Flush("Hello world!", renderer))
+	assert.Contains(t, preview.View(), "world!", "The flush should render the content received since the last rendering.")
+	assert.False(t, preview.Flush("Hello world!", renderer), "Nothing should be rendered without new content.")
+
+	rendered, _ = preview.Update("Hello world!"+strings.Repeat("a", stream_render_bytes), renderer)
+	assert.True(t, rendered, "A large content should be rendered before the delay.")
+
+	clock = clock.Add(stream_render_interval)
+	rendered, _ = preview.Update("Hello", renderer)
+	assert.True(t, rendered, "The content should be rendered after the delay.")
+
+	assert.Empty(t, preview.Reset().View())
+}
+
+// testCloseCodeFence tests that the code block left open at the end of a markdown content is closed.
+func testCloseCodeFence(t *testing.T) {
+	testCases := []struct {
+		name     string
+		markdown string
+		expected string
+	}{
+		{"NoBlock", "Some **text**", "Some **text**"},
+		{"ClosedBlock", "```go\nfmt.Println()\n```\n", "```go\nfmt.Println()\n```\n"},
+		{"OpenBlock", "Run:\n```sh\nls -la", "Run:\n```sh\nls -la\n```"},
+		{"OpenBlockNewline", "```sh\nls\n", "```sh\nls\n```"},
+		{"Tildes", "~~~~\n```\ncode", "~~~~\n```\ncode\n~~~~"},
+		{"ShorterFence", "````\ncode\n```", "````\ncode\n```\n````"},
+		{"SecondBlock", "```\na\n```\ntext\n```py\nb", "```\na\n```\ntext\n```py\nb\n```"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, closeCodeFence(tc.markdown))
+		})
+	}
+}
+
+// BenchmarkStreamPreview benchmarks the rendering of a large answer streamed in small chunks, most of them being
+// throttled like in the user interface.
+func BenchmarkStreamPreview(b *testing.B) {
+	renderer := NewRenderer(glamour.WithStandardStyle("notty"))
+	var chunks []string
+	for i := 0; i < 200; i++ {
+		chunks = append(chunks, fmt.Sprintf("| row %d | value %d |\n", i, i))
+		if i%20 == 0 {
+			chunks = append(chunks, "```go\nfmt.Println(\"streamed\")\n")
+		}
+		if i%20 == 10 {
+			chunks = append(chunks, "```\n")
+		}
+	}
+
+	for i := 0; i < b.N; i++ {
+		clock := time.Now()
+		preview := newTestStreamPreview(&clock)
+		answer := ""
+		for _, chunk := range chunks {
+			answer += chunk
+			clock = clock.Add(5 * time.Millisecond)
+			preview.Update(answer, renderer)
+		}
+		preview.Render(answer, renderer)
+	}
+}
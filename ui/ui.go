@@ -1,34 +1,144 @@
 package ui
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/akhilsharma90/terminal-assistant/ai"
 	"github.com/akhilsharma90/terminal-assistant/config"
+	"github.com/akhilsharma90/terminal-assistant/export"
 	"github.com/akhilsharma90/terminal-assistant/history"
 	"github.com/akhilsharma90/terminal-assistant/run"
+	"github.com/akhilsharma90/terminal-assistant/system"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/viper"
 )
 
+// job_termination_grace is the delay given to the background jobs to stop before being killed on exit.
+const job_termination_grace = 2 * time.Second
+
+// quit_interrupt_window is the delay during which a second ctrl+c quits after interrupting a command.
+const quit_interrupt_window = 2 * time.Second
+
+// alias_load_timeout is the delay given to the shell of the user to list its aliases at startup.
+const alias_load_timeout = 3 * time.Second
+
+// resize_debounce is the delay without window size change after which the content is rendered at the new width,
+// so dragging a split does not render the conversation at every intermediate width.
+const resize_debounce = 100 * time.Millisecond
+
+// prompt_max_suggestions is the maximum number of inputs of the history completing the typed text, cycled with tab.
+const prompt_max_suggestions = 10
+
+// min_wrap_width is the minimum width the content is wrapped at, narrower terminals displaying a plain view instead.
+const min_wrap_width = 20
+
+// model_preload_timeout is the delay given to the connection to the model to be established at startup.
+const model_preload_timeout = 10 * time.Second
+
+// default_raw_key is the key toggling the last answer between its rendered and raw markdown when none is configured.
+const default_raw_key = "ctrl+x"
+
+// default_transcript_key is the key saving the transcript of the session when none is configured.
+const default_transcript_key = "ctrl+t"
+
+// transcript_time_format is the format of the time naming the transcript files of the sessions.
+const transcript_time_format = "2006-01-02-150405"
+
+// empty_hint_interval is the minimum delay between two tips shown when enter is pressed on an empty input,
+// so pressing it repeatedly does not fill the conversation.
+const empty_hint_interval = 3 * time.Second
+
+// default_retry_key is the key sending again the last request that failed when none is configured.
+const default_retry_key = "ctrl+g"
+
+// exec_json_retries is the number of times a request of the exec mode is sent again when the answer contains an
+// invalid JSON object.
+const exec_json_retries = 2
+
+// streaming_status is the status of the spinner while the answer is streamed.
+const streaming_status = "responding"
+
+// capture_termination_grace is the delay given on exit to the captured command being executed to be interrupted.
+const capture_termination_grace = 3 * time.Second
+
 // UiState is a struct that represents the state of the user interface.
 type UiState struct {
-	error       error      // Any error that occurred.
-	runMode     RunMode    // The mode in which the program is running.
-	promptMode  PromptMode // The mode of the prompt.
-	configuring bool       // Whether the program is in configuration mode.
-	querying    bool       // Whether the program is in querying mode.
-	confirming  bool       // Whether the program is in confirming mode.
-	executing   bool       // Whether the program is in executing mode.
-	args        string     // The arguments passed to the program.
-	pipe        string     // The pipe used by the program.
-	buffer      string     // The buffer of the program.
-	command     string     // The command being executed by the program.
+	error               error                     // Any error that occurred.
+	fatal               bool                      // Whether the error ended the session, rendered instead of the user interface.
+	ctx                 context.Context           // The context of the session, cancelled on shutdown.
+	stop                context.CancelFunc        // The cancellation of the context of the session.
+	runMode             RunMode                   // The mode in which the program is running.
+	promptMode          PromptMode                // The mode of the prompt.
+	configuring         bool                      // Whether the program is in configuration mode.
+	querying            bool                      // Whether the program is in querying mode.
+	confirming          bool                      // Whether the program is in confirming mode.
+	executing           bool                      // Whether the program is in executing mode.
+	args                string                    // The arguments passed to the program.
+	pipe                string                    // The pipe used by the program.
+	pipeInput           *UiInput                  // The input whose piped standard input is read before starting, nil once read.
+	pipeProgress        chan pipeProgressMsg      // The number of bytes of the piped input read so far, while it is read.
+	pipeWarning         string                    // The warning telling the piped input was truncated while it was read, if not reported yet.
+	ingesting           bool                      // Whether the piped input is being read before starting.
+	buffer              string                    // The buffer of the program.
+	command             string                    // The command being executed by the program.
+	lastOutput          run.RunOutput             // The output of the last captured command.
+	keepJobs            bool                      // Whether the background jobs should keep running on exit.
+	turns               []export.ConversationTurn // The turns of the conversation of the session.
+	fixing              bool                      // Whether the user is asked to fix a failed command.
+	fixAttempts         int                       // The number of consecutive attempts to fix a command.
+	lastRequest         string                    // The last request sent to the AI in exec mode.
+	lastExecutedCommand string                    // The last command executed.
+	lastFailure         run.RunOutput             // The output of the last failed command.
+	cancel              context.CancelFunc        // The interruption of the captured command being executed, if any.
+	lastInterrupt       time.Time                 // The time of the last interruption of a captured command.
+	captureDone         chan struct{}             // Closed when the captured command being executed is finished.
+	piping              bool                      // Whether the output of the command being executed is piped into a chat question.
+	autoConfirm         bool                      // Whether the low risk commands are executed without confirmation for the rest of the session.
+	pendingImages       []string                  // The paths of the images attached to the next message.
+	progress            chan run.RunProgressMsg   // The output lines of the captured command being executed, if streamed.
+	lastAnswer          string                    // The raw text of the last chat answer or exec explanation, copied to the clipboard.
+	filteredHistory     *history.History          // The view of the history navigating the inputs of the prompt mode, if enabled.
+	resizes             int                       // The number of window size changes, identifying the last one.
+	confirmYes          bool                      // Whether the Yes choice of the confirmation is highlighted, No being highlighted by default.
+	confirmInput        string                    // The start of the confirmation word typed so far.
+	modelReady          bool                      // Whether the connection to the model was established, gating the first query of the REPL mode.
+	submitOnReady       bool                      // Whether the input of the prompt is sent once the connection to the model is established.
+	selecting           bool                      // Whether the mouse is released so the terminal selects text, in the REPL mode.
+	pendingPaste        string                    // The large pasted text waiting for a confirmation to be inserted into the prompt.
+	historyPrefix       string                    // The input typed before navigating the history, only the inputs starting with it being navigated.
+	historyRecall       string                    // The input of the history last displayed in the prompt.
+	failedRequest       *failedRequest            // The last request that failed with a transient error, sent again with the retry key.
+	streamFailed        chan struct{}             // Closed when the chat stream being awaited fails, so it is no longer awaited.
+	statusChannel       <-chan ai.EngineStatus    // The status channel of the engine being listened to, if any.
+	markdownStyle       string                    // The style of the markdown content overriding the configured one, if any.
+	transcriptPath      string                    // The path of the transcript of the session, once saved.
+	transcriptTurns     int                       // The number of turns of the session written to its transcript.
+	emptyHints          int                       // The number of tips shown when enter was pressed on an empty input.
+	lastEmptyHint       time.Time                 // The time of the last tip shown when enter was pressed on an empty input.
+	suggestionsSource   suggestionsSource         // What the suggestions of the prompt were computed from.
+}
+
+// suggestionsSource is a struct that represents what the suggestions of the prompt are computed from, so they are
+// only computed again when the input, the history or the configuration changes, or when the prompt is hidden or shown.
+type suggestionsSource struct {
+	value   string
+	hidden  bool
+	history *history.History
+	config  *config.Config
 }
 
 // UiDimensions is a struct that represents the dimensions of the user interface.
@@ -39,9 +149,15 @@ type UiDimensions struct {
 
 // UiComponents is a struct that represents the components of the user interface.
 type UiComponents struct {
-	prompt   *Prompt   // The prompt of the user interface.
-	renderer *Renderer // The renderer of the user interface.
-	spinner  *Spinner  // The spinner of the user interface.
+	prompt       *Prompt        // The prompt of the user interface.
+	renderer     *Renderer      // The renderer of the user interface.
+	spinner      *Spinner       // The spinner of the user interface.
+	viewport     *Viewport      // The scrollable viewport of the user interface.
+	live         *LiveOutput    // The live output of the captured command being executed.
+	conversation *Conversation  // The scrollable conversation displayed above the prompt in the REPL mode.
+	search       *Search        // The search in the conversation, displayed instead of the prompt.
+	status       *StatusBar     // The status bar displayed under the prompt in the REPL mode.
+	stream       *StreamPreview // The rendering of the answer being streamed in the chat prompt mode.
 }
 
 // Ui is a struct that represents the user interface.
@@ -52,6 +168,9 @@ type Ui struct {
 	config     *config.Config   // The configuration of the program.
 	engine     *ai.Engine       // The AI engine of the program.
 	history    *history.History // The history of the program.
+	jobs       *run.Jobs        // The background jobs of the program.
+	aliases    run.Aliases      // The aliases of the shell of the user, if resolved.
+	exitHooks  []func()         // The functions called on exit, the last registered being called first.
 }
 
 // NewUi is a function that creates a new Ui instance.
@@ -59,17 +178,20 @@ func NewUi(input *UiInput) *Ui {
 	// Create a new Ui instance with the input run mode and prompt mode, a new prompt, renderer, and spinner, and a new history.
 	return &Ui{
 		state: UiState{
-			error:       nil,
-			runMode:     input.GetRunMode(),
-			promptMode:  input.GetPromptMode(),
-			configuring: false,
-			querying:    false,
-			confirming:  false,
-			executing:   false,
-			args:        input.GetArgs(),
-			pipe:        input.GetPipe(),
-			buffer:      "",
-			command:     "",
+			error:         nil,
+			runMode:       input.GetRunMode(),
+			promptMode:    input.GetPromptMode(),
+			configuring:   false,
+			querying:      false,
+			confirming:    false,
+			executing:     false,
+			args:          input.GetArgs(),
+			pipeInput:     input,
+			buffer:        "",
+			command:       "",
+			keepJobs:      input.GetKeepJobs(),
+			pendingImages: input.GetImages(),
+			markdownStyle: input.GetStyle(),
 		},
 		dimensions: UiDimensions{
 			150,
@@ -77,24 +199,47 @@ func NewUi(input *UiInput) *Ui {
 		},
 		components: UiComponents{
 			prompt: NewPrompt(input.GetPromptMode()),
-			renderer: NewRenderer(
+			renderer: NewColorRenderer(
+				!input.GetNoColor(),
 				glamour.WithAutoStyle(),
-				glamour.WithWordWrap(150),
+				glamour.WithWordWrap(wrapWidth(150)),
 			),
-			spinner: NewSpinner(),
+			spinner:      NewSpinner(),
+			viewport:     NewViewport(150, 150).SetMouseWheelEnabled(input.GetMouseEnabled()),
+			live:         NewLiveOutput(150, 150),
+			conversation: NewConversation(150, 150),
+			search:       NewSearch(),
+			status:       newStatusBar(),
+			stream:       NewStreamPreview(),
 		},
 		history: history.NewHistory(),
+		jobs:    run.NewJobs(),
 	}
 }
 
 // Init initializes the UI and returns a tea.Cmd that represents the initial command to be executed.
-// It loads the configuration, handles any errors, and determines whether to start in REPL mode or CLI mode.
+// It loads the configuration and reads the piped input, if any, before starting in REPL mode or CLI mode.
 func (u *Ui) Init() tea.Cmd {
+	u.state.ctx, u.state.stop = context.WithCancel(context.Background())
+
 	// Load the configuration
 	config, err := config.NewConfig()
+
+	// Read the piped input in the background first, so a large input does not look like a frozen program
+	if input := u.state.pipeInput; input != nil && input.stdin != nil {
+		u.state.pipeInput = nil
+		return u.ingestPipe(input, config, err)
+	}
+
+	return u.start(config, err)
+}
+
+// start is a method of the Ui struct that starts the configuration when the configuration file is not found,
+// or the REPL mode or the CLI mode with the loaded configuration.
+func (u *Ui) start(config *config.Config, err error) tea.Cmd {
 	if err != nil {
 		// Handle the case when the configuration file is not found
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
+		if errors.As(err, &viper.ConfigFileNotFoundError{}) {
 			if u.state.runMode == ReplMode {
 				// If running in REPL mode, sequence the commands to clear the screen and start the configuration
 				return tea.Sequence(
@@ -114,6 +259,11 @@ func (u *Ui) Init() tea.Cmd {
 		}
 	}
 
+	// Disable the mouse support for the terminals misbehaving with it
+	if !config.GetUserConfig().GetMouse() {
+		u.components.viewport.SetMouseWheelEnabled(false)
+	}
+
 	// Determine whether to start in REPL mode or CLI mode
 	if u.state.runMode == ReplMode {
 		// Start in REPL mode
@@ -125,7 +275,34 @@ func (u *Ui) Init() tea.Cmd {
 }
 
 // Update is a method of the Ui struct that handles updating the UI based on the received message.
+// It also reports the background jobs that finished since the last update.
 func (u *Ui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := u.update(msg)
+	u.syncStatusBar()
+	u.syncSuggestions()
+
+	// Notify the user of the answer of a long query, which may have been left for another window
+	if notifyCmd := u.notifyLongQuery(msg); notifyCmd != nil {
+		cmd = tea.Batch(cmd, notifyCmd)
+	}
+
+	if u.state.runMode == ReplMode {
+		for _, job := range u.jobs.PopFinished() {
+			notification := u.components.renderer.RenderHelp(fmt.Sprintf(
+				"[job %d done: %s] %s",
+				job.GetId(),
+				formatJobState(job.IsRunning(), job.GetExitCode(), job.GetElapsed()),
+				escapeMarkdown(job.GetCommand()),
+			))
+			cmd = tea.Batch(cmd, u.print(notification))
+		}
+	}
+
+	return model, cmd
+}
+
+// update is a method of the Ui struct that handles the received message.
+func (u *Ui) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var (
 		cmds       []tea.Cmd
 		promptCmd  tea.Cmd
@@ -135,7 +312,7 @@ func (u *Ui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	// Handle spinner tick message
 	case spinner.TickMsg:
-		if u.state.querying {
+		if u.state.querying || u.state.ingesting {
 			u.components.spinner, spinnerCmd = u.components.spinner.Update(msg)
 			cmds = append(
 				cmds,
@@ -144,49 +321,172 @@ func (u *Ui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	// Handle window size message
 	case tea.WindowSizeMsg:
-		u.dimensions.width = msg.Width
-		u.dimensions.height = msg.Height
-		u.components.renderer = NewRenderer(
-			glamour.WithAutoStyle(),
-			glamour.WithWordWrap(u.dimensions.width),
-		)
+		// Lay out the components at once, and render the content at the new width once the size settles
+		u.layout(msg.Width, msg.Height)
+		if wrapWidth(msg.Width) != u.components.renderer.GetWidth() {
+			u.state.resizes++
+			resizes := u.state.resizes
+			cmds = append(cmds, tea.Tick(resize_debounce, func(time.Time) tea.Msg {
+				return resizeMsg(resizes)
+			}))
+		}
+	// Handle the connection to the model, sending the input waiting for it
+	case modelReadyMsg:
+		u.state.modelReady = true
+		submit := u.state.submitOnReady
+		u.state.submitOnReady = false
+		if msg.err != nil {
+			return u, u.print(u.components.renderer.RenderError(fmt.Sprintf("[model error] %s\n", msg.err)))
+		}
+		// Warn when the configured context window exceeds the one of the model, known once it is retrieved
+		if u.engine != nil && u.config != nil {
+			if warning := u.engine.CheckMaxContextTokens(); warning != "" {
+				cmds = append(cmds, u.print(u.components.renderer.RenderWarning(fmt.Sprintf("[config warning] %s\n", warning))))
+			}
+		}
+		if input := u.components.prompt.GetValue(); submit && input != "" && !u.state.querying && !u.state.confirming {
+			cmds = append(cmds, u.submitInput(input))
+		}
+	// Render the content at the width of the last window size change
+	case resizeMsg:
+		if int(msg) == u.state.resizes {
+			if err := u.rerender(); err != nil {
+				cmds = append(cmds, u.print(u.components.renderer.RenderWarning(fmt.Sprintf("[resize error] %s\n", err))))
+			}
+		}
+	// Scroll the viewport with the mouse wheel
+	case tea.MouseMsg:
+		if u.components.viewport.IsVisible() {
+			var viewportCmd tea.Cmd
+			u.components.viewport, viewportCmd = u.components.viewport.Update(msg)
+			return u, viewportCmd
+		}
+		if u.state.runMode == ReplMode {
+			var conversationCmd tea.Cmd
+			u.components.conversation, conversationCmd = u.components.conversation.Update(msg)
+			return u, conversationCmd
+		}
+	// Display the bytes of the piped input read so far, then start once it is read
+	case pipeProgressMsg:
+		u.components.spinner.SetBytes(int(msg))
+		return u, u.awaitPipeProgress()
+	case pipeIngestedMsg:
+		return u, u.finishPipe(msg)
 	// Handle keyboard input
 	case tea.KeyMsg:
+		// Only quit while the piped input is read, the program being started once it is read
+		if u.state.ingesting {
+			if msg.Type == tea.KeyCtrlC {
+				if u.state.stop != nil {
+					u.state.stop()
+				}
+				return u, tea.Quit
+			}
+			return u, nil
+		}
+		// Dismiss the banner of the last error on any key
+		u.clearError()
+		// Scroll or close the viewport while it is displayed
+		if u.components.viewport.IsVisible() && msg.Type != tea.KeyCtrlC {
+			if msg.Type == tea.KeyEsc || msg.String() == "q" {
+				return u, u.hideViewport()
+			}
+			var viewportCmd tea.Cmd
+			u.components.viewport, viewportCmd = u.components.viewport.Update(msg)
+			return u, viewportCmd
+		}
+		// Type the query searched in the conversation, or jump between its matches
+		if u.components.search.IsActive() && msg.Type != tea.KeyCtrlC {
+			return u, u.updateSearch(msg)
+		}
+		// Confirm the insertion of a large paste, any other key than y or enter discarding it
+		if u.state.pendingPaste != "" {
+			return u, u.answerPaste(msg)
+		}
+		// Insert the pasted text into the prompt as a single edit, never submitting it
+		if msg.Paste {
+			return u, u.paste(string(msg.Runes))
+		}
+		// Scroll the live output of the captured command being executed
+		if u.state.executing && (msg.Type == tea.KeyPgUp || msg.Type == tea.KeyPgDown) {
+			var liveCmd tea.Cmd
+			u.components.live, liveCmd = u.components.live.Update(msg)
+			return u, liveCmd
+		}
+		// Copy the last answer to the clipboard
+		if msg.String() == u.copyKey() && !u.state.configuring && !u.state.querying {
+			return u, u.copyLastAnswer()
+		}
+		// Display the last answer as raw markdown, or render it again
+		if msg.String() == u.rawKey() && u.state.runMode == ReplMode && !u.state.configuring {
+			return u, u.toggleRawAnswer()
+		}
+		// Save the transcript of the session, only the new turns being appended once saved
+		if msg.String() == u.transcriptKey() && !u.state.configuring {
+			return u, u.saveTranscript()
+		}
+		// Send again the last request that failed with a transient error
+		if msg.String() == u.retryKey() && u.state.failedRequest != nil &&
+			!u.state.configuring && !u.state.querying && !u.state.confirming && !u.state.executing {
+			return u, u.retryRequest()
+		}
+		// Scroll the conversation
+		if u.state.runMode == ReplMode && (msg.Type == tea.KeyPgUp || msg.Type == tea.KeyPgDown) {
+			var conversationCmd tea.Cmd
+			u.components.conversation, conversationCmd = u.components.conversation.Update(msg)
+			return u, conversationCmd
+		}
+		// Accept the suggestion displayed after the input, the keys moving the cursor otherwise
+		if (msg.Type == tea.KeyRight || msg.Type == tea.KeyEnd) &&
+			!u.state.configuring && !u.state.querying && !u.state.confirming && !u.state.executing &&
+			u.components.prompt.AcceptSuggestion() {
+			return u, nil
+		}
+		// Edit the prompt with the vi keymap, esc entering its normal mode, the keys it leaves keeping their role
+		if !u.state.configuring && !u.state.querying && !u.state.confirming && !u.state.executing &&
+			u.components.prompt.HandleViKey(msg) {
+			return u, nil
+		}
 		switch msg.Type {
-		// Quit the program
+		// Interrupt the captured command being executed or the request in flight, or quit the program
 		case tea.KeyCtrlC:
+			if u.state.executing && u.state.cancel != nil && time.Since(u.state.lastInterrupt) > quit_interrupt_window {
+				u.state.lastInterrupt = time.Now()
+				u.state.cancel()
+				return u, u.print(u.components.renderer.RenderWarning("\n[interrupting, press ctrl+c again to quit]"))
+			}
+			if u.state.querying && u.state.runMode == ReplMode && u.engine != nil && time.Since(u.state.lastInterrupt) > quit_interrupt_window {
+				// The interruption is reported once the request stopped, with the answer streamed so far
+				u.state.lastInterrupt = time.Now()
+				u.engine.Cancel()
+				return u, nil
+			}
 			return u, tea.Quit
-		// Navigate command history
+		// Navigate command history from the first or last line of the input, or move the cursor between its lines
 		case tea.KeyUp, tea.KeyDown:
 			if !u.state.querying && !u.state.confirming {
-				var input *string
-				if msg.Type == tea.KeyUp {
-					input = u.history.GetPrevious()
-				} else {
-					input = u.history.GetNext()
+				if (msg.Type == tea.KeyUp && !u.components.prompt.IsFirstLine()) ||
+					(msg.Type == tea.KeyDown && !u.components.prompt.IsLastLine()) {
+					u.components.prompt, promptCmd = u.components.prompt.Update(msg)
+					return u, promptCmd
 				}
-				if input != nil {
+				if input := u.navigateHistory(msg.Type == tea.KeyUp); input != nil {
 					u.components.prompt.SetValue(*input)
-					u.components.prompt, promptCmd = u.components.prompt.Update(msg)
-					cmds = append(
-						cmds,
-						promptCmd,
-					)
 				}
 			}
 		// Switch between chat and execution mode
 		case tea.KeyTab:
-			if !u.state.querying && !u.state.confirming {
+			// Cycle through the inputs completing the typed text, if several, or switch the prompt mode
+			if !u.state.querying && !u.state.confirming && !u.state.configuring && u.components.prompt.HasSuggestions() {
+				u.components.prompt.NextSuggestion()
+				return u, nil
+			}
+			if !u.state.querying && !u.state.confirming && u.isFeatureEnabled(config.ExecFeature) {
 				if u.state.promptMode == ChatPromptMode {
-					u.state.promptMode = ExecPromptMode
-					u.components.prompt.SetMode(ExecPromptMode)
-					u.engine.SetMode(ai.ExecEngineMode)
+					u.switchPromptMode(ExecPromptMode)
 				} else {
-					u.state.promptMode = ChatPromptMode
-					u.components.prompt.SetMode(ChatPromptMode)
-					u.engine.SetMode(ai.ChatEngineMode)
+					u.switchPromptMode(ChatPromptMode)
 				}
-				u.engine.Reset()
 				u.components.prompt, promptCmd = u.components.prompt.Update(msg)
 				cmds = append(
 					cmds,
@@ -194,38 +494,62 @@ func (u *Ui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					textinput.Blink,
 				)
 			}
-		// Process user input
+		// Process user input, or insert a newline in the input with alt+enter
 		case tea.KeyEnter:
+			// Answer the confirmation with the highlighted choice
+			if u.state.confirming && !u.state.fixing {
+				confirmation := "n"
+				if u.requiresConfirmationWord() {
+					// Only the exact confirmation word executes the command, any other input declining it
+					if u.matchesConfirmationWord(u.state.confirmInput) {
+						confirmation = "y"
+					}
+					u.state.confirmInput = ""
+				} else if u.state.confirmYes {
+					confirmation = "y"
+				}
+				return u, u.answerConfirmation(confirmation, msg)
+			}
+			if msg.Alt && !u.state.configuring && !u.state.querying && !u.state.confirming {
+				u.components.prompt, promptCmd = u.components.prompt.Update(msg)
+				return u, promptCmd
+			}
 			if u.state.configuring {
 				return u, u.finishConfig(u.components.prompt.GetValue())
 			}
 			if !u.state.querying && !u.state.confirming {
 				input := u.components.prompt.GetValue()
-				if input != "" {
-					inputPrint := u.components.prompt.AsString()
+				if name, args, ok := parseSlashCommand(input); ok && u.state.runMode == ReplMode {
+					inputPrint := u.echoInput()
 					u.history.Add(input)
 					u.components.prompt.SetValue("")
-					u.components.prompt.Blur()
 					u.components.prompt, promptCmd = u.components.prompt.Update(msg)
-					if u.state.promptMode == ChatPromptMode {
-						cmds = append(
-							cmds,
-							promptCmd,
-							tea.Println(inputPrint),
-							u.startChatStream(input),
-							u.awaitChatStream(),
-						)
-					} else {
-						cmds = append(
-							cmds,
-							promptCmd,
-							tea.Println(inputPrint),
-							u.startExec(input),
-							u.components.spinner.Tick,
-						)
+					return u, tea.Sequence(
+						promptCmd,
+						u.print(inputPrint),
+						u.runSlashCommand(name, args),
+					)
+				}
+				if input != "" && u.state.runMode == ReplMode && !u.state.modelReady {
+					// Send the input once the connection to the model is established
+					if u.state.submitOnReady {
+						return u, nil
 					}
+					u.state.submitOnReady = true
+					return u, u.print(u.components.renderer.RenderHelp("[connecting to the model, the prompt is sent once connected]\n"))
+				}
+				if input != "" {
+					cmds = append(cmds, u.submitInput(input))
+				} else if u.state.runMode == ReplMode && !u.state.executing {
+					// React to an empty input with a tip, instead of doing nothing
+					cmds = append(cmds, u.emptyInputHint())
 				}
 			}
+		// Release the mouse so the terminal selects text, or capture it again to scroll the conversation
+		case tea.KeyCtrlO:
+			if u.state.runMode == ReplMode && u.components.viewport.IsMouseWheelEnabled() {
+				return u, u.toggleSelection()
+			}
 		// Show help message
 		case tea.KeyCtrlH:
 			if !u.state.configuring && !u.state.querying && !u.state.confirming {
@@ -233,13 +557,19 @@ func (u *Ui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				cmds = append(
 					cmds,
 					promptCmd,
-					tea.Println(u.components.renderer.RenderContent(u.components.renderer.RenderHelpMessage())),
+					u.printMarkdown(u.components.renderer.RenderHelpMessage(), ""),
 					textinput.Blink,
 				)
 			}
-		// Clear the screen
+		// Search in the conversation
+		case tea.KeyCtrlF:
+			if u.state.runMode == ReplMode && !u.state.configuring && !u.state.querying && !u.state.confirming && !u.state.executing {
+				return u, u.openSearch()
+			}
+		// Clear the screen, the conversation being restored with /unclear
 		case tea.KeyCtrlL:
 			if !u.state.querying && !u.state.confirming {
+				u.components.conversation.Hide()
 				u.components.prompt, promptCmd = u.components.prompt.Update(msg)
 				cmds = append(
 					cmds,
@@ -248,11 +578,17 @@ func (u *Ui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					textinput.Blink,
 				)
 			}
-		// Reset the program
+		// Reset the conversation, the inputs of the prompt being kept in the history
 		case tea.KeyCtrlR:
 			if !u.state.querying && !u.state.confirming {
-				u.history.Reset()
 				u.engine.Reset()
+				u.state.turns = nil
+				u.state.transcriptPath = ""
+				u.state.transcriptTurns = 0
+				u.state.autoConfirm = false
+				u.state.pendingImages = nil
+				u.state.lastAnswer = ""
+				u.components.conversation.Clear()
 				u.components.prompt.SetValue("")
 				u.components.prompt, promptCmd = u.components.prompt.Update(msg)
 				cmds = append(
@@ -264,7 +600,7 @@ func (u *Ui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		// Edit settings
 		case tea.KeyCtrlS:
-			if !u.state.querying && !u.state.confirming && !u.state.configuring && !u.state.executing {
+			if !u.state.querying && !u.state.confirming && !u.state.configuring && !u.state.executing && u.isFeatureEnabled(config.SettingsEditFeature) {
 				u.state.executing = true
 				u.state.buffer = ""
 				u.state.command = ""
@@ -277,39 +613,38 @@ func (u *Ui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				)
 			}
 		default:
-			if u.state.confirming {
+			if u.state.confirming && u.state.fixing {
+				u.state.confirming = false
+				u.state.fixing = false
+				u.components.prompt.SetValue("")
 				if strings.ToLower(msg.String()) == "y" {
-					u.state.confirming = false
-					u.state.executing = true
-					u.state.buffer = ""
-					u.components.prompt.SetValue("")
+					u.state.fixAttempts++
 					return u, tea.Sequence(
-						promptCmd,
-						u.execCommand(u.state.command),
+						u.print(u.components.renderer.RenderHelp(fmt.Sprintf("\n[fixing attempt %d/%d]\n", u.state.fixAttempts, u.config.GetUserConfig().GetFixMaxAttempts()))),
+						tea.Batch(
+							u.startFix(),
+							u.components.spinner.Tick,
+						),
 					)
-				} else {
-					u.state.confirming = false
-					u.state.executing = false
-					u.state.buffer = ""
-					u.components.prompt, promptCmd = u.components.prompt.Update(msg)
-					u.components.prompt.SetValue("")
-					u.components.prompt.Focus()
-					if u.state.runMode == ReplMode {
-						cmds = append(
-							cmds,
-							promptCmd,
-							tea.Println(fmt.Sprintf("\n%s\n", u.components.renderer.RenderWarning("[cancel]"))),
-							textinput.Blink,
-						)
-					} else {
-						return u, tea.Sequence(
-							promptCmd,
-							tea.Println(fmt.Sprintf("\n%s\n", u.components.renderer.RenderWarning("[cancel]"))),
-							tea.Quit,
-						)
-					}
 				}
-				u.state.command = ""
+				u.components.prompt, promptCmd = u.components.prompt.Update(msg)
+				u.components.prompt.Focus()
+				cmds = append(
+					cmds,
+					promptCmd,
+					textinput.Blink,
+				)
+			} else if u.state.confirming {
+				// Type the confirmation word, the shortcuts and the choices being disabled until it is answered
+				if u.requiresConfirmationWord() {
+					return u, u.typeConfirmation(msg)
+				}
+				// Move the highlight between the choices, or answer with a shortcut
+				if msg.Type == tea.KeyLeft || msg.Type == tea.KeyRight {
+					u.state.confirmYes = !u.state.confirmYes
+					return u, nil
+				}
+				return u, u.answerConfirmation(strings.ToLower(msg.String()), msg)
 			} else {
 				u.components.prompt.Focus()
 				u.components.prompt, promptCmd = u.components.prompt.Update(msg)
@@ -322,19 +657,77 @@ func (u *Ui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	// Handle AI engine execution output
 	case ai.EngineExecOutput:
-		var output string
-		if msg.IsExecutable() {
+		var markdown, output string
+		u.clearError()
+		u.components.status.SetUsage(u.engine.GetUsage())
+		u.state.lastAnswer = msg.GetExplanation()
+		// Explain the commands flagged as executable without a line to execute, instead of confirming nothing
+		msg = msg.Normalize()
+		if msg.IsExecutable() && u.config.GetUserConfig().GetBlockElevation() && run.RequiresElevation(u.resolveAliases(msg.GetCommand())) {
+			// Refuse the commands requiring elevated privileges when they are blocked
+			markdown = msg.GetDisplayCommandBlock(u.commandLanguage())
+			output += fmt.Sprintf("  %s\n", u.components.renderer.RenderError("[blocked: commands requiring elevated privileges are not allowed]"))
+			u.components.prompt.Focus()
+			if u.state.runMode == CliMode {
+				return u, tea.Sequence(
+					u.printAnswer(ExecPromptMode, markdown, output),
+					tea.Quit,
+				)
+			}
+		} else if program, allowed := u.policy().Check(u.resolveAliases(msg.GetCommand())); msg.IsExecutable() && !allowed {
+			// Refuse the commands running a program blocked by the policy
+			markdown = msg.GetDisplayCommandBlock(u.commandLanguage())
+			output += fmt.Sprintf("  %s\n", u.components.renderer.RenderError(fmt.Sprintf("[blocked by policy: %s is not allowed]", program)))
+			u.components.prompt.Focus()
+			if u.state.runMode == CliMode {
+				return u, tea.Sequence(
+					u.printAnswer(ExecPromptMode, markdown, output),
+					tea.Quit,
+				)
+			}
+		} else if reason, ok := u.autoExecReason(msg.GetCommand()); msg.IsExecutable() && ok {
+			// Execute the read-only commands, or the low risk commands once allowed for the session, without confirmation
+			u.addTurn(export.AssistantRole, fmt.Sprintf("%s\n\n%s", msg.GetDisplayCommand(), msg.GetExplanation()))
+			u.state.command = msg.GetCommand()
+			u.state.buffer = ""
+			markdown = msg.GetDisplayCommandBlock(u.commandLanguage())
+			output += fmt.Sprintf("  %s\n\n", u.components.renderer.RenderHelp(msg.GetExplanation()))
+			output += fmt.Sprintf("  %s\n", u.components.renderer.RenderHelp(fmt.Sprintf("[auto-executed (%s)]", reason)))
+			u.components.prompt.Blur()
+			execCmd := u.execCommand(u.state.command)
+			if u.canCapture(u.state.command) {
+				execCmd = u.captureCommand(u.state.command)
+			}
+			return u, tea.Sequence(
+				u.printAnswer(ExecPromptMode, markdown, output),
+				execCmd,
+			)
+		} else if msg.IsExecutable() {
+			u.addTurn(export.AssistantRole, fmt.Sprintf("%s\n\n%s", msg.GetDisplayCommand(), msg.GetExplanation()))
+			// The confirmation is displayed under the command until it is answered, No being highlighted
 			u.state.confirming = true
+			u.state.confirmYes = false
+			u.state.confirmInput = ""
 			u.state.command = msg.GetCommand()
-			output = u.components.renderer.RenderContent(fmt.Sprintf("`%s`", u.state.command))
-			output += fmt.Sprintf("  %s\n\n  confirm execution? [y/N]", u.components.renderer.RenderHelp(msg.GetExplanation()))
+			markdown = msg.GetDisplayCommandBlock(u.commandLanguage())
+			output += fmt.Sprintf("  %s\n\n", u.components.renderer.RenderHelp(msg.GetExplanation()))
+			if run.RequiresElevation(u.resolveAliases(u.state.command)) {
+				output += fmt.Sprintf("  %s\n\n", u.components.renderer.RenderWarning("requires elevated privileges, it will run in the terminal"))
+			}
 			u.components.prompt.Blur()
 		} else {
-			output = u.components.renderer.RenderContent(msg.GetExplanation())
+			u.addTurn(export.AssistantRole, msg.GetExplanation())
+			markdown = msg.GetExplanation()
+			if msg.Empty {
+				output += fmt.Sprintf("  %s\n", u.components.renderer.RenderWarning("[the answer had no command to execute]"))
+			}
+			if u.state.runMode == ReplMode {
+				output += u.answerMetadata()
+			}
 			u.components.prompt.Focus()
 			if u.state.runMode == CliMode {
 				return u, tea.Sequence(
-					tea.Println(output),
+					u.printAnswer(ExecPromptMode, markdown, output),
 					tea.Quit,
 				)
 			}
@@ -343,64 +736,313 @@ func (u *Ui) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return u, tea.Sequence(
 			promptCmd,
 			textinput.Blink,
-			tea.Println(output),
+			u.printAnswer(ExecPromptMode, markdown, output),
 		)
+	// Display what the engine is doing next to the spinner, the statuses of a replaced engine being ignored
+	case engineStatusMsg:
+		if msg.channel != u.state.statusChannel {
+			return u, nil
+		}
+		if u.state.querying {
+			u.components.spinner.SetStatus(msg.status.GetMessage())
+		}
+		return u, u.awaitEngineStatus(msg.channel)
 	// Handle AI engine chat stream output
 	case ai.EngineChatStreamOutput:
+		u.clearError()
+		// Keep the raw answer, only rendered for the display at the current width
+		u.state.buffer += msg.GetContent()
 		if msg.IsLast() {
-			output := u.components.renderer.RenderContent(u.state.buffer)
+			u.state.querying = false
+			u.components.status.SetUsage(u.engine.GetUsage())
+			u.addTurn(export.AssistantRole, u.state.buffer)
+			u.state.lastAnswer = u.state.buffer
+			markdown := u.state.buffer
+			output := ""
+			if msg.IsInterrupt() {
+				output += u.components.renderer.RenderWarning("[interrupted]\n")
+			} else if u.state.runMode == ReplMode {
+				output += u.answerMetadata()
+			}
 			u.state.buffer = ""
+			u.components.stream.Reset()
 			u.components.prompt.Focus()
 			if u.state.runMode == CliMode {
 				return u, tea.Sequence(
-					tea.Println(output),
+					u.printAnswer(ChatPromptMode, markdown, output),
 					tea.Quit,
 				)
 			} else {
-				return u, tea.Sequence(
-					tea.Println(output),
-					textinput.Blink,
-				)
+				// Replace the answer being streamed with the complete answer
+				u.components.conversation.SetPending("").AppendAnswer(u.answerMarker(ChatPromptMode), markdown, output, u.components.renderer)
+				return u, textinput.Blink
 			}
 		} else {
-			return u, u.awaitChatStream()
+			// Tell how long the answer is so far next to the spinner
+			u.components.spinner.SetStatus(streaming_status).SetTokens(msg.GetEstimatedTotalTokens())
+			// Render the answer received so far, throttled so the long answers stay responsive
+			rendered, renderCmd := u.components.stream.Update(u.state.buffer, u.components.renderer)
+			if rendered && u.state.runMode == ReplMode {
+				u.components.conversation.SetPending(u.answerMarker(ChatPromptMode) + u.components.stream.View())
+			}
+			return u, tea.Batch(u.awaitChatStream(), renderCmd)
+		}
+	// Render the content of the answer being streamed received since its last throttled rendering
+	case streamFlushMsg:
+		if u.state.querying && u.components.stream.Flush(u.state.buffer, u.components.renderer) && u.state.runMode == ReplMode {
+			u.components.conversation.SetPending(u.answerMarker(ChatPromptMode) + u.components.stream.View())
 		}
+		return u, nil
+	// Handle the output lines of the captured command being executed
+	case run.RunProgressMsg:
+		if u.state.executing {
+			u.components.live.Append(msg)
+		}
+		return u, u.awaitProgress()
 	// Handle runner feedback
 	case run.RunOutput:
+		u.finishInterruptible()
 		u.state.querying = false
+		u.state.executing = false
+		u.state.command = ""
+		if !msg.HasError() {
+			u.clearError()
+		}
 		u.components.prompt, promptCmd = u.components.prompt.Update(msg)
 		u.components.prompt.Focus()
-		output := u.components.renderer.RenderSuccess(fmt.Sprintf("\n%s\n", msg.GetSuccessMessage()))
+		status := withDuration(msg.GetSuccessMessage(), msg.GetDuration())
+		output := u.components.renderer.RenderOutput(fmt.Sprintf("\n%s\n", status), 0)
 		if msg.HasError() {
-			output = u.components.renderer.RenderError(fmt.Sprintf("\n%s\n", msg.GetErrorMessage()))
+			// Color the status by the exit code of the command, the exit code 1 being often not a failure
+			code := exitStatus(msg)
+			status = withDuration(msg.GetErrorMessage(), msg.GetDuration())
+			output = u.components.renderer.RenderOutput(fmt.Sprintf("\n%s\n", status), code)
+			// Highlight the exit code of the command, the signal and the timeout being part of the error message
+			if code > 0 && strings.HasSuffix(status, run.FormatExitCode(code)) {
+				output = fmt.Sprintf(
+					"%s%s\n",
+					u.components.renderer.RenderOutput("\n"+strings.TrimSuffix(status, run.FormatExitCode(code)), code),
+					u.components.renderer.RenderExitCode(code),
+				)
+			}
+		}
+		if msg.IsCaptured() {
+			u.state.lastOutput = msg
+			u.state.progress = nil
+			u.components.live.Reset()
+			// Collapse the output to the last screenful above the status
+			screenful := u.dimensions.height - 3
+			if msg.HasError() {
+				// Show the tail of the stderr under the status of the failed command
+				output = u.components.renderer.RenderCollapsedOutput(msg.GetStdout(), "", screenful) + output
+				if tail := msg.GetStderrTail(); tail != "" {
+					output += u.components.renderer.RenderStderrTail(tail, u.dimensions.width) + "\n"
+				}
+			} else {
+				output = u.components.renderer.RenderCollapsedOutput(msg.GetStdout(), msg.GetStderr(), screenful) + output
+			}
+			u.addCommandTurn(fmt.Sprintf("```\n%s\n```\n\n%s", strings.TrimRight(run.StripAnsi(msg.GetStdout()+msg.GetStderr()), "\n"), commandStatus(msg)), msg.GetDuration())
+		} else {
+			u.addCommandTurn(commandStatus(msg), msg.GetDuration())
+		}
+		// Annotate the input that generated the command with its duration, saved in the history file
+		u.history.SetDuration(msg.GetDuration())
+		if u.state.piping {
+			u.state.piping = false
+			return u, tea.Sequence(
+				u.print(output),
+				u.pipeOutput(msg),
+			)
+		}
+		if u.canFix(msg) {
+			// Offer to ask the AI to fix the failed command
+			u.state.confirming = true
+			u.state.fixing = true
+			u.state.lastFailure = msg
+			u.components.prompt.Blur()
+			return u, tea.Sequence(
+				u.print(output),
+				u.print(fmt.Sprintf("  %s", u.components.renderer.RenderWarning("command failed — ask AI to fix it? [y/N]"))),
+				promptCmd,
+			)
 		}
 		if u.state.runMode == CliMode {
 			return u, tea.Sequence(
-				tea.Println(output),
+				u.print(output),
 				tea.Quit,
 			)
 		} else {
 			return u, tea.Sequence(
-				tea.Println(output),
+				u.print(output),
 				promptCmd,
 				textinput.Blink,
 			)
 		}
-	// Handle errors
+	// Handle the edited settings, the changed fields being listed under their status
+	case settingsMsg:
+		model, outputCmd := u.Update(msg.output)
+		if len(msg.changes) == 0 {
+			return model, outputCmd
+		}
+		changes := make([]string, len(msg.changes))
+		for i, change := range msg.changes {
+			changes[i] = u.components.renderer.RenderHelp(fmt.Sprintf("[changed: %s]", escapeMarkdown(change.String())))
+		}
+		return model, tea.Sequence(outputCmd, u.print(strings.Join(changes, "\n")+"\n"))
+	// Handle the content displayed above the prompt
+	case printMsg:
+		u.components.conversation.Append(string(msg))
+		return u, nil
+	case printMarkdownMsg:
+		if msg.answer {
+			u.components.conversation.AppendAnswer(msg.prefix, msg.markdown, msg.suffix, u.components.renderer)
+		} else {
+			u.components.conversation.AppendMarkdown(msg.markdown, msg.suffix, u.components.renderer)
+		}
+		return u, nil
+	// Handle the panics recovered in the commands
+	case RecoveryMsg:
+		crashLog := system.GetCrashLogFile()
+		message := fmt.Sprintf("[internal error: see %s]", crashLog)
+		if err := writeCrashLog(crashLog, msg.Stack); err != nil {
+			message = fmt.Sprintf("[internal error: %s]", err)
+		}
+		return u, tea.Sequence(
+			tea.Println(u.components.renderer.RenderError(message)),
+			tea.Quit,
+		)
+	// Handle the failed requests of the REPL mode, printed above the prompt unless the setup is broken, the transient
+	// errors offering to send the request again
+	case requestFailedMsg:
+		if u.state.runMode != ReplMode || errors.Is(msg.err, ai.ErrInterrupted) || config.IsSetupError(msg.err) {
+			return u.update(msg.err)
+		}
+		u.state.querying = false
+		u.state.buffer = ""
+		u.components.stream.Reset()
+		u.components.conversation.SetPending("")
+		u.components.status.SetUsage(u.engine.GetUsage())
+		u.components.prompt.Focus()
+		u.state.failedRequest = nil
+		message := fmt.Sprintf("\n%s %s\n", errorLabel(msg.err), msg.err)
+		if ai.IsTransient(msg.err) && msg.request != nil {
+			u.state.failedRequest = msg.request
+			message = fmt.Sprintf("\n%s %s (%s to retry)\n", errorLabel(msg.err), msg.err, u.retryKey())
+		}
+		return u, tea.Sequence(
+			u.print(u.components.renderer.RenderError(message)),
+			textinput.Blink,
+		)
+	// Handle errors, the requests interrupted by the user restoring the prompt, and the other errors being rendered in
+	// a banner above it unless they end the session
 	case error:
+		if errors.Is(msg, ai.ErrInterrupted) {
+			u.state.querying = false
+			u.components.status.SetUsage(u.engine.GetUsage())
+			u.components.prompt.Focus()
+			return u, tea.Sequence(
+				u.print(u.components.renderer.RenderWarning("\n[interrupted]\n")),
+				textinput.Blink,
+			)
+		}
+		u.state.querying = false
+		if u.state.runMode != ReplMode || config.IsSetupError(msg) {
+			u.setFatalError(msg)
+			return u, tea.Quit
+		}
 		u.state.error = msg
-		return u, nil
+		u.components.prompt.Focus()
+		return u, textinput.Blink
 	}
 
 	return u, tea.Batch(cmds...)
 }
 
+// modelReadyMsg is a message telling the connection to the model was established, or the error preventing it.
+type modelReadyMsg struct {
+	err error
+}
+
+// failedRequest is a struct that represents a request to the AI that failed, sent again in its prompt mode.
+type failedRequest struct {
+	mode  PromptMode // The prompt mode the request was sent in.
+	input string     // The input of the request.
+}
+
+// requestFailedMsg is a message telling a request to the AI failed, with the request to send again if it can be.
+type requestFailedMsg struct {
+	request *failedRequest
+	err     error
+}
+
+// settingsMsg is a message telling the settings were edited, with the fields of the configuration that changed.
+type settingsMsg struct {
+	output  run.RunOutput
+	changes []config.ConfigChange
+}
+
+// resizeMsg is a message rendering the content at the new width, identifying the window size change it follows.
+type resizeMsg int
+
+// Resize is a method of the Ui struct that sets the dimensions of the terminal, like on a window size message,
+// so the user interface can be laid out without a terminal. The content is wrapped at the new width at once, the
+// current wrapping being kept if the renderer cannot be reconfigured, in which case an error is returned.
+func (u *Ui) Resize(width, height int) error {
+	u.layout(width, height)
+
+	return u.rerender()
+}
+
+// layout is a method of the Ui struct that sets the dimensions of the terminal and of the components.
+func (u *Ui) layout(width, height int) {
+	u.dimensions.width = width
+	u.dimensions.height = height
+	u.components.viewport.Resize(u.dimensions.width, u.dimensions.height)
+	u.components.live.Resize(u.dimensions.width, u.dimensions.height-1)
+	u.components.prompt.SetWidth(u.dimensions.width)
+	u.components.conversation.Resize(u.dimensions.width)
+	u.components.status.SetWidth(u.dimensions.width)
+}
+
+// rerender is a method of the Ui struct that renders the markdown content of the conversation and the answer being
+// streamed at the width of the terminal. Nothing is rendered if the width did not change.
+func (u *Ui) rerender() error {
+	width := wrapWidth(u.dimensions.width)
+	if width == u.components.renderer.GetWidth() {
+		return nil
+	}
+	if err := u.components.renderer.Resize(width); err != nil {
+		return err
+	}
+
+	u.components.conversation.Rerender(u.components.renderer)
+	if u.state.querying && u.state.promptMode == ChatPromptMode && u.state.buffer != "" {
+		// Render the raw answer being streamed at once at the new width, never the rendering at the previous one
+		rendered := u.components.stream.Render(u.state.buffer, u.components.renderer)
+		if u.state.runMode == ReplMode {
+			u.components.conversation.SetPending(u.answerMarker(ChatPromptMode) + rendered)
+		}
+	}
+
+	return nil
+}
+
 // View returns the string representation of the user interface.
 // It renders different views based on the state of the UI.
 func (u *Ui) View() string {
-	if u.state.error != nil {
-		// Render error message
-		return u.components.renderer.RenderError(fmt.Sprintf("[error] %s", u.state.error))
+	if u.state.error != nil && u.state.fatal {
+		// Render the error ending the session
+		return u.components.renderer.RenderError(fmt.Sprintf("%s %s", errorLabel(u.state.error), u.state.error))
+	}
+
+	if u.dimensions.width < min_wrap_width {
+		// Render a plain view, the content being unreadable at this width
+		return u.narrowView()
+	}
+
+	if u.components.viewport.IsVisible() {
+		// Render viewport view
+		return u.components.viewport.View(u.components.renderer)
 	}
 
 	if u.state.configuring {
@@ -412,92 +1054,357 @@ func (u *Ui) View() string {
 		)
 	}
 
-	if !u.state.querying && !u.state.confirming && !u.state.executing {
-		// Render prompt view
-		return u.components.prompt.View()
-	}
-
-	if u.state.promptMode == ChatPromptMode {
-		// Render chat mode view
-		return u.components.renderer.RenderContent(u.state.buffer)
-	} else {
-		if u.state.querying {
-			// Render spinner view
-			return u.components.spinner.View()
-		} else {
-			if !u.state.executing {
-				// Render content view
-				return u.components.renderer.RenderContent(u.state.buffer)
-			}
+	footer := u.footerView()
+	if u.state.runMode == ReplMode {
+		// Render the banner of the last error above the prompt, and the keys available in the current state and the
+		// status bar under it
+		if u.state.error != nil {
+			footer = strings.TrimSuffix(fmt.Sprintf("%s\n%s", u.errorBannerView(), footer), "\n")
+		}
+		if hints := u.hintsView(); hints != "" {
+			footer = strings.TrimPrefix(fmt.Sprintf("%s\n%s", footer, hints), "\n")
 		}
+		if u.isStatusBarVisible() {
+			footer = strings.TrimPrefix(fmt.Sprintf("%s\n%s", footer, u.components.status.View(u.components.renderer)), "\n")
+		}
+		// Render the conversation above the footer, which stays at the bottom of the terminal
+		if footer == "" {
+			return u.components.conversation.View(u.dimensions.height)
+		}
+		return fmt.Sprintf(
+			"%s\n%s",
+			u.components.conversation.View(u.dimensions.height-lipgloss.Height(footer)),
+			footer,
+		)
 	}
 
-	return ""
+	return footer
 }
 
-// startRepl is a method of the Ui struct that starts the REPL (Read-Eval-Print Loop) mode.
-func (u *Ui) startRepl(config *config.Config) tea.Cmd {
-	return tea.Sequence(
-		tea.ClearScreen,
-		tea.Println(u.components.renderer.RenderContent(u.components.renderer.RenderHelpMessage())),
-		textinput.Blink,
-		func() tea.Msg {
-			u.config = config
+// errorBannerView is a method of the Ui struct that returns the banner of the last error, rendered above the prompt
+// until a key is pressed or the next action succeeds.
+func (u *Ui) errorBannerView() string {
+	return u.components.renderer.RenderError(fmt.Sprintf("%s %s (press any key to dismiss)", errorLabel(u.state.error), u.state.error))
+}
 
-			// Set the prompt mode based on the default prompt mode in the configuration
-			if u.state.promptMode == DefaultPromptMode {
-				u.state.promptMode = GetPromptModeFromString(config.GetUserConfig().GetDefaultPromptMode())
-			}
+// errorLabel is a function that returns the label of an error, telling apart the answers of the model containing an
+// invalid JSON object.
+func errorLabel(err error) string {
+	if ai.IsInvalidJSON(err) {
+		return "[model returned invalid JSON]"
+	}
 
-			engineMode := ai.ExecEngineMode
-			if u.state.promptMode == ChatPromptMode {
-				engineMode = ai.ChatEngineMode
-			}
+	return "[error]"
+}
 
-			// Create a new engine with the specified engine mode and configuration
-			engine, err := ai.NewEngine(engineMode, config)
-			if err != nil {
-				return err
-			}
+// setFatalError is a method of the Ui struct that records the error ending the session, rendered instead of the user
+// interface once the program quits.
+func (u *Ui) setFatalError(err error) {
+	u.state.error = err
+	u.state.fatal = true
+}
 
-			if u.state.pipe != "" {
-				engine.SetPipe(u.state.pipe)
-			}
+// clearError is a method of the Ui struct that dismisses the banner of the last error, the error ending the session
+// being kept.
+func (u *Ui) clearError() {
+	if !u.state.fatal {
+		u.state.error = nil
+	}
+}
 
-			u.engine = engine
-			u.state.buffer = "Welcome \n\n"
-			u.state.command = ""
-			u.components.prompt = NewPrompt(u.state.promptMode)
+// narrowView is a method of the Ui struct that returns a plain view of the user interface for the terminals too
+// narrow to render the content: a notice followed by the prompt, the confirmation of the command or the raw answer.
+func (u *Ui) narrowView() string {
+	content := u.components.prompt.GetValue()
+	switch {
+	case u.state.confirming && !u.state.fixing:
+		level := run.EstimateExecutionRisk(u.resolveAliases(u.state.command))
+		content = run.StripAnsi(u.components.renderer.RenderConfirmationPrompt(u.state.command, u.state.lastAnswer, level, u.confirmationWord()))
+	case u.state.confirming:
+		content = u.state.command
+	case u.state.querying && u.state.promptMode == ChatPromptMode:
+		content = u.state.buffer
+	case u.state.querying || u.state.executing:
+		content = "..."
+	}
+
+	view := strings.TrimRight(fmt.Sprintf("[terminal too narrow]\n%s", content), "\n")
+	if u.dimensions.width <= 0 {
+		return view
+	}
+
+	return lipgloss.NewStyle().Width(u.dimensions.width).Render(view)
+}
+
+// wrapWidth is a function that returns the width the content is wrapped at for a terminal width,
+// at least min_wrap_width so the rendering never wraps at a zero or negative width.
+func wrapWidth(width int) int {
+	if width < min_wrap_width {
+		return min_wrap_width
+	}
+
+	return width
+}
+
+// footerView is a method of the Ui struct that returns the string representation of the bottom of the user interface,
+// like the prompt, the spinner or the live output of the command being executed.
+func (u *Ui) footerView() string {
+	if u.state.ingesting {
+		// Render the spinner with the bytes of the piped input read so far
+		return u.components.spinner.View()
+	}
+
+	if u.state.executing && u.components.live.HasLines() {
+		// Render the live output of the captured command being executed
+		return u.components.live.View(u.components.renderer)
+	}
+
+	if u.state.confirming && !u.state.fixing {
+		// Render the confirmation of the command
+		return u.confirmationView()
+	}
+
+	if u.state.pendingPaste != "" {
+		// Render the confirmation of a large paste under the prompt
+		return fmt.Sprintf("%s\n%s", u.components.prompt.View(), u.components.renderer.RenderHelp(
+			fmt.Sprintf("paste %s into prompt? [y/n]", formatPasteSize(len(u.state.pendingPaste))),
+		))
+	}
+
+	if u.components.search.IsActive() {
+		// Render the search in the conversation instead of the prompt
+		return u.components.search.View(
+			u.components.renderer,
+			u.components.conversation.GetCurrentMatch(),
+			u.components.conversation.GetMatchCount(),
+		)
+	}
+
+	if !u.state.querying && !u.state.confirming && !u.state.executing {
+		// Render prompt view, with a badge when the low risk commands are executed without confirmation
+		if u.state.autoConfirm {
+			return fmt.Sprintf("%s %s", u.components.renderer.RenderAutoBadge(), u.components.prompt.View())
+		}
+		return u.components.prompt.View()
+	}
+
+	if u.state.promptMode == ChatPromptMode {
+		if u.state.querying && u.state.buffer == "" {
+			// Render spinner view until the answer starts being streamed
+			return u.components.spinner.View()
+		}
+		if u.state.runMode == ReplMode && u.state.querying {
+			// The answer being streamed is rendered in the conversation, the spinner telling its progress
+			return u.components.spinner.View()
+		}
+		// Render chat mode view, the answer being streamed being rendered at most every stream_render_interval
+		if u.state.querying {
+			return u.components.stream.View()
+		}
+		return u.components.renderer.RenderContent(u.state.buffer)
+	} else {
+		if u.state.querying {
+			// Render spinner view
+			return u.components.spinner.View()
+		} else {
+			if !u.state.executing {
+				// Render content view
+				return u.components.renderer.RenderContent(u.state.buffer)
+			}
+		}
+	}
+
+	return ""
+}
+
+// startRepl is a method of the Ui struct that starts the REPL (Read-Eval-Print Loop) mode.
+func (u *Ui) startRepl(config *config.Config) tea.Cmd {
+	// Load the history of the previous sessions, the errors being displayed under the help
+	warnings := ""
+	if err := u.loadHistory(config); err != nil {
+		warnings += u.components.renderer.RenderWarning(fmt.Sprintf("[history error] %s\n", err))
+	}
+	if err := u.loadAliases(config); err != nil {
+		warnings += u.components.renderer.RenderWarning(fmt.Sprintf("[aliases error] %s\n", err))
+	}
+	for _, warning := range config.Validate() {
+		warnings += u.components.renderer.RenderWarning(fmt.Sprintf("[config warning] %s\n", warning))
+	}
+	// Scroll the conversation with the mouse wheel, or release the mouse if it was captured by the program options
+	mouseCmd := tea.DisableMouse
+	if u.components.viewport.IsMouseWheelEnabled() {
+		mouseCmd = tea.EnableMouseCellMotion
+	}
+
+	// Display the welcome message of the configuration above the help, if any
+	var welcomeCmd tea.Cmd
+	if welcome := config.GetUserConfig().GetWelcomeMessage(); welcome != "" {
+		welcomeCmd = u.printMarkdown(welcome, "")
+	}
+
+	return tea.Sequence(
+		tea.ClearScreen,
+		mouseCmd,
+		welcomeCmd,
+		u.printMarkdown(u.components.renderer.RenderHelpMessage(), warnings),
+		textinput.Blink,
+		safeCmd(func() tea.Msg {
+			u.config = config
+
+			// Set the prompt mode based on the default prompt mode in the configuration, the chat mode if exec is disabled
+			if mode, err := resolvePromptMode(u.state.promptMode, config); err == nil {
+				u.state.promptMode = mode
+			} else {
+				u.state.promptMode = ChatPromptMode
+			}
+
+			engineMode := ai.ExecEngineMode
+			if u.state.promptMode == ChatPromptMode {
+				engineMode = ai.ChatEngineMode
+			}
+
+			// Create a new engine with the specified engine mode and configuration, quitting if the pipe is too large
+			engine, pipeWarning, err := u.newPipedEngine(engineMode, config)
+			if err != nil {
+				u.setFatalError(err)
+				return tea.Quit()
+			}
+
+			u.engine = engine
+			u.filterHistory()
+			u.configureSpinner(config)
+			u.state.buffer = ""
+			u.state.command = ""
+			u.components.prompt = u.newPrompt(u.state.promptMode)
+
+			// Inject the default context files, reporting the missing ones, the truncation of the pipe and an unknown code style
+			warnings := injectContextFiles(engine, config)
+			if pipeWarning != "" {
+				warnings = append(warnings, pipeWarning)
+			}
+			if err := u.configureRenderer(config); err != nil {
+				warnings = append(warnings, fmt.Sprintf("[style error] %s", err))
+			}
+			if len(warnings) > 0 {
+				return u.print(u.components.renderer.RenderWarning(strings.Join(warnings, "\n")))()
+			}
 
 			return nil
-		},
+		}),
+		u.preloadModel(),
+	)
+}
+
+// preloadModel is a method of the Ui struct that establishes the connection to the model in the background,
+// while the welcome message is read, so the first query does not wait for it.
+func (u *Ui) preloadModel() tea.Cmd {
+	return safeCmd(func() tea.Msg {
+		if u.engine == nil {
+			return modelReadyMsg{}
+		}
+
+		ctx, cancel := context.WithTimeout(u.state.ctx, model_preload_timeout)
+		defer cancel()
+
+		return modelReadyMsg{err: u.engine.PreloadModel(ctx)}
+	})
+}
+
+// submitInput is a method of the Ui struct that sends an input of the prompt to the AI in the current prompt mode,
+// echoing it above the prompt and adding it to the history and to the turns of the session.
+func (u *Ui) submitInput(input string) tea.Cmd {
+	inputPrint := u.echoInput()
+	u.state.failedRequest = nil
+	u.history.AddWithMode(input, history.PromptMode(u.state.promptMode.String()))
+	u.addTurn(export.UserRole, input)
+	u.state.pendingImages = nil
+	u.components.prompt.SetValue("")
+	u.components.prompt.Blur()
+
+	if u.state.promptMode == ChatPromptMode {
+		return tea.Batch(
+			u.print(inputPrint),
+			u.startChatStream(input),
+			u.awaitChatStream(),
+		)
+	}
+
+	u.state.lastRequest = input
+	u.state.fixAttempts = 0
+
+	return tea.Batch(
+		u.print(inputPrint),
+		u.startExec(input),
+		u.components.spinner.Tick,
 	)
 }
 
+// errExecDisabled is the error of the exec prompt mode asked for while it is disabled.
+var errExecDisabled = errors.New("the exec mode is disabled by user_disabled_features")
+
+// resolvePromptMode is a function that returns the prompt mode to start in, the default prompt mode of the
+// configuration replacing DefaultPromptMode. The chat mode replaces the default exec mode when exec is disabled,
+// and an error is returned when the exec mode was asked for.
+func resolvePromptMode(mode PromptMode, cfg *config.Config) (PromptMode, error) {
+	explicit := mode != DefaultPromptMode
+	if !explicit {
+		mode = GetPromptModeFromString(cfg.GetUserConfig().GetDefaultPromptMode())
+	}
+	if mode != ChatPromptMode && !config.IsFeatureEnabled(config.ExecFeature, cfg) {
+		if explicit {
+			return mode, errExecDisabled
+		}
+		return ChatPromptMode, nil
+	}
+
+	return mode, nil
+}
+
+// isFeatureEnabled is a method of the Ui struct that checks if a feature is enabled by the configuration.
+func (u *Ui) isFeatureEnabled(name string) bool {
+	return config.IsFeatureEnabled(name, u.config)
+}
+
 // startCli is a method of the Ui struct that starts the CLI (Command Line Interface) mode.
 // It initializes the engine, sets the prompt mode, and handles different modes of execution.
 func (u *Ui) startCli(config *config.Config) tea.Cmd {
 	u.config = config
 
-	// Set the prompt mode based on the default prompt mode in the configuration
-	if u.state.promptMode == DefaultPromptMode {
-		u.state.promptMode = GetPromptModeFromString(config.GetUserConfig().GetDefaultPromptMode())
+	// Set the prompt mode based on the default prompt mode in the configuration, refusing the disabled exec mode
+	mode, err := resolvePromptMode(u.state.promptMode, config)
+	if err != nil {
+		u.setFatalError(err)
+		return tea.Quit
 	}
+	u.state.promptMode = mode
 
 	engineMode := ai.ExecEngineMode
 	if u.state.promptMode == ChatPromptMode {
 		engineMode = ai.ChatEngineMode
 	}
 
-	// Create a new engine with the specified engine mode and configuration
-	engine, err := ai.NewEngine(engineMode, config)
+	// Resolve the aliases on a best effort basis, the command being generated anyway
+	_ = u.loadAliases(config)
+
+	// Create a new engine with the specified engine mode and configuration, quitting if the pipe is too large
+	engine, pipeWarning, err := u.newPipedEngine(engineMode, config)
 	if err != nil {
-		u.state.error = err
-		return nil
+		u.setFatalError(err)
+		return tea.Quit
 	}
 
-	if u.state.pipe != "" {
-		engine.SetPipe(u.state.pipe)
+	// Inject the default context files, reporting the missing ones, the truncation of the pipe and an unknown style
+	// before the answer, the default rendering being kept not to fail the command
+	var notice tea.Cmd
+	warnings := injectContextFiles(engine, config)
+	if pipeWarning != "" {
+		warnings = append(warnings, pipeWarning)
+	}
+	if err := u.configureRenderer(config); err != nil {
+		warnings = append(warnings, fmt.Sprintf("[style error] %s", err))
+	}
+	if len(warnings) > 0 {
+		notice = u.print(u.components.renderer.RenderWarning(strings.Join(warnings, "\n")))
 	}
 
 	u.engine = engine
@@ -505,24 +1412,29 @@ func (u *Ui) startCli(config *config.Config) tea.Cmd {
 	u.state.confirming = false
 	u.state.buffer = ""
 	u.state.command = ""
+	u.configureSpinner(config)
+	u.components.spinner.Start()
 
 	if u.state.promptMode == ExecPromptMode {
 		// If the prompt mode is ExecPromptMode, execute the completion command
 		return tea.Batch(
+			notice,
 			u.components.spinner.Tick,
-			func() tea.Msg {
-				output, err := u.engine.ExecCompletion(u.state.args)
+			u.listenEngineStatus(),
+			safeCmd(func() tea.Msg {
+				output, err := u.engine.ExecCompletionWithRetry(u.state.args, exec_json_retries)
 				u.state.querying = false
 				if err != nil {
 					return err
 				}
 
 				return *output
-			},
+			}),
 		)
 	} else {
 		// If the prompt mode is ChatPromptMode, start the chat stream and await the response
 		return tea.Batch(
+			notice,
 			u.startChatStream(u.state.args),
 			u.awaitChatStream(),
 		)
@@ -531,7 +1443,7 @@ func (u *Ui) startCli(config *config.Config) tea.Cmd {
 
 // startConfig is a method of the Ui struct that starts the configuration mode.
 func (u *Ui) startConfig() tea.Cmd {
-	return func() tea.Msg {
+	return safeCmd(func() tea.Msg {
 		// Set the UI state
 		u.state.configuring = true
 		u.state.querying = false
@@ -543,10 +1455,10 @@ func (u *Ui) startConfig() tea.Cmd {
 		u.state.command = ""
 
 		// Initialize a new prompt with ConfigPromptMode
-		u.components.prompt = NewPrompt(ConfigPromptMode)
+		u.components.prompt = u.newPrompt(ConfigPromptMode)
 
 		return nil
-	}
+	})
 }
 
 // finishConfig is a method of the Ui struct that finishes the configuration process.
@@ -557,21 +1469,33 @@ func (u *Ui) finishConfig(key string) tea.Cmd {
 	// Write configuration to file
 	config, err := config.WriteConfig(key, true)
 	if err != nil {
-		u.state.error = err
-		return nil
+		u.setFatalError(err)
+		return tea.Quit
 	}
 
 	u.config = config
 
-	// Initialize AI engine
-	engine, err := ai.NewEngine(ai.ExecEngineMode, config)
-	if err != nil {
-		u.state.error = err
-		return nil
+	// Inform the user of the settings status, summarized, and of the backup of the previous configuration
+	settings := u.components.renderer.RenderSuccess("\n[settings ok]") + "\n" + u.components.renderer.RenderKeyValList([][2]string{
+		{"model", config.GetAiConfig().GetModel()},
+		{"config file", config.GetSystemConfig().GetConfigFile()},
+	})
+	if config.GetBackupFile() != "" {
+		settings += "\n" + u.components.renderer.RenderWarning(fmt.Sprintf("[config backed up to %s]", config.GetBackupFile()))
 	}
 
-	if u.state.pipe != "" {
-		engine.SetPipe(u.state.pipe)
+	// Load the history of the previous sessions
+	if u.state.runMode == ReplMode {
+		if err := u.loadHistory(config); err != nil {
+			settings += "\n" + u.components.renderer.RenderWarning(fmt.Sprintf("[history error] %s", err))
+		}
+	}
+
+	// Initialize AI engine
+	engine, _, err := u.newPipedEngine(ai.ExecEngineMode, config)
+	if err != nil {
+		u.setFatalError(err)
+		return tea.Quit
 	}
 
 	u.engine = engine
@@ -580,15 +1504,16 @@ func (u *Ui) finishConfig(key string) tea.Cmd {
 		// If in REPL mode, return a sequence of commands
 		return tea.Sequence(
 			tea.ClearScreen,
-			tea.Println(u.components.renderer.RenderSuccess("\n[settings ok]\n")),
+			u.print(settings+"\n"),
 			textinput.Blink,
-			func() tea.Msg {
+			safeCmd(func() tea.Msg {
 				u.state.buffer = ""
 				u.state.command = ""
-				u.components.prompt = NewPrompt(ExecPromptMode)
+				u.components.prompt = u.newPrompt(ExecPromptMode)
 
 				return nil
-			},
+			}),
+			u.preloadModel(),
 		)
 	} else {
 		if u.state.promptMode == ExecPromptMode {
@@ -596,18 +1521,22 @@ func (u *Ui) finishConfig(key string) tea.Cmd {
 			u.state.querying = true
 			u.state.configuring = false
 			u.state.buffer = ""
-			return tea.Sequence(
-				tea.Println(u.components.renderer.RenderSuccess("\n[settings ok]")),
-				u.components.spinner.Tick,
-				func() tea.Msg {
-					output, err := u.engine.ExecCompletion(u.state.args)
-					u.state.querying = false
-					if err != nil {
-						return err
-					}
-
-					return *output
-				},
+			u.components.spinner.Start()
+			return tea.Batch(
+				u.listenEngineStatus(),
+				tea.Sequence(
+					u.print(settings),
+					u.components.spinner.Tick,
+					safeCmd(func() tea.Msg {
+						output, err := u.engine.ExecCompletionWithRetry(u.state.args, exec_json_retries)
+						u.state.querying = false
+						if err != nil {
+							return err
+						}
+
+						return *output
+					}),
+				),
 			)
 		} else {
 			// If in CLI mode with ChatPromptMode, return a batch of commands
@@ -621,25 +1550,64 @@ func (u *Ui) finishConfig(key string) tea.Cmd {
 
 // startExec is a method of the Ui struct that starts the execution of a command.
 func (u *Ui) startExec(input string) tea.Cmd {
-	return func() tea.Msg {
+	u.components.spinner.Start()
+
+	return tea.Batch(u.listenEngineStatus(), safeCmd(func() tea.Msg {
 		u.state.querying = true
 		u.state.confirming = false
 		u.state.buffer = ""
 		u.state.command = ""
 
-		output, err := u.engine.ExecCompletion(input)
+		output, err := u.engine.ExecCompletionWithRetry(input, exec_json_retries)
 		u.state.querying = false
 		if err != nil {
-			return err
+			return requestFailedMsg{request: &failedRequest{mode: ExecPromptMode, input: input}, err: err}
 		}
 
 		return *output
-	}
+	}))
+}
+
+// canFix is a method of the Ui struct that checks if the user can be offered to ask the AI to fix a failed command.
+func (u *Ui) canFix(output run.RunOutput) bool {
+	return output.HasError() &&
+		u.state.runMode == ReplMode &&
+		u.state.promptMode == ExecPromptMode &&
+		u.state.lastRequest != "" &&
+		u.config.GetUserConfig().GetFixFailedCommands() &&
+		u.state.fixAttempts < u.config.GetUserConfig().GetFixMaxAttempts()
+}
+
+// startFix is a method of the Ui struct that asks the AI to fix the last failed command.
+func (u *Ui) startFix() tea.Cmd {
+	failure := u.state.lastFailure
+	command := u.state.lastExecutedCommand
+	u.components.spinner.Start()
+
+	return tea.Batch(u.listenEngineStatus(), safeCmd(func() tea.Msg {
+		u.state.querying = true
+		u.state.confirming = false
+		u.state.buffer = ""
+		u.state.command = ""
+
+		output, err := u.engine.FixCompletion(u.state.lastRequest, command, failure.GetError().Error(), failure.GetStderrTail())
+		u.state.querying = false
+		if err != nil {
+			// The fix is asked again by running the command again, not with the retry key
+			return requestFailedMsg{err: err}
+		}
+
+		return *output
+	}))
 }
 
 // startChatStream is a method of the Ui struct that starts the chat stream.
 func (u *Ui) startChatStream(input string) tea.Cmd {
-	return func() tea.Msg {
+	u.components.spinner.Start()
+	failed := make(chan struct{})
+	u.state.streamFailed = failed
+
+	return tea.Batch(u.components.spinner.Tick, u.listenEngineStatus(), safeCmd(func() tea.Msg {
 		u.state.querying = true
 		u.state.executing = false
 		u.state.confirming = false
@@ -648,22 +1616,61 @@ func (u *Ui) startChatStream(input string) tea.Cmd {
 
 		err := u.engine.ChatStreamCompletion(input)
 		if err != nil {
-			return err
+			close(failed)
+			return requestFailedMsg{request: &failedRequest{mode: ChatPromptMode, input: input}, err: err}
 		}
 
 		return nil
+	}))
+}
+
+// engineStatusMsg is a message telling what the engine is doing, received from the status channel it was sent to.
+type engineStatusMsg struct {
+	status  ai.EngineStatus
+	channel <-chan ai.EngineStatus
+}
+
+// listenEngineStatus is a method of the Ui struct that starts listening to the status channel of the engine, unless
+// it is already listened to. The channel is listened to until the user interface stops.
+func (u *Ui) listenEngineStatus() tea.Cmd {
+	if u.engine == nil || u.engine.GetStatusChannel() == nil || u.state.statusChannel == u.engine.GetStatusChannel() {
+		return nil
+	}
+	u.state.statusChannel = u.engine.GetStatusChannel()
+
+	return u.awaitEngineStatus(u.state.statusChannel)
+}
+
+// awaitEngineStatus is a method of the Ui struct that awaits the next status sent to a status channel of the engine.
+func (u *Ui) awaitEngineStatus(channel <-chan ai.EngineStatus) tea.Cmd {
+	ctx := u.state.ctx
+	if ctx == nil {
+		ctx = context.Background()
 	}
+
+	return safeCmd(func() tea.Msg {
+		select {
+		case status := <-channel:
+			return engineStatusMsg{status: status, channel: channel}
+		case <-ctx.Done():
+			return nil
+		}
+	})
 }
 
-// awaitChatStream is a method of the Ui struct that awaits the chat stream response.
+// awaitChatStream is a method of the Ui struct that awaits the chat stream response, until the stream fails.
 func (u *Ui) awaitChatStream() tea.Cmd {
-	return func() tea.Msg {
-		output := <-u.engine.GetChannel()
-		u.state.buffer += output.GetContent()
-		u.state.querying = !output.IsLast()
+	failed := u.state.streamFailed
 
+	return safeCmd(func() tea.Msg {
+		var output ai.EngineChatStreamOutput
+		select {
+		case output = <-u.engine.GetChannel():
+		case <-failed:
+			return nil
+		}
 		return output
-	}
+	})
 }
 
 // execCommand is a method of the Ui struct that executes a command.
@@ -671,61 +1678,1417 @@ func (u *Ui) execCommand(input string) tea.Cmd {
 	u.state.querying = false
 	u.state.confirming = false
 	u.state.executing = true
+	u.state.lastExecutedCommand = input
 
-	c := run.PrepareInteractiveCommand(input)
+	c := run.PrepareInteractiveCommand(input, u.commandPreamble())
+	start := time.Now()
 
 	return tea.ExecProcess(c, func(error error) tea.Msg {
 		u.state.executing = false
 		u.state.command = ""
 
-		return run.NewRunOutput(error, "[error]", "[ok]")
+		return run.NewRunOutput(error, "[error]", "[ok]").WithDuration(time.Since(start))
 	})
 }
 
-// editSettings is a method of the Ui struct that handles editing the settings.
-func (u *Ui) editSettings() tea.Cmd {
-	// Update UI state
-	u.state.querying = false
-	u.state.confirming = false
-	u.state.executing = true
+// policy is a method of the Ui struct that returns the policy restricting the programs that can be executed.
+func (u *Ui) policy() *run.Policy {
+	return run.NewPolicy(
+		u.config.GetUserConfig().GetExecAllowlist(),
+		u.config.GetUserConfig().GetExecBlocklist(),
+	)
+}
 
-	// Prepare and execute the edit settings command
-	c := run.PrepareEditSettingsCommand(fmt.Sprintf(
-		"%s %s",
-		u.config.GetSystemConfig().GetEditor(),
-		u.config.GetSystemConfig().GetConfigFile(),
-	))
+// openSearch is a method of the Ui struct that displays the search in the conversation instead of the prompt.
+func (u *Ui) openSearch() tea.Cmd {
+	u.components.prompt.Blur()
 
-	return tea.ExecProcess(c, func(error error) tea.Msg {
-		// Update UI state
-		u.state.executing = false
-		u.state.command = ""
+	return u.components.search.Open()
+}
 
-		if error != nil {
-			// Handle error output
-			return run.NewRunOutput(error, "[settings error]", "")
-		}
+// closeSearch is a method of the Ui struct that stops the search in the conversation, the focus returning to
+// the prompt.
+func (u *Ui) closeSearch() tea.Cmd {
+	u.components.search.Close()
+	u.components.conversation.ClearSearch()
+	u.components.prompt.Focus()
 
-		// Create a new config instance
-		config, error := config.NewConfig()
-		if error != nil {
-			// Handle error output
-			return run.NewRunOutput(error, "[settings error]", "")
+	return textinput.Blink
+}
+
+// updateSearch is a method of the Ui struct that handles the keys of the search in the conversation: the query is
+// typed until enter is pressed, then n and N jump to the next and previous matches, / types the query again, and esc
+// closes the search.
+func (u *Ui) updateSearch(msg tea.KeyMsg) tea.Cmd {
+	if msg.Type == tea.KeyEsc {
+		return u.closeSearch()
+	}
+
+	if u.components.search.IsEditing() {
+		if msg.Type == tea.KeyEnter {
+			u.components.search.Confirm()
+			return nil
 		}
+		var searchCmd tea.Cmd
+		u.components.search, searchCmd = u.components.search.Update(msg)
+		u.components.conversation.Search(u.components.search.GetQuery())
+		return searchCmd
+	}
 
-		// Update UI config and engine
-		u.config = config
-		engine, error := ai.NewEngine(ai.ExecEngineMode, config)
-		if u.state.pipe != "" {
-			engine.SetPipe(u.state.pipe)
+	switch msg.String() {
+	case "n", "enter":
+		u.components.conversation.NextMatch()
+	case "N":
+		u.components.conversation.PreviousMatch()
+	case "/", "ctrl+f":
+		return u.components.search.Edit()
+	case "up", "down", "pgup", "pgdown":
+		var conversationCmd tea.Cmd
+		u.components.conversation, conversationCmd = u.components.conversation.Update(msg)
+		return conversationCmd
+	}
+
+	return nil
+}
+
+// showViewport is a method of the Ui struct that displays a content in the viewport.
+// The mouse is only captured while the viewport is displayed, to keep the terminal scrollback usable.
+func (u *Ui) showViewport(content string) tea.Cmd {
+	u.components.prompt.Blur()
+	u.components.viewport.Show(content)
+
+	if u.components.viewport.IsMouseWheelEnabled() {
+		return tea.EnableMouseCellMotion
+	}
+
+	return nil
+}
+
+// hideViewport is a method of the Ui struct that stops displaying the viewport and releases the mouse.
+func (u *Ui) hideViewport() tea.Cmd {
+	u.components.viewport.Hide()
+	u.components.prompt.Focus()
+
+	// The mouse wheel keeps scrolling the conversation in the REPL mode, unless the mouse is released to select text
+	if u.components.viewport.IsMouseWheelEnabled() && (u.state.runMode != ReplMode || u.state.selecting) {
+		return tea.Batch(tea.DisableMouse, textinput.Blink)
+	}
+
+	return textinput.Blink
+}
+
+// toggleSelection is a method of the Ui struct that releases the mouse so the terminal selects text without holding
+// shift, or captures it again so the mouse wheel scrolls the conversation. The selection mode is shown in the status bar.
+func (u *Ui) toggleSelection() tea.Cmd {
+	u.state.selecting = !u.state.selecting
+	u.components.status.SetSelecting(u.state.selecting)
+
+	if u.state.selecting {
+		return tea.DisableMouse
+	}
+
+	return tea.EnableMouseCellMotion
+}
+
+// paste is a method of the Ui struct that inserts a text pasted with the bracketed paste mode of the terminal into the
+// prompt, its newlines being kept by the multi-line prompt, so it is only submitted once the user presses enter.
+// The texts larger than paste_confirm_size are confirmed first, and the pastes are ignored while the prompt is hidden.
+func (u *Ui) paste(content string) tea.Cmd {
+	if u.state.querying || u.state.confirming || u.state.executing {
+		return nil
+	}
+
+	content = sanitizePaste(content)
+	if u.state.configuring {
+		// The key is a single line, the newline copied along with it being dropped
+		content = strings.TrimSpace(strings.ReplaceAll(content, "\n", ""))
+	}
+	if content == "" {
+		return nil
+	}
+	if len(content) > paste_confirm_size {
+		u.state.pendingPaste = content
+		return nil
+	}
+
+	u.components.prompt.Focus()
+	u.components.prompt.Insert(content)
+
+	return textinput.Blink
+}
+
+// answerPaste is a method of the Ui struct that inserts the large pasted text waiting for a confirmation into
+// the prompt when y or enter is pressed, any other key discarding it.
+func (u *Ui) answerPaste(msg tea.KeyMsg) tea.Cmd {
+	content := u.state.pendingPaste
+	u.state.pendingPaste = ""
+
+	if msg.Type != tea.KeyEnter && strings.ToLower(msg.String()) != "y" {
+		return nil
+	}
+
+	u.components.prompt.Focus()
+	u.components.prompt.Insert(content)
+
+	return textinput.Blink
+}
+
+// addTurn is a method of the Ui struct that records a turn of the conversation of the session.
+func (u *Ui) addTurn(role string, content string) {
+	if strings.TrimSpace(content) == "" {
+		return
+	}
+
+	u.state.turns = append(u.state.turns, export.NewConversationTurn(role, content))
+}
+
+// addCommandTurn is a method of the Ui struct that adds the result of a command to the turns of the session, its
+// duration being written in the transcript.
+func (u *Ui) addCommandTurn(content string, duration time.Duration) {
+	count := len(u.state.turns)
+	u.addTurn(export.AssistantRole, content)
+	if len(u.state.turns) > count {
+		u.state.turns[count] = u.state.turns[count].WithDuration(duration)
+	}
+}
+
+// commandStatus is a function that returns the status message of a run, without its duration.
+func commandStatus(output run.RunOutput) string {
+	if output.HasError() {
+		return output.GetErrorMessage()
+	}
+
+	return output.GetSuccessMessage()
+}
+
+// exitStatus is a function that returns the exit code coloring the status of a failed run: the exit code of the
+// command, -1 if it was killed by a signal, interrupted or timed out, and 2 if it could not be run at all.
+func exitStatus(output run.RunOutput) int {
+	if code := output.GetExitCode(); code > 0 {
+		return code
+	}
+	if output.GetSignal() != nil || output.WasTimeout() || errors.Is(output.GetError(), run.ErrInterrupted) {
+		return -1
+	}
+
+	return 2
+}
+
+// withDuration is a function that adds a duration to the first bracketed tag of a status message.
+// Durations under 10 seconds have a sub-second precision.
+func withDuration(message string, duration time.Duration) string {
+	end := strings.Index(message, "]")
+	if duration <= 0 || end < 0 {
+		return message
+	}
+
+	formatted := fmt.Sprintf("%.1fs", duration.Seconds())
+	if duration >= 10*time.Second {
+		formatted = duration.Round(time.Second).String()
+	}
+
+	return fmt.Sprintf("%s · %s%s", message[:end], formatted, message[end:])
+}
+
+// canCapture is a method of the Ui struct that checks if a command can be executed with a captured output.
+// Commands requiring elevated privileges always run in the terminal so the password prompt works.
+func (u *Ui) canCapture(input string) bool {
+	return u.config.GetUserConfig().GetCaptureOutput() && !run.IsInteractive(u.resolveAliases(input)) && !run.RequiresElevation(u.resolveAliases(input))
+}
+
+// commandPreamble is a method of the Ui struct that returns the preamble prepended to the commands executed.
+func (u *Ui) commandPreamble() string {
+	preamble := ""
+	if u.config != nil {
+		preamble = u.config.GetUserConfig().GetCommandPreamble()
+	}
+
+	// Define the aliases of the user, so the shell expands them itself
+	return strings.TrimSpace(u.aliases.Definitions() + "\n" + preamble)
+}
+
+// resolveAliases is a method of the Ui struct that returns a command as the shell runs it, its aliases being
+// expanded, so the checks apply to the programs actually executed while the command itself is kept unchanged.
+func (u *Ui) resolveAliases(input string) string {
+	return u.aliases.Expand(input)
+}
+
+// captureCommand is a method of the Ui struct that executes a command without a TTY and captures its output.
+func (u *Ui) captureCommand(input string) tea.Cmd {
+	u.state.querying = false
+	u.state.confirming = false
+	u.state.executing = true
+	u.state.lastExecutedCommand = input
+
+	ctx, done := u.startInterruptible()
+	preamble := u.commandPreamble()
+	progress := make(chan run.RunProgressMsg)
+	u.state.progress = progress
+	u.components.live.Reset()
+
+	return tea.Batch(
+		safeCmd(func() tea.Msg {
+			start := time.Now()
+			stdout, stderr, _, err := run.RunStreamed(ctx, run.WithPreamble(preamble, input), progress)
+			close(done)
+
+			return run.NewCapturedRunOutput(err, interruptibleErrorMessage(err, "[error]"), "[ok]", stdout, stderr).WithDuration(time.Since(start))
+		}),
+		u.awaitProgress(),
+	)
+}
+
+// awaitProgress is a method of the Ui struct that waits for the next output lines of the captured command being
+// executed. The lines are read until the channel is closed, so the command never blocks on a full channel.
+func (u *Ui) awaitProgress() tea.Cmd {
+	progress := u.state.progress
+	if progress == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		msg, ok := <-progress
+		if !ok {
+			return nil
+		}
+
+		return msg
+	}
+}
+
+// sandboxCommand is a method of the Ui struct that executes a command in a throwaway container.
+// Interactive commands run in the terminal, the other commands have their output captured.
+func (u *Ui) sandboxCommand(input string) tea.Cmd {
+	u.state.querying = false
+	u.state.confirming = false
+	u.state.executing = true
+	u.state.lastExecutedCommand = input
+
+	workdir, err := os.Getwd()
+	if err != nil {
+		u.state.executing = false
+		u.state.command = ""
+		return func() tea.Msg {
+			return run.NewRunOutput(err, "[sandbox error]", "")
+		}
+	}
+
+	sandbox := run.NewSandbox(
+		u.config.GetUserConfig().GetSandbox(),
+		u.config.GetUserConfig().GetSandboxImage(),
+		workdir,
+	)
+
+	if run.IsInteractive(u.resolveAliases(input)) {
+		if err := sandbox.Check(); err != nil {
+			u.state.executing = false
+			u.state.command = ""
+			return func() tea.Msg {
+				return run.NewRunOutput(err, "[sandbox error]", "")
+			}
+		}
+
+		c := sandbox.PrepareCommand(context.Background(), input, true)
+		start := time.Now()
+
+		return tea.ExecProcess(c, func(error error) tea.Msg {
+			u.state.executing = false
+			u.state.command = ""
+
+			return run.NewRunOutput(error, "[sandbox error]", "[sandbox ok]").WithDuration(time.Since(start))
+		})
+	}
+
+	ctx, done := u.startInterruptible()
+
+	return safeCmd(func() tea.Msg {
+		start := time.Now()
+		stdout, stderr, _, err := sandbox.RunCaptured(ctx, input)
+		close(done)
+
+		return run.NewCapturedRunOutput(err, interruptibleErrorMessage(err, "[sandbox error]"), "[sandbox ok]", stdout, stderr).WithDuration(time.Since(start))
+	})
+}
+
+// startInterruptible is a method of the Ui struct that returns the context of a captured command, cancelled when the
+// user interrupts the command with ctrl+c, and the channel the command closes once finished. The state is only
+// changed by Update, the command returning its output when it is finished.
+func (u *Ui) startInterruptible() (context.Context, chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	u.state.cancel = cancel
+	u.state.captureDone = make(chan struct{})
+
+	return ctx, u.state.captureDone
+}
+
+// finishInterruptible is a method of the Ui struct that releases the context of the finished captured command, once
+// its output is received.
+func (u *Ui) finishInterruptible() {
+	if u.state.cancel != nil {
+		u.state.cancel()
+		u.state.cancel = nil
+		u.state.captureDone = nil
+	}
+}
+
+// stopInterruptible is a method of the Ui struct that interrupts the captured command being executed, if any,
+// and waits for it to stop.
+func (u *Ui) stopInterruptible() {
+	cancel, done := u.state.cancel, u.state.captureDone
+	if cancel == nil {
+		return
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(capture_termination_grace):
+	}
+}
+
+// interruptibleErrorMessage is a function that returns the error message of a captured command,
+// reporting its interruption by the user.
+func interruptibleErrorMessage(err error, message string) string {
+	if errors.Is(err, run.ErrInterrupted) {
+		return "[interrupted]"
+	}
+
+	return message
+}
+
+// answerConfirmation is a method of the Ui struct that handles the answer to the confirmation of the execution of a command:
+// "y" executes it, "!" in the terminal, "a" for the rest of the session, "p" to ask about its output,
+// "s" in a sandbox and "b" in the background, any other answer cancelling it.
+func (u *Ui) answerConfirmation(confirmation string, msg tea.KeyMsg) tea.Cmd {
+	var promptCmd tea.Cmd
+	if confirmation == "a" && u.state.runMode == ReplMode && run.EstimateExecutionRisk(u.resolveAliases(u.state.command)) <= run.LowRisk {
+		// Execute the command and stop asking for the confirmation of the low risk commands
+		u.state.autoConfirm = true
+		confirmation = "y"
+	}
+	if confirmation == "y" || confirmation == "!" {
+		u.state.confirming = false
+		u.state.executing = true
+		u.state.buffer = ""
+		u.components.prompt.SetValue("")
+		// The "!" confirmation forces the interactive execution
+		if confirmation == "y" && u.canCapture(u.state.command) {
+			return tea.Sequence(
+				promptCmd,
+				u.captureCommand(u.state.command),
+			)
+		}
+		return tea.Sequence(
+			promptCmd,
+			u.execCommand(u.state.command),
+		)
+	} else if confirmation == "p" && u.canPipe(u.state.command) {
+		// Execute the command and ask the AI about its output
+		u.state.confirming = false
+		u.state.executing = true
+		u.state.piping = true
+		u.state.buffer = ""
+		u.components.prompt.SetValue("")
+		return tea.Sequence(
+			promptCmd,
+			u.captureCommand(u.state.command),
+		)
+	} else if confirmation == "s" && u.config.GetUserConfig().GetSandbox() != "" {
+		u.state.confirming = false
+		u.state.executing = true
+		u.state.buffer = ""
+		u.components.prompt.SetValue("")
+		return tea.Sequence(
+			promptCmd,
+			u.sandboxCommand(u.state.command),
+		)
+	} else if confirmation == "b" && u.state.runMode == ReplMode {
+		u.state.confirming = false
+		u.state.executing = false
+		u.state.buffer = ""
+		u.components.prompt, promptCmd = u.components.prompt.Update(msg)
+		u.components.prompt.SetValue("")
+		u.components.prompt.Focus()
+		output := u.startJob(u.state.command)
+		u.state.command = ""
+		return tea.Sequence(
+			promptCmd,
+			u.print(output),
+			textinput.Blink,
+		)
+	} else {
+		u.state.confirming = false
+		u.state.executing = false
+		u.state.buffer = ""
+		u.components.prompt, promptCmd = u.components.prompt.Update(msg)
+		u.components.prompt.SetValue("")
+		u.components.prompt.Focus()
+		if u.state.runMode == ReplMode {
+			u.state.command = ""
+			return tea.Batch(
+				promptCmd,
+				u.print(fmt.Sprintf("\n%s\n", u.components.renderer.RenderWarning("[cancel]"))),
+				textinput.Blink,
+			)
+		} else {
+			return tea.Sequence(
+				promptCmd,
+				u.print(fmt.Sprintf("\n%s\n", u.components.renderer.RenderWarning("[cancel]"))),
+				tea.Quit,
+			)
+		}
+	}
+}
+
+// confirmationView is a method of the Ui struct that returns the confirmation of the execution of the command,
+// in a border colored according to its risk level, with the Yes and No choices.
+func (u *Ui) confirmationView() string {
+	level := run.EstimateExecutionRisk(u.resolveAliases(u.state.command))
+
+	if u.requiresConfirmationWord() {
+		// Render the input of the confirmation word instead of the choices
+		return u.components.renderer.RenderConfirmation(
+			fmt.Sprintf(
+				"%s\n> %s\n%s",
+				u.components.renderer.RenderConfirmationQuestion(
+					level,
+					fmt.Sprintf("type %s and press enter to run this command", u.confirmationWord()),
+				),
+				u.state.confirmInput,
+				u.components.renderer.RenderHelp("(enter to answer, esc to decline)"),
+			),
+			level,
+		)
+	}
+
+	return u.components.renderer.RenderConfirmation(
+		fmt.Sprintf(
+			"%s\n%s",
+			u.components.renderer.RenderConfirmationQuestion(
+				level,
+				fmt.Sprintf("run this command?  %s", u.components.renderer.RenderConfirmationChoices(u.state.confirmYes, level)),
+			),
+			u.components.renderer.RenderHelp(u.confirmationHelp()),
+		),
+		level,
+	)
+}
+
+// confirmationHelp is a method of the Ui struct that returns the help of the additional confirmation choices.
+func (u *Ui) confirmationHelp() string {
+	choices := []string{"←/→ and enter or y/n to answer", "! to run in the terminal"}
+	if u.state.runMode == ReplMode {
+		choices = append(choices, "b to run in the background")
+		if run.EstimateExecutionRisk(u.resolveAliases(u.state.command)) <= run.LowRisk {
+			choices = append(choices, "a to always allow for this session")
+		}
+	}
+	if u.canPipe(u.state.command) {
+		choices = append(choices, "p to ask about the output")
+	}
+	if u.config.GetUserConfig().GetSandbox() != "" {
+		choices = append(choices, "s to run in a sandbox")
+	}
+
+	return fmt.Sprintf("(%s)", strings.Join(choices, ", "))
+}
+
+// confirmationWord is a method of the Ui struct that returns the word typed to confirm the execution of a command,
+// "y" unless configured.
+func (u *Ui) confirmationWord() string {
+	if u.config == nil {
+		return "y"
+	}
+	if word := strings.TrimSpace(u.config.GetUserConfig().GetConfirmationWord()); word != "" {
+		return word
+	}
+
+	return "y"
+}
+
+// requiresConfirmationWord is a method of the Ui struct that checks if a word other than "y" is configured to confirm
+// the execution of a command, in which case it must be typed and the shortcuts of the choices are disabled.
+func (u *Ui) requiresConfirmationWord() bool {
+	return strings.ToLower(u.confirmationWord()) != "y"
+}
+
+// matchesConfirmationWord is a method of the Ui struct that checks if an input is the confirmation word, its case
+// mattering only when configured.
+func (u *Ui) matchesConfirmationWord(input string) bool {
+	if u.config != nil && u.config.GetUserConfig().GetConfirmationCaseSensitive() {
+		return input == u.confirmationWord()
+	}
+
+	return strings.EqualFold(input, u.confirmationWord())
+}
+
+// typeConfirmation is a method of the Ui struct that handles a key typed while the confirmation word is required:
+// the characters are added to the input displayed in the confirmation and backspace removes the last one, esc
+// declining the command. The input is only answered with enter.
+func (u *Ui) typeConfirmation(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyRunes:
+		u.state.confirmInput += string(msg.Runes)
+	case tea.KeySpace:
+		u.state.confirmInput += " "
+	case tea.KeyBackspace:
+		if input := []rune(u.state.confirmInput); len(input) > 0 {
+			u.state.confirmInput = string(input[:len(input)-1])
+		}
+	case tea.KeyEsc:
+		u.state.confirmInput = ""
+		return u.answerConfirmation("n", msg)
+	}
+
+	return nil
+}
+
+// autoExecReason is a method of the Ui struct that checks if a command can be executed without confirmation,
+// and returns the reason shown to the user.
+func (u *Ui) autoExecReason(input string) (string, bool) {
+	if u.config.GetUserConfig().GetAutoExecSafeCommands() && run.IsReadOnly(u.resolveAliases(input)) {
+		return "read-only", true
+	}
+	if u.state.autoConfirm && run.EstimateExecutionRisk(u.resolveAliases(input)) <= run.LowRisk {
+		return "allowed for this session", true
+	}
+
+	return "", false
+}
+
+// injectContextFiles is a function that injects the default context files of the configuration into
+// the context of an engine, "~" and glob patterns being expanded. The files that cannot be injected are not an error,
+// since they may not exist in every directory, and warnings are returned instead.
+func injectContextFiles(engine *ai.Engine, config *config.Config) []string {
+	var warnings []string
+	for _, pattern := range config.GetUserConfig().GetDefaultContextFiles() {
+		expanded, err := homedir.Expand(pattern)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("[context file error] %s: %s", pattern, err))
+			continue
+		}
+
+		paths, err := filepath.Glob(expanded)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("[context file error] %s: %s", pattern, err))
+			continue
+		}
+		if len(paths) == 0 {
+			warnings = append(warnings, fmt.Sprintf("[context file not found] %s", pattern))
+			continue
+		}
+
+		for _, path := range paths {
+			if err := engine.InjectFileContext(path); err != nil {
+				warnings = append(warnings, fmt.Sprintf("[context file error] %s: %s", path, err))
+			}
+		}
+	}
+
+	return warnings
+}
+
+// print is a method of the Ui struct that displays a content above the prompt: it is appended to the conversation
+// in the REPL mode, and printed in the terminal in the CLI mode.
+func (u *Ui) print(content string) tea.Cmd {
+	if u.state.runMode != ReplMode {
+		return tea.Println(content)
+	}
+
+	return func() tea.Msg {
+		return printMsg(content)
+	}
+}
+
+// printAnswer is a method of the Ui struct that displays the markdown content of an answer above the prompt like
+// printMarkdown, preceded by the marker of the prompt mode it answers.
+func (u *Ui) printAnswer(mode PromptMode, markdown string, suffix string) tea.Cmd {
+	marker := u.answerMarker(mode)
+	if u.state.runMode != ReplMode {
+		return tea.Println(marker + u.components.renderer.RenderContent(markdown) + suffix)
+	}
+
+	return func() tea.Msg {
+		return printMarkdownMsg{prefix: marker, markdown: markdown, suffix: suffix, answer: true}
+	}
+}
+
+// echoInput is a method of the Ui struct that returns the input of the prompt echoed in the conversation, with the
+// indicator, the color and the label of its prompt mode.
+func (u *Ui) echoInput() string {
+	mode := u.components.prompt.GetMode()
+
+	return u.components.renderer.RenderEcho(u.components.prompt.AsString(), mode.String(), u.components.prompt.GetModeStyle(mode))
+}
+
+// answerMarker is a method of the Ui struct that returns the rendered line marking an answer of a prompt mode,
+// distinguishing the chat answers from the exec explanations, empty with the minimal theme.
+func (u *Ui) answerMarker(mode PromptMode) string {
+	label := "chat answer"
+	if mode == ExecPromptMode {
+		label = "exec explanation"
+	}
+
+	return u.components.renderer.RenderAnswerMarker(label, u.components.prompt.GetModeStyle(mode))
+}
+
+// printMarkdown is a method of the Ui struct that displays a markdown content above the prompt, followed by a rendered
+// suffix. In the REPL mode, the content is rendered again at the new width when the terminal is resized.
+func (u *Ui) printMarkdown(markdown string, suffix string) tea.Cmd {
+	if u.state.runMode != ReplMode {
+		return tea.Println(u.components.renderer.RenderContent(markdown) + suffix)
+	}
+
+	return func() tea.Msg {
+		return printMarkdownMsg{markdown: markdown, suffix: suffix}
+	}
+}
+
+// newPrompt is a method of the Ui struct that creates a prompt fitting the terminal, its height growing with its
+// content up to the configured maximum. The indicators are read from the settings loaded so far when the
+// configuration is not created yet, like for the prompt asking for the key.
+func (u *Ui) newPrompt(mode PromptMode) *Prompt {
+	prompt := NewPrompt(mode).SetWidth(u.dimensions.width)
+	indicators := config.ReadPromptIndicators()
+	if u.config != nil {
+		prompt.SetMaxHeight(u.config.GetUserConfig().GetPromptMaxHeight())
+		prompt.SetEditingMode(u.config.GetUserConfig().GetEditingMode())
+		indicators = u.config.GetUserConfig().GetPromptIndicators()
+	}
+	for _, mode := range []PromptMode{ExecPromptMode, ChatPromptMode, ConfigPromptMode} {
+		prompt.SetIndicator(mode, indicators[mode.String()])
+	}
+
+	return prompt
+}
+
+// newEngine is a method of the Ui struct that creates an engine from the context of the session, telling it the
+// pipe and the aliases of the user and attaching the pending images.
+func (u *Ui) newEngine(mode ai.EngineMode, config *config.Config) (*ai.Engine, error) {
+	ctx := u.state.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	opts := []ai.EngineOption{ai.WithAliases(u.aliases)}
+	if u.engine != nil {
+		// Keep counting the tokens used in the session
+		opts = append(opts, ai.WithUsage(u.engine.GetUsage()))
+	}
+	if u.state.pipe != "" {
+		opts = append(opts, ai.WithPipe(u.state.pipe))
+	}
+
+	engine, err := ai.NewEngine(ctx, mode, config, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, image := range u.state.pendingImages {
+		if err := engine.AttachImage(image); err != nil {
+			return nil, fmt.Errorf("cannot attach %s: %w", image, err)
+		}
+	}
+
+	return engine, nil
+}
+
+// newPipedEngine is a method of the Ui struct that creates an engine like newEngine, truncating the pipe to the
+// maximum size when it is too large and the truncation is enabled, in which case a warning is returned.
+func (u *Ui) newPipedEngine(mode ai.EngineMode, config *config.Config) (*ai.Engine, string, error) {
+	// Report once the truncation of the pipe while it was read
+	warning := u.state.pipeWarning
+	u.state.pipeWarning = ""
+
+	engine, err := u.newEngine(mode, config)
+	var sizeErr *ai.PipeSizeError
+	if !errors.As(err, &sizeErr) || !config.GetUserConfig().GetTruncatePipe() {
+		return engine, warning, err
+	}
+
+	max := sizeErr.GetMax()
+	u.state.pipe = truncatePipe(u.state.pipe, max)
+	engine, err = u.newEngine(mode, config)
+
+	return engine, fmt.Sprintf("[pipe truncated to %d bytes]", max), err
+}
+
+// newStatusBar is a function that creates a status bar showing the current directory.
+func newStatusBar() *StatusBar {
+	status := NewStatusBar(150)
+	if dir, err := os.Getwd(); err == nil {
+		status.SetDir(dir)
+	}
+
+	return status
+}
+
+// syncStatusBar is a method of the Ui struct that updates the status bar with the prompt mode and the model.
+// The tokens used are updated when the answers are received, since the engine counts them while it runs.
+func (u *Ui) syncStatusBar() {
+	u.components.status.SetMode(u.state.promptMode)
+	if u.config != nil {
+		u.components.status.SetModel(u.config.GetAiConfig().GetModel())
+	}
+}
+
+// isStatusBarVisible is a method of the Ui struct that checks if the status bar is displayed: it is only displayed
+// in the REPL mode when enabled, and hidden when the terminal is too small.
+func (u *Ui) isStatusBarVisible() bool {
+	return u.state.runMode == ReplMode &&
+		u.config != nil &&
+		u.config.GetUserConfig().GetStatusBar() &&
+		u.dimensions.height >= status_bar_min_height
+}
+
+// keyHints is a method of the Ui struct that returns the keys available in the current state of the REPL mode,
+// the rebound keys being the configured ones.
+func (u *Ui) keyHints() []keyHint {
+	switch {
+	case u.state.configuring:
+		return nil
+	case u.state.confirming && u.state.fixing:
+		return []keyHint{{"y", "fix"}, {"n", "skip"}}
+	case u.state.confirming && u.requiresConfirmationWord():
+		return []keyHint{{"enter", "answer"}}
+	case u.state.confirming:
+		return []keyHint{{"y", "run"}, {"n", "cancel"}, {"!", "terminal"}}
+	case u.state.pendingPaste != "":
+		return []keyHint{{"y", "paste"}, {"n", "discard"}}
+	case u.components.search.IsEditing():
+		return []keyHint{{"enter", "confirm"}, {"esc", "close"}}
+	case u.components.search.IsActive():
+		return []keyHint{{"n/N", "next/previous"}, {"/", "edit"}, {"esc", "close"}}
+	case u.state.executing:
+		return []keyHint{{"pgup/pgdn", "scroll"}, {"ctrl+c", "interrupt"}}
+	case u.state.querying:
+		return []keyHint{{"ctrl+c", "cancel"}}
+	case u.state.ingesting:
+		return []keyHint{{"ctrl+c", "quit"}}
+	}
+
+	hints := []keyHint{{"tab", "mode"}, {"ctrl+h", "help"}}
+	if u.components.prompt.HasSuggestions() {
+		hints[0] = keyHint{"tab", "next"}
+	}
+	if u.components.prompt.GetViMode() == vi_normal_mode {
+		hints = append([]keyHint{{"i", "insert"}}, hints...)
+	}
+	if u.components.prompt.GetSuggestion() != "" {
+		hints = append([]keyHint{{"→", "accept"}}, hints...)
+	}
+	if u.state.lastAnswer != "" {
+		hints = append(hints, keyHint{u.copyKey(), "copy"}, keyHint{u.rawKey(), "raw"})
+	}
+	if u.state.failedRequest != nil {
+		hints = append(hints, keyHint{u.retryKey(), "retry"})
+	}
+
+	return append(hints, keyHint{"ctrl+c", "quit"})
+}
+
+// hintsView is a method of the Ui struct that returns the line of the keys available in the current state,
+// empty if the hints are disabled, the terminal is too small or no key is available.
+func (u *Ui) hintsView() string {
+	if u.state.runMode != ReplMode ||
+		u.config == nil ||
+		!u.config.GetUserConfig().GetFooterHints() ||
+		u.dimensions.height < status_bar_min_height {
+		return ""
+	}
+
+	hints := formatKeyHints(u.keyHints())
+	if hints == "" || lipgloss.Width(hints) > u.dimensions.width {
+		return ""
+	}
+
+	return u.components.renderer.RenderHint(hints)
+}
+
+// copyKey is a method of the Ui struct that returns the key copying the last answer to the clipboard.
+func (u *Ui) copyKey() string {
+	if u.config == nil || u.config.GetUserConfig().GetCopyKey() == "" {
+		return default_copy_key
+	}
+
+	return u.config.GetUserConfig().GetCopyKey()
+}
+
+// notifyLongQuery is a method of the Ui struct that returns a command ringing the bell and sending a desktop
+// notification when a message completes a query that took longer than the configured duration, nil otherwise.
+// The duration is the one displayed by the spinner since the query started.
+func (u *Ui) notifyLongQuery(msg tea.Msg) tea.Cmd {
+	if u.config == nil || u.config.GetUserConfig().GetNotifyAfterSeconds() <= 0 {
+		return nil
+	}
+	if u.components.spinner.GetElapsed() < time.Duration(u.config.GetUserConfig().GetNotifyAfterSeconds())*time.Second {
+		return nil
+	}
+
+	var summary string
+	switch msg := msg.(type) {
+	case ai.EngineExecOutput:
+		summary = msg.GetExplanation()
+		if msg.IsExecutable() {
+			summary = msg.GetCommand()
+		}
+	case ai.EngineChatStreamOutput:
+		if !msg.IsLast() || msg.IsInterrupt() {
+			return nil
+		}
+		summary = u.state.lastAnswer
+	default:
+		return nil
+	}
+
+	return func() tea.Msg {
+		// The notification is best effort, the answer being displayed anyway
+		_ = notifyAnswer(notificationSummary(summary))
+		return nil
+	}
+}
+
+// retryKey is a method of the Ui struct that returns the key sending again the last request that failed.
+func (u *Ui) retryKey() string {
+	if u.config == nil || u.config.GetUserConfig().GetRetryKey() == "" {
+		return default_retry_key
+	}
+
+	return u.config.GetUserConfig().GetRetryKey()
+}
+
+// retryRequest is a method of the Ui struct that sends again the last request that failed with a transient error,
+// in the prompt mode it was sent in.
+func (u *Ui) retryRequest() tea.Cmd {
+	request := *u.state.failedRequest
+	u.state.failedRequest = nil
+	if request.mode != u.state.promptMode {
+		u.switchPromptMode(request.mode)
+	}
+	u.components.prompt.Blur()
+
+	notice := u.print(u.components.renderer.RenderHelp("[retrying]\n"))
+	if request.mode == ChatPromptMode {
+		return tea.Batch(notice, u.startChatStream(request.input), u.awaitChatStream())
+	}
+	u.state.lastRequest = request.input
+	u.state.fixAttempts = 0
+
+	return tea.Batch(notice, u.startExec(request.input), u.components.spinner.Tick)
+}
+
+// switchPromptMode is a method of the Ui struct that switches the prompt and the engine to a prompt mode, the
+// conversation of the engine being reset.
+func (u *Ui) switchPromptMode(mode PromptMode) {
+	u.state.promptMode = mode
+	u.components.prompt.SetMode(mode)
+	if mode == ChatPromptMode {
+		u.engine.SetMode(ai.ChatEngineMode)
+	} else {
+		u.engine.SetMode(ai.ExecEngineMode)
+	}
+	u.filterHistory()
+	u.engine.Reset()
+}
+
+// copyLastAnswer is a method of the Ui struct that copies the raw text of the last answer to the clipboard.
+// When the answer has exactly one code block, only its content is copied, unless disabled.
+func (u *Ui) copyLastAnswer() tea.Cmd {
+	text := u.state.lastAnswer
+	if strings.TrimSpace(text) == "" {
+		return u.print(u.components.renderer.RenderWarning("[nothing to copy]\n"))
+	}
+
+	if u.config == nil || u.config.GetUserConfig().GetCopyCodeBlock() {
+		if blocks := extractCodeBlocks(text); len(blocks) == 1 {
+			text = blocks[0]
+		}
+	}
+
+	if err := copyToClipboard(text); err != nil {
+		return u.print(u.components.renderer.RenderError(fmt.Sprintf("[copy error] %s\n", err)))
+	}
+
+	return u.print(u.components.renderer.RenderHelp(fmt.Sprintf("[copied %d chars]\n", utf8.RuneCountInString(text))))
+}
+
+// emptyInputHint is a method of the Ui struct that returns a command printing a tip when enter is pressed on an empty
+// input, the tips being cycled and shown at most every empty_hint_interval, nil otherwise.
+func (u *Ui) emptyInputHint() tea.Cmd {
+	if time.Since(u.state.lastEmptyHint) < empty_hint_interval {
+		return nil
+	}
+
+	request, other := "type what you want to do, like \"list the big files\"", "chat"
+	if u.state.promptMode == ChatPromptMode {
+		request, other = "type a question", "exec"
+	}
+	tips := []string{
+		request,
+		fmt.Sprintf("tab switches to the %s mode", other),
+		"ctrl+h shows the help",
+	}
+	tip := tips[u.state.emptyHints%len(tips)]
+	u.state.emptyHints++
+	u.state.lastEmptyHint = time.Now()
+
+	return u.print(u.components.renderer.RenderHint(fmt.Sprintf("[tip] %s\n", tip)))
+}
+
+// rawKey is a method of the Ui struct that returns the key toggling the last answer between its rendered and raw
+// markdown.
+func (u *Ui) rawKey() string {
+	if u.config == nil || u.config.GetUserConfig().GetRawKey() == "" {
+		return default_raw_key
+	}
+
+	return u.config.GetUserConfig().GetRawKey()
+}
+
+// toggleRawAnswer is a method of the Ui struct that displays the last answer of the conversation as raw markdown, or
+// renders it again, nothing being changed while a query is running.
+func (u *Ui) toggleRawAnswer() tea.Cmd {
+	if u.state.querying {
+		return u.print(u.components.renderer.RenderHint("[the answer can be displayed as raw markdown once received]\n"))
+	}
+	if !u.components.conversation.ToggleRaw(u.components.renderer) {
+		return u.print(u.components.renderer.RenderHint("[no answer to display as raw markdown]\n"))
+	}
+
+	return nil
+}
+
+// transcriptKey is a method of the Ui struct that returns the key saving the transcript of the session.
+func (u *Ui) transcriptKey() string {
+	if u.config == nil || u.config.GetUserConfig().GetTranscriptKey() == "" {
+		return default_transcript_key
+	}
+
+	return u.config.GetUserConfig().GetTranscriptKey()
+}
+
+// saveTranscript is a method of the Ui struct that saves the turns of the session to a markdown file named after
+// the time it is first saved, in the configured directory. Once saved, only the new turns are appended to the file,
+// so it can be saved again like a checkpoint, the whole session being saved to a new file if it was removed.
+func (u *Ui) saveTranscript() tea.Cmd {
+	if u.state.querying || u.state.confirming || u.state.executing {
+		return u.print(u.components.renderer.RenderWarning(fmt.Sprintf("[wait for the answer to press %s and save the transcript]\n", u.transcriptKey())))
+	}
+	if len(u.state.turns) == 0 {
+		return u.print(u.components.renderer.RenderWarning("[nothing to save]\n"))
+	}
+
+	if u.state.transcriptPath != "" {
+		if _, err := os.Stat(u.state.transcriptPath); err != nil {
+			u.state.transcriptPath = ""
+			u.state.transcriptTurns = 0
+		}
+	}
+
+	var err error
+	switch {
+	case u.state.transcriptPath == "":
+		var path string
+		path, err = newTranscriptPath(u.transcriptDir(), time.Now())
+		if err == nil {
+			err = export.SaveMarkdown(u.state.turns, path)
+		}
+		if err == nil {
+			u.state.transcriptPath = path
+		}
+	case u.state.transcriptTurns == len(u.state.turns):
+		return u.print(u.components.renderer.RenderHelp(fmt.Sprintf("[transcript up to date in %s]\n", escapeMarkdown(u.state.transcriptPath))))
+	default:
+		err = export.AppendMarkdown(u.state.turns[u.state.transcriptTurns:], u.state.transcriptPath)
+	}
+	if err != nil {
+		return u.print(u.components.renderer.RenderError(fmt.Sprintf("[transcript error] %s\n", err)))
+	}
+	u.state.transcriptTurns = len(u.state.turns)
+
+	return u.print(u.components.renderer.RenderSuccess(fmt.Sprintf("[transcript saved to %s]\n", u.state.transcriptPath)))
+}
+
+// transcriptDir is a method of the Ui struct that returns the directory of the saved session transcripts.
+func (u *Ui) transcriptDir() string {
+	if u.config == nil || u.config.GetUserConfig().GetTranscriptDir() == "" {
+		return system.GetTranscriptDirectory()
+	}
+
+	return u.config.GetUserConfig().GetTranscriptDir()
+}
+
+// newTranscriptPath is a function that returns the path of a new transcript file named after a time in a directory,
+// "~" being expanded. The directory is created if needed, and a number is appended to the name of an existing file.
+func newTranscriptPath(dir string, now time.Time) (string, error) {
+	expanded, err := homedir.Expand(dir)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(expanded, 0700); err != nil {
+		return "", err
+	}
+
+	name := "session-" + now.Format(transcript_time_format)
+	path := filepath.Join(expanded, name+".md")
+	for i := 2; ; i++ {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return path, nil
+		} else if err != nil {
+			return "", err
+		}
+		path = filepath.Join(expanded, fmt.Sprintf("%s-%d.md", name, i))
+	}
+}
+
+// canPipe is a method of the Ui struct that checks if the output of a command can be piped into a chat question.
+// The output must be captured, so only the commands that don't need a terminal can be piped, in the REPL mode.
+func (u *Ui) canPipe(input string) bool {
+	return u.state.runMode == ReplMode && !run.IsInteractive(u.resolveAliases(input)) && !run.RequiresElevation(u.resolveAliases(input))
+}
+
+// pipeOutput is a method of the Ui struct that sets the captured output of a command as the pipe of the engine,
+// switches to the chat mode and prefills the prompt with a question about the output.
+func (u *Ui) pipeOutput(output run.RunOutput) tea.Cmd {
+	pipe := truncatePipe(strings.TrimSpace(run.StripAnsi(output.GetStdout()+output.GetStderr())), u.engine.GetMaxPipeSizeBytes())
+	if pipe == "" {
+		return tea.Sequence(
+			u.print(u.components.renderer.RenderWarning("[no output to pipe]\n")),
+			textinput.Blink,
+		)
+	}
+
+	if err := u.engine.SetPipe(pipe); err != nil {
+		return tea.Sequence(
+			u.print(u.components.renderer.RenderError(fmt.Sprintf("[pipe error] %s\n", err))),
+			textinput.Blink,
+		)
+	}
+	u.state.pipe = pipe
+	u.state.promptMode = ChatPromptMode
+	u.components.prompt.SetMode(ChatPromptMode)
+	u.engine.SetMode(ai.ChatEngineMode)
+	u.filterHistory()
+	u.components.prompt.SetValue("explain this output: ")
+	u.components.prompt.Focus()
+
+	return tea.Sequence(
+		u.print(u.components.renderer.RenderHelp("[output piped into the chat]\n")),
+		textinput.Blink,
+	)
+}
+
+// startJob is a method of the Ui struct that starts a command in the background and returns the message to print.
+func (u *Ui) startJob(input string) string {
+	job, err := u.jobs.Start(input, u.commandPreamble())
+	if err != nil {
+		return u.components.renderer.RenderError(fmt.Sprintf("\n[job error]: %s\n", err))
+	}
+
+	return u.components.renderer.RenderHelp(fmt.Sprintf("\n[job %d started] %s\n", job.GetId(), escapeMarkdown(job.GetCommand())))
+}
+
+// configureSpinner is a method of the Ui struct that sets the character set and the message of the spinner.
+func (u *Ui) configureSpinner(config *config.Config) {
+	u.components.spinner.
+		SetStyle(config.GetUserConfig().GetSpinnerStyle()).
+		SetLabel(config.GetUserConfig().GetSpinnerLabel())
+}
+
+// configureRenderer is a method of the Ui struct that sets the color scheme, the style of the markdown content, the
+// --style flag overriding the configured one, the style highlighting the code blocks and the theme of the
+// conversation. The valid settings are applied even if another one is unknown, the unknown ones being returned as
+// a single error.
+func (u *Ui) configureRenderer(config *config.Config) error {
+	markdownStyle := config.GetUserConfig().GetMarkdownStyle()
+	if u.state.markdownStyle != "" {
+		markdownStyle = u.state.markdownStyle
+	}
+
+	var failures []string
+	for _, err := range []error{
+		SetColorScheme(config.GetUserConfig().GetColorScheme()),
+		u.components.renderer.SetMarkdownStyle(markdownStyle),
+		u.components.renderer.SetCodeStyle(config.GetUserConfig().GetCodeStyle()),
+		u.components.renderer.SetTheme(config.GetUserConfig().GetTheme()),
+	} {
+		if err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, ", "))
+	}
+
+	return nil
+}
+
+// commandLanguage is a method of the Ui struct that returns the language highlighting the generated commands,
+// the shell of the user if known, or the POSIX shell.
+func (u *Ui) commandLanguage() string {
+	if u.config == nil || u.config.GetSystemConfig().GetShell() == "" {
+		return "sh"
+	}
+
+	shell := u.config.GetSystemConfig().GetShell()
+	if shell == "pwsh" {
+		return "powershell"
+	}
+
+	return shell
+}
+
+// answerMetadata is a method of the Ui struct that returns the line displayed under an answer,
+// with the model and the time elapsed since the request.
+func (u *Ui) answerMetadata() string {
+	model := "answer"
+	if u.config != nil {
+		model = u.config.GetAiConfig().GetModel()
+	}
+
+	return u.components.renderer.RenderHelp(withDuration(fmt.Sprintf("[%s]", escapeMarkdown(model)), u.components.spinner.GetElapsed())) + "\n"
+}
+
+// filterHistory is a method of the Ui struct that updates the view of the history navigating the inputs of
+// the prompt mode, when enabled.
+func (u *Ui) filterHistory() {
+	u.state.filteredHistory = nil
+	if u.config != nil && u.config.GetUserConfig().GetFilterHistoryByMode() {
+		u.state.filteredHistory = u.history.Filter(history.PromptMode(u.state.promptMode.String()))
+	}
+}
+
+// navigateHistory is a method of the Ui struct that returns the previous or the next input of the history, nil if
+// there is none. When the prompt has content typed by the user, only the inputs starting with it are navigated, like
+// the history-search-backward of readline, the typed prefix being kept while the recalled inputs are not edited.
+func (u *Ui) navigateHistory(previous bool) *string {
+	if value := u.components.prompt.GetValue(); value != u.state.historyRecall {
+		u.state.historyPrefix = value
+	}
+
+	var input *string
+	switch {
+	case u.state.historyPrefix == "" && previous:
+		input = u.navigableHistory().GetPrevious()
+	case u.state.historyPrefix == "":
+		input = u.navigableHistory().GetNext()
+	case previous:
+		input = u.navigableHistory().GetPreviousFiltered(u.state.historyPrefix)
+	default:
+		input = u.navigableHistory().GetNextFiltered(u.state.historyPrefix)
+		if input == nil {
+			// Give back the typed prefix after the most recent matching input
+			input = &u.state.historyPrefix
+		}
+	}
+	if input != nil {
+		u.state.historyRecall = *input
+	}
+
+	return input
+}
+
+// syncSuggestions is a method of the Ui struct that computes the suggestions of the prompt again once the input
+// changed, so the suggestion rendered and accepted is always the one of the displayed input.
+func (u *Ui) syncSuggestions() {
+	source := suggestionsSource{
+		value:   u.components.prompt.GetValue(),
+		hidden:  u.isSuggestionHidden(),
+		history: u.navigableHistory(),
+		config:  u.config,
+	}
+	if source == u.state.suggestionsSource {
+		return
+	}
+
+	u.state.suggestionsSource = source
+	u.components.prompt.WithSuggestions(u.promptSuggestions())
+}
+
+// isSuggestionHidden is a method of the Ui struct that checks if nothing is suggested because the prompt is not
+// being edited.
+func (u *Ui) isSuggestionHidden() bool {
+	return u.state.configuring || u.state.querying || u.state.confirming || u.state.executing || u.state.pendingPaste != ""
+}
+
+// promptSuggestions is a method of the Ui struct that returns the inputs completing the text typed in the prompt,
+// suggested after it like the autosuggestions of fish: the names of the slash commands for a slash command, or
+// the most recent inputs of the history starting with it, the most recent first. Nothing is suggested outside of
+// the editing of the prompt, and the inputs of the history are not suggested when disabled.
+func (u *Ui) promptSuggestions() []string {
+	if u.isSuggestionHidden() {
+		return nil
+	}
+
+	value := u.components.prompt.GetValue()
+	if strings.HasPrefix(value, slash_prefix) && !strings.Contains(value, " ") {
+		suggestions := make([]string, len(slashCommands))
+		for i, name := range slashCommands {
+			suggestions[i] = slash_prefix + name
+		}
+		return suggestions
+	}
+	if u.config == nil || !u.config.GetUserConfig().GetAutosuggest() {
+		return nil
+	}
+
+	return u.navigableHistory().FindAllPrefix(value, prompt_max_suggestions)
+}
+
+// navigableHistory is a method of the Ui struct that returns the history navigated with the arrows,
+// filtered by prompt mode when enabled.
+func (u *Ui) navigableHistory() *history.History {
+	if u.state.filteredHistory != nil {
+		return u.state.filteredHistory
+	}
+
+	return u.history
+}
+
+// loadHistory is a method of the Ui struct that limits the size of the history and loads the history file, the
+// history being saved to it on exit.
+func (u *Ui) loadHistory(config *config.Config) error {
+	u.history.SetMaxSize(config.GetUserConfig().GetMaxHistorySize())
+	u.OnExit(func() {
+		if err := u.history.Save(config.GetSystemConfig().GetHistoryFile()); err != nil {
+			fmt.Println(u.components.renderer.RenderError(fmt.Sprintf("[history error] %s", err)))
+		}
+	})
+
+	return u.history.Load(config.GetSystemConfig().GetHistoryFile())
+}
+
+// loadAliases is a method of the Ui struct that resolves the aliases of the shell of the user, when enabled.
+func (u *Ui) loadAliases(config *config.Config) error {
+	aliases, err := loadAliases(config)
+	if err != nil {
+		return err
+	}
+	u.aliases = aliases
+
+	return nil
+}
+
+// loadAliases is a function that resolves the aliases of the shell of the user, when enabled,
+// no aliases being returned otherwise.
+func loadAliases(config *config.Config) (run.Aliases, error) {
+	if !config.GetUserConfig().GetResolveAliases() || config.GetSystemConfig().GetShell() == "" {
+		return nil, nil
+	}
+
+	return run.LoadAliases(config.GetSystemConfig().GetShell(), alias_load_timeout)
+}
+
+// OnExit is a method of the Ui struct that registers a function called on exit by Shutdown, like to save a file,
+// whatever the way the program quits. The functions are called in the reverse order of their registration.
+func (u *Ui) OnExit(fn func()) *Ui {
+	u.exitHooks = append(u.exitHooks, fn)
+
+	return u
+}
+
+// runExitHooks is a method of the Ui struct that calls the functions registered with OnExit once, the last registered
+// first. Their calls are deferred, so they are all called even if one panics, the panics being written to the crash log.
+func (u *Ui) runExitHooks() {
+	hooks := u.exitHooks
+	u.exitHooks = nil
+	for _, hook := range hooks {
+		defer runExitHook(hook)
+	}
+}
+
+// runExitHook is a function that calls a function registered with OnExit, writing its panic to the crash log.
+func runExitHook(hook func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			_ = writeCrashLog(system.GetCrashLogFile(), fmt.Sprintf("%v\n%s", r, debug.Stack()))
+		}
+	}()
+
+	hook()
+}
+
+// Shutdown is a method of the Ui struct that calls the functions registered with OnExit, like the one saving
+// the history, and terminates the background jobs, unless they should keep running.
+func (u *Ui) Shutdown() {
+	u.runExitHooks()
+
+	// Interrupt the captured command being executed, if any, and cancel the context of the session
+	u.stopInterruptible()
+	if u.state.stop != nil {
+		u.state.stop()
+	}
+
+	if u.state.keepJobs {
+		return
+	}
+
+	u.jobs.Terminate(job_termination_grace)
+	u.jobs.Clean()
+}
+
+// editSettings is a method of the Ui struct that handles editing the settings.
+func (u *Ui) editSettings() tea.Cmd {
+	// Update UI state
+	u.state.querying = false
+	u.state.confirming = false
+	u.state.executing = true
+
+	// The editor is detected when none is set, and there may be none installed
+	editor := u.config.GetSystemConfig().GetEditor()
+	if editor == "" {
+		u.state.executing = false
+		return func() tea.Msg {
+			return run.NewRunOutput(errors.New("no editor found, set the EDITOR environment variable"), "[settings error]", "")
+		}
+	}
+
+	// Prepare and execute the edit settings command
+	c := run.PrepareEditSettingsCommand(fmt.Sprintf(
+		"%s %s",
+		editor,
+		run.Quote(u.config.GetSystemConfig().GetConfigFile()),
+	))
+
+	return tea.ExecProcess(c, func(error error) tea.Msg {
+		// Update UI state
+		u.state.executing = false
+		u.state.command = ""
+
+		if error != nil {
+			// Handle error output
+			return run.NewRunOutput(error, "[settings error]", "")
+		}
+
+		// Create a new config instance
+		config, error := config.NewConfig()
+		if error != nil {
+			// Handle error output
+			return run.NewRunOutput(error, "[settings error]", "")
+		}
+
+		// Update UI config, history size and engine, telling what changed
+		changes := u.config.Diff(config)
+		u.config = config
+		u.history.SetMaxSize(config.GetUserConfig().GetMaxHistorySize())
+		u.filterHistory()
+		u.configureSpinner(config)
+		if error := u.configureRenderer(config); error != nil {
+			return run.NewRunOutput(error, "[settings error]", "")
 		}
+		// The pipe was already truncated at startup if needed
+		engine, _, error := u.newPipedEngine(ai.ExecEngineMode, config)
 		if error != nil {
 			// Handle error output
 			return run.NewRunOutput(error, "[settings error]", "")
 		}
+		// The missing context files were already reported at startup
+		_ = injectContextFiles(engine, config)
 		u.engine = engine
 
 		// Return success output
-		return run.NewRunOutput(nil, "", "[settings ok]")
+		return settingsMsg{output: run.NewRunOutput(nil, "", "[settings ok]"), changes: changes}
 	})
 }
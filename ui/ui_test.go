@@ -0,0 +1,1505 @@
+package ui
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akhilsharma90/terminal-assistant/ai"
+	"github.com/akhilsharma90/terminal-assistant/config"
+	"github.com/akhilsharma90/terminal-assistant/export"
+	"github.com/akhilsharma90/terminal-assistant/run"
+	"github.com/akhilsharma90/terminal-assistant/system"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUIModel(t *testing.T) {
+	t.Run("WithDuration", testWithDuration)
+	t.Run("ExitStatus", testExitStatus)
+	t.Run("HistoryCommand", testHistoryCommand)
+	t.Run("InterruptCapturedCommand", testInterruptCapturedCommand)
+	t.Run("InterruptChatStream", testInterruptChatStream)
+	t.Run("OnExit", testOnExit)
+	t.Run("FinishConfigHistory", testFinishConfigHistory)
+	t.Run("RequestFailed", testRequestFailed)
+	t.Run("ErrorBanner", testErrorBanner)
+	t.Run("SearchConversation", testSearchConversation)
+	t.Run("RedoCommand", testRedoCommand)
+	t.Run("ToggleRawAnswer", testToggleRawAnswer)
+	t.Run("ConfirmCommand", testConfirmCommand)
+	t.Run("ConfirmationChoices", testConfirmationChoices)
+	t.Run("ConfirmationWord", testConfirmationWord)
+	t.Run("EmptyCommand", testEmptyCommand)
+	t.Run("ExportCommand", testExportCommand)
+	t.Run("AttachCommand", testAttachCommand)
+	t.Run("ImportCommand", testImportCommand)
+	t.Run("UnclearCommand", testUnclearCommand)
+	t.Run("DisabledFeatures", testDisabledFeatures)
+	t.Run("InjectContextFiles", testInjectContextFiles)
+	t.Run("ConfigureRenderer", testConfigureRenderer)
+	t.Run("ResetConversation", testResetConversation)
+	t.Run("NewPipedEngine", testNewPipedEngine)
+	t.Run("ConversationView", testConversationView)
+	t.Run("Resize", testResize)
+	t.Run("ResizeDebounce", testResizeDebounce)
+	t.Run("NarrowTerminal", testNarrowTerminal)
+	t.Run("ResizeDuringStream", testResizeDuringStream)
+	t.Run("EngineStatus", testEngineStatus)
+	t.Run("ModelReady", testModelReady)
+	t.Run("KeyHints", testKeyHints)
+	t.Run("PromptIndicators", testPromptIndicators)
+	t.Run("SelectionMode", testSelectionMode)
+	t.Run("BracketedPaste", testBracketedPaste)
+	t.Run("StatusBarView", testStatusBarVisibility)
+	t.Run("CopyLastAnswer", testCopyLastAnswer)
+	t.Run("SaveTranscript", testSaveTranscript)
+	t.Run("EchoInput", testEchoInput)
+	t.Run("EmptyInputHint", testEmptyInputHint)
+	t.Run("NewTranscriptPath", testNewTranscriptPath)
+	t.Run("FilterHistoryByMode", testFilterHistoryByMode)
+	t.Run("HistoryPrefixSearch", testHistoryPrefixSearch)
+	t.Run("ViEditingMode", testViEditingMode)
+	t.Run("Autosuggestion", testAutosuggestion)
+	t.Run("SettingsChanges", testSettingsChanges)
+	t.Run("ShellAliases", testShellAliases)
+}
+
+// newTestUi creates a new Ui instance in REPL mode for testing purposes.
+func newTestUi(t *testing.T) *Ui {
+	t.Helper()
+
+	return NewUi(&UiInput{
+		runMode:    ReplMode,
+		promptMode: ExecPromptMode,
+	})
+}
+
+// testExitStatus tests that the status of a failed run is colored by the exit code of its command, a command killed
+// by a signal or that could not be run having its own colors.
+func testExitStatus(t *testing.T) {
+	assert.Equal(t, 1, exitStatus(run.NewRunOutput(exec.Command("sh", "-c", "exit 1").Run(), "[error]", "[ok]")))
+	assert.Equal(t, 3, exitStatus(run.NewRunOutput(exec.Command("sh", "-c", "exit 3").Run(), "[error]", "[ok]")))
+	assert.Equal(t, -1, exitStatus(run.NewRunOutput(run.ErrInterrupted, "[error]", "[ok]")))
+	assert.Equal(t, -1, exitStatus(run.NewRunOutput(context.DeadlineExceeded, "[error]", "[ok]")))
+	assert.Equal(t, 2, exitStatus(run.NewRunOutput(errors.New("not found"), "[error]", "[ok]")))
+	if runtime.GOOS != "windows" {
+		assert.Equal(t, -1, exitStatus(run.NewRunOutput(exec.Command("sh", "-c", "kill -TERM $$").Run(), "[error]", "[ok]")))
+	}
+}
+
+// testWithDuration tests the withDuration function.
+func testWithDuration(t *testing.T) {
+	testCases := []struct {
+		name     string
+		message  string
+		duration time.Duration
+		expected string
+	}{
+		{"NoDuration", "[ok]", 0, "[ok]"},
+		{"SubSecond", "[ok]", 320 * time.Millisecond, "[ok · 0.3s]"},
+		{"UnderTenSeconds", "[ok]", 3210 * time.Millisecond, "[ok · 3.2s]"},
+		{"AboveTenSeconds", "[ok]", 12600 * time.Millisecond, "[ok · 13s]"},
+		{"Error", "[error]: exit status 1", 1500 * time.Millisecond, "[error · 1.5s]: exit status 1"},
+		{"NoTag", "done", time.Second, "done"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, withDuration(tc.message, tc.duration), "The message should match the expected value.")
+		})
+	}
+}
+
+// testHistoryCommand tests that the "/history" slash command replays the session in the viewport.
+func testHistoryCommand(t *testing.T) {
+	u := newTestUi(t)
+	u.addTurn(export.UserRole, "list files")
+	u.addTurn(export.AssistantRole, "`ls -la`")
+
+	u.runSlashCommand("history", nil)
+	assert.True(t, u.components.viewport.IsVisible(), "The viewport should be visible.")
+	assert.Contains(t, run.StripAnsi(u.View()), "list files", "The view should replay the session.")
+	assert.Contains(t, run.StripAnsi(u.View()), "ls -la", "The view should replay the session.")
+
+	u.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	assert.False(t, u.components.viewport.IsVisible(), "The viewport should be closed.")
+	assert.NotContains(t, run.StripAnsi(u.View()), "list files", "The view should return to the prompt.")
+}
+
+// testInterruptCapturedCommand tests that the output of the captured command is displayed while it runs,
+// that ctrl+c interrupts the captured command instead of quitting, and that a second ctrl+c quits.
+func testInterruptCapturedCommand(t *testing.T) {
+	u := newTestUi(t)
+
+	batch, ok := u.captureCommand("echo started; sleep 30")().(tea.BatchMsg)
+	require.True(t, ok, "The command should be run while its output is streamed.")
+	outputs := make(chan tea.Msg, len(batch)+1)
+	for _, cmd := range batch {
+		go func(cmd tea.Cmd) {
+			outputs <- cmd()
+		}(cmd)
+	}
+
+	progress, ok := (<-outputs).(run.RunProgressMsg)
+	require.True(t, ok, "The output lines should be streamed.")
+	_, awaitCmd := u.Update(progress)
+	assert.Contains(t, run.StripAnsi(u.View()), "started", "The view should display the live output.")
+	go func() {
+		outputs <- awaitCmd()
+	}()
+
+	_, interruptCmd := u.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	require.NotNil(t, interruptCmd)
+	assert.NotEqual(t, tea.Quit(), interruptCmd(), "The first ctrl+c should not quit.")
+
+	timeout := time.After(5 * time.Second)
+	for done := false; !done; {
+		select {
+		case msg := <-outputs:
+			output, ok := msg.(run.RunOutput)
+			if !ok {
+				continue
+			}
+			assert.Equal(t, "[interrupted]", output.GetErrorMessage(), "The interruption should be reported.")
+			assert.Equal(t, "started\n", output.GetStdout(), "The output before the interruption should be kept.")
+			done = true
+		case <-timeout:
+			t.Fatal("The command should be interrupted.")
+		}
+	}
+
+	u.state.executing = true
+	u.startInterruptible()
+	_, quitCmd := u.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	require.NotNil(t, quitCmd)
+	assert.Equal(t, tea.Quit(), quitCmd(), "A second ctrl+c should quit.")
+	u.finishInterruptible()
+}
+
+// testConfirmCommand tests that the "/confirm on" command revokes the session override of the confirmation
+// and removes its badge from the prompt area.
+func testConfirmCommand(t *testing.T) {
+	u := newTestUi(t)
+	u.state.autoConfirm = true
+	assert.Contains(t, run.StripAnsi(u.View()), "auto", "The prompt area should show the override.")
+
+	u.runSlashCommand("confirm", []string{"off"})
+	assert.True(t, u.state.autoConfirm, "An invalid command should keep the override.")
+
+	u.runSlashCommand("confirm", []string{"on"})
+	assert.False(t, u.state.autoConfirm, "The override should be revoked.")
+	assert.NotContains(t, run.StripAnsi(u.View()), "auto", "The prompt area should not show the override.")
+}
+
+// testConfirmationChoices tests that the confirmation of a command is answered with the highlighted choice,
+// No being highlighted by default, or with the y/n shortcuts.
+func testConfirmationChoices(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "terminal-assistant.json"), []byte(`{"openai_key": "test_key"}`), 0600))
+	viper.AddConfigPath(dir)
+	cfg, err := config.NewConfig()
+	require.NoError(t, err)
+
+	newConfirmingUi := func() *Ui {
+		u := newTestUi(t)
+		u.config = cfg
+		u.engine = &ai.Engine{}
+		u.Update(ai.EngineExecOutput{Command: "mkdir build", Explanation: "Creates the build directory.", Executable: true})
+		require.True(t, u.state.confirming, "The command should be confirmed.")
+		return u
+	}
+
+	u := newConfirmingUi()
+	assert.Contains(t, run.StripAnsi(u.View()), "  Yes    [ No ]", "No should be highlighted by default.")
+	u.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.False(t, u.state.confirming, "Enter should answer the confirmation.")
+	assert.False(t, u.state.executing, "Enter should cancel the command by default.")
+
+	u = newConfirmingUi()
+	u.Update(tea.KeyMsg{Type: tea.KeyRight})
+	assert.Contains(t, run.StripAnsi(u.View()), "[ Yes ]    No", "Yes should be highlighted.")
+	u.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	assert.Contains(t, run.StripAnsi(u.View()), "  Yes    [ No ]", "No should be highlighted again.")
+	u.Update(tea.KeyMsg{Type: tea.KeyRight})
+	u.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.True(t, u.state.executing, "Enter should execute the command once Yes is highlighted.")
+
+	u = newConfirmingUi()
+	u.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	assert.True(t, u.state.executing, "y should execute the command.")
+
+	u = newConfirmingUi()
+	u.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	assert.False(t, u.state.confirming)
+	assert.False(t, u.state.executing, "n should cancel the command.")
+}
+
+// testEmptyCommand tests that a command flagged as executable without a line to execute is explained with
+// a warning, instead of being confirmed.
+func testEmptyCommand(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "terminal-assistant.json"), []byte(`{"openai_key": "test_key"}`), 0600))
+	viper.AddConfigPath(dir)
+	t.Cleanup(viper.Reset)
+	cfg, err := config.NewConfig()
+	require.NoError(t, err)
+
+	for name, command := range map[string]string{"Empty": "", "Whitespace": " \n\t", "Comments": "# list the files\n# with ls"} {
+		t.Run(name, func(t *testing.T) {
+			u := newTestUi(t)
+			u.config = cfg
+			u.engine = &ai.Engine{}
+			u.components.prompt.Blur()
+
+			_, cmd := u.Update(ai.EngineExecOutput{Command: command, Explanation: "Use ls.", Executable: true})
+			drainPrints(u, cmd)
+			assert.False(t, u.state.confirming, "Nothing should be confirmed.")
+			assert.Empty(t, u.state.command)
+			assert.True(t, u.components.prompt.isFocused(), "The prompt should be focused.")
+			view := run.StripAnsi(u.components.conversation.View(20))
+			assert.Contains(t, view, "Use ls.")
+			assert.Contains(t, view, "[the answer had no command to execute]")
+		})
+	}
+}
+
+// testConfirmationWord tests that the command is only executed once the configured confirmation word is typed and
+// answered with enter, the shortcuts and the choices being disabled, a mistyped word declining it and the case
+// mattering only when configured.
+func testConfirmationWord(t *testing.T) {
+	newConfirmingUi := func(content string) *Ui {
+		viper.Reset()
+		t.Cleanup(viper.Reset)
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "terminal-assistant.json"), []byte(content), 0600))
+		viper.AddConfigPath(dir)
+		cfg, err := config.NewConfig()
+		require.NoError(t, err)
+
+		u := newTestUi(t)
+		u.config = cfg
+		u.engine = &ai.Engine{}
+		u.Update(ai.EngineExecOutput{Command: "mkdir build", Explanation: "Creates the build directory.", Executable: true})
+		require.True(t, u.state.confirming, "The command should be confirmed.")
+		return u
+	}
+	typeKeys := func(u *Ui, keys string) {
+		for _, key := range keys {
+			u.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{key}})
+		}
+	}
+
+	enter := func(u *Ui) {
+		u.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	}
+
+	u := newConfirmingUi(`{"openai_key": "test_key", "user_confirmation_word": "Execute"}`)
+	assert.Contains(t, run.StripAnsi(u.View()), "type Execute and press enter")
+	typeKeys(u, "exec")
+	assert.Contains(t, run.StripAnsi(u.View()), "> exec", "The typed input should be displayed.")
+	typeKeys(u, "UTE")
+	assert.True(t, u.state.confirming, "The complete word should wait for enter.")
+	enter(u)
+	assert.True(t, u.state.executing, "The complete word should execute the command, whatever its case.")
+
+	u = newConfirmingUi(`{"openai_key": "test_key", "user_confirmation_word": "Execute"}`)
+	typeKeys(u, "executex")
+	u.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	enter(u)
+	assert.True(t, u.state.executing, "Backspace should remove the last typed character.")
+
+	for _, shortcut := range []string{"y", "a", "e", "!", "b"} {
+		u = newConfirmingUi(`{"openai_key": "test_key", "user_confirmation_word": "Execute"}`)
+		typeKeys(u, shortcut)
+		assert.True(t, u.state.confirming, "The %s shortcut should be disabled.", shortcut)
+		assert.False(t, u.state.executing, "The %s shortcut should not execute the command.", shortcut)
+	}
+
+	u = newConfirmingUi(`{"openai_key": "test_key", "user_confirmation_word": "Execute"}`)
+	u.Update(tea.KeyMsg{Type: tea.KeyRight})
+	enter(u)
+	assert.False(t, u.state.confirming)
+	assert.False(t, u.state.executing, "Selecting the Yes choice should not execute the command.")
+
+	u = newConfirmingUi(`{"openai_key": "test_key", "user_confirmation_word": "Execute"}`)
+	typeKeys(u, "exec")
+	enter(u)
+	assert.False(t, u.state.executing, "An incomplete word should decline the command.")
+
+	u = newConfirmingUi(`{"openai_key": "test_key", "user_confirmation_word": "Execute"}`)
+	typeKeys(u, "exx")
+	enter(u)
+	assert.False(t, u.state.executing, "A mistyped word should decline the command.")
+
+	u = newConfirmingUi(`{"openai_key": "test_key", "user_confirmation_word": "Execute", "user_confirmation_case_sensitive": true}`)
+	typeKeys(u, "execute")
+	enter(u)
+	assert.False(t, u.state.executing, "The case of the word should matter when configured.")
+}
+
+// testExportCommand tests that the "/export session" command saves the session as markdown or as HTML.
+func testExportCommand(t *testing.T) {
+	u := newTestUi(t)
+	u.addTurn(export.UserRole, "list files")
+
+	markdownPath := filepath.Join(t.TempDir(), "session.md")
+	assert.Contains(t, u.exportCommand([]string{"session", markdownPath}), "session exported")
+	content, err := os.ReadFile(markdownPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "# terminal-assistant session")
+
+	htmlPath := filepath.Join(t.TempDir(), "session.html")
+	assert.Contains(t, u.exportCommand([]string{"session", "html", htmlPath}), "session exported")
+	content, err = os.ReadFile(htmlPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "<!DOCTYPE html>")
+	assert.Contains(t, string(content), "list files")
+
+	assert.Contains(t, u.exportCommand([]string{"session"}), "usage")
+}
+
+// testAttachCommand tests that the "/attach" command attaches the images and rejects the other files.
+func testAttachCommand(t *testing.T) {
+	u := newTestUi(t)
+	u.engine = &ai.Engine{}
+
+	dir := t.TempDir()
+	image := filepath.Join(dir, "screenshot.png")
+	require.NoError(t, os.WriteFile(image, []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), 0600))
+	text := filepath.Join(dir, "notes.txt")
+	require.NoError(t, os.WriteFile(text, []byte("notes"), 0600))
+
+	assert.Contains(t, u.attachCommand([]string{image}), "image attached")
+	assert.Contains(t, u.attachCommand([]string{text}), "attach error")
+	assert.Contains(t, u.attachCommand(nil), "usage")
+	assert.Equal(t, []string{image}, u.state.pendingImages, "Only the image should be pending.")
+	assert.Len(t, u.engine.GetImages(), 1, "Only the image should be attached.")
+}
+
+// testImportCommand tests that the commands of the history file of a shell are added to the history, without
+// duplicates.
+func testImportCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".bash_history")
+	require.NoError(t, os.WriteFile(path, []byte("ls\npwd\nls\n"), 0600))
+	t.Setenv("HISTFILE", path)
+	t.Setenv("SHELL", "/bin/bash")
+	u := newTestUi(t)
+	u.history.Add("pwd")
+
+	assert.Contains(t, run.StripAnsi(u.importCommand([]string{"history", "bash"})), "[3 commands imported from "+path+", 2 duplicates removed]")
+	assert.Equal(t, map[int]string{0: "pwd", 1: "ls"}, u.history.GetAll())
+	assert.Contains(t, u.importCommand([]string{"history", "csh"}), "unknown shell")
+	assert.Contains(t, u.importCommand([]string{"history"}), "usage")
+}
+
+// testUnclearCommand tests that ctrl+l only clears the screen of the conversation, displayed again by /unclear.
+func testUnclearCommand(t *testing.T) {
+	u := newTestUi(t)
+	u.components.conversation.Append("a long answer")
+	u.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+	assert.NotContains(t, u.components.conversation.View(10), "a long answer", "The screen should be cleared.")
+
+	assert.Contains(t, u.unclearCommand([]string{"x"}), "usage")
+	assert.Contains(t, run.StripAnsi(u.unclearCommand(nil)), "[1 screen restored]")
+	assert.Contains(t, u.components.conversation.View(10), "a long answer", "The cleared screen should be displayed again.")
+	assert.Contains(t, run.StripAnsi(u.unclearCommand([]string{"2"})), "[no cleared screen]")
+}
+
+// testRedoCommand tests that the "/redo" command executes again the last command executed, which is kept once it
+// finished.
+func testRedoCommand(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		filepath.Join(dir, "terminal-assistant.json"),
+		[]byte(`{"openai_key": "test_key", "user_capture_output": false}`),
+		0600,
+	))
+	viper.AddConfigPath(dir)
+	cfg, err := config.NewConfig()
+	require.NoError(t, err)
+
+	u := newTestUi(t)
+	u.config = cfg
+	drainPrints(u, u.runSlashCommand("redo", nil))
+	assert.Contains(t, run.StripAnsi(u.components.conversation.View(10)), "[no previous command]")
+	assert.False(t, u.state.executing)
+
+	u.state.lastExecutedCommand = "ls -la"
+	drainPrints(u, u.runSlashCommand("redo", nil))
+	assert.Contains(t, run.StripAnsi(u.components.conversation.View(10)), "[re-executing: ls -la]")
+	assert.True(t, u.state.executing, "The last command should be executed again.")
+	assert.Equal(t, "ls -la", u.state.lastExecutedCommand, "The last command should be kept.")
+
+	u = newTestUi(t)
+	u.config = cfg
+	u.state.lastExecutedCommand = "echo `whoami`"
+	drainPrints(u, u.runSlashCommand("redo", nil))
+	assert.Contains(t, run.StripAnsi(u.components.conversation.View(10)), "[re-executing: echo `whoami`]", "The command should be displayed verbatim.")
+}
+
+// testToggleRawAnswer tests that ctrl+x displays the last answer as raw markdown and renders it again, nothing being
+// changed while querying.
+func testToggleRawAnswer(t *testing.T) {
+	u := newTestUi(t)
+	u.Update(printMarkdownMsg{prefix: "", markdown: "# Title", suffix: "", answer: true})
+
+	u.state.querying = true
+	_, cmd := u.Update(tea.KeyMsg{Type: tea.KeyCtrlX})
+	drainPrints(u, cmd)
+	assert.Contains(t, run.StripAnsi(u.components.conversation.View(20)), "once received", "A hint should be displayed while querying.")
+	assert.NotContains(t, u.components.conversation.View(20), "```markdown")
+
+	u.state.querying = false
+	u.Update(tea.KeyMsg{Type: tea.KeyCtrlX})
+	assert.Equal(t, "```markdown\n# Title\n```\n", u.components.conversation.blocks[0].content, "The answer should be raw.")
+	u.Update(tea.KeyMsg{Type: tea.KeyCtrlX})
+	assert.NotContains(t, u.components.conversation.View(20), "```markdown", "The answer should be rendered again.")
+}
+
+// testDisabledFeatures tests that the features listed in user_disabled_features cannot be used, the chat mode
+// replacing the default exec mode when exec is disabled.
+func testDisabledFeatures(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		filepath.Join(dir, "terminal-assistant.json"),
+		[]byte(`{"openai_key": "test_key", "user_default_prompt_mode": "exec", "user_disabled_features": ["exec", "settings_edit", "import"]}`),
+		0600,
+	))
+	viper.AddConfigPath(dir)
+	cfg, err := config.NewConfig()
+	require.NoError(t, err)
+
+	mode, err := resolvePromptMode(DefaultPromptMode, cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, ChatPromptMode, mode, "The chat mode should replace the default exec mode.")
+	_, err = resolvePromptMode(ExecPromptMode, cfg)
+	assert.ErrorIs(t, err, errExecDisabled, "The exec mode asked for should be refused.")
+
+	u := newTestUi(t)
+	u.config = cfg
+	u.engine = &ai.Engine{}
+	u.state.promptMode = ChatPromptMode
+	u.Update(tea.KeyMsg{Type: tea.KeyTab})
+	assert.Equal(t, ChatPromptMode, u.state.promptMode, "The exec mode should not be switched to.")
+
+	_, cmd := u.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	assert.Nil(t, cmd, "The settings should not be edited.")
+	assert.False(t, u.state.executing)
+
+	drainPrints(u, u.runSlashCommand("import", []string{"history", "bash"}))
+	assert.Contains(t, run.StripAnsi(u.components.conversation.View(10)), "[/import is disabled]")
+}
+
+// testInjectContextFiles tests that the default context files are injected, glob patterns being expanded,
+// and that the missing files are reported as warnings.
+func testInjectContextFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# project"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.env"), []byte("PORT=8080"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.env"), []byte("HOST=localhost"), 0600))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(dir, "terminal-assistant.json"),
+		[]byte(fmt.Sprintf(`{"openai_key": "test_key", "user_default_context_files": [%q, %q, %q]}`,
+			filepath.Join(dir, "README.md"), filepath.Join(dir, "*.env"), filepath.Join(dir, "missing.md"))),
+		0600,
+	))
+	viper.AddConfigPath(dir)
+	cfg, err := config.NewConfig()
+	require.NoError(t, err)
+
+	engine := &ai.Engine{}
+	warnings := injectContextFiles(engine, cfg)
+
+	assert.Equal(t, []string{filepath.Join(dir, "README.md"), filepath.Join(dir, "a.env"), filepath.Join(dir, "b.env")}, engine.GetContextFiles())
+	require.Len(t, warnings, 1, "The missing file should be reported.")
+	assert.Contains(t, warnings[0], "[context file not found]")
+}
+
+// testConfigureRenderer tests that the valid settings of the rendering are applied even if others are unknown, all the
+// unknown ones being reported.
+func testConfigureRenderer(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		filepath.Join(dir, "terminal-assistant.json"),
+		[]byte(`{"openai_key": "test_key", "user_color_scheme": "sepia", "user_code_style": "unknown", "user_theme": "minimal"}`),
+		0600,
+	))
+	viper.AddConfigPath(dir)
+	cfg, err := config.NewConfig()
+	require.NoError(t, err)
+
+	u := newTestUi(t)
+	err = u.configureRenderer(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown color scheme "sepia"`)
+	assert.Contains(t, err.Error(), `unknown code style "unknown"`)
+	assert.Equal(t, minimal_theme, u.components.renderer.GetTheme(), "The theme should be applied after the unknown settings.")
+}
+
+// testResetConversation tests that ctrl+r resets the conversation and the turns of the session, the inputs of the
+// prompt being kept in the history.
+func testResetConversation(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "terminal-assistant.json"), []byte(`{"openai_key": "test_key"}`), 0600))
+	viper.AddConfigPath(dir)
+	cfg, err := config.NewConfig()
+	require.NoError(t, err)
+	engine, err := ai.NewEngine(context.Background(), ai.ExecEngineMode, cfg)
+	require.NoError(t, err)
+
+	u := newTestUi(t)
+	u.config = cfg
+	u.engine = engine
+	u.history.Add("list files").Add("show disk usage")
+	u.addTurn(export.UserRole, "list files")
+
+	u.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	assert.Empty(t, u.state.turns, "The turns of the session should be reset.")
+	assert.Equal(t, 2, u.history.Len(), "The inputs should be kept in the history.")
+	u.Update(tea.KeyMsg{Type: tea.KeyUp})
+	assert.Equal(t, "show disk usage", u.components.prompt.GetValue(), "The inputs should still be navigated.")
+}
+
+// testEngineStatus tests that the statuses of the engine replace the message of the spinner while querying, until
+// the answer starts being streamed and its estimated number of tokens is displayed, and that the statuses of
+// a replaced engine are ignored.
+func testEngineStatus(t *testing.T) {
+	engine, err := ai.NewEngine(context.Background(), ai.ChatEngineMode, newTestPlainConfig(t))
+	require.NoError(t, err)
+
+	u := newTestUi(t)
+	u.state.promptMode = ChatPromptMode
+	u.engine = engine
+	assert.NotNil(t, u.listenEngineStatus())
+	assert.Nil(t, u.listenEngineStatus(), "The status channel should be listened to once.")
+
+	u.state.querying = true
+	_, cmd := u.Update(engineStatusMsg{status: ai.NewEngineStatus("contacting gpt-4o-mini"), channel: engine.GetStatusChannel()})
+	assert.NotNil(t, cmd, "The next status should be awaited.")
+	assert.Contains(t, run.StripAnsi(u.View()), "contacting gpt-4o-mini... 0s")
+
+	_, cmd = u.Update(engineStatusMsg{status: ai.NewEngineStatus("running lookup"), channel: make(chan ai.EngineStatus)})
+	assert.Nil(t, cmd, "The statuses of a replaced engine should no longer be awaited.")
+	assert.Contains(t, run.StripAnsi(u.View()), "contacting gpt-4o-mini")
+
+	u.Update(ai.NewEngineChatStreamOutput("Hello", false))
+	view := run.StripAnsi(u.View())
+	assert.NotContains(t, view, "contacting", "The status should be replaced once the answer starts being streamed.")
+	assert.Contains(t, view, "responding... 0s", "The spinner should tell the answer is being streamed.")
+}
+
+// testNewPipedEngine tests that the pipe exceeding the maximum size or the context window is truncated when enabled,
+// and refused otherwise.
+func testNewPipedEngine(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "terminal-assistant.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{"openai_key": "test_key", "user_max_pipe_size_bytes": 100}`), 0600))
+	viper.AddConfigPath(dir)
+	cfg, err := config.NewConfig()
+	require.NoError(t, err)
+
+	u := newTestUi(t)
+	u.state.pipe = strings.Repeat("é", 100)
+	engine, warning, err := u.newPipedEngine(ai.ChatEngineMode, cfg)
+	require.NoError(t, err)
+	assert.NotNil(t, engine)
+	assert.Equal(t, "[pipe truncated to 100 bytes]", warning)
+	assert.LessOrEqual(t, len(u.state.pipe), 100, "The pipe should be truncated to the maximum size.")
+
+	require.NoError(t, os.WriteFile(file, []byte(`{"openai_key": "test_key", "user_max_pipe_size_bytes": 100, "user_truncate_pipe": false}`), 0600))
+	cfg, err = config.NewConfig()
+	require.NoError(t, err)
+
+	u = newTestUi(t)
+	u.state.pipe = strings.Repeat("é", 100)
+	_, warning, err = u.newPipedEngine(ai.ChatEngineMode, cfg)
+	assert.ErrorIs(t, err, ai.ErrPipeTooLarge, "The pipe should be refused when the truncation is disabled.")
+	assert.Empty(t, warning)
+
+	require.NoError(t, os.WriteFile(file, []byte(`{"openai_key": "test_key", "user_max_context_tokens": 10}`), 0600))
+	cfg, err = config.NewConfig()
+	require.NoError(t, err)
+
+	u = newTestUi(t)
+	u.state.pipe = strings.Repeat("a", 100)
+	_, warning, err = u.newPipedEngine(ai.ChatEngineMode, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "[pipe truncated to 40 bytes]", warning, "The pipe should be truncated to the context window.")
+}
+
+// testConversationView tests that the printed content is displayed in the conversation, the prompt staying
+// at the bottom of the terminal in the REPL mode.
+func testConversationView(t *testing.T) {
+	u := newTestUi(t)
+	u.Update(tea.WindowSizeMsg{Width: 80, Height: 10})
+
+	_, cmd := u.Update(printMsg("hello"))
+	assert.Nil(t, cmd)
+
+	lines := strings.Split(run.StripAnsi(u.View()), "\n")
+	assert.Len(t, lines, 10, "The view should fill the terminal.")
+	assert.Contains(t, lines[0], "hello", "The conversation should be displayed above the prompt.")
+	assert.Contains(t, lines[len(lines)-1], exec_icon, "The prompt should be at the bottom.")
+
+	u.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+	assert.NotContains(t, run.StripAnsi(u.View()), "hello", "ctrl+l should clear the conversation.")
+}
+
+// testResize tests that the content is wrapped at the width set without a terminal.
+func testResize(t *testing.T) {
+	testCases := []struct {
+		name  string
+		width int
+		lines int
+	}{
+		{"80Columns", 80, 3},
+		{"120Columns", 120, 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			u := newTestUi(t)
+			renderer := u.components.renderer
+			require.NoError(t, u.Resize(tc.width, 20))
+			assert.Same(t, renderer, u.components.renderer, "The renderer should be resized, not replaced.")
+			assert.Equal(t, tc.width, u.components.renderer.GetWidth())
+
+			u.Update(printMsg(u.components.renderer.RenderContent(strings.Repeat("wrap ", 40))))
+
+			lines := strings.Split(run.StripAnsi(u.View()), "\n")
+			assert.Len(t, lines, 20, "The view should fill the terminal.")
+			wrapped := 0
+			for _, line := range lines {
+				assert.LessOrEqual(t, len([]rune(line)), tc.width, "The lines should fit in the terminal.")
+				if strings.Contains(line, "wrap") {
+					wrapped++
+				}
+			}
+			assert.Equal(t, tc.lines, wrapped, "The content should be wrapped at the width.")
+		})
+	}
+}
+
+// testResizeDebounce tests that the conversation is rendered again at the width of the last window size change
+// only, and not when only the height changed.
+func testResizeDebounce(t *testing.T) {
+	u := newTestUi(t)
+	require.NoError(t, u.Resize(120, 20))
+	u.Update(printMarkdownMsg{markdown: strings.Repeat("wrap ", 40)})
+	renderer := u.components.renderer.contentRenderer
+
+	_, cmd := u.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	assert.Nil(t, cmd, "Nothing should be rendered when only the height changed.")
+	assert.Same(t, renderer, u.components.renderer.contentRenderer, "The renderer should be kept when only the height changed.")
+
+	_, cmd = u.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	assert.NotNil(t, cmd, "The rendering should be scheduled.")
+	u.Update(tea.WindowSizeMsg{Width: 60, Height: 30})
+	assert.Equal(t, 60, u.dimensions.width, "The components should be laid out at once.")
+	assert.Equal(t, 120, u.components.renderer.GetWidth(), "The content should not be rendered before the size settles.")
+
+	u.Update(resizeMsg(u.state.resizes - 1))
+	assert.Equal(t, 120, u.components.renderer.GetWidth(), "The intermediate sizes should not be rendered.")
+
+	u.Update(resizeMsg(u.state.resizes))
+	assert.Equal(t, 60, u.components.renderer.GetWidth(), "The content should be rendered at the last width.")
+	for _, line := range strings.Split(run.StripAnsi(u.View()), "\n") {
+		assert.LessOrEqual(t, len([]rune(line)), 60, "The conversation should be wrapped at the new width.")
+	}
+}
+
+// testResizeDuringStream tests that the answer being streamed is rendered at the new width when the terminal is
+// resized between its chunks, the complete answer being printed once, without the rendering at the previous width.
+func testResizeDuringStream(t *testing.T) {
+	u := newTestUi(t)
+	u.engine = &ai.Engine{}
+	require.NoError(t, u.Resize(120, 20))
+	u.state.promptMode = ChatPromptMode
+	u.state.querying = true
+
+	u.Update(ai.NewEngineChatStreamOutput("alpha "+strings.Repeat("wrap ", 30), false))
+	u.Update(tea.WindowSizeMsg{Width: 60, Height: 20})
+	u.Update(ai.NewEngineChatStreamOutput(strings.Repeat("wrap ", 30), false))
+	u.Update(resizeMsg(u.state.resizes))
+	assert.Equal(t, 60, u.components.renderer.GetWidth())
+	for _, line := range strings.Split(run.StripAnsi(u.components.stream.View()), "\n") {
+		assert.LessOrEqual(t, len([]rune(line)), 60, "The answer being streamed should be wrapped at the new width.")
+	}
+
+	_, cmd := u.Update(ai.NewEngineChatStreamOutput("omega", true))
+	drainPrints(u, cmd)
+	assert.False(t, u.state.querying, "The prompt should be restored.")
+	assert.Empty(t, u.state.buffer)
+	view := run.StripAnsi(u.components.conversation.View(100))
+	assert.Equal(t, 1, strings.Count(view, "alpha"), "The answer should be printed once.")
+	assert.Equal(t, 1, strings.Count(view, "omega"), "The answer should be printed once.")
+	assert.Equal(t, 60, strings.Count(view, "wrap"), "The answer should be printed once.")
+	for _, line := range strings.Split(view, "\n") {
+		assert.LessOrEqual(t, len([]rune(line)), 60, "The answer should be wrapped at the new width.")
+	}
+}
+
+// testNarrowTerminal tests that a terminal narrower than the minimum wrap width displays a plain view,
+// the content being wrapped at the minimum width.
+func testNarrowTerminal(t *testing.T) {
+	u := newTestUi(t)
+	u.Update(printMarkdownMsg{markdown: strings.Repeat("wrap ", 40)})
+	u.components.prompt.SetValue("list files")
+
+	require.NotPanics(t, func() {
+		u.Update(tea.WindowSizeMsg{Width: 5, Height: 10})
+		u.Update(resizeMsg(u.state.resizes))
+	})
+	assert.Equal(t, min_wrap_width, u.components.renderer.GetWidth(), "The content should be wrapped at the minimum width.")
+
+	var view string
+	require.NotPanics(t, func() { view = u.View() })
+	assert.NotContains(t, view, "\x1b", "The view should not be rendered.")
+	assert.Contains(t, strings.Join(strings.Fields(view), ""), "[terminaltoonarrow]", "The view should tell the terminal is too narrow.")
+	assert.Contains(t, strings.Join(strings.Fields(view), ""), "listfiles", "The view should display the prompt.")
+	for _, line := range strings.Split(view, "\n") {
+		assert.LessOrEqual(t, len([]rune(line)), 5, "The lines should fit in the terminal.")
+	}
+
+	u.state.confirming = true
+	u.state.command = "rm -rf build"
+	u.state.lastAnswer = "Removes the build directory."
+	view = strings.Join(strings.Fields(u.View()), "")
+	assert.Contains(t, view, "rm-rfbuild", "The view should display the command to confirm.")
+	assert.Contains(t, view, "runthiscommand?[y/N]", "The view should display the answer expected.")
+	u.state.confirming = false
+
+	require.NoError(t, u.Resize(80, 20))
+	assert.NotContains(t, u.View(), "too narrow", "The content should be rendered again in a wider terminal.")
+}
+
+// testModelReady tests that the first query of the REPL mode is sent once the connection to the model is established,
+// and kept in the prompt if the connection failed.
+func testModelReady(t *testing.T) {
+	newConnectingUi := func() *Ui {
+		u := newTestUi(t)
+		u.engine = &ai.Engine{}
+		u.components.prompt.SetValue("list files")
+		_, cmd := u.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.NotNil(t, cmd, "The connection should be reported.")
+		assert.True(t, u.state.submitOnReady, "The query should wait for the connection.")
+		assert.Equal(t, "list files", u.components.prompt.GetValue(), "The query should be kept in the prompt.")
+		return u
+	}
+
+	u := newConnectingUi()
+	_, cmd := u.Update(modelReadyMsg{})
+	require.NotNil(t, cmd, "The query should be sent once connected.")
+	assert.True(t, u.state.modelReady)
+	assert.Equal(t, "list files", u.state.lastRequest, "The query should be sent.")
+	assert.Empty(t, u.components.prompt.GetValue())
+
+	u = newConnectingUi()
+	_, cmd = u.Update(modelReadyMsg{err: errors.New("invalid key")})
+	require.NotNil(t, cmd, "The error should be displayed.")
+	assert.Contains(t, run.StripAnsi(fmt.Sprint(cmd())), "[model error] invalid key")
+	assert.True(t, u.state.modelReady, "The next queries should not wait for the connection.")
+	assert.False(t, u.state.submitOnReady, "The query should not be sent after a failed connection.")
+	assert.Equal(t, "list files", u.components.prompt.GetValue(), "The query should be kept in the prompt.")
+}
+
+// testKeyHints tests that the keys available in the current state are displayed under the prompt,
+// the copy key being the configured one, and that they are hidden on narrow terminals or when disabled.
+func testKeyHints(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	newConfig := func(content string) *config.Config {
+		viper.Reset()
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "terminal-assistant.json"), []byte(content), 0600))
+		viper.AddConfigPath(dir)
+		cfg, err := config.NewConfig()
+		require.NoError(t, err)
+		return cfg
+	}
+
+	u := newTestUi(t)
+	u.config = newConfig(`{"openai_key": "test_key", "user_copy_key": "ctrl+y", "user_raw_key": "ctrl+o", "user_retry_key": "ctrl+t"}`)
+	u.Update(tea.WindowSizeMsg{Width: 100, Height: 20})
+	assert.Equal(t, "tab: mode · ctrl+h: help · ctrl+c: quit", run.StripAnsi(u.hintsView()), "The keys of the prompt should be displayed.")
+
+	u.state.lastAnswer = "Lists the files."
+	assert.Equal(t, "tab: mode · ctrl+h: help · ctrl+y: copy · ctrl+o: raw · ctrl+c: quit", run.StripAnsi(u.hintsView()), "The configured keys should be displayed.")
+
+	u.state.failedRequest = &failedRequest{}
+	assert.Contains(t, run.StripAnsi(u.hintsView()), "ctrl+t: retry", "The configured retry key should be displayed.")
+	u.state.failedRequest = nil
+
+	u.state.querying = true
+	assert.Equal(t, "ctrl+c: cancel", run.StripAnsi(u.hintsView()), "The cancellation should be displayed while querying.")
+	u.state.querying = false
+
+	u.state.confirming = true
+	u.state.command = "ls"
+	assert.Equal(t, "y: run · n: cancel · !: terminal", run.StripAnsi(u.hintsView()), "The answers should be displayed while confirming.")
+	u.state.fixing = true
+	assert.Equal(t, "y: fix · n: skip", run.StripAnsi(u.hintsView()), "The answers should be displayed while proposing a fix.")
+	u.state.confirming, u.state.fixing = false, false
+
+	u.Update(tea.WindowSizeMsg{Width: 30, Height: 20})
+	assert.Empty(t, u.hintsView(), "The keys should be hidden on narrow terminals.")
+
+	u.config = newConfig(`{"openai_key": "test_key", "user_footer_hints": false}`)
+	u.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+	assert.Empty(t, u.hintsView(), "The keys should be hidden when disabled.")
+}
+
+// testPromptIndicators tests that the prompts use the symbols of the configuration, the prompt asking for the key
+// included.
+func testPromptIndicators(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		filepath.Join(dir, "terminal-assistant.json"),
+		[]byte(`{"openai_key": "test_key", "user_prompt_indicators": {"exec": {"symbol": "❯", "color": "#00ff00"}, "chat": {"symbol": "?"}}}`),
+		0600,
+	))
+	viper.AddConfigPath(dir)
+	cfg, err := config.NewConfig()
+	require.NoError(t, err)
+
+	u := newTestUi(t)
+	u.config = cfg
+	prompt := u.newPrompt(ExecPromptMode)
+	assert.Equal(t, "❯ ", run.StripAnsi(prompt.GetIcon()), "The configured exec symbol should be used.")
+	assert.Equal(t, "? ", run.StripAnsi(prompt.SetMode(ChatPromptMode).GetIcon()), "The configured chat symbol should be used.")
+	assert.Equal(t, config_icon, run.StripAnsi(u.newPrompt(ConfigPromptMode).GetIcon()), "The default symbol should be used when none is configured.")
+
+	viper.Reset()
+	viper.Set("user_prompt_indicators", map[string]interface{}{"config": map[string]interface{}{"symbol": "#"}})
+	u = newTestUi(t)
+	u.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+	u.startConfig()()
+	require.True(t, u.state.configuring)
+	assert.Contains(t, run.StripAnsi(u.View()), "# ", "The configured config symbol should be rendered before the configuration file is created.")
+}
+
+// testSelectionMode tests that ctrl+o releases the mouse so the terminal selects text and captures it again,
+// and that the mouse wheel does not change the confirmation.
+func testSelectionMode(t *testing.T) {
+	u := NewUi(&UiInput{runMode: ReplMode, promptMode: ExecPromptMode, mouse: true})
+	u.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+
+	_, cmd := u.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	require.NotNil(t, cmd, "The mouse should be released.")
+	assert.True(t, u.state.selecting)
+	assert.Contains(t, run.StripAnsi(u.components.status.View(u.components.renderer)), "select", "The selection mode should be shown in the status bar.")
+
+	_, cmd = u.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	require.NotNil(t, cmd, "The mouse should be captured again.")
+	assert.False(t, u.state.selecting)
+	assert.NotContains(t, run.StripAnsi(u.components.status.View(u.components.renderer)), "select")
+
+	u = newTestUi(t)
+	u.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	assert.False(t, u.state.selecting, "The selection mode should not be toggled without the mouse support.")
+
+	u = NewUi(&UiInput{runMode: ReplMode, promptMode: ExecPromptMode, mouse: true})
+	u.engine = &ai.Engine{}
+	u.config = newTestPlainConfig(t)
+	u.Update(ai.EngineExecOutput{Command: "mkdir build", Executable: true})
+	require.True(t, u.state.confirming)
+	u.Update(tea.MouseMsg{Type: tea.MouseWheelDown})
+	u.Update(tea.MouseMsg{Type: tea.MouseLeft})
+	assert.True(t, u.state.confirming, "The mouse should not answer the confirmation.")
+	assert.False(t, u.state.confirmYes, "The mouse should not change the highlighted choice.")
+}
+
+// testBracketedPaste tests that a paste is inserted into the prompt as a single edit, without being submitted,
+// the large pastes being confirmed first.
+func testBracketedPaste(t *testing.T) {
+	u := newTestUi(t)
+	u.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+
+	u.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("echo one\r\necho two\recho\x07 three"), Paste: true})
+	assert.Equal(t, "echo one\necho two\necho three", u.components.prompt.GetValue(), "The newlines should be kept and the control characters removed.")
+	assert.False(t, u.state.querying, "The paste should not be submitted.")
+
+	u.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("\x03\x04"), Paste: true})
+	assert.Equal(t, "echo one\necho two\necho three", u.components.prompt.GetValue(), "The control characters should not be interpreted as keys.")
+
+	u.components.prompt.SetValue("")
+	large := strings.Repeat("a", 14*1024)
+	u.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(large), Paste: true})
+	assert.Empty(t, u.components.prompt.GetValue(), "A large paste should wait for a confirmation.")
+	assert.Contains(t, run.StripAnsi(u.View()), "paste 14KB into prompt? [y/n]")
+	u.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	assert.Equal(t, large, u.components.prompt.GetValue(), "A confirmed paste should be inserted.")
+
+	u.components.prompt.SetValue("")
+	u.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(large), Paste: true})
+	u.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	assert.Empty(t, u.components.prompt.GetValue(), "A declined paste should be discarded.")
+	assert.Empty(t, u.state.pendingPaste)
+
+	u.state.querying = true
+	u.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ls"), Paste: true})
+	assert.Empty(t, u.components.prompt.GetValue(), "The pastes should be ignored while querying.")
+	u.state.querying = false
+
+	u.state.configuring = true
+	u.components.prompt.SetMode(ConfigPromptMode)
+	u.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("sk-test\n"), Paste: true})
+	assert.Equal(t, "sk-test", u.components.prompt.GetValue(), "The key should be pasted on a single line.")
+}
+
+// testStatusBarVisibility tests that the status bar is displayed under the prompt in the REPL mode,
+// and hidden when the terminal is too small.
+func testStatusBarVisibility(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "terminal-assistant.json"), []byte(`{"openai_key": "test_key"}`), 0600))
+	viper.AddConfigPath(dir)
+	cfg, err := config.NewConfig()
+	require.NoError(t, err)
+
+	u := newTestUi(t)
+	u.config = cfg
+	u.engine = &ai.Engine{}
+	u.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+	u.Update(tea.KeyMsg{Type: tea.KeyTab})
+
+	lines := strings.Split(run.StripAnsi(u.View()), "\n")
+	assert.Len(t, lines, 20, "The view should fill the terminal.")
+	assert.Contains(t, lines[len(lines)-1], "chat", "The status bar should display the prompt mode after a switch.")
+	assert.Contains(t, lines[len(lines)-1], "tokens", "The status bar should be at the bottom.")
+	assert.Contains(t, lines[len(lines)-2], "tab: mode", "The keys should be displayed above the status bar.")
+	assert.Contains(t, lines[len(lines)-3], chat_icon, "The prompt should be above the keys.")
+
+	u.Update(tea.WindowSizeMsg{Width: 80, Height: status_bar_min_height - 1})
+	assert.NotContains(t, run.StripAnsi(u.View()), "tokens", "The status bar should be hidden on small terminals.")
+}
+
+// testInterruptChatStream tests that ctrl+c interrupts the answer being streamed instead of quitting,
+// the answer streamed so far being kept in the conversation.
+func testInterruptChatStream(t *testing.T) {
+	u := newTestUi(t)
+	u.engine = &ai.Engine{}
+	u.state.promptMode = ChatPromptMode
+	u.state.querying = true
+	u.state.buffer = "Once upon "
+
+	_, cmd := u.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	assert.Nil(t, cmd, "The first ctrl+c should not quit.")
+
+	_, cmd = u.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	require.NotNil(t, cmd)
+	assert.Equal(t, tea.Quit(), cmd(), "A second ctrl+c should quit.")
+
+	_, cmd = u.Update(fmt.Errorf("request: %w", ai.ErrInterrupted))
+	require.NotNil(t, cmd)
+	assert.Nil(t, u.state.error, "An interrupted request should not be an error.")
+	assert.False(t, u.state.querying, "The prompt should be restored.")
+}
+
+// testOnExit tests that the functions registered with OnExit are called once on shutdown, the last registered first,
+// a panic being written to the crash log without skipping the other functions.
+func testOnExit(t *testing.T) {
+	homedir.DisableCache = true
+	t.Cleanup(func() { homedir.DisableCache = false })
+	t.Setenv("HOME", t.TempDir())
+
+	u := newTestUi(t)
+	var calls []string
+	u.OnExit(func() { calls = append(calls, "first") }).
+		OnExit(func() { panic("hook failed") }).
+		OnExit(func() { calls = append(calls, "last") })
+
+	require.NotPanics(t, u.Shutdown)
+	assert.Equal(t, []string{"last", "first"}, calls, "The functions should be called in the reverse order.")
+	crashLog, err := os.ReadFile(system.GetCrashLogFile())
+	require.NoError(t, err)
+	assert.Contains(t, string(crashLog), "hook failed", "The panic should be written to the crash log.")
+
+	u.Shutdown()
+	assert.Len(t, calls, 2, "The functions should be called once.")
+}
+
+// testFinishConfigHistory tests that the history of the first session, started by the configuration, is saved on exit.
+func testFinishConfigHistory(t *testing.T) {
+	homedir.DisableCache = true
+	t.Cleanup(func() { homedir.DisableCache = false })
+	t.Setenv("HOME", t.TempDir())
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	u := newTestUi(t)
+	cmd := u.finishConfig("test_key")
+	require.NotNil(t, u.config, "The configuration should be written.")
+	// Read the first message printed by the sequence, the next commands contacting the model
+	var printed string
+	commands := reflect.ValueOf(cmd())
+	for i := 0; i < commands.Len() && printed == ""; i++ {
+		if msg, ok := commands.Index(i).Interface().(tea.Cmd)().(printMsg); ok {
+			printed = run.StripAnsi(string(msg))
+		}
+	}
+	assert.Contains(t, printed, "model        "+u.config.GetAiConfig().GetModel(), "The settings should be summarized.")
+	assert.Contains(t, printed, "config file  "+system.GetConfigFile())
+	u.history.Add("ls -la")
+	u.Shutdown()
+
+	content, err := os.ReadFile(system.GetHistoryFile())
+	require.NoError(t, err, "The history should be saved on exit.")
+	assert.Contains(t, string(content), "ls -la")
+}
+
+// testRequestFailed tests that a transient error of a request is printed above the prompt and sent again with
+// ctrl+g, the other errors not being sent again and the errors of the setup keeping the error view.
+func testRequestFailed(t *testing.T) {
+	u := newTestUi(t)
+	u.engine = &ai.Engine{}
+	u.state.querying = true
+	request := &failedRequest{mode: ExecPromptMode, input: "list files"}
+
+	_, cmd := u.Update(requestFailedMsg{request: request, err: ai.NewTransientError(errors.New("rate limit reached"))})
+	drainPrints(u, cmd)
+	assert.Nil(t, u.state.error, "A transient error should not end the session.")
+	assert.False(t, u.state.querying, "The prompt should be restored.")
+	assert.Equal(t, request, u.state.failedRequest, "The failed request should be kept to be sent again.")
+	assert.Contains(t, run.StripAnsi(u.components.conversation.View(20)), "[error] rate limit reached (ctrl+g to retry)")
+	assert.Contains(t, formatKeyHints(u.keyHints()), "ctrl+g: retry")
+
+	_, cmd = u.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+	assert.NotNil(t, cmd, "ctrl+g should send the request again.")
+	assert.Nil(t, u.state.failedRequest, "The request should be sent again once.")
+	assert.Equal(t, "list files", u.state.lastRequest)
+
+	u.Update(requestFailedMsg{request: request, err: errors.New("invalid field command")})
+	assert.Nil(t, u.state.error, "A request error should not end the session.")
+	assert.Nil(t, u.state.failedRequest, "A request error that is not transient should not be sent again.")
+
+	_, cmd = u.Update(requestFailedMsg{request: request, err: ai.NewInvalidJSONError(errors.New("unexpected end of JSON input"))})
+	drainPrints(u, cmd)
+	assert.Contains(t, run.StripAnsi(u.components.conversation.View(20)), "[model returned invalid JSON] unexpected end of JSON input")
+
+	u.Update(requestFailedMsg{request: request, err: config.NewSetupError(errors.New("invalid key"))})
+	assert.Error(t, u.state.error, "An error of the setup should end the session.")
+}
+
+// testErrorBanner tests that an error is rendered in a banner above the prompt until a key is pressed, the errors
+// ending the session being rendered instead of the user interface.
+func testErrorBanner(t *testing.T) {
+	u := newTestUi(t)
+	u.layout(80, 24)
+	u.state.querying = true
+
+	_, cmd := u.Update(errors.New("disk full"))
+	require.NotNil(t, cmd)
+	assert.NotEqual(t, tea.Quit(), cmd(), "A transient error should not end the session.")
+	assert.False(t, u.state.querying, "The prompt should be restored.")
+	view := run.StripAnsi(u.View())
+	assert.Contains(t, view, "[error] disk full (press any key to dismiss)", "The error should be rendered above the prompt.")
+	assert.Contains(t, view, run.StripAnsi(u.components.prompt.View()), "The prompt should stay visible.")
+
+	u.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")})
+	assert.Nil(t, u.state.error, "A key press should dismiss the error.")
+	assert.NotContains(t, run.StripAnsi(u.View()), "disk full", "The view should return to the prompt.")
+	assert.Contains(t, run.StripAnsi(u.View()), run.StripAnsi(u.components.prompt.View()))
+
+	u.Update(errors.New("disk full"))
+	u.Update(run.NewRunOutput(nil, "[error]", "[ok]"))
+	assert.Nil(t, u.state.error, "A successful action should dismiss the error.")
+
+	_, cmd = u.Update(config.NewSetupError(errors.New("invalid key")))
+	require.NotNil(t, cmd)
+	assert.Equal(t, tea.Quit(), cmd(), "An error of the setup should end the session.")
+	u.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")})
+	assert.Equal(t, "[error] invalid key", run.StripAnsi(u.View()), "The error ending the session should replace the interface.")
+}
+
+// testSearchConversation tests that ctrl+f opens the search in the conversation instead of the prompt, n jumping to
+// the next match once the query is confirmed, and esc returning the focus to the prompt.
+func testSearchConversation(t *testing.T) {
+	u := newTestUi(t)
+	u.layout(80, 24)
+	appendBlocks(u.components.conversation, 1, 3)
+
+	u.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+	assert.True(t, u.components.search.IsActive(), "ctrl+f should open the search.")
+	assert.False(t, u.components.prompt.isFocused(), "The prompt should lose the focus.")
+
+	u.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("block")})
+	assert.Equal(t, 3, u.components.conversation.GetMatchCount(), "The matches should be found while typing.")
+	assert.Equal(t, "", u.components.prompt.GetValue(), "The query should not be typed in the prompt.")
+	assert.Contains(t, run.StripAnsi(u.View()), "3/3")
+
+	u.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	u.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	assert.Equal(t, 0, u.components.conversation.GetCurrentMatch(), "n should jump to the next match.")
+	u.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("N")})
+	assert.Equal(t, 2, u.components.conversation.GetCurrentMatch(), "N should jump to the previous match.")
+	assert.Contains(t, formatKeyHints(u.keyHints()), "n/N: next/previous")
+
+	u.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.False(t, u.components.search.IsActive(), "esc should close the search.")
+	assert.True(t, u.components.prompt.isFocused(), "The focus should return to the prompt.")
+	assert.Equal(t, 0, u.components.conversation.GetMatchCount())
+	assert.Contains(t, run.StripAnsi(u.View()), run.StripAnsi(u.components.prompt.View()))
+}
+
+// testCopyLastAnswer tests that ctrl+y copies the last answer, or its only code block, to the clipboard.
+func testCopyLastAnswer(t *testing.T) {
+	terminal := useTestTerminal(t)
+	t.Setenv("SSH_TTY", "/dev/pts/0")
+	t.Setenv("TMUX", "")
+	u := newTestUi(t)
+
+	_, cmd := u.Update(tea.KeyMsg{Type: tea.KeyCtrlY})
+	require.NotNil(t, cmd)
+	assert.Contains(t, string(cmd().(printMsg)), "[nothing to copy]")
+
+	u.state.lastAnswer = "Use:\n\n```sh\nls -la\n```"
+	_, cmd = u.Update(tea.KeyMsg{Type: tea.KeyCtrlY})
+	require.NotNil(t, cmd)
+	assert.Contains(t, string(cmd().(printMsg)), "[copied 6 chars]", "Only the code block should be copied.")
+	assert.Contains(t, terminal.String(), base64.StdEncoding.EncodeToString([]byte("ls -la")))
+}
+
+// testSaveTranscript tests that ctrl+t saves the transcript of the session to a new file in the configured directory,
+// then appends only the new turns to it, nothing being saved while querying.
+func testSaveTranscript(t *testing.T) {
+	dir := t.TempDir()
+	transcripts := filepath.Join(dir, "transcripts")
+	require.NoError(t, os.WriteFile(
+		filepath.Join(dir, "terminal-assistant.json"),
+		[]byte(fmt.Sprintf(`{"openai_key": "test_key", "user_transcript_dir": %q}`, transcripts)),
+		0600,
+	))
+	viper.AddConfigPath(dir)
+	cfg, err := config.NewConfig()
+	require.NoError(t, err)
+
+	u := newTestUi(t)
+	u.config = cfg
+
+	_, cmd := u.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	require.NotNil(t, cmd)
+	assert.Contains(t, string(cmd().(printMsg)), "[nothing to save]")
+
+	u.addTurn(export.UserRole, "list files")
+	u.addTurn(export.AssistantRole, "`ls -la`")
+	_, cmd = u.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	require.NotNil(t, cmd)
+	path := u.state.transcriptPath
+	assert.Equal(t, transcripts, filepath.Dir(path), "The transcript should be saved in the configured directory.")
+	assert.Contains(t, string(cmd().(printMsg)), "[transcript saved to "+path+"]")
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, export.Markdown(u.state.turns), string(content))
+
+	_, cmd = u.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	assert.Contains(t, string(cmd().(printMsg)), "[transcript up to date")
+
+	u.state.querying = true
+	u.addTurn(export.UserRole, "show disk usage")
+	_, cmd = u.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	assert.Contains(t, string(cmd().(printMsg)), "[wait for the answer", "Nothing should be saved while querying.")
+	assert.Equal(t, 2, u.state.transcriptTurns)
+
+	u.state.querying = false
+	u.addTurn(export.AssistantRole, "`du -sh`")
+	u.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	assert.Equal(t, path, u.state.transcriptPath, "The transcript should be saved to the same file.")
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, export.Markdown(u.state.turns), string(content), "Only the new turns should be appended.")
+
+	require.NoError(t, os.Remove(path))
+	u.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	content, err = os.ReadFile(u.state.transcriptPath)
+	require.NoError(t, err)
+	assert.Equal(t, export.Markdown(u.state.turns), string(content), "A removed transcript should be saved again entirely.")
+
+	u.history.Add("show disk usage")
+	u.Update(run.NewRunOutput(nil, "[error]", "[ok]").WithDuration(1500 * time.Millisecond))
+	last := u.state.turns[len(u.state.turns)-1]
+	assert.Equal(t, "[ok]", last.GetContent())
+	assert.Equal(t, 1500*time.Millisecond, last.GetDuration(), "The duration of the command should be kept in its turn.")
+	assert.Equal(t, 1500*time.Millisecond, u.history.GetDuration(u.history.Len()-1), "The duration should annotate the input.")
+	u.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	content, err = os.ReadFile(u.state.transcriptPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "ran in 1.5s", "The duration should be written in the transcript.")
+}
+
+// testEchoInput tests that the echoed inputs carry the indicator and the label of their prompt mode, and that
+// the answers are marked with their mode.
+func testEchoInput(t *testing.T) {
+	u := newTestUi(t)
+	u.components.prompt.SetIndicator(ChatPromptMode, config.NewPromptIndicator("?", ""))
+
+	testCases := []struct {
+		mode     PromptMode
+		value    string
+		expected string
+	}{
+		{ExecPromptMode, "list files", "🚀 > list files · exec"},
+		{ChatPromptMode, "what is ls?", "? what is ls? · chat"},
+		{ConfigPromptMode, "key", "🔒 > key · config"},
+	}
+	for _, tc := range testCases {
+		u.components.prompt.SetMode(tc.mode).SetValue(tc.value)
+		assert.Equal(t, tc.expected, run.StripAnsi(u.echoInput()), "The echoed %s input should be labeled.", tc.mode)
+	}
+
+	assert.Equal(t, "  ┃ chat answer", run.StripAnsi(u.answerMarker(ChatPromptMode)))
+	assert.Equal(t, "  ┃ exec explanation", run.StripAnsi(u.answerMarker(ExecPromptMode)))
+
+	require.NoError(t, u.components.renderer.SetTheme(minimal_theme))
+	u.components.prompt.SetMode(ExecPromptMode).SetValue("list files")
+	assert.Equal(t, "🚀 > list files", run.StripAnsi(u.echoInput()), "The minimal theme should not label the inputs.")
+	assert.Empty(t, u.answerMarker(ExecPromptMode), "The minimal theme should not mark the answers.")
+}
+
+// testEmptyInputHint tests that enter on an empty input prints a tip, the tips being cycled and rate limited.
+func testEmptyInputHint(t *testing.T) {
+	u := newTestUi(t)
+
+	_, cmd := u.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	drainPrints(u, cmd)
+	assert.Contains(t, run.StripAnsi(u.components.conversation.View(20)), `[tip] type what you want to do, like "list the big files"`)
+
+	_, cmd = u.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	drainPrints(u, cmd)
+	assert.Equal(t, 1, u.state.emptyHints, "Pressing enter again at once should not print another tip.")
+
+	u.state.lastEmptyHint = time.Now().Add(-empty_hint_interval)
+	_, cmd = u.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	drainPrints(u, cmd)
+	assert.Contains(t, run.StripAnsi(u.components.conversation.View(20)), "[tip] tab switches to the chat mode", "The next tip should be printed.")
+
+	u.state.lastEmptyHint = time.Time{}
+	u.state.confirming = true
+	u.state.command = "ls"
+	u.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, 2, u.state.emptyHints, "Enter should answer the confirmation instead of printing a tip.")
+	assert.False(t, u.state.confirming, "Enter should answer No by default.")
+}
+
+// testNewTranscriptPath tests that the transcript files are named after the time, without overwriting one.
+func testNewTranscriptPath(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "transcripts")
+	now := time.Date(2026, 1, 31, 15, 45, 2, 0, time.UTC)
+
+	path, err := newTranscriptPath(dir, now)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "session-2026-01-31-154502.md"), path)
+	require.NoError(t, os.WriteFile(path, nil, 0600))
+
+	path, err = newTranscriptPath(dir, now)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "session-2026-01-31-154502-2.md"), path, "An existing file should not be overwritten.")
+}
+
+// testFilterHistoryByMode tests that the arrows navigate only the inputs of the prompt mode when enabled.
+func testFilterHistoryByMode(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		filepath.Join(dir, "terminal-assistant.json"),
+		[]byte(`{"openai_key": "test_key", "user_filter_history_by_mode": true}`),
+		0600,
+	))
+	viper.AddConfigPath(dir)
+	cfg, err := config.NewConfig()
+	require.NoError(t, err)
+
+	u := newTestUi(t)
+	u.config = cfg
+	u.engine = &ai.Engine{}
+	u.history.AddWithMode("ls", "exec").AddWithMode("what is ls?", "chat").AddWithMode("pwd", "exec")
+
+	u.Update(tea.KeyMsg{Type: tea.KeyTab})
+	u.Update(tea.KeyMsg{Type: tea.KeyUp})
+	assert.Equal(t, "what is ls?", u.components.prompt.GetValue(), "Only the chat inputs should be navigated.")
+
+	u.Update(tea.KeyMsg{Type: tea.KeyTab})
+	u.history.AddWithMode("date", "exec")
+	u.Update(tea.KeyMsg{Type: tea.KeyUp})
+	u.Update(tea.KeyMsg{Type: tea.KeyUp})
+	assert.Equal(t, "pwd", u.components.prompt.GetValue(), "Only the exec inputs should be navigated.")
+}
+
+// testHistoryPrefixSearch tests that only the inputs starting with the typed prefix are navigated,
+// the prefix being kept while navigating and given back after the most recent input.
+func testHistoryPrefixSearch(t *testing.T) {
+	u := newTestUi(t)
+	u.history.Add("git status").Add("ls").Add("git log").Add("pwd")
+
+	u.components.prompt.SetValue("git")
+	u.Update(tea.KeyMsg{Type: tea.KeyUp})
+	assert.Equal(t, "git log", u.components.prompt.GetValue())
+	u.Update(tea.KeyMsg{Type: tea.KeyUp})
+	assert.Equal(t, "git status", u.components.prompt.GetValue(), "The typed prefix should be kept while navigating.")
+	for i := 0; i < 3 && u.components.prompt.GetValue() != "git log"; i++ {
+		u.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	assert.Equal(t, "git log", u.components.prompt.GetValue(), "The inputs without the prefix should be skipped.")
+	u.Update(tea.KeyMsg{Type: tea.KeyDown})
+	assert.Equal(t, "git", u.components.prompt.GetValue(), "The typed prefix should be given back after the most recent input.")
+
+	u = newTestUi(t)
+	u.history.Add("git status").Add("ls")
+	u.Update(tea.KeyMsg{Type: tea.KeyUp})
+	u.Update(tea.KeyMsg{Type: tea.KeyUp})
+	assert.Equal(t, "git status", u.components.prompt.GetValue(), "All the inputs should be navigated from an empty prompt.")
+}
+
+// testViEditingMode tests that the vi keymap of the configuration edits the prompt, esc entering its normal mode
+// only while the prompt is displayed.
+func testViEditingMode(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		filepath.Join(dir, "terminal-assistant.json"),
+		[]byte(`{"openai_key": "test_key", "user_editing_mode": "vi"}`),
+		0600,
+	))
+	viper.AddConfigPath(dir)
+	cfg, err := config.NewConfig()
+	require.NoError(t, err)
+
+	u := newTestUi(t)
+	u.config = cfg
+	u.components.prompt = u.newPrompt(ExecPromptMode).SetValue("ls -la")
+
+	u.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, vi_normal_mode, u.components.prompt.GetViMode(), "Esc should enter the normal mode.")
+	assert.Equal(t, "i: insert", u.keyHints()[0].String())
+	u.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	assert.Equal(t, "ls -l", u.components.prompt.GetValue(), "The keys of the normal mode should edit the prompt.")
+
+	u.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	u.state.querying = true
+	u.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, vi_insert_mode, u.components.prompt.GetViMode(), "Esc should not enter the normal mode while the prompt is hidden.")
+}
+
+// testAutosuggestion tests that the most recent input of the history starting with the typed text is suggested,
+// cycled with tab and accepted with the right arrow, and never suggested while confirming or when disabled.
+func testAutosuggestion(t *testing.T) {
+	newConfig := func(content string) *config.Config {
+		viper.Reset()
+		t.Cleanup(viper.Reset)
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "terminal-assistant.json"), []byte(content), 0600))
+		viper.AddConfigPath(dir)
+		cfg, err := config.NewConfig()
+		require.NoError(t, err)
+
+		return cfg
+	}
+
+	u := newTestUi(t)
+	u.config = newConfig(`{"openai_key": "test_key"}`)
+	u.history.AddWithMode("git status", "exec").AddWithMode("git log", "exec").AddWithMode("git help", "chat")
+	u.state.filteredHistory = u.history.Filter("exec")
+
+	u.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("git")})
+	assert.Equal(t, " log", u.components.prompt.GetSuggestion(), "The inputs of another prompt mode should be skipped.")
+	assert.Equal(t, "→: accept", u.keyHints()[0].String())
+	u.Update(tea.KeyMsg{Type: tea.KeyTab})
+	assert.Equal(t, " status", u.components.prompt.GetSuggestion(), "Tab should cycle through the suggestions.")
+	assert.Equal(t, ExecPromptMode, u.state.promptMode, "Tab should not switch the prompt mode.")
+	assert.Equal(t, "tab: next", u.keyHints()[1].String())
+	u.Update(tea.KeyMsg{Type: tea.KeyTab})
+	u.Update(tea.KeyMsg{Type: tea.KeyRight})
+	assert.Equal(t, "git log", u.components.prompt.GetValue(), "The right arrow should accept the suggestion.")
+
+	u.components.prompt.SetValue("")
+	u.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/hi")})
+	assert.Equal(t, "story", u.components.prompt.GetSuggestion(), "The slash commands should be completed.")
+
+	u.components.prompt.SetValue("git s")
+	u.state.confirming = true
+	u.Update(nil)
+	assert.Empty(t, u.components.prompt.GetSuggestion(), "Nothing should be suggested while confirming.")
+
+	u = newTestUi(t)
+	u.config = newConfig(`{"openai_key": "test_key", "user_autosuggest": false}`)
+	u.history.Add("git status")
+	u.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("git")})
+	assert.Empty(t, u.components.prompt.GetSuggestion(), "Nothing should be suggested when disabled.")
+}
+
+// testSettingsChanges tests that the fields changed by the edition of the settings are listed under their status.
+func testSettingsChanges(t *testing.T) {
+	u := newTestUi(t)
+	u.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+
+	_, cmd := u.Update(settingsMsg{
+		output:  run.NewRunOutput(nil, "", "[settings ok]"),
+		changes: []config.ConfigChange{{Field: "user.Mouse", OldValue: true, NewValue: false}},
+	})
+	drainPrints(u, cmd)
+
+	view := run.StripAnsi(u.View())
+	assert.Contains(t, view, "[settings ok]")
+	assert.Contains(t, view, "[changed: user.Mouse: true → false]")
+	assert.Less(t, strings.Index(view, "[settings ok]"), strings.Index(view, "[changed:"), "The changes should follow the status.")
+}
+
+// drainPrints runs a command and the commands it batches or sequences, displaying the content they print.
+func drainPrints(u *Ui, cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+
+	msg := cmd()
+	switch msg.(type) {
+	case printMsg, printMarkdownMsg:
+		u.Update(msg)
+		return
+	}
+	// The sequences of commands are not exported by bubbletea
+	if value := reflect.ValueOf(msg); value.Kind() == reflect.Slice {
+		for i := 0; i < value.Len(); i++ {
+			if cmd, ok := value.Index(i).Interface().(tea.Cmd); ok {
+				drainPrints(u, cmd)
+			}
+		}
+	}
+}
+
+// testShellAliases tests that the proposed commands keep the aliases of the user, which are defined for the shell
+// running them, while the risk is estimated on the expanded command.
+func testShellAliases(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "terminal-assistant.json"), []byte(`{"openai_key": "test_key"}`), 0600))
+	viper.AddConfigPath(dir)
+	cfg, err := config.NewConfig()
+	require.NoError(t, err)
+
+	u := newTestUi(t)
+	u.config = cfg
+	u.engine = &ai.Engine{}
+	u.aliases = run.Aliases{"cleanall": "rm -rf /tmp/build"}
+	u.Update(ai.EngineExecOutput{Command: "cleanall", Executable: true})
+	require.True(t, u.state.confirming, "The command should be confirmed.")
+	assert.Equal(t, "cleanall", u.state.command, "The alias should not be expanded in the command.")
+	assert.Contains(t, run.StripAnsi(u.View()), "critical risk", "The risk should be estimated on the expanded command.")
+	assert.Contains(t, u.commandPreamble(), "alias cleanall='rm -rf /tmp/build'", "The alias should be defined for the shell.")
+}
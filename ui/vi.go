@@ -0,0 +1,253 @@
+package ui
+
+import (
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Constants for the editing modes of the prompt.
+const (
+	editing_mode_emacs = "emacs"
+	editing_mode_vi    = "vi"
+)
+
+// Constants for the names of the modes of the vi keymap, displayed before the prompt.
+const (
+	vi_insert_mode = "INSERT"
+	vi_normal_mode = "NORMAL"
+)
+
+// viRuneClass is the class of a rune for the word motions of the vi keymap: the words are runs of letters, digits
+// and underscores, or runs of the other non-blank characters, separated by blanks.
+type viRuneClass int
+
+const (
+	viBlank viRuneClass = iota
+	viWord
+	viPunctuation
+)
+
+// getViRuneClass is a function that returns the class of a rune for the word motions.
+func getViRuneClass(r rune) viRuneClass {
+	switch {
+	case unicode.IsSpace(r):
+		return viBlank
+	case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+		return viWord
+	default:
+		return viPunctuation
+	}
+}
+
+// viBuffer is a struct that represents the content of the multi-line input edited by the vi keymap,
+// its lines and the position of the cursor, the column being counted in runes.
+type viBuffer struct {
+	lines []string
+	row   int
+	col   int
+}
+
+// line is a method on the viBuffer struct that returns the runes of the line of the cursor.
+func (b *viBuffer) line() []rune {
+	return []rune(b.lines[b.row])
+}
+
+// setLine is a method on the viBuffer struct that replaces the line of the cursor.
+func (b *viBuffer) setLine(line []rune) {
+	b.lines[b.row] = string(line)
+}
+
+// clamp is a method on the viBuffer struct that keeps the cursor in the line, on its last character in the normal
+// mode or after it in the insert mode.
+func (b *viBuffer) clamp(normal bool) {
+	last := len(b.line())
+	if normal && last > 0 {
+		last--
+	}
+	if b.col > last {
+		b.col = last
+	}
+	if b.col < 0 {
+		b.col = 0
+	}
+}
+
+// viKeymap is a struct that implements a vi-style modal editing of the multi-line input of the prompt: the insert
+// mode keeps the default keys and esc enters the normal mode, where the keys move the cursor and edit the line.
+type viKeymap struct {
+	normal  bool   // Whether the keymap is in the normal mode, the insert mode being the default.
+	pending string // The operator waiting for its motion, like "d" or "c", empty if none.
+}
+
+// getMode is a method on the viKeymap struct that returns the name of the current mode, like "NORMAL".
+func (k *viKeymap) getMode() string {
+	if k.normal {
+		return vi_normal_mode
+	}
+
+	return vi_insert_mode
+}
+
+// handle is a method on the viKeymap struct that applies a key to the buffer. It returns false for the keys left to
+// the input and the user interface: all the keys of the insert mode but esc, and enter, the arrows and the control
+// keys of the normal mode. Enter goes back to the insert mode, so the next input starts in it.
+func (k *viKeymap) handle(msg tea.KeyMsg, b *viBuffer) bool {
+	if !k.normal {
+		if msg.Type != tea.KeyEsc {
+			return false
+		}
+		// The cursor moves back on the last inserted character, like in vi
+		k.normal = true
+		b.col--
+		b.clamp(true)
+		return true
+	}
+
+	switch msg.Type {
+	case tea.KeyEnter:
+		k.normal = false
+		k.pending = ""
+		return false
+	case tea.KeyEsc:
+		k.pending = ""
+		return true
+	case tea.KeyBackspace:
+		k.pending = ""
+		b.col--
+		b.clamp(true)
+		return true
+	case tea.KeyRunes, tea.KeySpace:
+		if msg.Alt {
+			return false
+		}
+	default:
+		return false
+	}
+
+	key := string(msg.Runes)
+	if k.pending != "" {
+		operator := k.pending
+		k.pending = ""
+		k.applyOperator(operator+key, b)
+		b.clamp(k.normal)
+		return true
+	}
+
+	line := b.line()
+	switch key {
+	case "h":
+		b.col--
+	case "l":
+		b.col++
+	case "0":
+		b.col = 0
+	case "$":
+		b.col = len(line)
+	case "w":
+		b.col = viWordForward(line, b.col)
+	case "b":
+		b.col = viWordBackward(line, b.col)
+	case "x":
+		if b.col < len(line) {
+			b.setLine(append(line[:b.col:b.col], line[b.col+1:]...))
+		}
+	case "d", "c":
+		k.pending = key
+	case "i":
+		k.normal = false
+	case "a":
+		k.normal = false
+		if len(line) > 0 {
+			b.col++
+		}
+	case "I":
+		k.normal = false
+		b.col = 0
+	case "A":
+		k.normal = false
+		b.col = len(line)
+	}
+	b.clamp(k.normal)
+
+	// The other characters are ignored, never inserted in the normal mode
+	return true
+}
+
+// applyOperator is a method on the viKeymap struct that applies an operator followed by its motion, "dd" deleting
+// the line and "cw" changing the end of the word of the cursor. The unknown commands are ignored.
+func (k *viKeymap) applyOperator(command string, b *viBuffer) {
+	switch command {
+	case "dd":
+		if len(b.lines) == 1 {
+			b.lines[0] = ""
+		} else {
+			b.lines = append(b.lines[:b.row], b.lines[b.row+1:]...)
+			if b.row >= len(b.lines) {
+				b.row = len(b.lines) - 1
+			}
+		}
+		b.col = 0
+	case "cw":
+		line := b.line()
+		if b.col < len(line) {
+			end := viWordEnd(line, b.col)
+			b.setLine(append(line[:b.col:b.col], line[end:]...))
+		}
+		k.normal = false
+	}
+}
+
+// viWordForward is a function that returns the column of the start of the next word of a line, like the w motion,
+// or the end of the line if there is none.
+func viWordForward(line []rune, col int) int {
+	if col >= len(line) {
+		return len(line)
+	}
+
+	i := col
+	if class := getViRuneClass(line[i]); class != viBlank {
+		for i < len(line) && getViRuneClass(line[i]) == class {
+			i++
+		}
+	}
+	for i < len(line) && getViRuneClass(line[i]) == viBlank {
+		i++
+	}
+
+	return i
+}
+
+// viWordBackward is a function that returns the column of the start of the word before the cursor, or of the word of
+// the cursor if it is not on its first character, like the b motion.
+func viWordBackward(line []rune, col int) int {
+	i := col
+	if i > len(line) {
+		i = len(line)
+	}
+	for i > 0 && getViRuneClass(line[i-1]) == viBlank {
+		i--
+	}
+	if i == 0 {
+		return 0
+	}
+
+	class := getViRuneClass(line[i-1])
+	for i > 0 && getViRuneClass(line[i-1]) == class {
+		i--
+	}
+
+	return i
+}
+
+// viWordEnd is a function that returns the column after the end of the run of characters of the class of the cursor,
+// the range changed by the cw command which, like in vi, keeps the blanks following the word.
+func viWordEnd(line []rune, col int) int {
+	class := getViRuneClass(line[col])
+	i := col
+	for i < len(line) && getViRuneClass(line[i]) == class {
+		i++
+	}
+
+	return i
+}
@@ -0,0 +1,114 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUIVi(t *testing.T) {
+	t.Run("WordMotions", testViWordMotions)
+	t.Run("Keymap", testViKeymap)
+}
+
+// viKeys returns the key messages typing a sequence of characters, like "dd".
+func viKeys(keys string) []tea.KeyMsg {
+	msgs := []tea.KeyMsg{}
+	for _, r := range keys {
+		msgs = append(msgs, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+
+	return msgs
+}
+
+// testViWordMotions tests the columns reached by the word motions.
+func testViWordMotions(t *testing.T) {
+	line := []rune("git commit -m 'fix: typo'")
+
+	testCases := []struct {
+		name     string
+		motion   func([]rune, int) int
+		col      int
+		expected int
+	}{
+		{"ForwardFromWord", viWordForward, 0, 4},
+		{"ForwardFromMiddle", viWordForward, 5, 11},
+		{"ForwardToPunctuation", viWordForward, 11, 12},
+		{"ForwardFromBlank", viWordForward, 3, 4},
+		{"ForwardAtEnd", viWordForward, 24, 25},
+		{"BackwardFromWord", viWordBackward, 4, 0},
+		{"BackwardFromMiddle", viWordBackward, 7, 4},
+		{"BackwardToPunctuation", viWordBackward, 12, 11},
+		{"BackwardAtStart", viWordBackward, 0, 0},
+		{"EndOfWord", viWordEnd, 4, 10},
+		{"EndOfPunctuation", viWordEnd, 14, 15},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.motion(line, tc.col))
+		})
+	}
+}
+
+// testViKeymap tests the motions and edits of the normal mode against a buffer, and the switches between the modes.
+func testViKeymap(t *testing.T) {
+	testCases := []struct {
+		name     string
+		lines    []string
+		row      int
+		col      int
+		keys     string
+		expected []string
+		col2     int
+		normal   bool
+	}{
+		{"Left", []string{"echo hello"}, 0, 10, "hh", []string{"echo hello"}, 7, true},
+		{"LeftAtStart", []string{"echo"}, 0, 1, "hhh", []string{"echo"}, 0, true},
+		{"Right", []string{"echo"}, 0, 0, "ll", []string{"echo"}, 2, true},
+		{"RightAtEnd", []string{"echo"}, 0, 4, "lll", []string{"echo"}, 3, true},
+		{"Start", []string{"echo hello"}, 0, 10, "0", []string{"echo hello"}, 0, true},
+		{"End", []string{"echo hello"}, 0, 1, "$", []string{"echo hello"}, 9, true},
+		{"WordForward", []string{"echo hello world"}, 0, 1, "0ww", []string{"echo hello world"}, 11, true},
+		{"WordBackward", []string{"echo hello world"}, 0, 16, "bb", []string{"echo hello world"}, 5, true},
+		{"DeleteCharacter", []string{"echo hello"}, 0, 10, "0x", []string{"cho hello"}, 0, true},
+		{"DeleteLine", []string{"first", "second", "third"}, 1, 3, "dd", []string{"first", "third"}, 0, true},
+		{"DeleteLastLine", []string{"first", "second"}, 1, 3, "dd", []string{"first"}, 0, true},
+		{"DeleteSingleLine", []string{"echo hello"}, 0, 5, "dd", []string{""}, 0, true},
+		{"ChangeWord", []string{"echo hello world"}, 0, 16, "bbcw", []string{"echo  world"}, 5, false},
+		{"UnknownOperator", []string{"echo"}, 0, 4, "dz", []string{"echo"}, 3, true},
+		{"IgnoredCharacter", []string{"echo"}, 0, 4, "z", []string{"echo"}, 3, true},
+		{"Insert", []string{"echo"}, 0, 4, "hi", []string{"echo"}, 2, false},
+		{"Append", []string{"echo"}, 0, 4, "0a", []string{"echo"}, 1, false},
+		{"AppendAtEnd", []string{"echo"}, 0, 4, "0A", []string{"echo"}, 4, false},
+		{"InsertAtStart", []string{"echo"}, 0, 4, "I", []string{"echo"}, 0, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			k := &viKeymap{}
+			b := &viBuffer{lines: tc.lines, row: tc.row, col: tc.col}
+
+			assert.True(t, k.handle(tea.KeyMsg{Type: tea.KeyEsc}, b), "Esc should enter the normal mode.")
+			for _, msg := range viKeys(tc.keys) {
+				assert.True(t, k.handle(msg, b), "The characters should be handled in the normal mode.")
+			}
+
+			assert.Equal(t, tc.expected, b.lines)
+			assert.Equal(t, tc.col2, b.col)
+			assert.Equal(t, tc.normal, k.normal)
+		})
+	}
+
+	k := &viKeymap{}
+	b := &viBuffer{lines: []string{"echo"}, col: 4}
+	assert.False(t, k.handle(viKeys("x")[0], b), "The characters should be inserted in the insert mode.")
+	k.handle(tea.KeyMsg{Type: tea.KeyEsc}, b)
+	assert.Equal(t, vi_normal_mode, k.getMode())
+	assert.False(t, k.handle(tea.KeyMsg{Type: tea.KeyEnter}, b), "Enter should still submit the input.")
+	assert.Equal(t, vi_insert_mode, k.getMode(), "Enter should go back to the insert mode.")
+	k.handle(tea.KeyMsg{Type: tea.KeyEsc}, b)
+	assert.False(t, k.handle(tea.KeyMsg{Type: tea.KeyCtrlC}, b), "The control keys should keep their role.")
+	assert.False(t, k.handle(tea.KeyMsg{Type: tea.KeyUp}, b), "The arrows should keep their role.")
+}
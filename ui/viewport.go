@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// viewport_help is the help displayed under the viewport.
+const viewport_help = "↑/↓ pgup/pgdn: scroll · q/esc: close"
+
+// Viewport is a struct that represents a scrollable view of a long content in the user interface.
+type Viewport struct {
+	visible  bool           // Whether the viewport is displayed.
+	viewport viewport.Model // The viewport model.
+}
+
+// NewViewport is a function that creates a new Viewport instance.
+func NewViewport(width int, height int) *Viewport {
+	return &Viewport{
+		visible:  false,
+		viewport: viewport.New(width, viewportHeight(height)),
+	}
+}
+
+// SetMouseWheelEnabled is a method on the Viewport struct that sets whether the mouse wheel scrolls the viewport.
+func (v *Viewport) SetMouseWheelEnabled(enabled bool) *Viewport {
+	v.viewport.MouseWheelEnabled = enabled
+
+	return v
+}
+
+// IsMouseWheelEnabled is a method on the Viewport struct that returns whether the mouse wheel scrolls the viewport.
+func (v *Viewport) IsMouseWheelEnabled() bool {
+	return v.viewport.MouseWheelEnabled
+}
+
+// Show is a method on the Viewport struct that displays a content, scrolled to the top.
+func (v *Viewport) Show(content string) *Viewport {
+	v.visible = true
+	v.viewport.SetContent(content)
+	v.viewport.GotoTop()
+
+	return v
+}
+
+// Hide is a method on the Viewport struct that stops displaying the viewport.
+func (v *Viewport) Hide() *Viewport {
+	v.visible = false
+	v.viewport.SetContent("")
+
+	return v
+}
+
+// IsVisible is a method on the Viewport struct that returns whether the viewport is displayed.
+func (v *Viewport) IsVisible() bool {
+	return v.visible
+}
+
+// Resize is a method on the Viewport struct that sets the dimensions of the viewport.
+func (v *Viewport) Resize(width int, height int) *Viewport {
+	v.viewport.Width = width
+	v.viewport.Height = viewportHeight(height)
+
+	return v
+}
+
+// Update is a method on the Viewport struct that updates the viewport model with a message.
+func (v *Viewport) Update(msg tea.Msg) (*Viewport, tea.Cmd) {
+	var updateCmd tea.Cmd
+	v.viewport, updateCmd = v.viewport.Update(msg)
+
+	return v, updateCmd
+}
+
+// View is a method on the Viewport struct that returns a string representation of the viewport and its help.
+func (v *Viewport) View(renderer *Renderer) string {
+	return fmt.Sprintf(
+		"%s\n%s",
+		v.viewport.View(),
+		renderer.RenderHelp(fmt.Sprintf("%s · %3.f%%", viewport_help, v.viewport.ScrollPercent()*100)),
+	)
+}
+
+// viewportHeight is a function that returns the height of the viewport, keeping a line for the help.
+func viewportHeight(height int) int {
+	if height <= 1 {
+		return 1
+	}
+
+	return height - 1
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUIViewport(t *testing.T) {
+	t.Run("ShowHide", testViewportShowHide)
+	t.Run("Scroll", testViewportScroll)
+	t.Run("MouseWheel", testViewportMouseWheel)
+}
+
+// testViewportShowHide tests the Show and Hide methods of the Viewport struct.
+func testViewportShowHide(t *testing.T) {
+	v := NewViewport(80, 10)
+	assert.False(t, v.IsVisible(), "The viewport should be hidden.")
+
+	v.Show("hello")
+	assert.True(t, v.IsVisible(), "The viewport should be visible.")
+	assert.Contains(t, v.View(NewRenderer(glamour.WithAutoStyle())), "hello", "The viewport should display the content.")
+
+	v.Hide()
+	assert.False(t, v.IsVisible(), "The viewport should be hidden.")
+}
+
+// testViewportScroll tests the scrolling of the Viewport struct.
+func testViewportScroll(t *testing.T) {
+	lines := []string{}
+	for i := 0; i < 50; i++ {
+		lines = append(lines, strings.Repeat("x", i%10)+"line")
+	}
+	lines[0] = "first"
+	lines[49] = "last"
+
+	r := NewRenderer(glamour.WithAutoStyle())
+	v := NewViewport(80, 10).Show(strings.Join(lines, "\n"))
+	assert.Contains(t, v.View(r), "first", "The viewport should start at the top.")
+
+	for i := 0; i < 10; i++ {
+		v, _ = v.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	}
+	assert.Contains(t, v.View(r), "last", "The viewport should scroll to the bottom.")
+	assert.NotContains(t, v.View(r), "first", "The top should be scrolled out.")
+}
+
+// testViewportMouseWheel tests the scrolling of the Viewport struct with the mouse wheel.
+func testViewportMouseWheel(t *testing.T) {
+	lines := []string{"first"}
+	for i := 0; i < 20; i++ {
+		lines = append(lines, "line")
+	}
+
+	r := NewRenderer(glamour.WithAutoStyle())
+	v := NewViewport(80, 10).SetMouseWheelEnabled(false).Show(strings.Join(lines, "\n"))
+	assert.False(t, v.IsMouseWheelEnabled(), "The mouse wheel should be disabled.")
+
+	v, _ = v.Update(tea.MouseMsg{Type: tea.MouseWheelDown})
+	assert.Contains(t, v.View(r), "first", "The viewport should ignore the mouse wheel.")
+
+	v.SetMouseWheelEnabled(true)
+	v, _ = v.Update(tea.MouseMsg{Type: tea.MouseWheelDown})
+	assert.NotContains(t, v.View(r), "first", "The viewport should scroll with the mouse wheel.")
+}